@@ -0,0 +1,69 @@
+package adcptest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/adcptest"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/claude"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeIDE(t *testing.T) {
+	ide := &adcptest.FakeIDE{}
+	recipe := adcptest.Recipe(adcptest.Ide(adcptest.IdeSpec{
+		Commands: []*adcp.Command{adcptest.TextCommand("review", "Review")},
+		Allow:    []*adcp.OperationPermission{adcptest.Bash("go test:*")},
+	}))
+
+	_, err := recipes.New(ide).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	require.Len(t, ide.Calls(), 1)
+	assert.Equal(t, "review", ide.Calls()[0].Ide.GetCommands().GetEntries()[0].GetName())
+	assert.NotNil(t, ide.Calls()[0].GenerationContext)
+}
+
+func TestHTTPStubAndMemoryTarget(t *testing.T) {
+	stub := adcptest.NewHTTPStub().Respond("https://example.com/guide.md", http.StatusOK, "# Guide\n")
+	target := adcptest.NewMemoryTarget(map[string]string{
+		".mcp.json": `{"mcpServers": {"existing": {"type": "http", "url": "https://example.com/mcp"}}}`,
+	})
+	recipe := adcptest.Recipe(
+		adcptest.Ide(adcptest.IdeSpec{Servers: map[string]*adcp.McpServer{"devplan": adcptest.StdioServer("devplan mcp")}}),
+		adcptest.GithubContext("docs/guide.md", "https://example.com/guide.md"),
+		adcptest.TextContext("../outside.md", "nope"),
+	)
+
+	r := recipes.New(claude.NewIDEProvider(), recipes.WithHTTPClient(stub.Client()), recipes.WithFS(target.FS()))
+	res, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/guide.md"}, stub.Requests())
+
+	err = target.Persist(context.Background(), res)
+	assert.ErrorIs(t, err, core.ErrPathEscapesRoot)
+
+	recipe.GetContext().SetEntries(recipe.GetContext().GetEntries()[:1])
+	res, err = r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	require.NoError(t, target.Persist(context.Background(), res))
+
+	guide, ok := target.File("docs/guide.md")
+	assert.True(t, ok)
+	assert.Equal(t, "# Guide\n", guide)
+	mcp, _ := target.File(".mcp.json")
+	assert.Contains(t, mcp, `"existing"`)
+	assert.Contains(t, mcp, `"devplan"`)
+}
+
+func TestHTTPStub_NotFound(t *testing.T) {
+	stub := adcptest.NewHTTPStub()
+	resp, err := stub.Client().Get("https://example.com/missing")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,83 @@
+package adcptest
+
+import "github.com/devplaninc/adcp/clients/go/adcp"
+
+// TextContext returns a context entry with inline content.
+func TextContext(path, text string) *adcp.ContextEntry {
+	return adcp.ContextEntry_builder{Path: path, From: adcp.ContextFrom_builder{Text: &text}.Build()}.Build()
+}
+
+// CmdContext returns a context entry produced by a shell command.
+func CmdContext(path, cmd string) *adcp.ContextEntry {
+	return adcp.ContextEntry_builder{Path: path, From: adcp.ContextFrom_builder{Cmd: &cmd}.Build()}.Build()
+}
+
+// GithubContext returns a context entry fetched from a GitHub URL.
+func GithubContext(path, url string) *adcp.ContextEntry {
+	return adcp.ContextEntry_builder{
+		Path: path,
+		From: adcp.ContextFrom_builder{Github: adcp.GitReference_builder{Path: url}.Build()}.Build(),
+	}.Build()
+}
+
+// TextCommand returns a command with an inline body.
+func TextCommand(name, text string) *adcp.Command {
+	return adcp.Command_builder{Name: name, From: adcp.CommandFrom_builder{Text: &text}.Build()}.Build()
+}
+
+// StdioServer returns a stdio MCP server running command.
+func StdioServer(command string) *adcp.McpServer {
+	return adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: command}.Build()}.Build()
+}
+
+// HTTPServer returns an HTTP MCP server at url.
+func HTTPServer(url string) *adcp.McpServer {
+	return adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: url}.Build()}.Build()
+}
+
+// Bash returns a Bash permission.
+func Bash(pattern string) *adcp.OperationPermission {
+	return adcp.OperationPermission_builder{Bash: &pattern}.Build()
+}
+
+// Read returns a Read permission.
+func Read(pattern string) *adcp.OperationPermission {
+	return adcp.OperationPermission_builder{Read: &pattern}.Build()
+}
+
+// Write returns a Write permission.
+func Write(pattern string) *adcp.OperationPermission {
+	return adcp.OperationPermission_builder{Write: &pattern}.Build()
+}
+
+// IdeSpec lists the parts of an IDE configuration. Empty parts are left unset.
+type IdeSpec struct {
+	Commands []*adcp.Command
+	Servers  map[string]*adcp.McpServer
+	Allow    []*adcp.OperationPermission
+	Deny     []*adcp.OperationPermission
+}
+
+// Ide builds an IDE configuration from spec.
+func Ide(spec IdeSpec) *adcp.Ide {
+	b := adcp.Ide_builder{}
+	if len(spec.Commands) > 0 {
+		b.Commands = adcp.Commands_builder{Entries: spec.Commands}.Build()
+	}
+	if len(spec.Servers) > 0 {
+		b.Mcp = adcp.Mcp_builder{Servers: spec.Servers}.Build()
+	}
+	if len(spec.Allow) > 0 || len(spec.Deny) > 0 {
+		b.Permissions = adcp.Permissions_builder{Allow: spec.Allow, Deny: spec.Deny}.Build()
+	}
+	return b.Build()
+}
+
+// Recipe builds a recipe from context entries and an optional IDE configuration.
+func Recipe(ide *adcp.Ide, context ...*adcp.ContextEntry) *adcp.Recipe {
+	b := adcp.Recipe_builder{Ide: ide}
+	if len(context) > 0 {
+		b.Context = adcp.Context_builder{Entries: context}.Build()
+	}
+	return b.Build()
+}
@@ -0,0 +1,3 @@
+// Package adcptest provides fakes and in-memory fixtures for testing code that materializes
+// recipes, without hitting the network or the filesystem.
+package adcptest
@@ -0,0 +1,67 @@
+package adcptest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HTTPStub serves canned responses by URL to an *http.Client. Unknown URLs get a 404.
+type HTTPStub struct {
+	mu        sync.Mutex
+	responses map[string]stubResponse
+	requests  []string
+}
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+// NewHTTPStub returns a stub without any responses.
+func NewHTTPStub() *HTTPStub {
+	return &HTTPStub{responses: map[string]stubResponse{}}
+}
+
+// Respond registers the response for url and returns s for chaining.
+func (s *HTTPStub) Respond(url string, status int, body string) *HTTPStub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[url] = stubResponse{status: status, body: body}
+	return s
+}
+
+// Client returns an HTTP client served by the stub, e.g. for recipes.WithHTTPClient.
+func (s *HTTPStub) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// Requests returns the requested URLs in order.
+func (s *HTTPStub) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *HTTPStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	url := req.URL.String()
+	s.mu.Lock()
+	s.requests = append(s.requests, url)
+	resp, ok := s.responses[url]
+	s.mu.Unlock()
+	if !ok {
+		resp = stubResponse{status: http.StatusNotFound, body: "not found"}
+	}
+	return &http.Response{
+		StatusCode: resp.status,
+		Status:     http.StatusText(resp.status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(resp.body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package adcptest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// FakeIDE is an IDE provider that records its calls and returns a canned result.
+type FakeIDE struct {
+	// Result is returned by Materialize. Nil results in an empty MaterializedResult.
+	Result *adcp.MaterializedResult
+	// Err is returned by Materialize when set.
+	Err error
+
+	mu    sync.Mutex
+	calls []FakeIDECall
+}
+
+// FakeIDECall records the arguments of a FakeIDE.Materialize call.
+type FakeIDECall struct {
+	Ide               *adcp.Ide
+	GenerationContext *core.GenerationContext
+}
+
+func (f *FakeIDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, FakeIDECall{Ide: ide, GenerationContext: core.GenerationContextFrom(ctx)})
+	f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.Result == nil {
+		return adcp.MaterializedResult_builder{}.Build(), nil
+	}
+	return f.Result, nil
+}
+
+// Calls returns the recorded Materialize calls in order.
+func (f *FakeIDE) Calls() []FakeIDECall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeIDECall(nil), f.calls...)
+}
@@ -0,0 +1,45 @@
+package adcptest
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics is a core.Metrics implementation that records everything in memory.
+type Metrics struct {
+	mu        sync.Mutex
+	counters  map[string]int
+	durations map[string][]time.Duration
+}
+
+func (m *Metrics) IncCounter(name string, _ map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counters == nil {
+		m.counters = map[string]int{}
+	}
+	m.counters[name]++
+}
+
+func (m *Metrics) ObserveDuration(name string, d time.Duration, _ map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.durations == nil {
+		m.durations = map[string][]time.Duration{}
+	}
+	m.durations[name] = append(m.durations[name], d)
+}
+
+// Counter returns the value of the counter name.
+func (m *Metrics) Counter(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[name]
+}
+
+// Durations returns the samples recorded in the histogram name.
+func (m *Metrics) Durations(name string) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.durations[name]...)
+}
@@ -0,0 +1,91 @@
+package adcptest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// MemoryTarget is an in-memory persistence target mirroring core.PersistMaterializedResult.
+type MemoryTarget struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+// NewMemoryTarget returns a target pre-populated with files, keyed by slash-separated relative path.
+func NewMemoryTarget(files map[string]string) *MemoryTarget {
+	t := &MemoryTarget{files: map[string]string{}}
+	for p, content := range files {
+		t.files[path.Clean(p)] = content
+	}
+	return t
+}
+
+// Persist stores all file entries of result, overwriting existing files. Like
+// core.PersistMaterializedResult, it rejects empty paths and paths escaping the root.
+func (t *MemoryTarget) Persist(ctx context.Context, result *adcp.MaterializedResult) error {
+	if result == nil {
+		return fmt.Errorf("materialized result cannot be nil")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, e := range result.GetEntries() {
+		if !e.HasFile() {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := strings.TrimSpace(e.GetFile().GetPath())
+		if p == "" {
+			return fmt.Errorf("entry %d: file path cannot be empty", i)
+		}
+		rel := strings.TrimPrefix(path.Clean(p), "/")
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("entry %d: %w: %s", i, core.ErrPathEscapesRoot, p)
+		}
+		if core.IsDryRun(ctx) {
+			continue
+		}
+		t.files[rel] = e.GetFile().GetContent()
+	}
+	return nil
+}
+
+// File returns the content stored at p.
+func (t *MemoryTarget) File(p string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	content, ok := t.files[path.Clean(p)]
+	return content, ok
+}
+
+// Files returns a copy of all stored files.
+func (t *MemoryTarget) Files() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	files := make(map[string]string, len(t.files))
+	for p, content := range t.files {
+		files[p] = content
+	}
+	return files
+}
+
+// FS returns a snapshot of the stored files, e.g. to pass to recipes.WithFS so that a following
+// materialization merges into the previously persisted output.
+func (t *MemoryTarget) FS() fs.FS {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fsys := fstest.MapFS{}
+	for p, content := range t.files {
+		fsys[p] = &fstest.MapFile{Data: []byte(content), Mode: 0o644}
+	}
+	return fsys
+}
@@ -0,0 +1,174 @@
+// Package batch materializes executable recipes into many repositories at once, e.g. to apply
+// organization recipes to every repository nightly:
+//
+//	summary := (&batch.Runner{Concurrency: 16}).Run(ctx, jobs)
+//	for _, res := range summary.Failed() {
+//		log.Printf("%s: %v", res.Root, res.Err)
+//	}
+//
+// All repositories share one HTTP client, one limiter bounding subprocesses and remote fetches,
+// and one in-memory core.State, so GitHub files pinned to a commit are fetched once per batch.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/executable"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultConcurrency is how many repositories are materialized at once when Runner.Concurrency is not set.
+const DefaultConcurrency = 8
+
+// Job materializes Recipe into the repository at Root.
+type Job struct {
+	Root   string
+	Recipe *adcp.ExecutableRecipe
+	// Options configure this job's recipe after the Runner's options.
+	Options []recipes.Option
+}
+
+// Result is the outcome of one Job.
+type Result struct {
+	Root string
+	// Report lists the files written, warnings and secrets used for this repository.
+	Report   core.ReportData
+	Duration time.Duration
+	// Err is the failure of this job, if any. In best-effort mode it may be a *core.MultiError
+	// while the successful entries were still written.
+	Err error
+}
+
+// Summary aggregates the results of a batch, in job order.
+type Summary struct {
+	Results []Result
+}
+
+// Failed returns the results of the jobs that failed.
+func (s *Summary) Failed() []Result {
+	var failed []Result
+	for _, r := range s.Results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Err joins the failures of all jobs, each prefixed with its root, or returns nil if all succeeded.
+func (s *Summary) Err() error {
+	var errs []error
+	for _, r := range s.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", r.Root, r.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// Files returns the number of files written across all repositories.
+func (s *Summary) Files() int {
+	n := 0
+	for _, r := range s.Results {
+		n += len(r.Report.Files)
+	}
+	return n
+}
+
+// Runner materializes jobs concurrently. The zero value is ready to use.
+type Runner struct {
+	// Concurrency bounds how many repositories are materialized at once. Defaults to DefaultConcurrency.
+	Concurrency int
+	// MaxConcurrency bounds subprocesses and remote fetches across all repositories. Zero means
+	// core.DefaultMaxConcurrency; a negative value removes the limit.
+	MaxConcurrency int
+	// HTTPClient is shared by all jobs. Defaults to the client carried by the context
+	// (see core.WithHTTPClient), or core.DefaultHTTPClient().
+	HTTPClient *http.Client
+	// State caches content shared between repositories. Defaults to a fresh core.State per Run.
+	State *core.State
+	// Options configure the recipe of every job.
+	Options []recipes.Option
+}
+
+// Run materializes every job into its root and persists the result there. Commands of a job run
+// in its root and existing files are read from it. A failing job does not stop the others;
+// cancelling ctx fails the jobs not finished yet.
+func (r *Runner) Run(ctx context.Context, jobs []Job) *Summary {
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	maxConcurrency := r.MaxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = core.DefaultMaxConcurrency
+	}
+	state := r.State
+	if state == nil {
+		state = core.NewState()
+	}
+	ctx = core.WithLimiter(ctx, core.NewLimiter(maxConcurrency))
+	ctx = core.WithState(ctx, state)
+	if r.HTTPClient != nil {
+		ctx = core.WithHTTPClient(ctx, r.HTTPClient)
+	}
+	log := core.Logger(ctx).With("op", "batch.Run")
+	log.Debug("Starting batch", "jobs", len(jobs), "concurrency", concurrency)
+
+	summary := &Summary{Results: make([]Result, len(jobs))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for idx, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				summary.Results[idx] = r.run(ctx, job)
+			case <-ctx.Done():
+				summary.Results[idx] = Result{Root: job.Root, Err: ctx.Err()}
+			}
+		}()
+	}
+	wg.Wait()
+	log.Debug("Batch finished", "jobs", len(jobs), "failed", len(summary.Failed()), "files", summary.Files())
+	return summary
+}
+
+func (r *Runner) run(ctx context.Context, job Job) Result {
+	start := time.Now()
+	report := core.NewReport()
+	err := materialize(ctx, job, report, append(append([]recipes.Option(nil), r.Options...), job.Options...))
+	core.Logger(ctx).Debug("Materialized repository", "root", job.Root, "error", err, "duration", time.Since(start))
+	return Result{Root: job.Root, Report: report.Data(), Duration: time.Since(start), Err: err}
+}
+
+func materialize(ctx context.Context, job Job, report *core.Report, opts []recipes.Option) error {
+	if job.Recipe == nil {
+		return recipes.ErrNilRecipe
+	}
+	info, err := os.Stat(job.Root)
+	if err != nil {
+		return fmt.Errorf("invalid root: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid root: %s is not a directory", job.Root)
+	}
+	ctx = core.WithCommandDir(ctx, job.Root)
+	opts = append(opts, recipes.WithFS(os.DirFS(job.Root)), recipes.WithReport(report))
+	result, err := executable.ForRecipe(job.Recipe, opts...).Materialize(ctx)
+	if result == nil {
+		return err
+	}
+	if persistErr := core.PersistMaterializedResult(ctx, job.Root, result); persistErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to persist: %w", persistErr))
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package batch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func executableRecipe(entries ...*adcp.ContextEntry) *adcp.ExecutableRecipe {
+	return adcp.ExecutableRecipe_builder{
+		Recipe: adcp.Recipe_builder{
+			Context: adcp.Context_builder{Entries: entries}.Build(),
+		}.Build(),
+		EntryPoint: adcp.EntryPoint_builder{IdeType: "claude"}.Build(),
+	}.Build()
+}
+
+func TestRunner_Run(t *testing.T) {
+	recipe := executableRecipe(
+		adcp.ContextEntry_builder{Path: "AGENTS.md", From: adcp.ContextFrom_builder{Text: strPtr("# Org guidance\n")}.Build()}.Build(),
+		adcp.ContextEntry_builder{Path: "REPO.md", From: adcp.ContextFrom_builder{Cmd: strPtr("cat name.txt")}.Build()}.Build(),
+	)
+	var jobs []Job
+	for _, name := range []string{"api", "web", "cli"} {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "name.txt"), []byte(name), 0o644))
+		jobs = append(jobs, Job{Root: root, Recipe: recipe})
+	}
+	jobs = append(jobs, Job{Root: filepath.Join(t.TempDir(), "missing"), Recipe: recipe})
+
+	summary := (&Runner{Concurrency: 2}).Run(context.Background(), jobs)
+
+	require.Len(t, summary.Results, 4)
+	for i, name := range []string{"api", "web", "cli"} {
+		res := summary.Results[i]
+		require.NoError(t, res.Err, name)
+		assert.Equal(t, jobs[i].Root, res.Root)
+		assert.Equal(t, []string{"AGENTS.md", "REPO.md"}, res.Report.Files)
+		data, err := os.ReadFile(filepath.Join(res.Root, "REPO.md"))
+		require.NoError(t, err)
+		assert.Equal(t, name, string(data), "commands run in the job's root")
+	}
+	assert.Equal(t, 6, summary.Files())
+
+	failed := summary.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, jobs[3].Root, failed[0].Root)
+	require.Error(t, summary.Err())
+	assert.Contains(t, summary.Err().Error(), jobs[3].Root)
+}
+
+func TestRunner_Run_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recipe := executableRecipe(
+		adcp.ContextEntry_builder{Path: "AGENTS.md", From: adcp.ContextFrom_builder{Cmd: strPtr("echo hi")}.Build()}.Build(),
+	)
+
+	summary := (&Runner{}).Run(ctx, []Job{{Root: t.TempDir(), Recipe: recipe}, {Root: t.TempDir(), Recipe: recipe}})
+	assert.Len(t, summary.Failed(), 2)
+	assert.ErrorIs(t, summary.Err(), context.Canceled)
+}
+
+func TestRunner_Run_NilRecipe(t *testing.T) {
+	summary := (&Runner{}).Run(context.Background(), []Job{{Root: t.TempDir()}})
+	require.Len(t, summary.Failed(), 1)
+}
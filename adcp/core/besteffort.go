@@ -0,0 +1,18 @@
+package core
+
+import "context"
+
+type bestEffortKey struct{}
+
+// WithBestEffort returns a copy of ctx in best-effort mode: entries that fail are skipped instead of
+// aborting materialization. Components then return the partial result together with a *MultiError
+// describing every failed entry.
+func WithBestEffort(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bestEffortKey{}, true)
+}
+
+// IsBestEffort reports whether ctx is in best-effort mode.
+func IsBestEffort(ctx context.Context) bool {
+	v, _ := ctx.Value(bestEffortKey{}).(bool)
+	return v
+}
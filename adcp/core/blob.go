@@ -0,0 +1,290 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultStreamThreshold is the content size above which Blobs moves content to disk.
+const DefaultStreamThreshold = 1 << 20
+
+// blobRefPrefix starts the references that stand in for blob content in MaterializedResult entries.
+const blobRefPrefix = "adcp-blob:"
+
+// Blobs stores content too large to keep in memory in temporary files. Such content is represented
+// in MaterializedResult entries by a reference, which PersistMaterializedResult streams to the
+// destination file; use Resolve to read it back. Close removes the temporary files, so results must
+// be persisted before. Blobs is safe for concurrent use; a nil Blobs keeps everything in memory.
+type Blobs struct {
+	// Threshold is the size above which spooled content is moved to disk. Zero means DefaultStreamThreshold.
+	Threshold int64
+
+	dir   string
+	mu    sync.Mutex
+	tmp   string
+	files map[string]string
+}
+
+// NewBlobs returns Blobs storing temporary files in a new directory under dir, or under the
+// default temporary directory if dir is empty. The directory is created on first use.
+func NewBlobs(dir string) *Blobs {
+	return &Blobs{dir: dir, files: map[string]string{}}
+}
+
+func (b *Blobs) threshold() int64 {
+	if b.Threshold > 0 {
+		return b.Threshold
+	}
+	return DefaultStreamThreshold
+}
+
+// create opens a new blob file and returns its reference.
+func (b *Blobs) create() (string, *os.File, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tmp == "" {
+		tmp, err := os.MkdirTemp(b.dir, "adcp-blobs-")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create blob directory: %w", err)
+		}
+		b.tmp = tmp
+	}
+	ref := blobRefPrefix + strconv.Itoa(len(b.files)+1)
+	f, err := os.Create(filepath.Join(b.tmp, strconv.Itoa(len(b.files)+1)))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create blob: %w", err)
+	}
+	b.files[ref] = f.Name()
+	return ref, f, nil
+}
+
+// Put stores content in a blob file and returns its reference.
+func (b *Blobs) Put(content string) (string, error) {
+	ref, f, err := b.create()
+	if err != nil {
+		return "", err
+	}
+	_, err = io.WriteString(f, content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	return ref, nil
+}
+
+// Has reports whether content is a reference to a blob of b.
+func (b *Blobs) Has(content string) bool {
+	if b == nil || !strings.HasPrefix(content, blobRefPrefix) {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.files[content]
+	return ok
+}
+
+// Open returns a reader for the blob referenced by ref.
+func (b *Blobs) Open(ref string) (io.ReadCloser, error) {
+	if !b.Has(ref) {
+		return nil, fmt.Errorf("unknown blob %q", ref)
+	}
+	b.mu.Lock()
+	name := b.files[ref]
+	b.mu.Unlock()
+	return os.Open(name)
+}
+
+// Size returns the size of content in bytes, reading the size of the blob file if content is a
+// blob reference.
+func (b *Blobs) Size(content string) (int64, error) {
+	if !b.Has(content) {
+		return int64(len(content)), nil
+	}
+	b.mu.Lock()
+	name := b.files[content]
+	b.mu.Unlock()
+	info, err := os.Stat(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read blob: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// Resolve returns the content referenced by content if it is a blob reference, and content itself otherwise.
+func (b *Blobs) Resolve(content string) (string, error) {
+	if !b.Has(content) {
+		return content, nil
+	}
+	r, err := b.Open(content)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read blob: %w", err)
+	}
+	return string(data), nil
+}
+
+// Close removes all blob files. References become invalid.
+func (b *Blobs) Close() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tmp == "" {
+		return nil
+	}
+	err := os.RemoveAll(b.tmp)
+	b.tmp = ""
+	b.files = map[string]string{}
+	return err
+}
+
+// Spool is an io.Writer collecting content in memory until it exceeds the threshold of its Blobs,
+// after which everything is moved to a blob file and further writes go straight to disk.
+type Spool struct {
+	blobs *Blobs
+	buf   bytes.Buffer
+	f     *os.File
+	ref   string
+	n     int64
+}
+
+// NewSpool returns an empty Spool. A Spool of a nil Blobs keeps everything in memory.
+func (b *Blobs) NewSpool() *Spool {
+	return &Spool{blobs: b}
+}
+
+func (s *Spool) Write(p []byte) (int, error) {
+	s.n += int64(len(p))
+	if s.f != nil {
+		return s.f.Write(p)
+	}
+	if s.blobs == nil || s.n <= s.blobs.threshold() {
+		return s.buf.Write(p)
+	}
+	ref, f, err := s.blobs.create()
+	if err != nil {
+		return 0, err
+	}
+	s.ref, s.f = ref, f
+	if _, err := s.buf.WriteTo(f); err != nil {
+		return 0, fmt.Errorf("failed to write blob: %w", err)
+	}
+	return f.Write(p)
+}
+
+// Len returns the number of bytes written.
+func (s *Spool) Len() int64 {
+	return s.n
+}
+
+// Spilled reports whether the content was moved to a blob file.
+func (s *Spool) Spilled() bool {
+	return s.f != nil
+}
+
+// Content finishes writing and returns the content, or a blob reference if it was moved to disk.
+func (s *Spool) Content() (string, error) {
+	if s.f == nil {
+		return s.buf.String(), nil
+	}
+	if err := s.f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	return s.ref, nil
+}
+
+// Spill keeps at most budget bytes of entry content in memory: once the accumulated size of the
+// entries exceeds budget, the content of every further entry is moved to b and replaced by its
+// reference. Content that already references a blob does not count towards the budget.
+func (b *Blobs) Spill(entries []*adcp.MaterializedResult_Entry, budget int64) error {
+	s := b.NewSpiller(budget)
+	for _, e := range entries {
+		if err := s.Spill(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Spiller applies a budget like Blobs.Spill, but to entries one at a time as they are produced, so
+// that entries collected after spilling never hold more than the budget in memory. It is safe for
+// concurrent use.
+type Spiller struct {
+	blobs  *Blobs
+	budget int64
+	mu     sync.Mutex
+	total  int64
+}
+
+// NewSpiller returns a Spiller moving content beyond budget bytes to b.
+func (b *Blobs) NewSpiller(budget int64) *Spiller {
+	return &Spiller{blobs: b, budget: budget}
+}
+
+// Spill counts the content of e towards the budget and, once the budget is exceeded, moves it to
+// the blobs, replacing it by its reference.
+func (s *Spiller) Spill(e *adcp.MaterializedResult_Entry) error {
+	f := e.GetFile()
+	if f == nil || s.blobs.Has(f.GetContent()) {
+		return nil
+	}
+	s.mu.Lock()
+	s.total += int64(len(f.GetContent()))
+	over := s.total > s.budget
+	s.mu.Unlock()
+	if !over {
+		return nil
+	}
+	ref, err := s.blobs.Put(f.GetContent())
+	if err != nil {
+		return fmt.Errorf("failed to spill %s: %w", f.GetPath(), err)
+	}
+	f.SetContent(ref)
+	return nil
+}
+
+type blobsKey struct{}
+
+// WithBlobs returns a copy of ctx carrying blobs.
+func WithBlobs(ctx context.Context, blobs *Blobs) context.Context {
+	return context.WithValue(ctx, blobsKey{}, blobs)
+}
+
+// BlobsFrom returns the Blobs carried by ctx, or nil if there is none.
+func BlobsFrom(ctx context.Context) *Blobs {
+	b, _ := ctx.Value(blobsKey{}).(*Blobs)
+	return b
+}
+
+type streamingKey struct{}
+
+// WithStreaming returns a copy of ctx in which fetches may return blob references instead of large
+// content (see Blobs). Only callers that write the content unchanged to a file should use it.
+func WithStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+// NewSpool returns a Spool for fetched content: backed by the Blobs carried by ctx in streaming
+// mode (see WithStreaming), and in memory otherwise.
+func NewSpool(ctx context.Context) *Spool {
+	if streaming, _ := ctx.Value(streamingKey{}).(bool); streaming {
+		return BlobsFrom(ctx).NewSpool()
+	}
+	return (*Blobs)(nil).NewSpool()
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool(t *testing.T) {
+	blobs := NewBlobs(t.TempDir())
+	blobs.Threshold = 4
+	defer func() { _ = blobs.Close() }()
+
+	small := blobs.NewSpool()
+	_, _ = small.Write([]byte("abc"))
+	content, err := small.Content()
+	require.NoError(t, err)
+	assert.Equal(t, "abc", content)
+	assert.False(t, small.Spilled())
+
+	large := blobs.NewSpool()
+	_, _ = large.Write([]byte("abc"))
+	_, _ = large.Write([]byte("defgh"))
+	ref, err := large.Content()
+	require.NoError(t, err)
+	assert.True(t, large.Spilled())
+	assert.Equal(t, int64(8), large.Len())
+	assert.True(t, blobs.Has(ref))
+	resolved, err := blobs.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdefgh", resolved)
+
+	require.NoError(t, blobs.Close())
+	assert.False(t, blobs.Has(ref))
+}
+
+func TestNewSpool_RequiresStreaming(t *testing.T) {
+	blobs := NewBlobs(t.TempDir())
+	blobs.Threshold = 1
+	defer func() { _ = blobs.Close() }()
+	ctx := WithBlobs(context.Background(), blobs)
+
+	s := NewSpool(ctx)
+	_, _ = s.Write([]byte("in memory"))
+	assert.False(t, s.Spilled())
+
+	s = NewSpool(WithStreaming(ctx))
+	_, _ = s.Write([]byte("on disk"))
+	assert.True(t, s.Spilled())
+}
+
+func TestPersistMaterializedResult_StreamsBlobs(t *testing.T) {
+	blobs := NewBlobs(t.TempDir())
+	defer func() { _ = blobs.Close() }()
+	big := strings.Repeat("x", 100)
+	ref, err := blobs.Put(big)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	res := adcp.MaterializedResult_builder{Entries: []*adcp.MaterializedResult_Entry{
+		adcp.MaterializedResult_Entry_builder{File: adcp.FullFileContent_builder{Path: "big.txt", Content: ref}.Build()}.Build(),
+	}}.Build()
+	require.NoError(t, PersistMaterializedResult(WithBlobs(context.Background(), blobs), root, res))
+
+	data, err := os.ReadFile(filepath.Join(root, "big.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, big, string(data))
+}
+
+func TestBlobs_Spill(t *testing.T) {
+	blobs := NewBlobs(t.TempDir())
+	defer func() { _ = blobs.Close() }()
+	entry := func(path, content string) *adcp.MaterializedResult_Entry {
+		return adcp.MaterializedResult_Entry_builder{File: adcp.FullFileContent_builder{Path: path, Content: content}.Build()}.Build()
+	}
+	entries := []*adcp.MaterializedResult_Entry{entry("a", "1234"), entry("b", "5678"), entry("c", "90")}
+
+	require.NoError(t, blobs.Spill(entries, 6))
+	assert.Equal(t, "1234", entries[0].GetFile().GetContent())
+	assert.True(t, blobs.Has(entries[1].GetFile().GetContent()))
+	assert.True(t, blobs.Has(entries[2].GetFile().GetContent()))
+	content, err := blobs.Resolve(entries[1].GetFile().GetContent())
+	require.NoError(t, err)
+	assert.Equal(t, "5678", content)
+}
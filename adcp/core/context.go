@@ -1,6 +1,16 @@
 package core
 
-import "github.com/devplaninc/adcp/clients/go/adcp"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
 
 type GenerationContext struct {
 	Prefetched map[string]*adcp.FetchedData
@@ -12,3 +22,90 @@ func (g *GenerationContext) GetPrefetched() map[string]*adcp.FetchedData {
 	}
 	return g.Prefetched
 }
+
+// DefaultDebugDumpPath is the conventional location of a GenerationContext debug dump.
+const DefaultDebugDumpPath = ".adcp/debug.json"
+
+// debugPreviewLength bounds the preview of each prefetched value in a dump.
+const debugPreviewLength = 200
+
+// GenerationContextDump is a redacted snapshot of a GenerationContext for diagnostics.
+type GenerationContextDump struct {
+	Prefetched []PrefetchedDump `json:"prefetched"`
+}
+
+// PrefetchedDump describes one prefetched value.
+type PrefetchedDump struct {
+	ID   string `json:"id"`
+	Size int    `json:"size"`
+	// Preview is the beginning of the value with credentials redacted.
+	Preview string `json:"preview"`
+}
+
+// Dump returns a redacted snapshot of g with prefetched entries sorted by ID.
+func (g *GenerationContext) Dump() GenerationContextDump {
+	dump := GenerationContextDump{Prefetched: []PrefetchedDump{}}
+	for id, d := range g.GetPrefetched() {
+		data := d.GetData()
+		preview := data
+		if len(preview) > debugPreviewLength {
+			preview = strings.ToValidUTF8(preview[:debugPreviewLength], "") + "…"
+		}
+		dump.Prefetched = append(dump.Prefetched, PrefetchedDump{ID: id, Size: len(data), Preview: Redact(preview)})
+	}
+	slices.SortFunc(dump.Prefetched, func(a, b PrefetchedDump) int { return strings.Compare(a.ID, b.ID) })
+	return dump
+}
+
+// JSON returns the dump as indented JSON.
+func (d GenerationContextDump) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal generation context dump: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// String summarizes g by its prefetched IDs and sizes, without any values.
+func (g *GenerationContext) String() string {
+	var parts []string
+	for _, p := range g.Dump().Prefetched {
+		parts = append(parts, fmt.Sprintf("%s (%d bytes)", p.ID, p.Size))
+	}
+	return "GenerationContext{prefetched: [" + strings.Join(parts, ", ") + "]}"
+}
+
+type generationContextKey struct{}
+
+// WithGenerationContext returns a copy of ctx carrying genCtx, making it available to
+// components that only receive a context.Context, such as IDE providers.
+func WithGenerationContext(ctx context.Context, genCtx *GenerationContext) context.Context {
+	return context.WithValue(ctx, generationContextKey{}, genCtx)
+}
+
+// GenerationContextFrom returns the GenerationContext carried by ctx, or nil if there is none.
+func GenerationContextFrom(ctx context.Context) *GenerationContext {
+	genCtx, _ := ctx.Value(generationContextKey{}).(*GenerationContext)
+	return genCtx
+}
+
+// WriteDebugDump writes the redacted dump of g as JSON to path, creating parent directories.
+// Relative paths are resolved against CommandDir(ctx) (see ResolvePath). Nothing is written in
+// dry-run mode.
+func WriteDebugDump(ctx context.Context, path string, g *GenerationContext) error {
+	data, err := g.Dump().JSON()
+	if err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+	path = ResolvePath(ctx, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directories for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write debug dump %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Kinds of drift reported by DetectDrift.
+const (
+	// DriftMissing: a materialized file was deleted.
+	DriftMissing = "missing"
+	// DriftLocalEdit: the file was edited after materialization; the recipe still produces the
+	// materialized content. Consider upstreaming the edit to the recipe.
+	DriftLocalEdit = "local-edit"
+	// DriftOutdated: the recipe now produces different content; the file is as materialized.
+	// Re-run materialization.
+	DriftOutdated = "outdated"
+	// DriftConflict: both the file and the recipe output changed since materialization.
+	DriftConflict = "conflict"
+	// DriftModified: the file differs from what the recipe produces; without a manifest it is
+	// unknown which side changed.
+	DriftModified = "modified"
+	// DriftOrphaned: a materialized file is no longer produced by the recipe.
+	DriftOrphaned = "orphaned"
+)
+
+// Materializer produces the result of a recipe, e.g. *recipes.Recipe.
+type Materializer interface {
+	Materialize(ctx context.Context, recipe *adcp.Recipe) (*adcp.MaterializedResult, error)
+}
+
+// FileDrift describes one drifted file.
+type FileDrift struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+}
+
+// DetectDrift reports files previously materialized under root whose content on disk no longer
+// matches what recipe produces with m. Previously materialized files are taken from the manifest
+// written by WriteManifest; without one, every file the recipe produces is compared. Existing
+// files are read from root unless ctx carries a filesystem (see WithFS).
+// The recipe is materialized in dry-run mode, so nothing is written and no commands are executed;
+// files whose content depends on a command (planned changes with a Note) are not compared.
+// The result is sorted by path and empty when there is no drift.
+func DetectDrift(ctx context.Context, root string, m Materializer, recipe *adcp.Recipe) ([]FileDrift, error) {
+	ctx, err := withRootFS(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadManifest(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	result, err := m.Materialize(WithDryRun(ctx, plan), recipe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to materialize recipe: %w", err)
+	}
+	expected, err := NewManifest(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	unknown := map[string]bool{}
+	for _, change := range plan.Changes() {
+		if change.Note != "" {
+			unknown[filepath.ToSlash(filepath.Clean(change.Path))] = true
+		}
+	}
+
+	materialized := map[string]string{}
+	if manifest != nil {
+		materialized = manifest.Files
+	} else {
+		for path := range expected.Files {
+			materialized[path] = ""
+		}
+	}
+
+	var drift []FileDrift
+	for path, materializedHash := range materialized {
+		if unknown[path] {
+			continue
+		}
+		kind, err := driftKind(ctx, path, materializedHash, expected.Files)
+		if err != nil {
+			return nil, err
+		}
+		if kind != "" {
+			drift = append(drift, FileDrift{Path: path, Kind: kind})
+		}
+	}
+	slices.SortFunc(drift, func(a, b FileDrift) int { return strings.Compare(a.Path, b.Path) })
+	return drift, nil
+}
+
+// driftKind classifies the file at path, or returns "" if it has not drifted. materializedHash is
+// empty when the materialized content is unknown.
+func driftKind(ctx context.Context, path, materializedHash string, expected map[string]string) (string, error) {
+	expectedHash, produced := expected[path]
+	data, err := ReadFile(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if !produced {
+			return "", nil
+		}
+		return DriftMissing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !produced {
+		return DriftOrphaned, nil
+	}
+	onDisk := hashContent(data)
+	switch {
+	case onDisk == expectedHash:
+		return "", nil
+	case materializedHash == "":
+		return DriftModified, nil
+	case onDisk == materializedHash:
+		return DriftOutdated, nil
+	case expectedHash == materializedHash:
+		return DriftLocalEdit, nil
+	default:
+		return DriftConflict, nil
+	}
+}
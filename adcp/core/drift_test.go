@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticMaterializer materializes every recipe to the same files.
+type staticMaterializer map[string]string
+
+func (m staticMaterializer) Materialize(context.Context, *adcp.Recipe) (*adcp.MaterializedResult, error) {
+	var files [][2]string
+	for path, content := range m {
+		files = append(files, [2]string{path, content})
+	}
+	return fileEntries(files...), nil
+}
+
+func TestDetectDrift(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	before := staticMaterializer{
+		"same.md":     "same",
+		"edited.md":   "v1",
+		"outdated.md": "v1",
+		"conflict.md": "v1",
+		"deleted.md":  "v1",
+		"orphaned.md": "v1",
+	}
+	res, err := before.Materialize(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, PersistMaterializedResult(ctx, root, res))
+	require.NoError(t, WriteManifest(ctx, root, res))
+
+	write := func(path, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0o644))
+	}
+	write("edited.md", "local")
+	write("conflict.md", "local")
+	require.NoError(t, os.Remove(filepath.Join(root, "deleted.md")))
+
+	after := staticMaterializer{
+		"same.md":     "same",
+		"edited.md":   "v1",
+		"outdated.md": "v2",
+		"conflict.md": "v2",
+		"deleted.md":  "v1",
+		"new.md":      "new",
+	}
+	drift, err := DetectDrift(ctx, root, after, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []FileDrift{
+		{Path: "conflict.md", Kind: DriftConflict},
+		{Path: "deleted.md", Kind: DriftMissing},
+		{Path: "edited.md", Kind: DriftLocalEdit},
+		{Path: "orphaned.md", Kind: DriftOrphaned},
+		{Path: "outdated.md", Kind: DriftOutdated},
+	}, drift)
+}
+
+func TestDetectDrift_WithoutManifest(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.md"), []byte("local"), 0o644))
+
+	drift, err := DetectDrift(context.Background(), root, staticMaterializer{"a.md": "recipe", "b.md": "b"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []FileDrift{
+		{Path: "a.md", Kind: DriftModified},
+		{Path: "b.md", Kind: DriftMissing},
+	}, drift)
+}
+
+// commandMaterializer materializes a file from a command, recording it as a dry run would.
+type commandMaterializer struct {
+	dryRun bool
+}
+
+func (m *commandMaterializer) Materialize(ctx context.Context, _ *adcp.Recipe) (*adcp.MaterializedResult, error) {
+	m.dryRun = IsDryRun(ctx)
+	RecordPlannedChange(ctx, PlannedChange{Path: "cmd.md", Merge: MergeReplace, Note: "command not executed: date"})
+	return fileEntries([2]string{"cmd.md", "<output of date>\n"}, [2]string{"a.md", "a"}), nil
+}
+
+func TestDetectDrift_DryRun(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cmd.md"), []byte("Fri Oct 16\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.md"), []byte("a"), 0o644))
+
+	m := &commandMaterializer{}
+	drift, err := DetectDrift(context.Background(), root, m, nil)
+	require.NoError(t, err)
+	assert.True(t, m.dryRun, "the recipe must not execute commands")
+	assert.Empty(t, drift, "files produced by commands are not compared with placeholders")
+}
+
+func TestWriteManifest_UnderHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+	ctx := context.Background()
+	res := fileEntries([2]string{"a.md", "a"})
+
+	require.NoError(t, WriteManifest(ctx, "~/proj", res))
+	assert.FileExists(t, filepath.Join(home, "proj", ManifestName))
+	assert.NoFileExists(t, ManifestName)
+
+	m, err := ReadManifest(ctx, "~/proj")
+	require.NoError(t, err)
+	assert.Equal(t, ManifestGeneratedBy, m.GeneratedBy)
+	assert.Equal(t, map[string]string{"a.md": hashContent([]byte("a"))}, m.Files)
+
+	require.NoError(t, WriteManifest(WithDryRun(ctx, nil), "dry", res))
+	assert.NoDirExists(t, "dry")
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge strategies a provider applies when producing a file, as reported in a dry-run Plan.
+const (
+	// MergeReplace overwrites the file with generated content.
+	MergeReplace = "replace"
+	// MergeJSON deep-merges generated keys into the existing JSON document.
+	MergeJSON = "merge-json"
+	// MergeTOML merges generated tables into the existing TOML document.
+	MergeTOML = "merge-toml"
+	// MergeYAML merges generated entries into the existing YAML document, keeping its comments.
+	MergeYAML = "merge-yaml"
+	// MergeManagedBlock rewrites only the adcp managed block of the existing file.
+	MergeManagedBlock = "managed-block"
+)
+
+// PlannedChange describes a file a dry run would write and how its content would be produced.
+type PlannedChange struct {
+	Path  string
+	Merge string
+	// Note explains deviations from a real run, e.g. a command that was not executed.
+	Note string
+}
+
+// Plan collects the changes recorded during a dry run. It is safe for concurrent use.
+type Plan struct {
+	mu      sync.Mutex
+	changes []PlannedChange
+}
+
+// Record adds a planned change. Recording on a nil Plan is a no-op.
+func (p *Plan) Record(change PlannedChange) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.changes = append(p.changes, change)
+}
+
+// Changes returns the recorded changes in recording order.
+func (p *Plan) Changes() []PlannedChange {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedChange(nil), p.changes...)
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a copy of ctx in dry-run mode. Providers skip side effects such as executing
+// context, prefetch and command sources or external plugins, rendering placeholders instead, and
+// record the changes they would make into plan, which may be nil.
+// PersistMaterializedResult does not write files in dry-run mode.
+func WithDryRun(ctx context.Context, plan *Plan) context.Context {
+	if plan == nil {
+		plan = &Plan{}
+	}
+	return context.WithValue(ctx, dryRunKey{}, plan)
+}
+
+// IsDryRun reports whether ctx is in dry-run mode.
+func IsDryRun(ctx context.Context) bool {
+	return PlanFrom(ctx) != nil
+}
+
+// PlanFrom returns the dry-run Plan carried by ctx, or nil outside of dry-run mode.
+func PlanFrom(ctx context.Context) *Plan {
+	plan, _ := ctx.Value(dryRunKey{}).(*Plan)
+	return plan
+}
+
+// RecordPlannedChange records change into the Plan carried by ctx. It is a no-op outside of dry-run mode.
+func RecordPlannedChange(ctx context.Context, change PlannedChange) {
+	PlanFrom(ctx).Record(change)
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	assert.False(t, IsDryRun(context.Background()))
+	RecordPlannedChange(context.Background(), PlannedChange{Path: "ignored"})
+
+	plan := &Plan{}
+	ctx := WithDryRun(context.Background(), plan)
+	assert.True(t, IsDryRun(ctx))
+	RecordPlannedChange(ctx, PlannedChange{Path: ".mcp.json", Merge: MergeJSON})
+	assert.Equal(t, []PlannedChange{{Path: ".mcp.json", Merge: MergeJSON}}, plan.Changes())
+}
+
+func TestPersistMaterializedResult_DryRun(t *testing.T) {
+	root := t.TempDir()
+	res := adcp.MaterializedResult_builder{Entries: []*adcp.MaterializedResult_Entry{
+		adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: "a/b.txt", Content: "x"}.Build(),
+		}.Build(),
+	}}.Build()
+
+	require.NoError(t, PersistMaterializedResult(WithDryRun(context.Background(), nil), root, res))
+	_, err := os.Stat(filepath.Join(root, "a/b.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPathEscapesRoot is returned when a file path resolves outside of the target root directory.
+var ErrPathEscapesRoot = errors.New("path escapes root")
+
+// EntryError describes a failure to materialize a single entry.
+type EntryError struct {
+	// Path is the output path of the entry, if known.
+	Path string
+	// Source is the kind of source the entry is produced from, e.g. "text", "cmd" or "github".
+	Source string
+	Err    error
+}
+
+func (e *EntryError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		b.WriteString(e.Path)
+	}
+	if e.Source != "" {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("[" + e.Source + "]")
+	}
+	if b.Len() == 0 {
+		return e.Err.Error()
+	}
+	return b.String() + ": " + e.Err.Error()
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError lists every entry that failed, so that all problems can be fixed in one go.
+// Use errors.As to retrieve it from a returned error.
+type MultiError struct {
+	Errors []*EntryError
+}
+
+// Append adds err to the list. Nested MultiErrors are flattened and other errors are wrapped
+// into an EntryError without path and source. Appending nil is a no-op.
+// Wrapping context around a nested MultiError is dropped in favor of its entries.
+func (m *MultiError) Append(err error) {
+	if err == nil {
+		return
+	}
+	var multi *MultiError
+	if errors.As(err, &multi) {
+		m.Errors = append(m.Errors, multi.Errors...)
+		return
+	}
+	if entry, ok := err.(*EntryError); ok {
+		m.Errors = append(m.Errors, entry)
+		return
+	}
+	m.Errors = append(m.Errors, &EntryError{Err: err})
+}
+
+// ErrorOrNil returns m if it holds any errors, or nil otherwise.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d entries failed:", len(m.Errors))
+	for _, e := range m.Errors {
+		b.WriteString("\n- " + e.Error())
+	}
+	return b.String()
+}
+
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.Errors))
+	for i, e := range m.Errors {
+		errs[i] = e
+	}
+	return errs
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiError(t *testing.T) {
+	cause := errors.New("boom")
+	m := &MultiError{}
+	assert.NoError(t, m.ErrorOrNil())
+
+	m.Append(nil)
+	m.Append(&EntryError{Path: "a.md", Source: "cmd", Err: cause})
+	assert.Equal(t, "a.md [cmd]: boom", m.Error())
+
+	nested := &MultiError{}
+	nested.Append(&EntryError{Path: "b.md", Source: "github", Err: errors.New("404")})
+	m.Append(fmt.Errorf("wrapped: %w", nested))
+	m.Append(errors.New("plain"))
+
+	err := m.ErrorOrNil()
+	assert.Equal(t, "3 entries failed:\n- a.md [cmd]: boom\n- b.md [github]: 404\n- plain", err.Error())
+	assert.ErrorIs(t, err, cause)
+	var entry *EntryError
+	assert.ErrorAs(t, err, &entry)
+	assert.Equal(t, "a.md", entry.Path)
+}
@@ -0,0 +1,10 @@
+package executable
+
+import "errors"
+
+// ErrUnsupportedIDE is returned when an executable recipe targets an IDE type without a provider.
+var ErrUnsupportedIDE = errors.New("unsupported IDE type")
+
+// ErrPathConflict is returned when several IDEs of a multi-IDE entry point write different content
+// to the same path.
+var ErrPathConflict = errors.New("conflicting files for path")
@@ -2,19 +2,165 @@ package executable
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
 
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/aider"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/amazonq"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/amp"
 	"github.com/devplaninc/adcp-core/adcp/core/plugins/claude"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/cline"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/codex"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/continuedev"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/copilot"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/cursor"
 	"github.com/devplaninc/adcp-core/adcp/core/plugins/cursorcli"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/gemini"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/generic"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/goose"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/junie"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/opencode"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/roo"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/windsurf"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/zed"
 	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"google.golang.org/protobuf/proto"
 )
 
-func getIDE(ideType string) (recipes.IDEProvider, error) {
-	switch strings.ToLower(ideType) {
-	case "claude":
-		return claude.NewIDEProvider(), nil
-	case "cursor-cli":
-		return cursorcli.NewIDEProvider(), nil
+var (
+	idesMu sync.RWMutex
+	ides   = map[string]func() recipes.IDEProvider{}
+)
+
+func init() {
+	builtin := map[string]func() recipes.IDEProvider{
+		"aider":      func() recipes.IDEProvider { return aider.NewIDEProvider() },
+		"amazonq":    func() recipes.IDEProvider { return amazonq.NewIDEProvider() },
+		"amp":        func() recipes.IDEProvider { return amp.NewIDEProvider() },
+		"claude":     func() recipes.IDEProvider { return claude.NewIDEProvider() },
+		"cline":      func() recipes.IDEProvider { return cline.NewIDEProvider() },
+		"codex":      func() recipes.IDEProvider { return codex.NewIDEProvider() },
+		"continue":   func() recipes.IDEProvider { return continuedev.NewIDEProvider() },
+		"copilot":    func() recipes.IDEProvider { return copilot.NewIDEProvider() },
+		"cursor":     func() recipes.IDEProvider { return cursor.NewIDEProvider() },
+		"cursor-cli": func() recipes.IDEProvider { return cursorcli.NewIDEProvider() },
+		"gemini":     func() recipes.IDEProvider { return gemini.NewIDEProvider() },
+		"goose":      func() recipes.IDEProvider { return goose.NewIDEProvider() },
+		"junie":      func() recipes.IDEProvider { return junie.NewIDEProvider() },
+		"opencode":   func() recipes.IDEProvider { return opencode.NewIDEProvider() },
+		"roo":        func() recipes.IDEProvider { return roo.NewIDEProvider() },
+		"windsurf":   func() recipes.IDEProvider { return windsurf.NewIDEProvider() },
+		"zed":        func() recipes.IDEProvider { return zed.NewIDEProvider() },
+	}
+	for name, factory := range builtin {
+		RegisterIDE(name, factory)
+	}
+}
+
+// RegisterIDE makes the providers created by factory available to executable recipes whose entry
+// point has the IDE type name, e.g. for proprietary IDEs of binaries embedding adcp-core. Names are
+// case-insensitive. Registering a name again replaces its provider, including built-in ones.
+// factory is called for every materialization. RegisterIDE panics if name is empty or factory is nil.
+func RegisterIDE(name string, factory func() recipes.IDEProvider) {
+	if name == "" || factory == nil {
+		panic("executable: RegisterIDE requires a name and a factory")
+	}
+	idesMu.Lock()
+	defer idesMu.Unlock()
+	ides[strings.ToLower(name)] = factory
+}
+
+// ListSupportedIDEs returns the registered IDE types, sorted.
+func ListSupportedIDEs() []string {
+	idesMu.RLock()
+	defer idesMu.RUnlock()
+	return slices.Sorted(maps.Keys(ides))
+}
+
+// GenericIDEType is the IDE type of the generic IDE (see generic.Config). The generic IDE is
+// described by the recipe itself: its context entry at GenericConfigPath holds the config as
+// inline YAML or JSON text. That entry is not materialized.
+const GenericIDEType = "generic"
+
+// GenericConfigPath is the path of the context entry holding the config of the generic IDE.
+const GenericConfigPath = "@generic"
+
+// getIDE returns the provider for ideType: an external plugin for "exec:<path>" (see ExecIDE), the
+// generic IDE configured by recipe for GenericIDEType, or else the registered provider.
+func getIDE(ideType string, recipe *adcp.Recipe) (recipes.IDEProvider, error) {
+	if plugin, ok := execPlugin(ideType); ok {
+		if plugin.Command[0] == "" {
+			return nil, fmt.Errorf("%w: %v (missing plugin path)", ErrUnsupportedIDE, ideType)
+		}
+		return plugin, nil
+	}
+	if strings.EqualFold(ideType, GenericIDEType) {
+		return genericIDE(recipe)
+	}
+	idesMu.RLock()
+	factory, ok := ides[strings.ToLower(ideType)]
+	idesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %v (supported: %s)", ErrUnsupportedIDE, ideType, strings.Join(ListSupportedIDEs(), ", "))
+	}
+	return factory(), nil
+}
+
+// genericIDE returns the generic IDE described by the context entry of recipe at
+// GenericConfigPath.
+func genericIDE(recipe *adcp.Recipe) (recipes.IDEProvider, error) {
+	for _, entry := range recipe.GetContext().GetEntries() {
+		if entry.GetPath() != GenericConfigPath {
+			continue
+		}
+		if entry.GetFrom().WhichType() != adcp.ContextFrom_Text_case {
+			return nil, fmt.Errorf("%w: the config at %s must be inline text", generic.ErrInvalidConfig, GenericConfigPath)
+		}
+		cfg, err := generic.ParseConfig([]byte(entry.GetFrom().GetText()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", GenericConfigPath, err)
+		}
+		return generic.NewIDEProvider(cfg), nil
+	}
+	return nil, fmt.Errorf("%w: %s (missing context entry %s holding its config)", ErrUnsupportedIDE, GenericIDEType, GenericConfigPath)
+}
+
+// withoutGenericConfig returns recipe without the context entry configuring the generic IDE.
+func withoutGenericConfig(recipe *adcp.Recipe) *adcp.Recipe {
+	entries := recipe.GetContext().GetEntries()
+	if !slices.ContainsFunc(entries, isGenericConfig) {
+		return recipe
+	}
+	recipe = proto.CloneOf(recipe)
+	recipe.GetContext().SetEntries(slices.DeleteFunc(recipe.GetContext().GetEntries(), isGenericConfig))
+	return recipe
+}
+
+func isGenericConfig(entry *adcp.ContextEntry) bool {
+	return entry.GetPath() == GenericConfigPath
+}
+
+// ManagedPaths returns the files the providers of ideType may write, for uninstall and cleanup
+// tools. ideType may list several IDE types separated by commas, like entry points. Providers
+// that do not implement recipes.ManagedPathsProvider contribute nothing. GenericIDEType is
+// configured by a recipe; use Recipe.ManagedPaths for it.
+func ManagedPaths(ideType string) ([]recipes.ManagedPath, error) {
+	return managedPaths(ideType, nil)
+}
+
+func managedPaths(ideType string, recipe *adcp.Recipe) ([]recipes.ManagedPath, error) {
+	var paths []recipes.ManagedPath
+	for _, t := range splitIDETypes(ideType) {
+		ide, err := getIDE(t, recipe)
+		if err != nil {
+			return nil, err
+		}
+		if mp, ok := ide.(recipes.ManagedPathsProvider); ok {
+			paths = append(paths, mp.ManagedPaths()...)
+		}
 	}
-	return nil, fmt.Errorf("unsupported IDE type: %v", ideType)
+	return paths, nil
 }
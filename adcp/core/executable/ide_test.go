@@ -0,0 +1,157 @@
+package executable
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/generic"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubIDE writes files mapping paths to contents; without files it writes stub.md.
+type stubIDE struct {
+	files map[string]string
+}
+
+func (s stubIDE) Materialize(context.Context, *adcp.Ide) (*adcp.MaterializedResult, error) {
+	files := s.files
+	if files == nil {
+		files = map[string]string{"stub.md": "stub"}
+	}
+	var entries []*adcp.MaterializedResult_Entry
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: path, Content: files[path]}.Build(),
+		}.Build())
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// registerStubIDE registers ide under name for the duration of the test.
+func registerStubIDE(t *testing.T, name string, ide stubIDE) {
+	t.Helper()
+	RegisterIDE(name, func() recipes.IDEProvider { return ide })
+	t.Cleanup(func() {
+		idesMu.Lock()
+		delete(ides, strings.ToLower(name))
+		idesMu.Unlock()
+	})
+}
+
+func TestRegisterIDE(t *testing.T) {
+	registerStubIDE(t, "Acme-IDE", stubIDE{})
+
+	assert.Contains(t, ListSupportedIDEs(), "acme-ide")
+
+	exec := adcp.ExecutableRecipe_builder{
+		EntryPoint: adcp.EntryPoint_builder{IdeType: "acme-ide"}.Build(),
+		Recipe:     adcp.Recipe_builder{Ide: adcp.Ide_builder{}.Build()}.Build(),
+	}.Build()
+	res, err := ForRecipe(exec).Materialize(context.Background())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "stub.md", res.GetEntries()[0].GetFile().GetPath())
+}
+
+func TestRegisterIDE_InvalidArguments(t *testing.T) {
+	assert.Panics(t, func() { RegisterIDE("", func() recipes.IDEProvider { return stubIDE{} }) })
+	assert.Panics(t, func() { RegisterIDE("acme", nil) })
+}
+
+func TestListSupportedIDEs(t *testing.T) {
+	names := ListSupportedIDEs()
+	assert.IsIncreasing(t, names)
+	for _, name := range []string{"claude", "cursor", "cursor-cli", "continue", "zed"} {
+		assert.Contains(t, names, name)
+	}
+}
+
+func TestGetIDE_Unsupported(t *testing.T) {
+	_, err := getIDE("unknown-ide", nil)
+	require.ErrorIs(t, err, ErrUnsupportedIDE)
+	assert.Contains(t, err.Error(), "claude")
+
+	p, err := getIDE("Claude", nil)
+	require.NoError(t, err)
+	assert.NotNil(t, p)
+}
+
+func TestGetIDE_Generic(t *testing.T) {
+	recipe := func(config string) *adcp.Recipe {
+		return adcp.Recipe_builder{Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: GenericConfigPath, From: adcp.ContextFrom_builder{Text: &config}.Build()}.Build(),
+		}}.Build()}.Build()
+	}
+
+	p, err := getIDE("Generic", recipe("commandsFolder: .acme/commands\n"))
+	require.NoError(t, err)
+	caps := p.(recipes.CapabilityProvider).Capabilities()
+	assert.True(t, caps.Commands)
+
+	p, err = getIDE(GenericIDEType, recipe(`{"mcpPath": ".acme/mcp.json"}`))
+	require.NoError(t, err)
+	assert.NotEmpty(t, p.(recipes.CapabilityProvider).Capabilities().MCPTransports, "JSON configs are accepted")
+
+	_, err = getIDE(GenericIDEType, recipe("commandFolder: typo\n"))
+	assert.ErrorIs(t, err, generic.ErrInvalidConfig)
+
+	_, err = getIDE(GenericIDEType, adcp.Recipe_builder{}.Build())
+	assert.ErrorIs(t, err, ErrUnsupportedIDE)
+}
+
+func TestExecutableRecipe_Materialize_Generic(t *testing.T) {
+	config := "commandsFolder: .acme/commands\nmemoryPath: ACME.md\n"
+	memory, review := "Be brief.", "Review the diff."
+	exec := adcp.ExecutableRecipe_builder{
+		EntryPoint: adcp.EntryPoint_builder{IdeType: GenericIDEType}.Build(),
+		Recipe: adcp.Recipe_builder{
+			Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{Path: GenericConfigPath, From: adcp.ContextFrom_builder{Text: &config}.Build()}.Build(),
+				adcp.ContextEntry_builder{Path: "@memory", From: adcp.ContextFrom_builder{Text: &memory}.Build()}.Build(),
+			}}.Build(),
+			Ide: adcp.Ide_builder{Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: &review}.Build()}.Build(),
+			}}.Build()}.Build(),
+		}.Build(),
+	}.Build()
+
+	res, err := ForRecipe(exec).Materialize(context.Background())
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Contains(t, paths, "ACME.md")
+	assert.Contains(t, paths, ".acme/commands/review.md")
+	assert.Len(t, exec.GetRecipe().GetContext().GetEntries(), 2, "the recipe is not modified")
+
+	managed, err := ForRecipe(exec).ManagedPaths()
+	require.NoError(t, err)
+	assert.NotEmpty(t, managed)
+}
+
+func TestManagedPaths(t *testing.T) {
+	paths, err := ManagedPaths("claude, codex")
+	require.NoError(t, err)
+	var patterns []string
+	for _, p := range paths {
+		patterns = append(patterns, p.Pattern)
+	}
+	for _, want := range []string{".claude/commands/*.md", ".mcp.json", ".claude/settings.local.json", "~/.codex/config.toml"} {
+		assert.Contains(t, patterns, want)
+	}
+
+	registerStubIDE(t, "plain", stubIDE{})
+	paths, err = ManagedPaths("plain")
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	_, err = ManagedPaths("unknown-ide")
+	assert.ErrorIs(t, err, ErrUnsupportedIDE)
+}
@@ -0,0 +1,47 @@
+package executable
+
+import (
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+)
+
+// managedMarkers delimit the sections several IDE providers may merge into the same file, e.g.
+// AGENTS.md or an ignore file.
+var managedMarkers = [][2]string{
+	{shared.ManagedBlockBegin, shared.ManagedBlockEnd},
+	{shared.ManagedMemoryBegin, shared.ManagedMemoryEnd},
+	{shared.ManagedLinesBegin, shared.ManagedLinesEnd},
+}
+
+// mergeManagedSections combines two versions of a file written by different IDE providers. Both
+// must have the same content outside of their managed sections; the managed sections of a are
+// kept and extended with what b writes into them. It returns false if the files differ outside of
+// managed sections or have none.
+func mergeManagedSections(a, b string) (string, bool) {
+	merged, outsideA, outsideB := a, a, b
+	found := false
+	for _, m := range managedMarkers {
+		bodyA, okA := shared.ManagedSection(a, m[0], m[1])
+		bodyB, okB := shared.ManagedSection(b, m[0], m[1])
+		if !okA && !okB {
+			continue
+		}
+		found = true
+		outsideA = shared.RemoveManagedBlockWithMarkers(outsideA, m[0], m[1])
+		outsideB = shared.RemoveManagedBlockWithMarkers(outsideB, m[0], m[1])
+		if !okB || strings.Contains(bodyA, strings.TrimRight(bodyB, "\n")) {
+			continue
+		}
+		body := bodyB
+		if okA {
+			body = strings.TrimRight(bodyA, "\n") + "\n\n" + bodyB
+		}
+		merged = shared.MergeManagedBlockWithMarkers(merged, body, m[0], m[1])
+	}
+	// Separators around managed sections depend on where they were inserted.
+	if !found || strings.Join(strings.Fields(outsideA), " ") != strings.Join(strings.Fields(outsideB), " ") {
+		return "", false
+	}
+	return merged, true
+}
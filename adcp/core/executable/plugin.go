@@ -0,0 +1,88 @@
+package executable
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ExecPrefix marks IDE types naming an external plugin binary, e.g. "exec:/usr/local/bin/adcp-acme".
+const ExecPrefix = "exec:"
+
+// PluginProtocolEnv is set in the environment of external plugins to the protocol they must speak,
+// currently PluginProtocol.
+const PluginProtocolEnv = "ADCP_PLUGIN_PROTOCOL"
+
+// PluginProtocol is the protocol spoken with external plugins: the plugin reads the adcp.Ide to
+// materialize as protojson from standard input, and writes the adcp.MaterializedResult as protojson
+// to standard output before exiting with status 0. A non-zero status fails materialization, with
+// the plugin's standard error included in the error.
+const PluginProtocol = "protojson/v1"
+
+// ExecIDE is an IDE provider running an external plugin (see PluginProtocol), so that providers can
+// be written in any language without recompiling adcp-core. Plugins run like prefetch commands: in
+// core.CommandDir, with core.CommandEnv added to the environment, and not at all in dry-run mode.
+type ExecIDE struct {
+	// Command is the plugin binary followed by its arguments.
+	Command []string
+}
+
+func (e ExecIDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if len(e.Command) == 0 || e.Command[0] == "" {
+		return nil, fmt.Errorf("plugin command cannot be empty")
+	}
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	if core.IsDryRun(ctx) {
+		// Plugins may have side effects, so a dry run does not run them and plans none of their files.
+		core.Logger(ctx).Debug("Dry run, skipping IDE plugin", "command", e.Command[0])
+		return &adcp.MaterializedResult{}, nil
+	}
+	input, err := protojson.Marshal(ide)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin input: %w", err)
+	}
+	env, err := core.CommandEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limiter := core.LimiterFrom(ctx)
+	if err := limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer limiter.Release()
+
+	cmd := exec.CommandContext(ctx, e.Command[0], e.Command[1:]...)
+	cmd.Dir = core.CommandDir(ctx)
+	cmd.Env = append(append(os.Environ(), env...), PluginProtocolEnv+"="+PluginProtocol)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	core.Logger(ctx).Debug("Running IDE plugin", "command", e.Command[0])
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run plugin %s: %w: %s", e.Command[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	res := &adcp.MaterializedResult{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(out, res); err != nil {
+		return nil, fmt.Errorf("failed to decode output of plugin %s: %w", e.Command[0], err)
+	}
+	return res, nil
+}
+
+// execPlugin returns the provider for an IDE type of the form "exec:<path>", if ideType has that form.
+func execPlugin(ideType string) (ExecIDE, bool) {
+	path, ok := strings.CutPrefix(ideType, ExecPrefix)
+	if !ok {
+		return ExecIDE{}, false
+	}
+	return ExecIDE{Command: []string{path}}, true
+}
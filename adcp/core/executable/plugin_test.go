@@ -0,0 +1,115 @@
+package executable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// pluginModeEnv makes the test binary act as an external plugin (see runTestPlugin).
+const pluginModeEnv = "ADCP_TEST_PLUGIN_MODE"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(pluginModeEnv); mode != "" {
+		os.Exit(runTestPlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin materializes every command of the Ide read from stdin as a file, below the home
+// directory for mode "home", or fails for mode "fail".
+func runTestPlugin(mode string) int {
+	if mode == "fail" {
+		fmt.Fprintln(os.Stderr, "plugin exploded")
+		return 3
+	}
+	if os.Getenv(PluginProtocolEnv) != PluginProtocol {
+		fmt.Fprintln(os.Stderr, "unexpected protocol")
+		return 1
+	}
+	in, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return 1
+	}
+	ide := &adcp.Ide{}
+	if err := protojson.Unmarshal(in, ide); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	dir := ".acme/"
+	if mode == "home" {
+		dir = core.HomePrefix + dir
+	}
+	var entries []*adcp.MaterializedResult_Entry
+	for _, c := range ide.GetCommands().GetEntries() {
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: dir + c.GetName() + ".md", Content: c.GetName()}.Build(),
+		}.Build())
+	}
+	out, err := protojson.Marshal(adcp.MaterializedResult_builder{Entries: entries}.Build())
+	if err != nil {
+		return 1
+	}
+	_, _ = os.Stdout.Write(out)
+	return 0
+}
+
+func TestExecIDE_Materialize(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review"}.Build(),
+		}}.Build(),
+	}.Build()
+
+	t.Run("result is decoded from stdout", func(t *testing.T) {
+		ctx := core.WithCommandEnv(context.Background(), map[string]string{pluginModeEnv: "ok"})
+		p, err := getIDE(ExecPrefix+exe, nil)
+		require.NoError(t, err)
+		res, err := p.Materialize(ctx, ide)
+		require.NoError(t, err)
+		require.Len(t, res.GetEntries(), 1)
+		assert.Equal(t, ".acme/review.md", res.GetEntries()[0].GetFile().GetPath())
+		assert.Equal(t, "review", res.GetEntries()[0].GetFile().GetContent())
+	})
+
+	t.Run("failure includes stderr", func(t *testing.T) {
+		ctx := core.WithCommandEnv(context.Background(), map[string]string{pluginModeEnv: "fail"})
+		_, err := ExecIDE{Command: []string{exe}}.Materialize(ctx, ide)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "plugin exploded")
+	})
+
+	t.Run("dry run does not run the plugin", func(t *testing.T) {
+		ctx := core.WithCommandEnv(core.WithDryRun(context.Background(), nil), map[string]string{pluginModeEnv: "fail"})
+		res, err := ExecIDE{Command: []string{exe}}.Materialize(ctx, ide)
+		require.NoError(t, err)
+		assert.Empty(t, res.GetEntries())
+	})
+
+	t.Run("home-relative entries are not persisted", func(t *testing.T) {
+		ctx := core.WithCommandEnv(context.Background(), map[string]string{pluginModeEnv: "home"})
+		res, err := ExecIDE{Command: []string{exe}}.Materialize(ctx, ide)
+		require.NoError(t, err)
+		userRoot := t.TempDir()
+		err = core.PersistMaterializedResult(core.WithUserRoot(ctx, userRoot), t.TempDir(), res)
+		require.ErrorIs(t, err, core.ErrPathEscapesRoot)
+		assert.NoFileExists(t, filepath.Join(userRoot, ".acme", "review.md"))
+	})
+
+	t.Run("missing plugin path", func(t *testing.T) {
+		_, err := getIDE(ExecPrefix, nil)
+		assert.ErrorIs(t, err, ErrUnsupportedIDE)
+	})
+}
@@ -3,25 +3,147 @@ package executable
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
+	"google.golang.org/protobuf/proto"
 )
 
-func ForRecipe(recipe *adcp.ExecutableRecipe) *Recipe {
-	return &Recipe{recipe}
+// ForRecipe returns an executable Recipe; opts configure the underlying recipes.Recipe.
+func ForRecipe(recipe *adcp.ExecutableRecipe, opts ...recipes.Option) *Recipe {
+	return &Recipe{recipe: recipe, opts: opts}
 }
 
 type Recipe struct {
 	recipe *adcp.ExecutableRecipe
+	opts   []recipes.Option
+	// ideOpts holds options applied only for an IDE type, keyed by the lowercase type.
+	ideOpts map[string][]recipes.Option
 }
 
+// WithIDEOptions adds opts for materializing for ideType only, after the options of ForRecipe, e.g.
+// per-IDE path overrides:
+//
+//	r.WithIDEOptions("claude", recipes.WithPathOverrides(map[string]string{
+//		shared.PathCommands: ".claude/commands/team",
+//	}))
+func (r *Recipe) WithIDEOptions(ideType string, opts ...recipes.Option) *Recipe {
+	if r.ideOpts == nil {
+		r.ideOpts = map[string][]recipes.Option{}
+	}
+	key := strings.ToLower(ideType)
+	r.ideOpts[key] = append(r.ideOpts[key], opts...)
+	return r
+}
+
+// Materialize materializes the recipe for the IDE type of the entry point. The IDE type may list
+// several IDEs separated by commas, e.g. "claude,cursor": the recipe is then materialized for each
+// of them in turn and the entries are merged. Commands and fetches run once and their content is
+// shared by all IDEs (see core.SourceCache), so every IDE renders the same inputs. Identical files
+// written by several IDEs are kept once, and the managed sections several IDEs write into the same
+// file, e.g. AGENTS.md, are combined; other differences for the same path fail with
+// ErrPathConflict.
 func (r *Recipe) Materialize(ctx context.Context) (*adcp.MaterializedResult, error) {
-	ideType := r.recipe.GetEntryPoint().GetIdeType()
-	ide, err := getIDE(ideType)
+	ideTypes := splitIDETypes(r.recipe.GetEntryPoint().GetIdeType())
+	switch len(ideTypes) {
+	case 0:
+		return r.materialize(ctx, r.recipe.GetEntryPoint().GetIdeType())
+	case 1:
+		return r.materialize(ctx, ideTypes[0])
+	}
+	if core.SourceCacheFrom(ctx) == nil {
+		ctx = core.WithSourceCache(ctx, core.NewSourceCache())
+	}
+	var entries []*adcp.MaterializedResult_Entry
+	// written maps each path to the index of its entry and the IDE types that produced it.
+	index := map[string]int{}
+	written := map[string][]string{}
+	for _, ideType := range ideTypes {
+		res, err := r.materialize(ctx, ideType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to materialize for %s: %w", ideType, err)
+		}
+		for _, entry := range res.GetEntries() {
+			f := entry.GetFile()
+			i, ok := index[f.GetPath()]
+			if !ok {
+				index[f.GetPath()] = len(entries)
+				written[f.GetPath()] = []string{ideType}
+				entries = append(entries, entry)
+				continue
+			}
+			merged, err := mergeEntry(ctx, entries[i], entry)
+			if err != nil {
+				return nil, err
+			}
+			if merged == nil {
+				return nil, fmt.Errorf("%w: %s is written by both %s and %s", ErrPathConflict, f.GetPath(),
+					strings.Join(written[f.GetPath()], ", "), ideType)
+			}
+			entries[i] = merged
+			written[f.GetPath()] = append(written[f.GetPath()], ideType)
+		}
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// mergeEntry returns the entry combining prev and next for the same path, or nil if they conflict.
+func mergeEntry(ctx context.Context, prev, next *adcp.MaterializedResult_Entry) (*adcp.MaterializedResult_Entry, error) {
+	if prev.GetFile().GetContent() == next.GetFile().GetContent() {
+		return prev, nil
+	}
+	// Content streamed to blobs is compared and merged by value.
+	blobs := core.BlobsFrom(ctx)
+	a, err := blobs.Resolve(prev.GetFile().GetContent())
+	if err != nil {
+		return nil, err
+	}
+	b, err := blobs.Resolve(next.GetFile().GetContent())
+	if err != nil {
+		return nil, err
+	}
+	if a == b {
+		return prev, nil
+	}
+	content, ok := mergeManagedSections(a, b)
+	if !ok {
+		return nil, nil
+	}
+	merged := proto.CloneOf(prev)
+	merged.GetFile().SetContent(content)
+	return merged, nil
+}
+
+func (r *Recipe) materialize(ctx context.Context, ideType string) (*adcp.MaterializedResult, error) {
+	ide, err := getIDE(ideType, r.recipe.GetRecipe())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get IDE: %w", err)
 	}
-	rec := &recipes.Recipe{IDE: ide}
-	return rec.Materialize(ctx, r.recipe.GetRecipe())
+	opts := append(slices.Clip(r.opts), r.ideOpts[strings.ToLower(ideType)]...)
+	rec := recipes.New(ide, opts...)
+	return rec.Materialize(ctx, withoutGenericConfig(r.recipe.GetRecipe()))
+}
+
+// ManagedPaths is like the package-level ManagedPaths for the IDE type of the entry point,
+// including the generic IDE configured by the recipe.
+func (r *Recipe) ManagedPaths() ([]recipes.ManagedPath, error) {
+	return managedPaths(r.recipe.GetEntryPoint().GetIdeType(), r.recipe.GetRecipe())
+}
+
+// splitIDETypes returns the distinct, non-empty IDE types of a comma-separated list, in order.
+func splitIDETypes(s string) []string {
+	var types []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		types = append(types, t)
+	}
+	return types
 }
@@ -2,8 +2,13 @@ package executable
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,6 +20,7 @@ func TestExecutableRecipe_Materialize_Table(t *testing.T) {
 		ideType     string
 		execRecipe  adcp.ExecutableRecipe_builder
 		wantErrSub  string
+		wantErrIs   error
 		wantEntries int
 	}{
 		{
@@ -33,6 +39,7 @@ func TestExecutableRecipe_Materialize_Table(t *testing.T) {
 				Recipe: adcp.Recipe_builder{}.Build(),
 			},
 			wantErrSub: "failed to get IDE",
+			wantErrIs:  ErrUnsupportedIDE,
 		},
 		{
 			name:    "error: underlying recipes.Materialize rejects nil Recipe",
@@ -40,6 +47,7 @@ func TestExecutableRecipe_Materialize_Table(t *testing.T) {
 			// leave Recipe unset so GetRecipe() returns nil
 			execRecipe: adcp.ExecutableRecipe_builder{},
 			wantErrSub: "recipe cannot be nil",
+			wantErrIs:  recipes.ErrNilRecipe,
 		},
 	}
 
@@ -54,6 +62,9 @@ func TestExecutableRecipe_Materialize_Table(t *testing.T) {
 			if tt.wantErrSub != "" {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.wantErrSub)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
 				return
 			}
 			require.NoError(t, err)
@@ -64,3 +75,140 @@ func TestExecutableRecipe_Materialize_Table(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutableRecipe_Materialize_MultiIDE(t *testing.T) {
+	registerStubIDE(t, "alpha", stubIDE{files: map[string]string{"AGENTS.md": "shared", ".alpha/a.md": "a"}})
+	registerStubIDE(t, "beta", stubIDE{files: map[string]string{"AGENTS.md": "shared", ".beta/b.md": "b"}})
+	registerStubIDE(t, "gamma", stubIDE{files: map[string]string{"AGENTS.md": "different"}})
+	registerStubIDE(t, "delta", stubIDE{files: map[string]string{"AGENTS.md": "# Project\n\n<!-- adcp:begin -->\nDelta.\n<!-- adcp:end -->\n"}})
+	registerStubIDE(t, "epsilon", stubIDE{files: map[string]string{"AGENTS.md": "# Project\n\n<!-- adcp:begin -->\nEpsilon.\n<!-- adcp:end -->\n"}})
+	registerStubIDE(t, "zeta", stubIDE{files: map[string]string{"AGENTS.md": "# Other\n\n<!-- adcp:begin -->\nZeta.\n<!-- adcp:end -->\n"}})
+
+	tests := []struct {
+		name       string
+		ideType    string
+		wantPaths  []string
+		wantAgents string
+		wantErrIs  error
+		wantErr    string
+	}{
+		{
+			name:      "entries of all IDEs are merged, identical files kept once",
+			ideType:   "alpha, beta",
+			wantPaths: []string{".alpha/a.md", "AGENTS.md", ".beta/b.md"},
+		},
+		{
+			name:      "repeated and empty IDE types are ignored",
+			ideType:   "alpha,,ALPHA,",
+			wantPaths: []string{".alpha/a.md", "AGENTS.md"},
+		},
+		{
+			name:      "different content for the same path conflicts",
+			ideType:   "alpha,gamma",
+			wantErrIs: ErrPathConflict,
+			wantErr:   "AGENTS.md is written by both alpha and gamma",
+		},
+		{
+			name:       "managed sections of the same file are combined",
+			ideType:    "delta,epsilon,delta",
+			wantPaths:  []string{"AGENTS.md"},
+			wantAgents: "# Project\n\n<!-- adcp:begin -->\nDelta.\n\nEpsilon.\n<!-- adcp:end -->\n",
+		},
+		{
+			name:      "different content outside of managed sections conflicts",
+			ideType:   "delta,zeta",
+			wantErrIs: ErrPathConflict,
+			wantErr:   "AGENTS.md is written by both delta and zeta",
+		},
+		{
+			name:      "unsupported IDE type fails",
+			ideType:   "alpha,unknown-ide",
+			wantErrIs: ErrUnsupportedIDE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := adcp.ExecutableRecipe_builder{
+				EntryPoint: adcp.EntryPoint_builder{IdeType: tt.ideType}.Build(),
+				Recipe:     adcp.Recipe_builder{Ide: adcp.Ide_builder{}.Build()}.Build(),
+			}.Build()
+			res, err := ForRecipe(exec).Materialize(context.Background())
+			if tt.wantErrIs != nil {
+				require.ErrorIs(t, err, tt.wantErrIs)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var paths []string
+			for _, e := range res.GetEntries() {
+				paths = append(paths, e.GetFile().GetPath())
+				if tt.wantAgents != "" && e.GetFile().GetPath() == "AGENTS.md" {
+					assert.Equal(t, tt.wantAgents, e.GetFile().GetContent())
+				}
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestExecutableRecipe_Materialize_MultiIDE_SharedSources(t *testing.T) {
+	registerStubIDE(t, "alpha", stubIDE{files: map[string]string{".alpha/a.md": "a"}})
+	registerStubIDE(t, "beta", stubIDE{files: map[string]string{".beta/b.md": "b"}})
+	dir := t.TempDir()
+	// Every execution prints a new number, like a timestamp would.
+	counter := "n=$(cat count 2>/dev/null || echo 0); n=$((n+1)); echo $n > count; echo $n"
+	exec := adcp.ExecutableRecipe_builder{
+		EntryPoint: adcp.EntryPoint_builder{IdeType: "alpha,beta"}.Build(),
+		Recipe: adcp.Recipe_builder{
+			Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{Path: "run.md", From: adcp.ContextFrom_builder{Cmd: &counter}.Build()}.Build(),
+			}}.Build(),
+			Ide: adcp.Ide_builder{}.Build(),
+		}.Build(),
+	}.Build()
+
+	res, err := ForRecipe(exec).Materialize(core.WithCommandDir(context.Background(), dir))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, e := range res.GetEntries() {
+		files[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, map[string]string{"run.md": "1\n", ".alpha/a.md": "a", ".beta/b.md": "b"}, files)
+	count, err := os.ReadFile(filepath.Join(dir, "count"))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n", string(count), "the command runs once for all IDEs")
+}
+
+func TestExecutableRecipe_Materialize_PathOverrides(t *testing.T) {
+	text := "Review the diff."
+	exec := adcp.ExecutableRecipe_builder{
+		EntryPoint: adcp.EntryPoint_builder{IdeType: "claude"}.Build(),
+		Recipe: adcp.Recipe_builder{Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: &text}.Build()}.Build(),
+			}}.Build(),
+		}.Build()}.Build(),
+	}.Build()
+
+	res, err := ForRecipe(exec).
+		WithIDEOptions("cursor", recipes.WithPathOverrides(map[string]string{shared.PathCommands: ".cursor/ignored"})).
+		WithIDEOptions("Claude", recipes.WithPathOverrides(map[string]string{shared.PathCommands: ".claude/commands/team"})).
+		Materialize(context.Background())
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Contains(t, paths, ".claude/commands/team/review.md")
+
+	_, err = ForRecipe(exec, recipes.WithPathOverrides(map[string]string{"hooks": ".claude/hooks"})).Materialize(context.Background())
+	assert.ErrorIs(t, err, shared.ErrUnsupportedPathOverride)
+
+	registerStubIDE(t, "plain", stubIDE{})
+	exec = adcp.ExecutableRecipe_builder{
+		EntryPoint: adcp.EntryPoint_builder{IdeType: "plain"}.Build(),
+		Recipe:     adcp.Recipe_builder{}.Build(),
+	}.Build()
+	_, err = ForRecipe(exec, recipes.WithPathOverrides(map[string]string{shared.PathCommands: "x"})).Materialize(context.Background())
+	assert.ErrorIs(t, err, recipes.ErrPathOverridesUnsupported)
+}
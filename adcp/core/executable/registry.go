@@ -0,0 +1,19 @@
+package executable
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp-core/adcp/core/registry"
+)
+
+// FromRegistry fetches the recipe referenced by ref, e.g. "registry://org/backend-recipe@1.4.0",
+// from client and returns it as an executable Recipe. Without a version, the latest one is used.
+func FromRegistry(ctx context.Context, client *registry.Client, ref string, opts ...recipes.Option) (*Recipe, error) {
+	recipe, _, err := client.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return ForRecipe(recipe, opts...), nil
+}
@@ -0,0 +1,35 @@
+package executable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/recipes/org/backend-recipe/versions/1.4.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"entryPoint": {"ideType": "claude"}, "recipe": {"context": {"entries": [{"path": "a.md", "from": {"text": "hi"}}]}}}`))
+	}))
+	defer srv.Close()
+	client := registry.NewClient(srv.URL, registry.WithHTTPClient(srv.Client()))
+	ctx := context.Background()
+
+	re, err := FromRegistry(ctx, client, "registry://org/backend-recipe@1.4.0")
+	require.NoError(t, err)
+	res, err := re.Materialize(ctx)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "hi", res.GetEntries()[0].GetFile().GetContent())
+
+	_, err = FromRegistry(ctx, client, "registry://org/backend-recipe@2.0.0")
+	assert.ErrorIs(t, err, registry.ErrNotFound)
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizePath returns p in Unicode normalization form C. macOS tends to produce decomposed
+// (NFD) names while Linux keeps names as given, so the same recipe would otherwise write different
+// files, e.g. "レビュー.md", depending on where its names were typed.
+func NormalizePath(p string) string {
+	return norm.NFC.String(p)
+}
+
+// reservedNames are device names Windows does not allow as file names, with or without extension.
+var reservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// IsReservedName reports whether the file name, e.g. "aux.md", is reserved on Windows.
+func IsReservedName(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	return reservedNames[strings.ToLower(strings.TrimRight(base, " "))]
+}
+
+// NonPortablePath describes why the slash-separated path p cannot be written on every supported
+// OS, or returns "" if it can.
+func NonPortablePath(p string) string {
+	for _, segment := range strings.Split(p, "/") {
+		switch {
+		case segment == "" || segment == "." || segment == "..":
+			continue
+		case IsReservedName(segment):
+			return fmt.Sprintf("%q is a reserved file name on Windows", segment)
+		case strings.HasSuffix(segment, ".") || strings.HasSuffix(segment, " "):
+			return fmt.Sprintf("%q ends with a dot or space, which Windows drops", segment)
+		case strings.ContainsAny(segment, `<>:"\|?*`):
+			return fmt.Sprintf("%q contains a character not allowed on Windows", segment)
+		case strings.ContainsFunc(segment, unicode.IsControl):
+			return fmt.Sprintf("%q contains a control character", segment)
+		}
+	}
+	return ""
+}
+
+// Transliterate approximates s with ASCII: accents are removed ("café" becomes "cafe"), Japanese
+// kana are romanized in Hepburn style ("レビュー" becomes "rebyu") and other non-ASCII characters,
+// such as kanji, are dropped.
+func Transliterate(s string) string {
+	var b strings.Builder
+	// Compatibility composition turns half-width katakana into full-width ones and keeps voiced
+	// kana, e.g. "ガ", in one piece.
+	runes := []rune(norm.NFKC.String(s))
+	geminate := false
+	for i := 0; i < len(runes); i++ {
+		r := toHiragana(runes[i])
+		if _, ok := kana[r]; !ok && r != 'っ' {
+			// Keep the ASCII part of other characters, dropping accents.
+			for _, d := range norm.NFD.String(string(r)) {
+				if d <= unicode.MaxASCII {
+					b.WriteRune(d)
+				}
+			}
+			geminate = false
+			continue
+		}
+		if r == 'っ' {
+			geminate = true
+			continue
+		}
+		syllable := kana[r]
+		// A syllable ending in "i" followed by a small ya, yu or yo forms a digraph, e.g. "kya".
+		if i+1 < len(runes) && strings.HasSuffix(syllable, "i") {
+			if v, ok := smallY[toHiragana(runes[i+1])]; ok {
+				base := strings.TrimSuffix(syllable, "i")
+				if !strings.HasSuffix(base, "sh") && !strings.HasSuffix(base, "ch") && base != "j" {
+					base += "y"
+				}
+				syllable = base + v
+				i++
+			}
+		}
+		if geminate {
+			if strings.HasPrefix(syllable, "ch") {
+				b.WriteByte('t')
+			} else if c := syllable[0]; !strings.ContainsRune("aeiou", rune(c)) {
+				b.WriteByte(c)
+			}
+			geminate = false
+		}
+		b.WriteString(syllable)
+	}
+	return b.String()
+}
+
+// toHiragana maps katakana to the corresponding hiragana.
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 0x60
+	}
+	return r
+}
+
+var smallY = map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o"}
+
+var kana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo", 'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'を': "o", 'ん': "n", 'ゔ': "vu",
+}
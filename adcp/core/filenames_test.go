@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePath(t *testing.T) {
+	// "が" decomposed into "か" and a combining voiced sound mark, as produced on macOS.
+	assert.Equal(t, "docs/が.md", NormalizePath("docs/が.md"))
+	assert.Equal(t, "docs/café.md", NormalizePath("docs/café.md"))
+	assert.Equal(t, "AGENTS.md", NormalizePath("AGENTS.md"))
+}
+
+func TestNonPortablePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		portable bool
+	}{
+		{path: ".claude/commands/review.md", portable: true},
+		{path: "docs/レビュー.md", portable: true},
+		{path: "../docs/a.md", portable: true},
+		{path: "docs/aux.md"},
+		{path: "CON/notes.md"},
+		{path: "docs/lpt1"},
+		{path: "docs/notes."},
+		{path: "docs/what?.md"},
+		{path: "docs/a\tb.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.portable, NonPortablePath(tt.path) == "", NonPortablePath(tt.path))
+		})
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := map[string]string{
+		"review":     "review",
+		"Café Check": "Cafe Check",
+		"レビュー":       "rebyu",
+		"しゅくだい":      "shukudai",
+		"ちょっと":       "chotto",
+		"マッチ":        "matchi",
+		"きょう":        "kyou",
+		"ジャンプ":       "janpu",
+		"日本語のテスト":    "notesuto",
+		"ｶﾀｶﾅ":       "katakana",
+	}
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, want, Transliterate(in))
+		})
+	}
+}
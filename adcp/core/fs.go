@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type fsKey struct{}
+
+type producedFilesKey struct{}
+
+// WithFS returns a copy of ctx whose reads of existing target files go to fsys instead of the
+// working directory, e.g. an in-memory snapshot of the target repository in tests.
+func WithFS(ctx context.Context, fsys fs.FS) context.Context {
+	return context.WithValue(ctx, fsKey{}, fsys)
+}
+
+// WithProducedFiles returns a copy of ctx whose reads of the given files, keyed by path, return
+// their content as produced earlier in the same materialization instead of what is on disk, e.g.
+// so that instructions merged into AGENTS.md keep the context merged into it before.
+func WithProducedFiles(ctx context.Context, files map[string]string) context.Context {
+	return context.WithValue(ctx, producedFilesKey{}, files)
+}
+
+// ReadFile reads name from the files produced earlier (see WithProducedFiles), then from the
+// filesystem carried by ctx, or from the OS relative to the working directory if there is none.
+func ReadFile(ctx context.Context, name string) ([]byte, error) {
+	if files, ok := ctx.Value(producedFilesKey{}).(map[string]string); ok {
+		if content, ok := files[name]; ok {
+			return []byte(content), nil
+		}
+	}
+	if fsys, ok := ctx.Value(fsKey{}).(fs.FS); ok && fsys != nil {
+		return fs.ReadFile(fsys, name)
+	}
+	return os.ReadFile(name)
+}
+
+// ResolvePath returns path relative to CommandDir(ctx), e.g. the root of the repository being
+// materialized, so files adcp writes besides the materialized result, such as the debug dump, end
+// up next to it rather than in the working directory. Absolute paths and paths outside of a
+// command directory are returned unchanged.
+func ResolvePath(ctx context.Context, path string) string {
+	if dir := CommandDir(ctx); dir != "" && !filepath.IsAbs(path) {
+		return filepath.Join(dir, path)
+	}
+	return path
+}
@@ -10,31 +10,70 @@ import (
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
-type Context struct{}
+type Context struct {
+	// MapPath optionally rewrites entry paths, e.g. to resolve logical destinations for the target IDE.
+	MapPath func(path string) (string, error)
+	// Render optionally adapts the content of an entry to the target IDE, given its mapped path,
+	// e.g. to add frontmatter to rule files.
+	Render func(path, content string) (string, error)
+	// Merge optionally combines the rendered content of an entry with the existing file at its
+	// mapped path, e.g. to only replace a managed section. Unless Render is set, content may be a
+	// blob reference (see core.Blobs.Resolve).
+	Merge func(ctx context.Context, path, content string) (string, error)
+}
 
 func (c *Context) Materialize(ctx context.Context, contextMsg *adcp.Context, genCtx *core.GenerationContext) (*adcp.MaterializedResult, error) {
-	if contextMsg == nil {
-		return nil, fmt.Errorf("context cannot be nil")
+	var resultEntries []*adcp.MaterializedResult_Entry
+	err := c.MaterializeFunc(ctx, contextMsg, genCtx, func(entry *adcp.MaterializedResult_Entry) error {
+		resultEntries = append(resultEntries, entry)
+		return nil
+	})
+	if err != nil && !core.IsBestEffort(ctx) {
+		return nil, err
 	}
 
-	entries := contextMsg.GetEntries()
-	if entries == nil {
-		return adcp.MaterializedResult_builder{}.Build(), nil
-	}
+	return adcp.MaterializedResult_builder{
+		Entries: resultEntries,
+	}.Build(), err
+}
 
-	var resultEntries []*adcp.MaterializedResult_Entry
+// EmitError wraps an error returned by the callback of MaterializeFunc, distinguishing it from
+// failures of entries.
+type EmitError struct {
+	Err error
+}
+
+func (e *EmitError) Error() string { return e.Err.Error() }
+
+func (e *EmitError) Unwrap() error { return e.Err }
 
-	for _, entry := range entries {
+// MaterializeFunc materializes entries one by one, passing each to fn as soon as it is produced.
+// All entries are attempted and their failures returned together as a *core.MultiError, unless
+// fn fails, which stops immediately with an *EmitError.
+func (c *Context) MaterializeFunc(ctx context.Context, contextMsg *adcp.Context, genCtx *core.GenerationContext, fn func(*adcp.MaterializedResult_Entry) error) error {
+	if contextMsg == nil {
+		return fmt.Errorf("context cannot be nil")
+	}
+
+	// All entries are attempted so that every failure is reported at once.
+	errs := &core.MultiError{}
+	observer := core.ObserverFrom(ctx)
+	for _, entry := range contextMsg.GetEntries() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		observer.EntryStarted(core.PhaseContext, entry.GetPath())
 		materializedEntry, err := c.materializeEntry(ctx, entry, genCtx)
+		observer.EntryFinished(core.PhaseContext, entry.GetPath(), err)
 		if err != nil {
-			return nil, fmt.Errorf("failed to materialize entry for path %s: %w", entry.GetPath(), err)
+			errs.Append(&core.EntryError{Path: entry.GetPath(), Source: entry.GetFrom().WhichType().String(), Err: err})
+			continue
+		}
+		if err := fn(materializedEntry); err != nil {
+			return &EmitError{Err: err}
 		}
-		resultEntries = append(resultEntries, materializedEntry)
 	}
-
-	return adcp.MaterializedResult_builder{
-		Entries: resultEntries,
-	}.Build(), nil
+	return errs.ErrorOrNil()
 }
 
 func (c *Context) materializeEntry(ctx context.Context, entry *adcp.ContextEntry, genCtx *core.GenerationContext) (*adcp.MaterializedResult_Entry, error) {
@@ -42,6 +81,17 @@ func (c *Context) materializeEntry(ctx context.Context, entry *adcp.ContextEntry
 	if path == "" {
 		return nil, fmt.Errorf("entry path cannot be empty")
 	}
+	if c.MapPath != nil {
+		mapped, err := c.MapPath(path)
+		if err != nil {
+			return nil, err
+		}
+		path = mapped
+	}
+	path = core.NormalizePath(path)
+	if reason := core.NonPortablePath(path); reason != "" {
+		core.Warn(ctx, core.Warning{Code: core.WarningNonPortablePath, Path: path, Message: reason})
+	}
 
 	if !entry.HasFrom() {
 		return nil, fmt.Errorf("entry must have a 'from' source")
@@ -51,6 +101,22 @@ func (c *Context) materializeEntry(ctx context.Context, entry *adcp.ContextEntry
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch content: %w", err)
 	}
+	if note := dryRunNote(entry.GetFrom(), genCtx); note != "" && core.IsDryRun(ctx) {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: path, Merge: core.MergeReplace, Note: note})
+	}
+	if c.Render != nil {
+		if content, err = core.BlobsFrom(ctx).Resolve(content); err != nil {
+			return nil, err
+		}
+		if content, err = c.Render(path, content); err != nil {
+			return nil, fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	if c.Merge != nil {
+		if content, err = c.Merge(ctx, path, content); err != nil {
+			return nil, fmt.Errorf("failed to merge content: %w", err)
+		}
+	}
 
 	return adcp.MaterializedResult_Entry_builder{
 		File: adcp.FullFileContent_builder{
@@ -69,20 +135,30 @@ func (c *Context) fetchContent(ctx context.Context, from *adcp.ContextFrom, genC
 	case adcp.ContextFrom_Text_case:
 		return from.GetText(), nil
 
+	// Content of these sources is written to the file unchanged, so large content may be streamed.
 	case adcp.ContextFrom_Cmd_case:
-		return utils2.ExecuteCommand(ctx, from.GetCmd())
+		if core.IsDryRun(ctx) {
+			// Commands may have side effects, so a dry run renders a placeholder instead.
+			return fmt.Sprintf("<output of %s>\n", from.GetCmd()), nil
+		}
+		return utils2.ExecuteCommand(core.WithStreaming(ctx), from.GetCmd())
 
 	case adcp.ContextFrom_Github_case:
-		return utils2.FetchGithub(ctx, from.GetGithub())
+		return utils2.FetchGithub(core.WithStreaming(ctx), from.GetGithub())
 
 	case adcp.ContextFrom_Combined_case:
 		return c.fetchCombined(ctx, from.GetCombined(), genCtx)
 
 	case adcp.ContextFrom_PrefetchId_case:
 		data, ok := genCtx.GetPrefetched()[from.GetPrefetchId()]
+		if !ok && core.IsDryRun(ctx) {
+			// Prefetch commands are not executed in a dry run.
+			return fmt.Sprintf("<prefetched %s>\n", from.GetPrefetchId()), nil
+		}
 		if !ok {
 			return "", fmt.Errorf("prefetch id [%v] not found", from.GetPrefetchId())
 		}
+		core.MetricsFrom(ctx).IncCounter(core.MetricCacheHits, map[string]string{"source": "prefetch"})
 		return data.GetData(), nil
 
 	default:
@@ -90,6 +166,31 @@ func (c *Context) fetchContent(ctx context.Context, from *adcp.ContextFrom, genC
 	}
 }
 
+// dryRunNote explains why a dry run renders placeholders instead of the content of from, or
+// returns "" if it renders the real content.
+func dryRunNote(from *adcp.ContextFrom, genCtx *core.GenerationContext) string {
+	switch from.WhichType() {
+	case adcp.ContextFrom_Cmd_case:
+		return "command not executed: " + from.GetCmd()
+	case adcp.ContextFrom_PrefetchId_case:
+		if _, ok := genCtx.GetPrefetched()[from.GetPrefetchId()]; !ok {
+			return "prefetch command not executed: " + from.GetPrefetchId()
+		}
+	case adcp.ContextFrom_Combined_case:
+		for _, item := range from.GetCombined().GetItems() {
+			switch item.WhichType() {
+			case adcp.CombinedContextSource_Item_Cmd_case:
+				return "command not executed: " + item.GetCmd()
+			case adcp.CombinedContextSource_Item_PrefetchId_case:
+				if _, ok := genCtx.GetPrefetched()[item.GetPrefetchId()]; !ok {
+					return "prefetch command not executed: " + item.GetPrefetchId()
+				}
+			}
+		}
+	}
+	return ""
+}
+
 func (c *Context) fetchCombined(ctx context.Context, combined *adcp.CombinedContextSource, genCtx *core.GenerationContext) (string, error) {
 	if combined == nil {
 		return "", fmt.Errorf("combined source cannot be nil")
@@ -122,6 +223,9 @@ func (c *Context) fetchCombinedItem(ctx context.Context, item *adcp.CombinedCont
 		return item.GetText(), nil
 
 	case adcp.CombinedContextSource_Item_Cmd_case:
+		if core.IsDryRun(ctx) {
+			return fmt.Sprintf("<output of %s>\n", item.GetCmd()), nil
+		}
 		return utils2.ExecuteCommand(ctx, item.GetCmd())
 
 	case adcp.CombinedContextSource_Item_Github_case:
@@ -129,9 +233,13 @@ func (c *Context) fetchCombinedItem(ctx context.Context, item *adcp.CombinedCont
 
 	case adcp.CombinedContextSource_Item_PrefetchId_case:
 		data, ok := genCtx.GetPrefetched()[item.GetPrefetchId()]
+		if !ok && core.IsDryRun(ctx) {
+			return fmt.Sprintf("<prefetched %s>\n", item.GetPrefetchId()), nil
+		}
 		if !ok {
 			return "", fmt.Errorf("prefetch id [%v] not found", item.GetPrefetchId())
 		}
+		core.MetricsFrom(ctx).IncCounter(core.MetricCacheHits, map[string]string{"source": "prefetch"})
 		return data.GetData(), nil
 
 	default:
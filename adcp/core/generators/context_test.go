@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	core2 "github.com/devplaninc/adcp-core/adcp/core"
@@ -135,6 +136,24 @@ func TestContext_MaterializeEntry(t *testing.T) {
 	}
 }
 
+func TestContext_MaterializeEntry_UnicodePaths(t *testing.T) {
+	report := core2.NewReport()
+	ctx := core2.WithReport(context.Background(), report)
+	c := &Context{}
+
+	// Decomposed (NFD) path as produced on macOS.
+	entry, err := c.materializeEntry(ctx, contextEntry("docs/\u30d2\u3099.md", textFrom("x")), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "docs/ビ.md", entry.GetFile().GetPath())
+	assert.Empty(t, report.Data().Warnings)
+
+	entry, err = c.materializeEntry(ctx, contextEntry("docs/aux.md", textFrom("x")), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "docs/aux.md", entry.GetFile().GetPath())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core2.WarningNonPortablePath, report.Data().Warnings[0].Code)
+}
+
 func TestContext_FetchContent(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -192,6 +211,34 @@ func TestContext_FetchContent(t *testing.T) {
 	}
 }
 
+func TestContext_FetchContent_DryRun(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	ctx := core2.WithDryRun(context.Background(), nil)
+	c := &Context{}
+
+	content, err := c.fetchContent(ctx, cmdFrom("touch "+marker), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "<output of touch "+marker+">\n", content)
+
+	content, err = c.fetchContent(ctx, combinedFrom(combinedCmdItem("touch "+marker)), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "<output of touch "+marker+">\n", content)
+
+	content, err = c.fetchContent(ctx, adcp.ContextFrom_builder{PrefetchId: strPtr("docs")}.Build(), &core2.GenerationContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "<prefetched docs>\n", content, "prefetch commands are not run either")
+
+	assert.NoFileExists(t, marker)
+
+	plan := &core2.Plan{}
+	entry := adcp.ContextEntry_builder{Path: "out.md", From: cmdFrom("date")}.Build()
+	_, err = c.materializeEntry(core2.WithDryRun(context.Background(), plan), entry, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []core2.PlannedChange{
+		{Path: "out.md", Merge: core2.MergeReplace, Note: "command not executed: date"},
+	}, plan.Changes(), "placeholders are reported in the plan")
+}
+
 func TestUtils_ExecuteCommand(t *testing.T) {
 	tests := []struct {
 		name    string
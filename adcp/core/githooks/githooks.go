@@ -0,0 +1,149 @@
+// Package githooks materializes git hooks declared by recipes, complementing IDE agent
+// configuration with repository-level guardrails such as pre-commit checks.
+package githooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/utils"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultDir is where git looks for hooks unless core.hooksPath is configured. When hooks are
+// materialized into another directory, e.g. a committed ".githooks", point core.hooksPath to it.
+//
+// In linked worktrees and submodules, .git is a file pointing to a git directory elsewhere, whose
+// hooks are outside the repository and cannot be materialized; DefaultDir then fails with
+// ErrLinkedGitDir, and hooks must go to a directory within the repository named by core.hooksPath.
+const DefaultDir = ".git/hooks"
+
+// DefaultShell is the interpreter of hook scripts without a shebang line.
+const DefaultShell = "/bin/sh"
+
+// ManagedMarker is added to every hook script so that hooks installed by adcp can be told apart
+// from hooks installed by hand or by other tools.
+const ManagedMarker = "# Managed by adcp. Local changes are overwritten."
+
+// ErrUnknownHook is returned for hook names git does not run.
+var ErrUnknownHook = errors.New("unknown git hook")
+
+// ErrLinkedGitDir is returned when hooks would be materialized into DefaultDir of a linked worktree
+// or submodule, whose .git is a file rather than a directory.
+var ErrLinkedGitDir = errors.New(".git is a file, not a directory")
+
+// Names lists the client-side hooks git runs.
+var Names = []string{
+	"applypatch-msg", "pre-applypatch", "post-applypatch",
+	"pre-commit", "pre-merge-commit", "prepare-commit-msg", "commit-msg", "post-commit",
+	"pre-rebase", "post-checkout", "post-merge", "pre-push", "pre-auto-gc", "post-rewrite",
+	"reference-transaction", "push-to-checkout", "post-index-change",
+}
+
+// Hook is a git hook, e.g. "pre-commit", whose script is taken from a text, command or GitHub source.
+type Hook struct {
+	Name string
+	From *adcp.CommandFrom
+}
+
+// Generator materializes hook scripts into Dir.
+type Generator struct {
+	// Dir is the hooks directory relative to the repository root. Defaults to DefaultDir.
+	Dir string
+}
+
+// Materialize returns one executable script per hook (see core.SetExecutable). Scripts without a shebang line run with
+// DefaultShell. Replacing an existing hook that was not installed by adcp is reported as a warning.
+// In best-effort mode, hooks that fail are skipped and returned as a *core.MultiError.
+func (g *Generator) Materialize(ctx context.Context, hooks []Hook) ([]*adcp.MaterializedResult_Entry, error) {
+	dir := g.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if dir == DefaultDir && len(hooks) > 0 {
+		if gitFile, err := core.ReadFile(ctx, ".git"); err == nil {
+			target := strings.TrimSpace(strings.TrimPrefix(string(gitFile), "gitdir:"))
+			return nil, fmt.Errorf("%w: hooks of %s cannot be installed into %s; materialize them into a directory set as core.hooksPath instead",
+				ErrLinkedGitDir, target, DefaultDir)
+		}
+	}
+	var entries []*adcp.MaterializedResult_Entry
+	failures := &core.MultiError{}
+	for _, h := range hooks {
+		p := path.Join(dir, h.Name)
+		entry, err := g.materializeHook(ctx, p, h)
+		if err != nil {
+			err = &core.EntryError{Path: p, Source: h.From.WhichType().String(), Err: err}
+			if !core.IsBestEffort(ctx) {
+				return nil, err
+			}
+			failures.Append(err)
+			continue
+		}
+		change := core.PlannedChange{Path: p, Merge: core.MergeReplace}
+		if h.From.WhichType() == adcp.CommandFrom_Cmd_case {
+			change.Note = "command not executed: " + h.From.GetCmd()
+		}
+		core.RecordPlannedChange(ctx, change)
+		entries = append(entries, entry)
+	}
+	return entries, failures.ErrorOrNil()
+}
+
+func (g *Generator) materializeHook(ctx context.Context, p string, h Hook) (*adcp.MaterializedResult_Entry, error) {
+	if !slices.Contains(Names, h.Name) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownHook, h.Name)
+	}
+	core.Logger(ctx).Debug("Materializing git hook", "name", h.Name, "path", p)
+	body, err := fetchScript(ctx, h.From)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := core.ReadFile(ctx, p); err == nil && !strings.Contains(string(existing), ManagedMarker) {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningReplacedGitHook,
+			Path:    p,
+			Message: fmt.Sprintf("Existing %s hook was not installed by adcp and is replaced", h.Name),
+		})
+	}
+	file := adcp.FullFileContent_builder{Path: p, Content: renderScript(body)}.Build()
+	core.SetExecutable(file)
+	return adcp.MaterializedResult_Entry_builder{File: file}.Build(), nil
+}
+
+func fetchScript(ctx context.Context, from *adcp.CommandFrom) (string, error) {
+	if from == nil || !from.HasType() {
+		return "", fmt.Errorf("hook 'from' source cannot be nil")
+	}
+	switch from.WhichType() {
+	case adcp.CommandFrom_Text_case:
+		return from.GetText(), nil
+	case adcp.CommandFrom_Cmd_case:
+		if core.IsDryRun(ctx) {
+			return fmt.Sprintf("# <output of %s>\n", from.GetCmd()), nil
+		}
+		return utils.ExecuteCommand(ctx, from.GetCmd())
+	case adcp.CommandFrom_Github_case:
+		return utils.FetchGithub(ctx, from.GetGithub())
+	default:
+		return "", fmt.Errorf("unknown or unset hook source type")
+	}
+}
+
+// renderScript puts ManagedMarker right after the shebang line of body, adding one if missing.
+func renderScript(body string) string {
+	shebang := "#!" + DefaultShell
+	if strings.HasPrefix(body, "#!") {
+		shebang, body, _ = strings.Cut(body, "\n")
+	}
+	body = strings.TrimLeft(body, "\n")
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return shebang + "\n" + ManagedMarker + "\n" + body
+}
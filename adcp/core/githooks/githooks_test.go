@@ -0,0 +1,105 @@
+package githooks
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func text(s string) *adcp.CommandFrom {
+	return adcp.CommandFrom_builder{Text: &s}.Build()
+}
+
+func TestGenerator_Materialize(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		hook     Hook
+		wantPath string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "script without shebang runs with the default shell",
+			hook:     Hook{Name: "pre-commit", From: text("make lint")},
+			wantPath: ".git/hooks/pre-commit",
+			want:     "#!/bin/sh\n" + ManagedMarker + "\nmake lint\n",
+		},
+		{
+			name:     "shebang is kept first",
+			dir:      ".githooks",
+			hook:     Hook{Name: "commit-msg", From: text("#!/usr/bin/env bash\n\ngrep -q JIRA \"$1\"\n")},
+			wantPath: ".githooks/commit-msg",
+			want:     "#!/usr/bin/env bash\n" + ManagedMarker + "\ngrep -q JIRA \"$1\"\n",
+		},
+		{
+			name:    "unknown hook",
+			hook:    Hook{Name: "pre-comit", From: text("true")},
+			wantErr: ErrUnknownHook,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := (&Generator{Dir: tt.dir}).Materialize(context.Background(), []Hook{tt.hook})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.wantPath, entries[0].GetFile().GetPath())
+			assert.Equal(t, tt.want, entries[0].GetFile().GetContent())
+			assert.True(t, core.IsExecutable(entries[0].GetFile()))
+		})
+	}
+}
+
+func TestGenerator_Materialize_ReplacedHook(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithReport(core.WithFS(context.Background(), fstest.MapFS{
+		".git/hooks/pre-commit": {Data: []byte("#!/bin/sh\nhusky run\n")},
+		".git/hooks/pre-push":   {Data: []byte("#!/bin/sh\n" + ManagedMarker + "\nold\n")},
+	}), report)
+
+	_, err := (&Generator{}).Materialize(ctx, []Hook{
+		{Name: "pre-commit", From: text("make lint")},
+		{Name: "pre-push", From: text("make test")},
+	})
+	require.NoError(t, err)
+	warnings := report.Data().Warnings
+	require.Len(t, warnings, 1)
+	assert.Equal(t, core.WarningReplacedGitHook, warnings[0].Code)
+	assert.Equal(t, ".git/hooks/pre-commit", warnings[0].Path)
+}
+
+func TestGenerator_Materialize_BestEffort(t *testing.T) {
+	entries, err := (&Generator{}).Materialize(core.WithBestEffort(context.Background()), []Hook{
+		{Name: "pre-commit", From: text("make lint")},
+		{Name: "pre-push"},
+	})
+	var multi *core.MultiError
+	require.ErrorAs(t, err, &multi)
+	require.Len(t, multi.Errors, 1)
+	assert.Equal(t, ".git/hooks/pre-push", multi.Errors[0].Path)
+	assert.Len(t, entries, 1)
+}
+
+func TestGenerator_Materialize_LinkedGitDir(t *testing.T) {
+	ctx := core.WithFS(context.Background(), fstest.MapFS{
+		".git": {Data: []byte("gitdir: /src/repo/.git/worktrees/feature\n")},
+	})
+	hooks := []Hook{{Name: "pre-commit", From: text("make lint")}}
+
+	_, err := (&Generator{}).Materialize(ctx, hooks)
+	assert.ErrorIs(t, err, ErrLinkedGitDir)
+	assert.ErrorContains(t, err, "/src/repo/.git/worktrees/feature")
+
+	entries, err := (&Generator{Dir: ".githooks"}).Materialize(ctx, hooks)
+	require.NoError(t, err)
+	assert.Equal(t, ".githooks/pre-commit", entries[0].GetFile().GetPath())
+}
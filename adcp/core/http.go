@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultHTTPClient is shared by all materializations that do not configure their own client, so
+// fetches reuse pooled keep-alive connections instead of paying a TLS handshake each time.
+var defaultHTTPClient = &http.Client{Transport: NewTransport()}
+
+// NewTransport returns an http.Transport tuned for fetching many files from a few hosts:
+// HTTP/2 where available, keep-alives with a generous idle pool per host, and bounded dial,
+// TLS handshake and response header timeouts. Proxy settings are taken from the environment.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   DefaultMaxConcurrency,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// DefaultHTTPClient returns the client used for remote fetches when none is configured. It is
+// shared process-wide and backed by NewTransport.
+func DefaultHTTPClient() *http.Client {
+	return defaultHTTPClient
+}
+
+type httpClientKey struct{}
+
+// WithHTTPClient returns a copy of ctx carrying client, used for all remote fetches.
+func WithHTTPClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, client)
+}
+
+// HTTPClient returns the HTTP client carried by ctx, or DefaultHTTPClient() if there is none.
+func HTTPClient(ctx context.Context) *http.Client {
+	if client, ok := ctx.Value(httpClientKey{}).(*http.Client); ok && client != nil {
+		return client
+	}
+	return defaultHTTPClient
+}
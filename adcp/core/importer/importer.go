@@ -0,0 +1,300 @@
+// Package importer synthesizes a recipe from the IDE configuration already present in a
+// repository, so teams can bootstrap recipes from what they have instead of transcribing it.
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipebuilder"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// commandFolders are scanned for command files, in order of precedence.
+var commandFolders = []string{".claude/commands", ".cursor/commands"}
+
+// mcpConfigPaths are scanned for MCP servers, in order of precedence.
+var mcpConfigPaths = []string{".mcp.json", ".cursor/mcp.json"}
+
+// settingsPaths are scanned for Claude permissions. Both files are combined.
+var settingsPaths = []string{".claude/settings.json", ".claude/settings.local.json"}
+
+// memoryFiles are imported verbatim as context files.
+var memoryFiles = []string{"CLAUDE.md", "AGENTS.md"}
+
+// contextFolders map IDE folders to the logical destinations of shared context entries, so the
+// imported recipe can be materialized for any IDE.
+var contextFolders = []struct{ dir, dest string }{
+	{".claude/rules", "@rules"},
+	{".cursor/rules", "@rules"},
+	{".claude/docs", "@docs"},
+	{".cursor/docs", "@docs"},
+}
+
+// Import scans fsys, the root of a repository, for Claude and Cursor configuration (commands,
+// rules, docs, memory files, MCP servers and permissions) and returns an equivalent recipe.
+// Content is imported as inline text. When both IDEs define the same command, rule or server, the
+// Claude one is kept. Configuration a recipe cannot express is left out and reported as a warning.
+func Import(ctx context.Context, fsys fs.FS) (*adcp.Recipe, error) {
+	im := &importer{fsys: fsys, b: recipebuilder.New(), seen: map[string]bool{}}
+	steps := []func(context.Context) error{im.memory, im.context, im.commands, im.mcp, im.permissions}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, err
+		}
+	}
+	recipe, err := im.b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build imported recipe: %w", err)
+	}
+	return recipe, nil
+}
+
+type importer struct {
+	fsys fs.FS
+	b    *recipebuilder.Builder
+	// seen tracks imported context paths, command names and server names to skip duplicates.
+	seen map[string]bool
+}
+
+// claim reports whether key is imported for the first time; duplicates are warned about.
+func (im *importer) claim(ctx context.Context, key, source string) bool {
+	if im.seen[key] {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningSkippedImport,
+			Path:    source,
+			Message: fmt.Sprintf("%s is already imported from another IDE", key),
+		})
+		return false
+	}
+	im.seen[key] = true
+	return true
+}
+
+func (im *importer) memory(ctx context.Context) error {
+	for _, p := range memoryFiles {
+		data, err := im.read(p)
+		if err != nil {
+			return err
+		}
+		if data != nil && im.claim(ctx, "context file "+p, p) {
+			im.b.WithContextFile(p, recipebuilder.Text(string(data)))
+		}
+	}
+	return nil
+}
+
+func (im *importer) context(ctx context.Context) error {
+	for _, folder := range contextFolders {
+		err := im.walk(folder.dir, func(p, rel string) error {
+			data, err := fs.ReadFile(im.fsys, p)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			dest := folder.dest + "/" + rel
+			if im.claim(ctx, "context file "+dest, p) {
+				im.b.WithContextFile(dest, recipebuilder.Text(string(data)))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) commands(ctx context.Context) error {
+	for _, folder := range commandFolders {
+		err := im.walk(folder, func(p, rel string) error {
+			name, ok := strings.CutSuffix(rel, ".md")
+			if !ok || strings.Contains(name, "/") {
+				core.Warn(ctx, core.Warning{
+					Code:    core.WarningSkippedImport,
+					Path:    p,
+					Message: "Only markdown files directly in the commands folder are imported as commands",
+				})
+				return nil
+			}
+			data, err := fs.ReadFile(im.fsys, p)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			if im.claim(ctx, "command "+name, p) {
+				im.b.WithCommand(name, recipebuilder.Text(string(data)))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mcpServerConfig struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+func (im *importer) mcp(ctx context.Context) error {
+	for _, p := range mcpConfigPaths {
+		data, err := im.read(p)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+		var config struct {
+			MCPServers map[string]mcpServerConfig `json:"mcpServers"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			core.Warn(ctx, core.Warning{Code: core.WarningInvalidExistingFile, Path: p, Message: err.Error()})
+			continue
+		}
+		names := make([]string, 0, len(config.MCPServers))
+		for name := range config.MCPServers {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			if im.claim(ctx, "MCP server "+name, p) {
+				im.importServer(ctx, p, name, config.MCPServers[name])
+			}
+		}
+	}
+	return nil
+}
+
+func (im *importer) importServer(ctx context.Context, p, name string, srv mcpServerConfig) {
+	warn := func(msg string) {
+		core.Warn(ctx, core.Warning{Code: core.WarningSkippedImport, Path: p, Message: fmt.Sprintf("MCP server %s: %s", name, msg)})
+	}
+	switch {
+	case srv.Command != "":
+		parts := append([]string{srv.Command}, srv.Args...)
+		if slices.ContainsFunc(parts, func(s string) bool { return strings.ContainsAny(s, " \t\n") }) {
+			warn("arguments containing whitespace are split when materialized")
+		}
+		if len(srv.Env) > 0 {
+			warn("env is not imported")
+		}
+		im.b.WithMCPStdio(name, strings.Join(parts, " "))
+	case srv.URL != "":
+		if len(srv.Headers) > 0 {
+			warn("headers are not imported")
+		}
+		im.b.WithMCPHTTP(name, srv.URL)
+	default:
+		warn("neither a command nor a url is set")
+	}
+}
+
+func (im *importer) permissions(ctx context.Context) error {
+	for _, p := range settingsPaths {
+		data, err := im.read(p)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+		var settings struct {
+			Permissions struct {
+				Allow []string `json:"allow"`
+				Deny  []string `json:"deny"`
+			} `json:"permissions"`
+		}
+		if err := json.Unmarshal(data, &settings); err != nil {
+			core.Warn(ctx, core.Warning{Code: core.WarningInvalidExistingFile, Path: p, Message: err.Error()})
+			continue
+		}
+		im.b.WithAllow(im.parsePermissions(ctx, p, "allow", settings.Permissions.Allow)...)
+		im.b.WithDeny(im.parsePermissions(ctx, p, "deny", settings.Permissions.Deny)...)
+	}
+	return nil
+}
+
+func (im *importer) parsePermissions(ctx context.Context, p, list string, rules []string) []*adcp.OperationPermission {
+	var perms []*adcp.OperationPermission
+	for _, rule := range rules {
+		// adcp derives these from the recipe's MCP servers and commands.
+		if strings.HasPrefix(rule, "mcp__") || strings.HasPrefix(rule, "SlashCommand(") {
+			continue
+		}
+		if !im.claim(ctx, list+" "+rule, p) {
+			continue
+		}
+		perm := parsePermission(rule)
+		if perm == nil {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningDroppedPermission,
+				Path:    p,
+				Message: fmt.Sprintf("Permission %s has no recipe equivalent (bash, read or write)", rule),
+			})
+			continue
+		}
+		perms = append(perms, perm)
+	}
+	return perms
+}
+
+// parsePermission converts a Claude permission rule such as "Bash(make test)" into a recipe permission.
+func parsePermission(rule string) *adcp.OperationPermission {
+	kind, rest, ok := strings.Cut(rule, "(")
+	pattern, closed := strings.CutSuffix(rest, ")")
+	if !ok || !closed {
+		return nil
+	}
+	switch kind {
+	case "Bash":
+		return recipebuilder.Bash(pattern)
+	case "Read":
+		return recipebuilder.Read(pattern)
+	case "Write":
+		return recipebuilder.Write(pattern)
+	}
+	return nil
+}
+
+// read returns the content of p, or nil if it does not exist.
+func (im *importer) read(p string) ([]byte, error) {
+	data, err := fs.ReadFile(im.fsys, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return data, nil
+}
+
+// walk calls fn for every regular file below dir in lexical order, with its path relative to dir.
+// Missing directories and adcp's own manifests are skipped.
+func (im *importer) walk(dir string, fn func(p, rel string) error) error {
+	return fs.WalkDir(im.fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == dir {
+			return fs.SkipDir
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", dir, err)
+		}
+		if d.IsDir() || !d.Type().IsRegular() || path.Base(p) == core.ManifestName {
+			return nil
+		}
+		rel := strings.TrimPrefix(p, dir+"/")
+		return fn(p, rel)
+	})
+}
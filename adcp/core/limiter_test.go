@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_BoundsConcurrency(t *testing.T) {
+	l := NewLimiter(2)
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, l.Acquire(context.Background()))
+			defer l.Release()
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestLimiter_AcquireCancelled(t *testing.T) {
+	l := NewLimiter(1)
+	require.NoError(t, l.Acquire(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, l.Acquire(ctx), context.Canceled)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	assert.Nil(t, NewLimiter(0))
+	assert.Nil(t, LimiterFrom(context.Background()))
+	assert.False(t, HasLimiter(context.Background()))
+
+	ctx := WithLimiter(context.Background(), NewLimiter(-1))
+	assert.True(t, HasLimiter(ctx))
+	l := LimiterFrom(ctx)
+	require.NoError(t, l.Acquire(ctx))
+	l.Release()
+}
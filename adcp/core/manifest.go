@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// ManifestName is the name of the files in which adcp records what it wrote. WriteManifest puts
+// one at the persist root; providers put one next to the files they track, e.g. in an IDE's
+// commands folder.
+const ManifestName = ".adcp-manifest.json"
+
+// ManifestGeneratedBy is the generatedBy value of every manifest adcp writes.
+const ManifestGeneratedBy = "adcp"
+
+// Manifest is the format of every manifest adcp writes. Each manifest fills only the fields it
+// needs.
+type Manifest struct {
+	GeneratedBy string `json:"generatedBy"`
+	// Files maps file paths, relative to the persist root, to the hex-encoded SHA-256 of their
+	// materialized content, so later runs can tell local edits apart from changes of the recipe
+	// (see DetectDrift).
+	Files map[string]string `json:"files,omitempty"`
+	// Commands lists the command files generated into a commands folder.
+	Commands []string `json:"commands,omitempty"`
+	// Entries lists the list entries merged into a settings file, keyed by their path in the file,
+	// e.g. "permissions.allow".
+	Entries map[string][]json.RawMessage `json:"entries,omitempty"`
+}
+
+// NewManifest returns the manifest of result. Content referencing a blob of the Blobs carried by
+// ctx is hashed from the blob.
+func NewManifest(ctx context.Context, result *adcp.MaterializedResult) (*Manifest, error) {
+	m := &Manifest{GeneratedBy: ManifestGeneratedBy, Files: map[string]string{}}
+	for _, e := range result.GetEntries() {
+		if !e.HasFile() {
+			continue
+		}
+		content, err := BlobsFrom(ctx).Resolve(e.GetFile().GetContent())
+		if err != nil {
+			return nil, err
+		}
+		m.Files[filepath.ToSlash(filepath.Clean(e.GetFile().GetPath()))] = hashContent([]byte(content))
+	}
+	return m, nil
+}
+
+// WriteManifest records the files of result in ManifestName under root, typically right after
+// PersistMaterializedResult with the same root. Like PersistMaterializedResult, a leading "~" in
+// root refers to the user's home directory and nothing is written in dry-run mode.
+func WriteManifest(ctx context.Context, root string, result *adcp.MaterializedResult) error {
+	m, err := NewManifest(ctx, result)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return PersistEntry(ctx, root, adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: ManifestName, Content: string(data) + "\n"}.Build(),
+	}.Build())
+}
+
+// ReadManifest reads the manifest written by WriteManifest under root, from the filesystem
+// carried by ctx if any (see WithFS). It returns nil without an error if there is none.
+func ReadManifest(ctx context.Context, root string) (*Manifest, error) {
+	ctx, err := withRootFS(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ReadFile(ctx, ManifestName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", ManifestName, err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", ManifestName, err)
+	}
+	if m.GeneratedBy != ManifestGeneratedBy || m.Files == nil {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+// withRootFS returns ctx reading files relative to root, unless it already carries a filesystem.
+func withRootFS(ctx context.Context, root string) (context.Context, error) {
+	if fsys, ok := ctx.Value(fsKey{}).(fs.FS); ok && fsys != nil {
+		return ctx, nil
+	}
+	root, err := resolveRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	return WithFS(ctx, os.DirFS(root)), nil
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
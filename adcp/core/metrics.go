@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Metric names reported to Metrics.
+const (
+	// MetricFetches counts remote fetches, tagged with "source" (e.g. "github") and "status" ("ok" or "error").
+	MetricFetches = "adcp.fetches"
+	// MetricFetchDuration records how long remote fetches take, tagged like MetricFetches.
+	MetricFetchDuration = "adcp.fetch.duration"
+	// MetricCacheHits counts sources served without fetching them again, tagged with "source":
+	// "prefetch" for prefetched data, "state" for content cached by the State of a previous run and
+	// "run" for content cached by the SourceCache of the current run.
+	MetricCacheHits = "adcp.cache.hits"
+	// MetricCommands counts executed commands, tagged with "status" ("ok" or "error").
+	MetricCommands = "adcp.commands"
+	// MetricCommandFailures counts commands that failed or were killed.
+	MetricCommandFailures = "adcp.command.failures"
+	// MetricCommandDuration records how long commands take, tagged with "status".
+	MetricCommandDuration = "adcp.command.duration"
+	// MetricMaterializeDuration records how long a whole recipe materialization takes, tagged with "status".
+	MetricMaterializeDuration = "adcp.materialize.duration"
+)
+
+// Metrics receives counters and duration samples, e.g. to monitor recipe health across many
+// repositories. Implementations adapt these to a metrics backend and must be safe for concurrent use.
+type Metrics interface {
+	// IncCounter increments the counter name by one.
+	IncCounter(name string, tags map[string]string)
+	// ObserveDuration records d in the histogram name.
+	ObserveDuration(name string, d time.Duration, tags map[string]string)
+}
+
+// NopMetrics discards all metrics.
+type NopMetrics struct{}
+
+func (NopMetrics) IncCounter(string, map[string]string)                     {}
+func (NopMetrics) ObserveDuration(string, time.Duration, map[string]string) {}
+
+type metricsKey struct{}
+
+// WithMetrics returns a copy of ctx carrying metrics.
+func WithMetrics(ctx context.Context, metrics Metrics) context.Context {
+	return context.WithValue(ctx, metricsKey{}, metrics)
+}
+
+// MetricsFrom returns the Metrics carried by ctx, or NopMetrics if there is none.
+func MetricsFrom(ctx context.Context) Metrics {
+	if m, ok := ctx.Value(metricsKey{}).(Metrics); ok && m != nil {
+		return m
+	}
+	return NopMetrics{}
+}
+
+// StatusTag returns the "status" tag value for err: "ok" when it is nil and "error" otherwise.
+func StatusTag(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// fileMarks is a set of files. Keys are weak, so marks do not keep files alive and are dropped
+// once their file is garbage collected.
+type fileMarks struct {
+	m sync.Map // weak.Pointer[adcp.FullFileContent] -> struct{}
+}
+
+func (s *fileMarks) set(file *adcp.FullFileContent) {
+	if file == nil {
+		return
+	}
+	key := weak.Make(file)
+	if _, loaded := s.m.LoadOrStore(key, struct{}{}); !loaded {
+		runtime.AddCleanup(file, func(key weak.Pointer[adcp.FullFileContent]) { s.m.Delete(key) }, key)
+	}
+}
+
+func (s *fileMarks) has(file *adcp.FullFileContent) bool {
+	if file == nil {
+		return false
+	}
+	_, ok := s.m.Load(weak.Make(file))
+	return ok
+}
+
+// executables holds the files marked by SetExecutable.
+var executables fileMarks
+
+// SetExecutable marks file as executable, e.g. a git hook script, so that PersistMaterializedResult
+// writes it with mode 0755 instead of 0644. The mark belongs to this message: copies of it, e.g.
+// decoded from a serialized result, are not executable.
+func SetExecutable(file *adcp.FullFileContent) {
+	executables.set(file)
+}
+
+// IsExecutable reports whether file was marked by SetExecutable.
+func IsExecutable(file *adcp.FullFileContent) bool {
+	return executables.has(file)
+}
@@ -0,0 +1,48 @@
+package core
+
+import "context"
+
+// Phases reported to an Observer.
+const (
+	PhasePrefetch = "prefetch"
+	PhaseContext  = "context"
+	PhaseIDE      = "ide"
+	PhaseGitHooks = "git-hooks"
+	PhasePersist  = "persist"
+)
+
+// Observer receives progress notifications during materialization, e.g. to render progress bars.
+// Methods may be called concurrently and must return quickly.
+type Observer interface {
+	// PhaseStarted is called when materialization enters a new phase.
+	PhaseStarted(phase string)
+	// EntryStarted is called before an entry (prefetch entry, context file, command, persisted file) is processed.
+	EntryStarted(phase, name string)
+	// EntryFinished is called after an entry was processed; err is nil on success.
+	EntryFinished(phase, name string, err error)
+	// BytesFetched reports the size of content fetched from a source such as a command or URL.
+	BytesFetched(source string, n int)
+}
+
+// NopObserver ignores all notifications. Embed it to implement only some Observer methods.
+type NopObserver struct{}
+
+func (NopObserver) PhaseStarted(string)                 {}
+func (NopObserver) EntryStarted(string, string)         {}
+func (NopObserver) EntryFinished(string, string, error) {}
+func (NopObserver) BytesFetched(string, int)            {}
+
+type observerKey struct{}
+
+// WithObserver returns a copy of ctx carrying observer.
+func WithObserver(ctx context.Context, observer Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, observer)
+}
+
+// ObserverFrom returns the Observer carried by ctx, or a NopObserver if there is none.
+func ObserverFrom(ctx context.Context) Observer {
+	if o, ok := ctx.Value(observerKey{}).(Observer); ok && o != nil {
+		return o
+	}
+	return NopObserver{}
+}
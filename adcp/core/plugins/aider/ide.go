@@ -0,0 +1,140 @@
+// Package aider materializes recipes for Aider: project conventions in CONVENTIONS.md and the files
+// Aider reads, along with configured settings, merged into .aider.conf.yml. Aider has no commands,
+// MCP servers or permissions, so those parts of a recipe are skipped and reported as unsupported.
+package aider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+const (
+	// DefaultConfigPath is the project-level Aider configuration.
+	DefaultConfigPath = ".aider.conf.yml"
+	// ConventionsPath is where memory context entries are written.
+	ConventionsPath = "CONVENTIONS.md"
+	// rulesDir holds rule context entries. Aider reads all files of directories listed in read.
+	rulesDir = ".aider/conventions"
+)
+
+// IDE materializes recipes for Aider. Context is handled by the embedded shared.IDE.
+type IDE struct {
+	*shared.IDE
+	// ConfigPath is the Aider configuration the read list and Settings are merged into. Comments
+	// and settings not managed by adcp are kept.
+	ConfigPath string
+	// Read lists the files and directories Aider loads read-only, e.g. the conventions.
+	Read []string
+	// Settings are set in ConfigPath, e.g. {"auto-commits": false}, replacing existing values.
+	Settings map[string]any
+}
+
+// Option configures the Aider IDE provider.
+type Option func(ide *IDE)
+
+// WithConfigPath merges settings into path instead of DefaultConfigPath.
+func WithConfigPath(path string) Option {
+	return func(ide *IDE) {
+		ide.ConfigPath = path
+	}
+}
+
+// WithRead adds files or directories Aider loads read-only, e.g. documentation written by the recipe.
+func WithRead(paths ...string) Option {
+	return func(ide *IDE) {
+		ide.Read = append(ide.Read, paths...)
+	}
+}
+
+// WithSettings sets Aider options in the configuration, keyed by their long flag names without
+// dashes in front, e.g. {"model": "sonnet", "auto-commits": false}.
+func WithSettings(settings map[string]any) Option {
+	return func(ide *IDE) {
+		ide.Settings = settings
+	}
+}
+
+// NewIDEProvider returns a provider for Aider. The configuration is written when the recipe has an
+// IDE section; recipes with context only produce the context files.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithContextPath(shared.DestinationRules, rulesDir),
+			shared.WithContextPath(shared.DestinationMemory, ConventionsPath),
+			shared.WithContextPath(shared.DestinationDocs, ".aider/docs"),
+		),
+		ConfigPath: DefaultConfigPath,
+		Read:       []string{ConventionsPath, rulesDir},
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds the Aider configuration to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	paths := i.IDE.ManagedPaths()
+	if i.ConfigPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: i.ConfigPath, Merge: core.MergeYAML})
+	}
+	return paths
+}
+
+// Capabilities reports that Aider supports memory files, rules and docs only.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	return recipes.Capabilities{
+		MemoryFiles: i.ContextPaths[shared.DestinationMemory] != "",
+		Rules:       i.ContextPaths[shared.DestinationRules] != "",
+		Docs:        i.ContextPaths[shared.DestinationDocs] != "",
+	}
+}
+
+// OverridePaths returns a copy of the IDE with context paths overridden. The read list keeps the
+// default locations; add overridden ones with WithRead.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// Materialize merges the read list and Settings into ConfigPath. Commands, MCP servers and
+// permissions are skipped; recipes report them as unsupported (see Capabilities).
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	if i.ConfigPath == "" || (len(i.Read) == 0 && len(i.Settings) == 0) {
+		return adcp.MaterializedResult_builder{}.Build(), nil
+	}
+	doc, ok := merge.ParseYAMLDocument(shared.ReadExistingFile(ctx, i.ConfigPath))
+	if !ok {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningInvalidExistingFile,
+			Path:    i.ConfigPath,
+			Message: "Existing file is not a valid YAML mapping; its content is discarded and the file is replaced",
+		})
+	}
+	if len(i.Read) > 0 {
+		doc.AppendUnique("read", i.Read...)
+	}
+	for _, key := range slices.Sorted(maps.Keys(i.Settings)) {
+		if err := doc.Set(key, i.Settings[key]); err != nil {
+			return nil, fmt.Errorf("failed to set aider setting %s: %w", key, err)
+		}
+	}
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal aider config: %w", err)
+	}
+	core.RecordPlannedChange(ctx, core.PlannedChange{Path: i.ConfigPath, Merge: core.MergeYAML})
+	return adcp.MaterializedResult_builder{Entries: []*adcp.MaterializedResult_Entry{
+		adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: i.ConfigPath, Content: content}.Build(),
+		}.Build(),
+	}}.Build(), nil
+}
@@ -0,0 +1,77 @@
+package aider
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Config(t *testing.T) {
+	g := NewIDEProvider(
+		WithRead("docs/architecture.md"),
+		WithSettings(map[string]any{"auto-commits": false, "model": "sonnet"}),
+	)
+	fsys := fstest.MapFS{DefaultConfigPath: {Data: []byte("# Team defaults\nmodel: gpt-4o\ndark-mode: true\nread: docs/architecture.md\n")}}
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, DefaultConfigPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.Equal(t, `# Team defaults
+model: sonnet
+dark-mode: true
+read:
+  - docs/architecture.md
+  - CONVENTIONS.md
+  - .aider/conventions
+auto-commits: false
+`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestRecipe_Materialize_ReportsUnsupported(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithReport(context.Background(), report)
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "@memory", From: adcp.ContextFrom_builder{Text: strPtr("Use tabs.\n")}.Build()}.Build(),
+			adcp.ContextEntry_builder{Path: "@rules/go.md", From: adcp.ContextFrom_builder{Text: strPtr("Run gofmt.\n")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+			}}.Build(),
+			Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+				"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+			}}.Build(),
+		}.Build(),
+	}.Build()
+
+	res, err := recipes.New(NewIDEProvider()).Materialize(ctx, recipe)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Equal(t, []string{ConventionsPath, ".aider/conventions/go.md", DefaultConfigPath}, paths)
+
+	var messages []string
+	for _, w := range report.Data().Warnings {
+		assert.Equal(t, core.WarningUnsupportedFeature, w.Code)
+		messages = append(messages, w.Message)
+	}
+	assert.Equal(t, []string{
+		"commands are not supported and will be ignored",
+		"MCP server devplan uses unsupported transport stdio and will be ignored",
+	}, messages)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
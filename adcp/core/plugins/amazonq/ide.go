@@ -0,0 +1,57 @@
+// Package amazonq materializes recipes for Amazon Q Developer: rules in .amazonq/rules, commands as
+// saved prompts in .amazonq/prompts and MCP servers in .amazonq/mcp.json.
+package amazonq
+
+import (
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Option configures the Amazon Q IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into .amazonq/mcp.json.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// NewIDEProvider returns a provider for Amazon Q Developer. Saved prompts are plain Markdown, so
+// command metadata is not rendered.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".amazonq/prompts"),
+		shared.WithMCPPath(".amazonq/mcp.json"),
+		shared.WithMCPServerFunc(mcpServer),
+		shared.WithCommandFrontmatterFields(),
+		shared.WithContextPath(shared.DestinationRules, ".amazonq/rules"),
+		shared.WithContextPath(shared.DestinationMemory, ".amazonq/rules/project.md"),
+		shared.WithContextPath(shared.DestinationDocs, ".amazonq/docs"),
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// mcpServerConfig is a server in Amazon Q's format, where only remote servers have a type.
+type mcpServerConfig struct {
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) any {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return mcpServerConfig{Type: "http", URL: url, Headers: opts.Headers}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return mcpServerConfig{Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
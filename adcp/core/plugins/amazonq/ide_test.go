@@ -0,0 +1,56 @@
+package amazonq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize(t *testing.T) {
+	g := NewIDEProvider(
+		WithMCPServerOptions(map[string]shared.MCPServerOptions{
+			"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+		}),
+		func(ide *shared.IDE) {
+			ide.CommandMetadata = map[string]shared.CommandMetadata{"review": {Description: "ignored"}}
+		},
+	)
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+		}}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "Review the diff.\n", m[".amazonq/prompts/review.md"])
+	assert.JSONEq(t, `{"mcpServers": {
+		"devplan": {"command": "devplan", "args": ["mcp"], "env": {"DEVPLAN_API_KEY": "key"}},
+		"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/"}
+	}}`, m[".amazonq/mcp.json"])
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*shared.IDE)
+
+	got, err := g.MapContextPath("@rules/style.md")
+	require.NoError(t, err)
+	assert.Equal(t, ".amazonq/rules/style.md", got)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
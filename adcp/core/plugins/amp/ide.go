@@ -0,0 +1,212 @@
+// Package amp materializes recipes for Amp: guidance in AGENT.md, commands in .agents/commands, and
+// MCP servers and permissions in Amp's workspace settings, .amp/settings.json.
+package amp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+const (
+	// DefaultSettingsPath is the workspace settings file of Amp.
+	DefaultSettingsPath = ".amp/settings.json"
+	// AgentPath is where memory context entries and agent instructions are written.
+	AgentPath = "AGENT.md"
+
+	mcpServersKey  = "amp.mcpServers"
+	permissionsKey = "amp.permissions"
+)
+
+// Option configures the Amp provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithSettingsPath writes MCP servers and permissions to path instead of DefaultSettingsPath, e.g.
+// ".config/amp/settings.json" when materializing into the home directory.
+func WithSettingsPath(path string) Option {
+	return func(ide *shared.IDE) {
+		if s, ok := ide.Settings.(*settings); ok {
+			s.path = path
+		}
+	}
+}
+
+// WithAgentInstructions generates a managed section with the given instructions in AGENT.md.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *shared.IDE) {
+		ide.AgentsMDPath = AgentPath
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into the settings.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// NewIDEProvider returns a provider for Amp. Amp commands are plain Markdown, so command metadata
+// is not rendered.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".agents/commands"),
+		shared.WithCommandFrontmatterFields(),
+		shared.WithAgentsMDPath(AgentPath),
+		shared.WithManagedMemory(true),
+		shared.WithContextPath(shared.DestinationMemory, AgentPath),
+		shared.WithContextPath(shared.DestinationDocs, ".amp/docs"),
+		// The settings convert MCP servers with the options of the IDE they belong to.
+		func(ide *shared.IDE) {
+			ide.Settings = &settings{ide: ide, path: DefaultSettingsPath}
+		},
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// settings writes MCP servers and permissions into Amp's settings file, which holds both.
+type settings struct {
+	ide  *shared.IDE
+	path string
+}
+
+// ManagedPaths reports the settings file, if any.
+func (s *settings) ManagedPaths() []recipes.ManagedPath {
+	if s.path == "" {
+		return nil
+	}
+	return []recipes.ManagedPath{{Pattern: s.path, Merge: core.MergeJSON}}
+}
+
+// SupportsPermissions reports that permissions are translated into amp.permissions rules.
+func (s *settings) SupportsPermissions() bool {
+	return s.path != ""
+}
+
+// SupportsMCP reports that MCP servers are written into amp.mcpServers.
+func (s *settings) SupportsMCP() bool {
+	return s.path != ""
+}
+
+func (s *settings) Update(ctx context.Context, input shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
+	perms := input.Permissions
+	hasPerms := len(perms.GetAllow()) > 0 || len(perms.GetDeny()) > 0
+	if s.path == "" || (!input.Ide.HasMcp() && !hasPerms) {
+		return nil, nil
+	}
+	doc, _ := merge.ParseDocument(shared.ReadExistingJSON(ctx, s.path))
+
+	if input.Ide.HasMcp() {
+		serverOptions, err := shared.ExpandServerOptions(ctx, s.ide.MCPServerOptions)
+		if err != nil {
+			return nil, err
+		}
+		servers := doc.Nested(mcpServersKey)
+		mcp := input.Ide.GetMcp().GetServers()
+		for _, name := range slices.Sorted(maps.Keys(mcp)) {
+			srv := newMCPServer(mcp[name], serverOptions[name])
+			if srv == nil {
+				core.Warn(ctx, core.Warning{
+					Code:    core.WarningIgnoredMCPServer,
+					Path:    s.path,
+					Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+				})
+				continue
+			}
+			if err := servers.Set(name, srv); err != nil {
+				return nil, fmt.Errorf("failed to set mcp server %s: %w", name, err)
+			}
+		}
+		if err := doc.Set(mcpServersKey, servers); err != nil {
+			return nil, err
+		}
+	}
+
+	if hasPerms {
+		// Amp applies the first matching rule, so deny rules go first.
+		var rules []permissionRule
+		for _, p := range perms.GetDeny() {
+			rules = append(rules, s.permissionRules(ctx, p, "reject")...)
+		}
+		for _, p := range perms.GetAllow() {
+			rules = append(rules, s.permissionRules(ctx, p, "allow")...)
+		}
+		patch := merge.NewObject()
+		if err := patch.Set(permissionsKey, rules); err != nil {
+			return nil, err
+		}
+		// Rules already present are kept in place; new ones are appended.
+		if err := merge.Deep(doc.Object, patch, nil); err != nil {
+			return nil, fmt.Errorf("failed to merge amp permissions: %w", err)
+		}
+	}
+
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal amp settings: %w", err)
+	}
+	core.RecordPlannedChange(ctx, core.PlannedChange{Path: s.path, Merge: core.MergeJSON})
+	return []*adcp.MaterializedResult_Entry{
+		adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: s.path, Content: content}.Build(),
+		}.Build(),
+	}, nil
+}
+
+// permissionRule is an entry of amp.permissions.
+type permissionRule struct {
+	Tool    string            `json:"tool"`
+	Matches map[string]string `json:"matches"`
+	Action  string            `json:"action"`
+}
+
+// permissionRules converts p into amp.permissions rules. Writes cover both editing and creating
+// files; Claude-style Bash prefix matchers ("git commit:*") become glob patterns ("git commit *").
+func (s *settings) permissionRules(ctx context.Context, p *adcp.OperationPermission, action string) []permissionRule {
+	switch p.WhichType() {
+	case adcp.OperationPermission_Bash_case:
+		return []permissionRule{{Tool: "Bash", Matches: map[string]string{"cmd": shared.BashPrefixToGlob(p.GetBash())}, Action: action}}
+	case adcp.OperationPermission_Read_case:
+		return []permissionRule{{Tool: "Read", Matches: map[string]string{"path": p.GetRead()}, Action: action}}
+	case adcp.OperationPermission_Write_case:
+		return []permissionRule{
+			{Tool: "edit_file", Matches: map[string]string{"path": p.GetWrite()}, Action: action},
+			{Tool: "create_file", Matches: map[string]string{"path": p.GetWrite()}, Action: action},
+		}
+	}
+	core.Warn(ctx, core.Warning{
+		Code:    core.WarningDroppedPermission,
+		Path:    s.path,
+		Message: "Permission without a supported kind (bash, read or write) is ignored",
+	})
+	return nil
+}
+
+// mcpServerConfig is a server in Amp's format, which has no type field.
+type mcpServerConfig struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func newMCPServer(s *adcp.McpServer, opts shared.MCPServerOptions) *mcpServerConfig {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return &mcpServerConfig{URL: url, Headers: opts.Headers}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return &mcpServerConfig{Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package amp
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Settings(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+	}))
+	fsys := fstest.MapFS{DefaultSettingsPath: {Data: []byte(`{
+  "amp.notifications.enabled": false,
+  "amp.permissions": [{"tool": "Bash", "matches": {"cmd": "make test"}, "action": "allow"}]
+}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+		Permissions: adcp.Permissions_builder{
+			Allow: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Bash: strPtr("make test")}.Build(),
+				adcp.OperationPermission_builder{Bash: strPtr("git commit:*")}.Build(),
+			},
+			Deny: []*adcp.OperationPermission{adcp.OperationPermission_builder{Write: strPtr("secrets/**")}.Build()},
+		}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, DefaultSettingsPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{
+		"amp.notifications.enabled": false,
+		"amp.permissions": [
+			{"tool": "Bash", "matches": {"cmd": "make test"}, "action": "allow"},
+			{"tool": "edit_file", "matches": {"path": "secrets/**"}, "action": "reject"},
+			{"tool": "create_file", "matches": {"path": "secrets/**"}, "action": "reject"},
+			{"tool": "Bash", "matches": {"cmd": "git commit *"}, "action": "allow"}
+		],
+		"amp.mcpServers": {
+			"devplan": {"command": "devplan", "args": ["mcp"], "env": {"DEVPLAN_API_KEY": "key"}},
+			"github": {"url": "https://api.githubcopilot.com/mcp/"}
+		}
+	}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Capabilities(t *testing.T) {
+	caps := NewIDEProvider().(recipes.CapabilityProvider).Capabilities()
+	assert.True(t, caps.Commands)
+	assert.True(t, caps.Permissions)
+	assert.True(t, caps.MemoryFiles)
+	assert.Equal(t, []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}, caps.MCPTransports)
+}
+
+func TestIDE_Materialize_AgentInstructions(t *testing.T) {
+	g := NewIDEProvider(WithAgentInstructions("Use pnpm."), WithSettingsPath(".config/amp/settings.json"))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, AgentPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "Use pnpm.")
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,45 @@
+package claude
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// largeSettingsInput returns a realistic large configuration: n permissions of each kind, n/10 MCP
+// servers and commands, and existing settings sharing half of the permissions.
+func largeSettingsInput(n int) (*adcp.Permissions, []string, []string, string) {
+	var allow, deny []*adcp.OperationPermission
+	for i := range n {
+		bash := fmt.Sprintf("npm run task-%d:*", i)
+		read := fmt.Sprintf("src/pkg%d/**", i)
+		write := fmt.Sprintf("secrets/%d/**", i)
+		allow = append(allow, adcp.OperationPermission_builder{Bash: &bash}.Build(), adcp.OperationPermission_builder{Read: &read}.Build())
+		deny = append(deny, adcp.OperationPermission_builder{Write: &write}.Build())
+	}
+	var servers, commands []string
+	for i := range n / 10 {
+		servers = append(servers, fmt.Sprintf("server-%d", i))
+		commands = append(commands, fmt.Sprintf("command-%d", i))
+	}
+	existing, err := (&settings{}).buildClaudeSettingsJSON(adcp.Permissions_builder{Allow: allow[:n/2]}.Build(), nil, nil, "")
+	if err != nil {
+		panic(err)
+	}
+	return adcp.Permissions_builder{Allow: allow, Deny: deny}.Build(), servers, commands, existing
+}
+
+func BenchmarkBuildClaudeSettingsJSON(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		perms, servers, commands, existing := largeSettingsInput(n)
+		b.Run(fmt.Sprintf("permissions=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := (&settings{}).buildClaudeSettingsJSON(perms, servers, commands, existing); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,42 +2,199 @@ package claude
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
+	"maps"
+	"path"
+	"slices"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
 	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
-func NewIDEProvider() recipes.IDEProvider {
-	return &shared.IDE{
-		CommandsFolder:     ".claude/commands",
-		MCPServersJSONPath: ".mcp.json",
-		Settings:           &settings{},
+// Project settings files of Claude Code. SettingsLocal is meant for personal settings and is
+// ignored by git, while SettingsShared is checked in and shared with the team.
+const (
+	SettingsLocal  = ".claude/settings.local.json"
+	SettingsShared = ".claude/settings.json"
+)
+
+// Option configures the Claude IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithCommandMetadata sets per-command metadata (description, argument-hint, allowed-tools, model)
+// rendered as frontmatter in .claude/commands/<name>.md.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *shared.IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithMCPServerOptions adds env (stdio), headers and bearer tokens (HTTP) to servers written into
+// .mcp.json. Claude Code expands ${VAR} references in their values, which keeps secrets out of the
+// file.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *shared.IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// NewIDEProvider returns a provider for Claude Code. With shared.WithScope(shared.ScopeUser), it
+// writes the user's configuration in ~/.claude and ~/.claude.json instead of the project's. With
+// WithPlugin, it writes a plugin instead.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".claude/commands"),
+		shared.WithAgentsFolder(".claude/agents"),
+		shared.WithSkillsFolder(".claude/skills"),
+		shared.WithMCPPath(".mcp.json"),
+		shared.WithEnvReference(shared.EnvReference),
+		shared.WithSettings(&settings{}),
+		shared.WithContextPath(shared.DestinationRules, ".claude/rules"),
+		shared.WithContextPath(shared.DestinationMemory, "CLAUDE.md"),
+		shared.WithManagedMemory(true),
+		shared.WithContextPath(shared.DestinationDocs, ".claude/docs"),
+		shared.WithUserPaths(map[string]string{
+			shared.PathCommands:      ".claude/commands",
+			shared.PathAgents:        ".claude/agents",
+			shared.PathSkills:        ".claude/skills",
+			shared.PathMCP:           ".claude.json",
+			shared.DestinationRules:  ".claude/rules",
+			shared.DestinationMemory: ".claude/CLAUDE.md",
+		}),
+	}
+	ide := shared.NewIDE(append(defaults, opts...)...)
+	// User settings live in ~/.claude/settings.json; there is no local variant.
+	if s, ok := ide.Settings.(*settings); ok && ide.Scope == shared.ScopeUser && s.path == "" {
+		s.path = SettingsShared
 	}
+	if s, ok := ide.Settings.(*settings); ok && s.plugin != nil {
+		s.plugin.applyPlugin(ide)
+	}
+	return ide
 }
 
 type settings struct {
 	shared.IDESettings
+	// path is the settings file, SettingsLocal when empty.
+	path  string
+	hooks []Hook
+	ask   []*adcp.OperationPermission
+	tools map[string][]ToolPermission
+	// mcpToolServers are the MCP servers with rules for individual tools.
+	mcpToolServers        map[string]bool
+	disabledMCPServers    map[string]bool
+	additionalDirectories []string
+	env                   map[string]string
+	projectMCPServers     ProjectMCPServers
+	outputStyles          []OutputStyle
+	outputStyle           string
+	statusLine            *StatusLine
+	// plugin, when set, replaces the settings file by the manifest and hooks of a plugin.
+	plugin *Plugin
 }
 
-func (s *settings) Update(_ context.Context, input shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
-	return materializePermissions(input.Permissions, input.MCPServerNames, input.CommandNames)
+// file returns the settings file permissions and other settings are merged into.
+func (s *settings) file() string {
+	if s.path == "" {
+		return SettingsLocal
+	}
+	return s.path
 }
 
-func materializePermissions(perms *adcp.Permissions, mcpServerNames []string, commandNames []string) ([]*adcp.MaterializedResult_Entry, error) {
-	var entries []*adcp.MaterializedResult_Entry
+// ManagedPaths reports the settings file permissions are merged into, its manifest and the output
+// styles, or the files of the plugin.
+func (s *settings) ManagedPaths() []recipes.ManagedPath {
+	if s.plugin != nil {
+		return s.plugin.managedPaths()
+	}
+	return []recipes.ManagedPath{
+		{Pattern: s.file(), Merge: core.MergeJSON},
+		{Pattern: s.manifestPath(), Merge: core.MergeReplace},
+		{Pattern: path.Join(outputStylesFolder, "*.md"), Merge: core.MergeReplace},
+	}
+}
+
+// SupportsHooks reports that hooks can be merged into Claude settings (see WithHooks).
+func (s *settings) SupportsHooks() bool {
+	return true
+}
+
+// HasHooks reports whether hooks are configured (see WithHooks).
+func (s *settings) HasHooks() bool {
+	return len(s.hooks) > 0
+}
 
-	// Read existing file content if it exists
-	existingContent := ""
-	settingsPath := ".claude/settings.local.json"
-	if data, err := os.ReadFile(settingsPath); err == nil {
-		existingContent = string(data)
+// SupportsPermissions reports that Claude settings translate recipe permissions, unless a plugin
+// is written.
+func (s *settings) SupportsPermissions() bool {
+	return s.plugin == nil
+}
+
+func (s *settings) Update(ctx context.Context, input shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
+	if s.plugin != nil {
+		return s.materializePlugin(ctx)
+	}
+	// Servers with rules for individual tools are not allowed as a whole; they are still enabled
+	// by patch. Disabled servers are neither allowed nor enabled.
+	servers := slices.DeleteFunc(slices.Clone(input.MCPServerNames), func(name string) bool {
+		return s.mcpToolServers[name] || s.disabledMCPServers[name]
+	})
+	entries, err := s.materializePermissions(ctx, input.Permissions, servers, input.CommandNames)
+	if err != nil {
+		return nil, err
 	}
+	warnPermissions(ctx, s.file(), s.ask)
+	for _, list := range slices.Sorted(maps.Keys(s.tools)) {
+		for _, p := range s.tools[list] {
+			for _, w := range ValidateToolPermission(p) {
+				core.Warn(ctx, core.Warning{Code: core.WarningSuspiciousPermission, Path: s.file(), Message: w})
+			}
+		}
+	}
+	// Entries merged by previous runs that the recipe no longer declares are removed.
+	managed, err := s.managedEntries(input, servers)
+	if err != nil {
+		return nil, err
+	}
+	previous := s.readManifest(ctx)
+	for _, e := range entries {
+		content, err := s.applyPatch(e.GetFile().GetContent(), input.MCPServerNames)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = pruneStale(content, previous, managed); err != nil {
+			return nil, err
+		}
+		e.GetFile().SetContent(content)
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: e.GetFile().GetPath(), Merge: core.MergeJSON})
+	}
+	manifest, err := s.materializeManifest(managed, previous)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: manifest.GetFile().GetPath(), Merge: core.MergeReplace})
+		entries = append(entries, manifest)
+	}
+	styles, err := s.materializeOutputStyles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, styles...), nil
+}
+
+func (s *settings) materializePermissions(ctx context.Context, perms *adcp.Permissions, mcpServerNames []string, commandNames []string) ([]*adcp.MaterializedResult_Entry, error) {
+	var entries []*adcp.MaterializedResult_Entry
+	settingsPath := s.file()
 
-	settingsContent, err := buildClaudeSettingsJSON(perms, mcpServerNames, commandNames, existingContent)
+	warnPermissions(ctx, settingsPath, append(perms.GetAllow(), perms.GetDeny()...))
+
+	existingContent := shared.ReadExistingJSON(ctx, settingsPath)
+
+	settingsContent, err := s.buildClaudeSettingsJSON(perms, mcpServerNames, commandNames, existingContent)
 	if err != nil {
 		return nil, err
 	}
@@ -47,46 +204,28 @@ func materializePermissions(perms *adcp.Permissions, mcpServerNames []string, co
 	return entries, nil
 }
 
-// JSON models for Claude configuration files
-
-type claudeSettings struct {
-	Permissions struct {
-		Allow       []string `json:"allow,omitempty"`
-		Deny        []string `json:"deny,omitempty"`
-		Ask         []string `json:"ask,omitempty"`
-		DefaultMode string   `json:"defaultMode,omitempty"`
-	} `json:"permissions"`
-	EnabledMcpjsonServers      []string `json:"enabledMcpjsonServers,omitempty"`
-	EnableAllProjectMcpServers bool     `json:"enableAllProjectMcpServers,omitempty"`
-}
-
-func buildClaudeSettingsJSON(perms *adcp.Permissions, mcpServerNames []string, commandNames []string, existingContent string) (string, error) {
-	var s claudeSettings
+func (s *settings) buildClaudeSettingsJSON(perms *adcp.Permissions, mcpServerNames []string, commandNames []string, existingContent string) (string, error) {
+	// Parse existing content if provided, keeping the user's key order and indentation.
+	// If parsing fails, start fresh.
+	doc, _ := merge.ParseDocument(existingContent)
 
-	// Parse existing content if provided
-	if existingContent != "" {
-		if err := json.Unmarshal([]byte(existingContent), &s); err != nil {
-			// If parsing fails, start fresh but log the error
-			s = claudeSettings{}
-		}
-	}
-
-	// Ensure non-nil slices
-	if s.Permissions.Allow == nil {
-		s.Permissions.Allow = []string{}
-	}
-	if s.Permissions.Deny == nil {
-		s.Permissions.Deny = []string{}
+	patch, err := s.permissionsPatch(perms, mcpServerNames, commandNames)
+	if err != nil {
+		return "", err
 	}
-	if s.Permissions.Ask == nil {
-		s.Permissions.Ask = []string{}
+	if err := merge.Deep(doc.Object, patch, nil); err != nil {
+		return "", fmt.Errorf("failed to merge settings json: %w", err)
 	}
-	s.Permissions.DefaultMode = "acceptEdits"
-	if s.EnabledMcpjsonServers == nil {
-		s.EnabledMcpjsonServers = []string{}
+	out, err := doc.String()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal settings json: %w", err)
 	}
-	s.EnableAllProjectMcpServers = true
+	return out, nil
+}
 
+// permissionsPatch returns the settings translated from the recipe's permissions, MCP servers and
+// commands.
+func (s *settings) permissionsPatch(perms *adcp.Permissions, mcpServerNames []string, commandNames []string) (*merge.Object, error) {
 	// Build new permissions from input
 	newAllow := make([]string, 0)
 	if perms != nil {
@@ -124,42 +263,64 @@ func buildClaudeSettingsJSON(perms *adcp.Permissions, mcpServerNames []string, c
 	}
 	newAllow = append(newAllow, cmdAllow...)
 
-	// Merge with existing permissions (deduplicate)
-	s.Permissions.Allow = mergeUniqueStrings(s.Permissions.Allow, newAllow)
-	s.Permissions.Deny = mergeUniqueStrings(s.Permissions.Deny, newDeny)
-
+	// Describe the managed keys; lists are merged with existing ones (deduplicated)
+	permissions := merge.NewObject()
+	if len(newAllow) > 0 {
+		if err := permissions.Set("allow", newAllow); err != nil {
+			return nil, err
+		}
+	}
+	if len(newDeny) > 0 {
+		if err := permissions.Set("deny", newDeny); err != nil {
+			return nil, err
+		}
+	}
+	if err := permissions.Set("defaultMode", "acceptEdits"); err != nil {
+		return nil, err
+	}
+	patch := merge.NewObject()
+	if err := patch.Set("permissions", permissions); err != nil {
+		return nil, err
+	}
 	// Add MCP server names to enabledMcpjsonServers
-	s.EnabledMcpjsonServers = mergeUniqueStrings(s.EnabledMcpjsonServers, mcpServerNames)
-
-	b, err := json.MarshalIndent(&s, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal settings json: %w", err)
+	if len(mcpServerNames) > 0 {
+		if err := patch.Set("enabledMcpjsonServers", mcpServerNames); err != nil {
+			return nil, err
+		}
 	}
-	return string(b), nil
-}
-
-// mergeUniqueStrings merges two string slices, removing duplicates
-func mergeUniqueStrings(existing, new []string) []string {
-	seen := make(map[string]bool)
-	result := make([]string, 0)
-
-	// Add existing items first
-	for _, s := range existing {
-		if !seen[s] {
-			seen[s] = true
-			result = append(result, s)
+	switch s.projectMCPServers {
+	case EnableAllProjectMCPServers:
+		if err := patch.Set("enableAllProjectMcpServers", true); err != nil {
+			return nil, err
+		}
+	case EnableListedProjectMCPServers:
+		if err := patch.Set("enableAllProjectMcpServers", false); err != nil {
+			return nil, err
 		}
 	}
+	return patch, nil
+}
 
-	// Add new items that aren't duplicates
-	for _, s := range new {
-		if !seen[s] {
-			seen[s] = true
-			result = append(result, s)
+// warnPermissions reports warnings for permissions that are dropped or will likely never match.
+func warnPermissions(ctx context.Context, path string, perms []*adcp.OperationPermission) {
+	for _, p := range perms {
+		if formatPermission(p) == "" {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningDroppedPermission,
+				Path:    path,
+				Message: "Permission without a supported kind (bash, read or write) is ignored",
+			})
+			continue
 		}
+		warnInvalidPermission(ctx, path, p)
 	}
+}
 
-	return result
+// warnInvalidPermission reports warnings for permission patterns that will likely never match.
+func warnInvalidPermission(ctx context.Context, path string, p *adcp.OperationPermission) {
+	for _, w := range ValidatePermission(p) {
+		core.Warn(ctx, core.Warning{Code: core.WarningSuspiciousPermission, Path: path, Message: w})
+	}
 }
 
 func formatPermission(p *adcp.OperationPermission) string {
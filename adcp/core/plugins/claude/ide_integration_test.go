@@ -4,11 +4,13 @@
 package claude
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,11 +24,7 @@ func TestIDE_Materialize_Permissions_MergeWithExisting(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing settings file with some permissions
 	existingSettings := `{
@@ -57,7 +55,7 @@ func TestIDE_Materialize_Permissions_MergeWithExisting(t *testing.T) {
 	}.Build()
 
 	// Execute
-	res, err := materializePermissions(ide.GetPermissions(), nil, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), nil, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -103,11 +101,7 @@ func TestIDE_Materialize_McpServers_AutoAddPermissions(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	ide := adcp.Ide_builder{
 		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
@@ -118,7 +112,7 @@ func TestIDE_Materialize_McpServers_AutoAddPermissions(t *testing.T) {
 	}.Build()
 
 	// Execute
-	res, err := materializePermissions(ide.GetPermissions(), []string{"github", "devplan", "filesystem"}, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), []string{"github", "devplan", "filesystem"}, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -161,11 +155,7 @@ func TestIDE_Materialize_Permissions_Deduplication(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing settings file with duplicate permission
 	existingSettings := `{
@@ -191,7 +181,7 @@ func TestIDE_Materialize_Permissions_Deduplication(t *testing.T) {
 	}.Build()
 
 	// Execute
-	res, err := materializePermissions(ide.GetPermissions(), nil, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), nil, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -236,11 +226,7 @@ func TestIDE_Materialize_Permissions_InvalidExistingJSON(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing settings file with invalid JSON
 	invalidJSON := `{ "permissions": { "allow": ["test" }`
@@ -256,7 +242,7 @@ func TestIDE_Materialize_Permissions_InvalidExistingJSON(t *testing.T) {
 	}.Build()
 
 	// Execute - should not error, just start fresh
-	res, err := materializePermissions(ide.GetPermissions(), nil, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), nil, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -289,11 +275,7 @@ func TestIDE_Materialize_Permissions_NoExistingFile(t *testing.T) {
 	tempDir := t.TempDir()
 	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, ".claude"), 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Define new permissions
 	allowBash := adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build()
@@ -305,7 +287,7 @@ func TestIDE_Materialize_Permissions_NoExistingFile(t *testing.T) {
 	}.Build()
 
 	// Execute
-	res, err := materializePermissions(ide.GetPermissions(), nil, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), nil, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -341,11 +323,7 @@ func TestIDE_Materialize_McpServers_PreserveExistingMcpPermissions(t *testing.T)
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing settings with MCP server already enabled and in allow list
 	existingSettings := `{
@@ -369,7 +347,7 @@ func TestIDE_Materialize_McpServers_PreserveExistingMcpPermissions(t *testing.T)
 	}.Build()
 
 	// Execute
-	res, err := materializePermissions(ide.GetPermissions(), []string{"github"}, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), []string{"github"}, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -427,11 +405,7 @@ func TestIDE_Materialize_McpServers_MergeWithExistingPermissions(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing settings with some permissions
 	existingSettings := `{
@@ -460,7 +434,7 @@ func TestIDE_Materialize_McpServers_MergeWithExistingPermissions(t *testing.T) {
 	}.Build()
 
 	// Execute
-	res, err := materializePermissions(ide.GetPermissions(), []string{"github", "devplan"}, nil)
+	res, err := (&settings{}).materializePermissions(ctx, ide.GetPermissions(), []string{"github", "devplan"}, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
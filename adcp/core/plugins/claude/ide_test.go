@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/adcptest"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,7 +30,7 @@ func TestIDE_Materialize_Permissions(t *testing.T) {
 		}.Build(),
 	}.Build()
 
-	res, err := materializePermissions(ide.GetPermissions(), nil, nil)
+	res, err := (&settings{}).materializePermissions(context.Background(), ide.GetPermissions(), nil, nil)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 
@@ -158,3 +164,237 @@ func TestIDE_Materialize_Command_Github(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestIDE_Materialize_Command_Frontmatter(t *testing.T) {
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{
+		"commit": {
+			Description:  "Create a git commit",
+			ArgumentHint: "[message]",
+			AllowedTools: []string{"Bash(git add:*)", "Bash(git commit:*)"},
+			Model:        "claude-3-5-haiku-20241022",
+		},
+	}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "commit", From: adcp.CommandFrom_builder{Text: strPtr("Commit the changes.")}.Build()}.Build(),
+			adcp.Command_builder{Name: "plain", From: adcp.CommandFrom_builder{Text: strPtr("No metadata.")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, `---
+description: Create a git commit
+argument-hint: "[message]"
+allowed-tools: Bash(git add:*), Bash(git commit:*)
+model: claude-3-5-haiku-20241022
+---
+
+Commit the changes.`, m[".claude/commands/commit.md"])
+	assert.Equal(t, "No metadata.", m[".claude/commands/plain.md"])
+}
+
+func TestIDE_Materialize_Command_EmbeddedFrontmatter(t *testing.T) {
+	body := "---\ndescription: Fix an issue\nallowed-tools: Bash(gh issue view:*)\ndisable-model-invocation: true\n---\n\nFix issue $ARGUMENTS.\n"
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{
+		"fix": {Description: "Fix a GitHub issue", ArgumentHint: "[issue]"},
+	}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "fix", From: adcp.CommandFrom_builder{Text: &body}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Equal(t, `---
+description: Fix a GitHub issue
+allowed-tools: Bash(gh issue view:*)
+disable-model-invocation: true
+argument-hint: "[issue]"
+---
+
+Fix issue $ARGUMENTS.
+`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_Agents(t *testing.T) {
+	g := NewIDEProvider(shared.WithAgents(shared.Agent{
+		Name:        "reviewer",
+		Description: "Reviews diffs for bugs",
+		Tools:       []string{"Read", "Grep", "Bash(git diff:*)"},
+		Model:       "sonnet",
+		From:        adcp.CommandFrom_builder{Text: strPtr("You review code.\n")}.Build(),
+	}))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, `---
+name: reviewer
+description: Reviews diffs for bugs
+tools: Read, Grep, Bash(git diff:*)
+model: sonnet
+---
+
+You review code.
+`, m[".claude/agents/reviewer.md"])
+}
+
+func TestIDE_Materialize_Skills(t *testing.T) {
+	g := NewIDEProvider(shared.WithSkills(shared.Skill{
+		Name:         "pdf",
+		Description:  "Extract text from PDF files",
+		AllowedTools: []string{"Read", "Bash(python:*)"},
+		From:         adcp.CommandFrom_builder{Text: strPtr("Use extract.py.\n")}.Build(),
+		Files: map[string]*adcp.CommandFrom{
+			"extract.py": adcp.CommandFrom_builder{Text: strPtr("print('pdf')\n")}.Build(),
+		},
+	}))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, `---
+name: pdf
+description: Extract text from PDF files
+allowed-tools: Read, Bash(python:*)
+---
+
+Use extract.py.
+`, m[".claude/skills/pdf/SKILL.md"])
+	assert.Equal(t, "print('pdf')\n", m[".claude/skills/pdf/extract.py"])
+}
+
+func TestRecipe_Materialize_ManagedMemory(t *testing.T) {
+	r := recipes.New(NewIDEProvider())
+	recipe := adcptest.Recipe(nil, adcptest.TextContext("@memory", "Run go test before committing.\n"))
+	content := "# Project\n\nHand-written notes.\n"
+
+	for range 2 {
+		ctx := core.WithFS(context.Background(), fstest.MapFS{"CLAUDE.md": {Data: []byte(content)}})
+		res, err := r.Materialize(ctx, recipe)
+		require.NoError(t, err)
+		require.Len(t, res.GetEntries(), 1)
+		content = res.GetEntries()[0].GetFile().GetContent()
+		assert.Equal(t, "# Project\n\nHand-written notes.\n\n"+
+			"<!-- adcp:begin -->\nRun go test before committing.\n<!-- adcp:end -->\n", content)
+	}
+}
+
+func TestIDE_Materialize_McpEnvAndHeaders(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"github":  {BearerTokenEnv: "GITHUB_TOKEN"},
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "${DEVPLAN_API_KEY}"}},
+	}))
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fstest.MapFS{}), ide)
+	require.NoError(t, err)
+	var content string
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == ".mcp.json" {
+			content = e.GetFile().GetContent()
+		}
+	}
+	require.NotEmpty(t, content)
+
+	var parsed struct {
+		McpServers map[string]struct {
+			Env     map[string]string `json:"env"`
+			Headers map[string]string `json:"headers"`
+		} `json:"mcpServers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(content), &parsed))
+	assert.Equal(t, map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}"}, parsed.McpServers["github"].Headers)
+	assert.Equal(t, map[string]string{"DEVPLAN_API_KEY": "${DEVPLAN_API_KEY}"}, parsed.McpServers["devplan"].Env)
+}
+
+func TestBuildClaudeSettingsJSON_PreservesKeyOrderAndIndent(t *testing.T) {
+	existing := `{
+    "model": "opus",
+    "permissions": {
+        "deny": [
+            "Read(.env)"
+        ],
+        "allow": [
+            "Bash(ls:*)"
+        ]
+    },
+    "hooks": {}
+}
+`
+	perms := adcp.Permissions_builder{
+		Allow: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build()},
+	}.Build()
+
+	out, err := (&settings{}).buildClaudeSettingsJSON(perms, nil, nil, existing)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+    "model": "opus",
+    "permissions": {
+        "deny": [
+            "Read(.env)"
+        ],
+        "allow": [
+            "Bash(ls:*)",
+            "Bash(go test:*)"
+        ],
+        "defaultMode": "acceptEdits"
+    },
+    "hooks": {},
+    "enableAllProjectMcpServers": true
+}
+`, out)
+}
+
+func TestBuildClaudeSettingsJSON_KeepsUnmanagedKeysByteForByte(t *testing.T) {
+	hooks := `{"PostToolUse": [{"matcher": "Write", "hooks": [{"type": "command", "command": "gofmt -w"}]}]}`
+	existing := "{\n  \"hooks\": " + hooks + ",\n  \"env\": {\"GOFLAGS\": \"-mod=mod\"},\n" +
+		"  \"permissions\": {\"allow\": []}\n}\n"
+	perms := adcp.Permissions_builder{
+		Allow: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build()},
+	}.Build()
+
+	out, err := (&settings{}).buildClaudeSettingsJSON(perms, nil, nil, existing)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "{\n  \"hooks\": "+hooks+",\n  \"env\": {\"GOFLAGS\": \"-mod=mod\"},\n"), out)
+	assert.Contains(t, out, `"Bash(go test:*)"`)
+}
+
+func TestIDE_Capabilities(t *testing.T) {
+	caps := NewIDEProvider().(recipes.CapabilityProvider).Capabilities()
+	assert.True(t, caps.Commands)
+	assert.True(t, caps.Permissions)
+	assert.True(t, caps.MemoryFiles)
+	assert.True(t, caps.Agents)
+	assert.True(t, caps.Skills)
+	assert.True(t, caps.Hooks)
+
+	assert.False(t, NewIDEProvider().(recipes.HookConfigurer).HasHooks())
+	assert.True(t, NewIDEProvider(WithHooks(Hook{Event: "Stop", Command: "make lint"})).(recipes.HookConfigurer).HasHooks())
+}
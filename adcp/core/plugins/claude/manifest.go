@@ -0,0 +1,116 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// manifestPath returns the core.Manifest of the settings file, next to it, e.g.
+// .claude/.adcp-manifest.settings.local.json. It lists the list entries adcp merged into the
+// settings file. Merging only ever adds entries, so the manifest is what lets a later run remove
+// the entries the recipe no longer declares without touching hand-written ones. An entry that was
+// also written by hand is removed with it.
+func (s *settings) manifestPath() string {
+	return path.Join(path.Dir(s.file()), ".adcp-manifest."+path.Base(s.file()))
+}
+
+// readManifest returns the entries recorded by a previous materialization, if any.
+func (s *settings) readManifest(ctx context.Context) map[string][]string {
+	data, err := core.ReadFile(ctx, s.manifestPath())
+	if err != nil {
+		return nil
+	}
+	var m core.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	entries := map[string][]string{}
+	for p, values := range m.Entries {
+		for _, v := range values {
+			entries[p] = append(entries[p], string(v))
+		}
+	}
+	return entries
+}
+
+// managedEntries returns the list entries the recipe and options merge into the settings file, in
+// the format of merge.Tracker.
+func (s *settings) managedEntries(input shared.SettingsInput, servers []string) (map[string][]string, error) {
+	tracker := &merge.Tracker{}
+	patch, err := s.permissionsPatch(input.Permissions, servers, input.CommandNames)
+	if err != nil {
+		return nil, err
+	}
+	if err := merge.Deep(merge.NewObject(), patch, tracker); err != nil {
+		return nil, err
+	}
+	if patch, err = s.patch(merge.NewObject(), input.MCPServerNames); err != nil {
+		return nil, err
+	}
+	if patch != nil {
+		if err := merge.Deep(merge.NewObject(), patch, tracker); err != nil {
+			return nil, err
+		}
+	}
+	entries := map[string][]string{}
+	for p, values := range tracker.Paths {
+		if len(values) > 0 {
+			entries[p] = values
+		}
+	}
+	return entries, nil
+}
+
+// pruneStale removes from content the entries of previous that are not in current.
+func pruneStale(content string, previous, current map[string][]string) (string, error) {
+	doc, _ := merge.ParseDocument(content)
+	pruned := false
+	for _, p := range slices.Sorted(maps.Keys(previous)) {
+		stale := slices.DeleteFunc(slices.Clone(previous[p]), func(v string) bool { return slices.Contains(current[p], v) })
+		if len(stale) == 0 {
+			continue
+		}
+		if err := merge.Prune(doc.Object, p, stale); err != nil {
+			return "", fmt.Errorf("failed to prune settings json: %w", err)
+		}
+		pruned = true
+	}
+	if !pruned {
+		return content, nil
+	}
+	out, err := doc.String()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal settings json: %w", err)
+	}
+	return out, nil
+}
+
+// materializeManifest records entries so later runs can prune the stale ones. No manifest is
+// written when there are no entries and none were recorded before.
+func (s *settings) materializeManifest(entries, previous map[string][]string) (*adcp.MaterializedResult_Entry, error) {
+	if len(entries) == 0 && len(previous) == 0 {
+		return nil, nil
+	}
+	m := core.Manifest{GeneratedBy: core.ManifestGeneratedBy, Entries: map[string][]json.RawMessage{}}
+	for p, values := range entries {
+		for _, v := range values {
+			m.Entries[p] = append(m.Entries[p], json.RawMessage(v))
+		}
+	}
+	content, err := merge.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings manifest: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: s.manifestPath(), Content: content}.Build(),
+	}.Build(), nil
+}
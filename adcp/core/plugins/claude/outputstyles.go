@@ -0,0 +1,81 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/frontmatter"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// outputStylesFolder is where output styles are written.
+const outputStylesFolder = ".claude/output-styles"
+
+// OutputStyle adapts the system prompt of Claude Code, e.g. for teaching or reviewing rather than
+// coding. Output styles are not part of the recipe model yet.
+type OutputStyle struct {
+	// Name identifies the style and names its file.
+	Name        string
+	Description string
+	// KeepCodingInstructions keeps the coding parts of Claude Code's system prompt.
+	KeepCodingInstructions bool
+	// From is the source of the style's instructions, like the source of a command.
+	From *adcp.CommandFrom
+}
+
+// WithOutputStyles writes styles to .claude/output-styles/<name>.md.
+func WithOutputStyles(styles ...OutputStyle) Option {
+	return withSettings(func(s *settings) {
+		s.outputStyles = append(s.outputStyles, styles...)
+	})
+}
+
+// WithOutputStyle activates the output style name, either one of WithOutputStyles or a built-in
+// style such as "Explanatory", by setting outputStyle in the settings file.
+func WithOutputStyle(name string) Option {
+	return withSettings(func(s *settings) {
+		s.outputStyle = name
+	})
+}
+
+// materializeOutputStyles renders the output styles into files with frontmatter.
+func (s *settings) materializeOutputStyles(ctx context.Context) ([]*adcp.MaterializedResult_Entry, error) {
+	var entries []*adcp.MaterializedResult_Entry
+	seen := map[string]bool{}
+	for _, style := range s.outputStyles {
+		if style.Name == "" || strings.ContainsAny(style.Name, `/\`) || strings.Trim(style.Name, ".") == "" {
+			return nil, fmt.Errorf("invalid output style name %q", style.Name)
+		}
+		if seen[style.Name] {
+			return nil, fmt.Errorf("duplicate output style name %q", style.Name)
+		}
+		seen[style.Name] = true
+		if style.From == nil {
+			return nil, fmt.Errorf("output style %s must have a 'from' source", style.Name)
+		}
+		body, err := shared.FetchCommandContent(ctx, style.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to materialize output style %s: %w", style.Name, err)
+		}
+		fm := frontmatter.New().
+			Set("name", style.Name).
+			Set("description", style.Description)
+		if style.KeepCodingInstructions {
+			fm.Set("keep-coding-instructions", true)
+		}
+		p := path.Join(outputStylesFolder, style.Name+".md")
+		change := core.PlannedChange{Path: p, Merge: core.MergeReplace}
+		if style.From.WhichType() == adcp.CommandFrom_Cmd_case {
+			change.Note = "command not executed: " + style.From.GetCmd()
+		}
+		core.RecordPlannedChange(ctx, change)
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: p, Content: fm.Render(body)}.Build(),
+		}.Build())
+	}
+	return entries, nil
+}
@@ -0,0 +1,136 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Locations of a Claude Code plugin, relative to the plugin directory.
+const (
+	pluginManifestFile = ".claude-plugin/plugin.json"
+	pluginHooksFile    = "hooks/hooks.json"
+)
+
+// Plugin describes a Claude Code plugin, which teams can distribute through a plugin marketplace
+// instead of committing the recipe's files to every project.
+type Plugin struct {
+	// Name identifies the plugin, e.g. "code-review". It must not contain spaces or slashes.
+	Name        string
+	Version     string
+	Description string
+	// Author is the name of the plugin's author, e.g. a team.
+	Author string
+	// Dir is the plugin directory relative to the root, e.g. "plugins/code-review". Empty writes
+	// the plugin at the root.
+	Dir string
+}
+
+// WithPlugin materializes the recipe as the Claude Code plugin p instead of project files:
+// .claude-plugin/plugin.json, commands/, agents/, skills/, hooks/hooks.json (see WithHooks) and
+// .mcp.json in p.Dir. Plugins cannot carry permissions, context files or other settings, which are
+// ignored with a warning.
+func WithPlugin(p Plugin) Option {
+	return withSettings(func(s *settings) {
+		s.plugin = &p
+	})
+}
+
+// applyPlugin replaces the project paths of ide by the layout of the plugin.
+func (p *Plugin) applyPlugin(ide *shared.IDE) {
+	ide.CommandsFolder = path.Join(p.Dir, "commands")
+	ide.AgentsFolder = path.Join(p.Dir, "agents")
+	ide.SkillsFolder = path.Join(p.Dir, "skills")
+	ide.MCPServersJSONPath = path.Join(p.Dir, ".mcp.json")
+	ide.AgentsMDPath = ""
+	ide.ContextPaths = nil
+	ide.ManagedMemory = false
+}
+
+// managedPaths reports the plugin manifest and hooks.
+func (p *Plugin) managedPaths() []recipes.ManagedPath {
+	return []recipes.ManagedPath{
+		{Pattern: path.Join(p.Dir, pluginManifestFile), Merge: core.MergeJSON},
+		{Pattern: path.Join(p.Dir, pluginHooksFile), Merge: core.MergeReplace},
+	}
+}
+
+// materializePlugin writes the plugin manifest, merged into an existing one so that fields it does
+// not manage, e.g. homepage or keywords, are kept, and the hooks of the plugin.
+func (s *settings) materializePlugin(ctx context.Context) ([]*adcp.MaterializedResult_Entry, error) {
+	p := s.plugin
+	if p.Name == "" || strings.ContainsAny(p.Name, " /\\") {
+		return nil, fmt.Errorf("invalid plugin name %q", p.Name)
+	}
+	if len(s.ask) > 0 || len(s.tools) > 0 || len(s.mcpToolServers) > 0 || len(s.additionalDirectories) > 0 ||
+		len(s.env) > 0 || len(s.outputStyles) > 0 || s.outputStyle != "" || s.statusLine != nil {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningUnsupportedFeature,
+			Path:    path.Join(p.Dir, pluginManifestFile),
+			Message: "Claude Code plugins cannot carry settings; permission, environment, output style and status line options are ignored",
+		})
+	}
+
+	manifestPath := path.Join(p.Dir, pluginManifestFile)
+	doc, _ := merge.ParseDocument(shared.ReadExistingJSON(ctx, manifestPath))
+	patch := merge.NewObject()
+	fields := []struct {
+		key, value string
+	}{
+		{"name", p.Name},
+		{"version", p.Version},
+		{"description", p.Description},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if err := patch.Set(f.key, f.value); err != nil {
+			return nil, err
+		}
+	}
+	if p.Author != "" {
+		if err := patch.Set("author", map[string]string{"name": p.Author}); err != nil {
+			return nil, err
+		}
+	}
+	if err := merge.Deep(doc.Object, patch, nil); err != nil {
+		return nil, fmt.Errorf("failed to merge plugin manifest: %w", err)
+	}
+	manifest, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin manifest: %w", err)
+	}
+	core.RecordPlannedChange(ctx, core.PlannedChange{Path: manifestPath, Merge: core.MergeJSON})
+	entries := []*adcp.MaterializedResult_Entry{adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: manifestPath, Content: manifest}.Build(),
+	}.Build()}
+
+	if len(s.hooks) > 0 {
+		hooks, err := buildHooks(s.hooks)
+		if err != nil {
+			return nil, err
+		}
+		out := merge.NewObject()
+		if err := out.Set("hooks", hooks); err != nil {
+			return nil, err
+		}
+		content, err := merge.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal plugin hooks: %w", err)
+		}
+		hooksPath := path.Join(p.Dir, pluginHooksFile)
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: hooksPath, Merge: core.MergeReplace})
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: hooksPath, Content: content}.Build(),
+		}.Build())
+	}
+	return entries, nil
+}
@@ -0,0 +1,322 @@
+package claude
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Hook events of Claude Code.
+const (
+	HookPreToolUse   = "PreToolUse"
+	HookPostToolUse  = "PostToolUse"
+	HookSessionStart = "SessionStart"
+)
+
+// Hook runs a shell command on a Claude Code event. Hooks are not part of the recipe model yet.
+type Hook struct {
+	// Event is the event triggering the hook, e.g. HookPreToolUse.
+	Event string
+	// Matcher selects the tools of tool events, e.g. "Bash" or "Edit|Write"; empty matches all tools.
+	// For HookSessionStart, it selects the session source, e.g. "startup".
+	Matcher string
+	// Command is the shell command to run.
+	Command string
+	// Timeout is the timeout of the command in seconds; zero uses Claude Code's default.
+	Timeout int
+}
+
+// WithSettingsFile merges permissions and other settings into path instead of SettingsLocal, e.g.
+// SettingsShared for settings reviewed and checked in with the project.
+func WithSettingsFile(path string) Option {
+	return withSettings(func(s *settings) {
+		s.path = path
+	})
+}
+
+// WithAskPermissions adds permissions to permissions.ask of the settings file, so that Claude Code
+// asks for confirmation before matching operations. The recipe model has no ask list yet.
+func WithAskPermissions(perms ...*adcp.OperationPermission) Option {
+	return withSettings(func(s *settings) {
+		s.ask = append(s.ask, perms...)
+	})
+}
+
+// WithAdditionalDirectories adds working directories Claude Code may access besides the project,
+// e.g. "../shared" for a sibling package of a monorepo, to permissions.additionalDirectories of
+// the settings file. Directories already listed are not added again.
+func WithAdditionalDirectories(dirs ...string) Option {
+	return withSettings(func(s *settings) {
+		s.additionalDirectories = append(s.additionalDirectories, dirs...)
+	})
+}
+
+// WithEnv merges environment variables into the env of the settings file, e.g.
+// {"BASH_DEFAULT_TIMEOUT_MS": "300000"}. Claude Code sets them for every session and the commands
+// it runs. Variables already set are overwritten; others are kept. The recipe model has no
+// environment yet.
+func WithEnv(env map[string]string) Option {
+	return withSettings(func(s *settings) {
+		if s.env == nil {
+			s.env = map[string]string{}
+		}
+		maps.Copy(s.env, env)
+	})
+}
+
+// WithDisabledMCPServers adds servers of .mcp.json to disabledMcpjsonServers of the settings file, so
+// that Claude Code does not start them, and removes them from enabledMcpjsonServers. Use
+// shared.WithRemovedMCPServers to delete servers instead.
+func WithDisabledMCPServers(names ...string) Option {
+	return withSettings(func(s *settings) {
+		if s.disabledMCPServers == nil {
+			s.disabledMCPServers = map[string]bool{}
+		}
+		for _, name := range names {
+			s.disabledMCPServers[name] = true
+		}
+	})
+}
+
+// ProjectMCPServers controls how enableAllProjectMcpServers is set in the settings file.
+type ProjectMCPServers int
+
+const (
+	// EnableAllProjectMCPServers sets enableAllProjectMcpServers, approving every server of .mcp.json,
+	// including servers added by hand. This is the default.
+	EnableAllProjectMCPServers ProjectMCPServers = iota
+	// EnableListedProjectMCPServers clears enableAllProjectMcpServers, so that only the servers of
+	// the recipe, listed in enabledMcpjsonServers, are approved.
+	EnableListedProjectMCPServers
+	// KeepProjectMCPServersSetting leaves enableAllProjectMcpServers as it is.
+	KeepProjectMCPServersSetting
+)
+
+// WithProjectMCPServers sets how enableAllProjectMcpServers is written; see ProjectMCPServers.
+func WithProjectMCPServers(mode ProjectMCPServers) Option {
+	return withSettings(func(s *settings) {
+		s.projectMCPServers = mode
+	})
+}
+
+// WithHooks merges hooks into the hooks of the settings file (see WithSettingsFile). Hooks already present are
+// not added again.
+func WithHooks(hooks ...Hook) Option {
+	return withSettings(func(s *settings) {
+		s.hooks = append(s.hooks, hooks...)
+	})
+}
+
+// StatusLine shows the output of a command at the bottom of Claude Code. Status lines are not part
+// of the recipe model yet.
+type StatusLine struct {
+	// Command prints the status line; it receives the session as JSON on stdin.
+	Command string
+	// Padding is the number of spaces around the status line; zero uses Claude Code's default.
+	Padding int
+	// Override replaces a status line already configured in the settings, which is kept otherwise.
+	Override bool
+}
+
+// WithStatusLine sets the statusLine of the settings file to run line.Command, unless a status line
+// is already configured and line.Override is false.
+func WithStatusLine(line StatusLine) Option {
+	return withSettings(func(s *settings) {
+		s.statusLine = &line
+	})
+}
+
+// withSettings configures the Claude settings of the IDE. It has no effect when the settings were
+// replaced, e.g. with shared.WithSettings.
+func withSettings(configure func(s *settings)) Option {
+	return func(ide *shared.IDE) {
+		if s, ok := ide.Settings.(*settings); ok {
+			configure(s)
+		}
+	}
+}
+
+type hookCommand struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+type statusLine struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Padding int    `json:"padding,omitempty"`
+}
+
+type hookMatcher struct {
+	Matcher string        `json:"matcher,omitempty"`
+	Hooks   []hookCommand `json:"hooks"`
+}
+
+// patch returns the settings configured by options, merged into the existing settings after
+// permissions, or nil when there are none.
+func (s *settings) patch(existing *merge.Object, mcpServerNames []string) (*merge.Object, error) {
+	patch := merge.NewObject()
+	var toolServers []string
+	for _, name := range mcpServerNames {
+		if s.mcpToolServers[name] && !s.disabledMCPServers[name] {
+			toolServers = append(toolServers, name)
+		}
+	}
+	if len(toolServers) > 0 {
+		if err := patch.Set("enabledMcpjsonServers", toolServers); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.disabledMCPServers) > 0 {
+		disabled := slices.Sorted(maps.Keys(s.disabledMCPServers))
+		var entries []string
+		for _, name := range disabled {
+			entries = append(entries, strconv.Quote(name))
+		}
+		if err := merge.Prune(existing, "enabledMcpjsonServers", entries); err != nil {
+			return nil, err
+		}
+		if err := patch.Set("disabledMcpjsonServers", disabled); err != nil {
+			return nil, err
+		}
+	}
+	rules := map[string][]string{}
+	for _, p := range s.ask {
+		if rule := formatPermission(p); rule != "" {
+			rules[PermissionAsk] = append(rules[PermissionAsk], rule)
+		}
+	}
+	for _, list := range slices.Sorted(maps.Keys(s.tools)) {
+		if list != PermissionAllow && list != PermissionAsk && list != PermissionDeny {
+			return nil, fmt.Errorf("unknown permission list %q", list)
+		}
+		for _, p := range s.tools[list] {
+			if strings.TrimSpace(p.Tool) != "" {
+				rules[list] = append(rules[list], p.String())
+			}
+		}
+	}
+	permissions := merge.NewObject()
+	for _, list := range []string{PermissionAllow, PermissionAsk, PermissionDeny} {
+		if len(rules[list]) == 0 {
+			continue
+		}
+		if err := permissions.Set(list, rules[list]); err != nil {
+			return nil, err
+		}
+	}
+	if dirs := slices.DeleteFunc(slices.Clone(s.additionalDirectories), func(d string) bool { return strings.TrimSpace(d) == "" }); len(dirs) > 0 {
+		if err := permissions.Set("additionalDirectories", merge.UniqueStrings(nil, dirs)); err != nil {
+			return nil, err
+		}
+	}
+	if len(permissions.Keys()) > 0 {
+		if err := patch.Set("permissions", permissions); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.env) > 0 {
+		env := merge.NewObject()
+		for _, name := range slices.Sorted(maps.Keys(s.env)) {
+			if strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("environment variable must have a name")
+			}
+			if err := env.Set(name, s.env[name]); err != nil {
+				return nil, err
+			}
+		}
+		if err := patch.Set("env", env); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.hooks) > 0 {
+		hooks, err := buildHooks(s.hooks)
+		if err != nil {
+			return nil, err
+		}
+		if err := patch.Set("hooks", hooks); err != nil {
+			return nil, err
+		}
+	}
+	if s.outputStyle != "" {
+		if err := patch.Set("outputStyle", s.outputStyle); err != nil {
+			return nil, err
+		}
+	}
+	if s.statusLine != nil && (s.statusLine.Override || !existing.Has("statusLine")) {
+		if s.statusLine.Command == "" {
+			return nil, fmt.Errorf("status line must have a command")
+		}
+		line := statusLine{Type: "command", Command: s.statusLine.Command, Padding: s.statusLine.Padding}
+		// The status line replaces the existing one as a whole instead of being merged into it.
+		if err := existing.Set("statusLine", line); err != nil {
+			return nil, err
+		}
+		if err := patch.Set("statusLine", line); err != nil {
+			return nil, err
+		}
+	}
+	if len(patch.Keys()) == 0 {
+		return nil, nil
+	}
+	return patch, nil
+}
+
+// buildHooks groups hooks by event and matcher, in order of appearance, into the format of the
+// hooks setting. Each matcher becomes one list entry, so that re-running with the same hooks
+// produces identical entries, which are deduplicated when merged.
+func buildHooks(hooks []Hook) (*merge.Object, error) {
+	out := merge.NewObject()
+	byEvent := map[string][]hookMatcher{}
+	var events []string
+	for _, h := range hooks {
+		if h.Event == "" || h.Command == "" {
+			return nil, fmt.Errorf("hook must have an event and a command")
+		}
+		matchers, seen := byEvent[h.Event]
+		if !seen {
+			events = append(events, h.Event)
+		}
+		cmd := hookCommand{Type: "command", Command: h.Command, Timeout: h.Timeout}
+		idx := slices.IndexFunc(matchers, func(m hookMatcher) bool { return m.Matcher == h.Matcher })
+		if idx < 0 {
+			matchers = append(matchers, hookMatcher{Matcher: h.Matcher})
+			idx = len(matchers) - 1
+		}
+		if !slices.Contains(matchers[idx].Hooks, cmd) {
+			matchers[idx].Hooks = append(matchers[idx].Hooks, cmd)
+		}
+		byEvent[h.Event] = matchers
+	}
+	for _, event := range events {
+		if err := out.Set(event, byEvent[event]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// applyPatch merges the settings configured by options into content, given the MCP servers of
+// the recipe.
+func (s *settings) applyPatch(content string, mcpServerNames []string) (string, error) {
+	doc, _ := merge.ParseDocument(content)
+	patch, err := s.patch(doc.Object, mcpServerNames)
+	if err != nil || patch == nil {
+		return content, err
+	}
+	if err := merge.Deep(doc.Object, patch, nil); err != nil {
+		return "", fmt.Errorf("failed to merge settings json: %w", err)
+	}
+	out, err := doc.String()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal settings json: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,509 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// materializeSettings materializes an empty IDE with opts over existing settings and returns the
+// content of the settings file.
+func materializeSettings(t *testing.T, existing string, opts ...Option) string {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	if existing != "" {
+		fsys[SettingsLocal] = &fstest.MapFile{Data: []byte(existing)}
+	}
+	res, err := NewIDEProvider(opts...).Materialize(core.WithFS(context.Background(), fsys), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == SettingsLocal {
+			return e.GetFile().GetContent()
+		}
+	}
+	t.Fatalf("%s not materialized", SettingsLocal)
+	return ""
+}
+
+func TestIDE_Materialize_Hooks(t *testing.T) {
+	opts := []Option{WithHooks(
+		Hook{Event: HookPreToolUse, Matcher: "Bash", Command: "./scripts/check.sh"},
+		Hook{Event: HookPostToolUse, Matcher: "Edit|Write", Command: "gofmt -w .", Timeout: 30},
+		Hook{Event: HookPreToolUse, Matcher: "Bash", Command: "./scripts/audit.sh"},
+		Hook{Event: HookPreToolUse, Matcher: "Bash", Command: "./scripts/check.sh"},
+		Hook{Event: HookSessionStart, Command: "git fetch"},
+	)}
+	existing := `{
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Read", "hooks": [{"type": "command", "command": "./mine.sh"}]}
+    ]
+  }
+}
+`
+	first := materializeSettings(t, existing, opts...)
+	assert.JSONEq(t, `{
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Read", "hooks": [{"type": "command", "command": "./mine.sh"}]},
+      {"matcher": "Bash", "hooks": [
+        {"type": "command", "command": "./scripts/check.sh"},
+        {"type": "command", "command": "./scripts/audit.sh"}
+      ]}
+    ],
+    "PostToolUse": [
+      {"matcher": "Edit|Write", "hooks": [{"type": "command", "command": "gofmt -w .", "timeout": 30}]}
+    ],
+    "SessionStart": [
+      {"hooks": [{"type": "command", "command": "git fetch"}]}
+    ]
+  },
+  "permissions": {"defaultMode": "acceptEdits"},
+  "enableAllProjectMcpServers": true
+}`, first)
+
+	assert.Equal(t, first, materializeSettings(t, first, opts...), "re-running must not duplicate hooks")
+}
+
+func TestIDE_Materialize_InvalidHook(t *testing.T) {
+	_, err := NewIDEProvider(WithHooks(Hook{Event: HookPreToolUse})).Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	assert.ErrorContains(t, err, "hook must have an event and a command")
+}
+
+func TestIDE_Materialize_OutputStyles(t *testing.T) {
+	text := "Explain each change before making it.\n"
+	g := NewIDEProvider(
+		WithOutputStyles(OutputStyle{
+			Name:                   "mentor",
+			Description:            "Teaches while coding",
+			KeepCodingInstructions: true,
+			From:                   adcp.CommandFrom_builder{Text: &text}.Build(),
+		}),
+		WithOutputStyle("mentor"),
+	)
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, `---
+name: mentor
+description: Teaches while coding
+keep-coding-instructions: true
+---
+
+Explain each change before making it.
+`, m[".claude/output-styles/mentor.md"])
+
+	var settings struct {
+		OutputStyle string `json:"outputStyle"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(m[SettingsLocal]), &settings))
+	assert.Equal(t, "mentor", settings.OutputStyle)
+}
+
+func TestIDE_Materialize_InvalidOutputStyle(t *testing.T) {
+	text := "x"
+	from := adcp.CommandFrom_builder{Text: &text}.Build()
+	tests := []struct {
+		name    string
+		styles  []OutputStyle
+		wantErr string
+	}{
+		{name: "path in name", styles: []OutputStyle{{Name: "../evil", From: from}}, wantErr: `invalid output style name "../evil"`},
+		{name: "duplicate", styles: []OutputStyle{{Name: "a", From: from}, {Name: "a", From: from}}, wantErr: `duplicate output style name "a"`},
+		{name: "missing source", styles: []OutputStyle{{Name: "a"}}, wantErr: "output style a must have a 'from' source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewIDEProvider(WithOutputStyles(tt.styles...)).Materialize(context.Background(), adcp.Ide_builder{}.Build())
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestIDE_Materialize_StatusLine(t *testing.T) {
+	custom := `{"statusLine": {"type": "command", "command": "~/bin/mine.sh", "padding": 2}}`
+	tests := []struct {
+		name     string
+		existing string
+		line     StatusLine
+		want     string
+	}{
+		{
+			name: "added",
+			line: StatusLine{Command: "./scripts/status.sh"},
+			want: `{"type": "command", "command": "./scripts/status.sh"}`,
+		},
+		{
+			name:     "existing kept",
+			existing: custom,
+			line:     StatusLine{Command: "./scripts/status.sh"},
+			want:     `{"type": "command", "command": "~/bin/mine.sh", "padding": 2}`,
+		},
+		{
+			name:     "existing overridden",
+			existing: custom,
+			line:     StatusLine{Command: "./scripts/status.sh", Padding: 1, Override: true},
+			want:     `{"type": "command", "command": "./scripts/status.sh", "padding": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := materializeSettings(t, tt.existing, WithStatusLine(tt.line))
+			var got struct {
+				StatusLine json.RawMessage `json:"statusLine"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(out), &got))
+			assert.JSONEq(t, tt.want, string(got.StatusLine))
+		})
+	}
+}
+
+func TestIDE_Materialize_SettingsFile(t *testing.T) {
+	perms := adcp.Permissions_builder{Allow: []*adcp.OperationPermission{
+		adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build(),
+	}}.Build()
+	g := NewIDEProvider(WithSettingsFile(SettingsShared))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{Permissions: perms}.Build())
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Equal(t, []string{SettingsShared, ".claude/.adcp-manifest.settings.json"}, paths)
+	assert.Contains(t, g.(recipes.ManagedPathsProvider).ManagedPaths(), recipes.ManagedPath{Pattern: SettingsShared, Merge: core.MergeJSON})
+}
+
+func TestIDE_Materialize_UserScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".claude.json"), []byte(`{"oauthAccount": {"email": "dev@example.com"}, "projects": {}}`), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".claude"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, SettingsShared), []byte(`{"model": "opus"}`), 0o644))
+
+	g := NewIDEProvider(shared.WithScope(shared.ScopeUser))
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+		}}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"docs": adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://docs.example.com/mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	var paths []string
+	files := map[string]string{}
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+		files[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.ElementsMatch(t, []string{
+		".claude/commands/review.md",
+		".claude/commands/.adcp-manifest.json",
+		SettingsShared,
+		".claude/.adcp-manifest.settings.json",
+		".claude.json",
+	}, paths)
+	// Existing user configuration is read from the home directory and kept.
+	assert.JSONEq(t, `{
+  "oauthAccount": {"email": "dev@example.com"},
+  "projects": {},
+  "mcpServers": {"docs": {"type": "http", "url": "https://docs.example.com/mcp"}}
+}`, files[".claude.json"])
+	var userSettings map[string]any
+	require.NoError(t, json.Unmarshal([]byte(files[SettingsShared]), &userSettings))
+	assert.Equal(t, "opus", userSettings["model"])
+
+	path, err := g.(recipes.ContextPathMapper).MapContextPath("@memory")
+	require.NoError(t, err)
+	assert.Equal(t, ".claude/CLAUDE.md", path)
+}
+
+func TestIDE_Materialize_AskPermissions(t *testing.T) {
+	ask := []*adcp.OperationPermission{
+		adcp.OperationPermission_builder{Bash: strPtr("git push:*")}.Build(),
+		adcp.OperationPermission_builder{Write: strPtr("go.mod")}.Build(),
+		{},
+	}
+	existing := `{"permissions": {"ask": ["Bash(git push:*)", "Bash(rm:*)"]}}`
+	report := core.NewReport()
+	fsys := fstest.MapFS{SettingsLocal: {Data: []byte(existing)}}
+	ctx := core.WithReport(core.WithFS(context.Background(), fsys), report)
+
+	res, err := NewIDEProvider(WithAskPermissions(ask...)).Materialize(ctx, adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	var settings struct {
+		Permissions struct {
+			Ask []string `json:"ask"`
+		} `json:"permissions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(res.GetEntries()[0].GetFile().GetContent()), &settings))
+	assert.Equal(t, []string{"Bash(git push:*)", "Bash(rm:*)", "Write(go.mod)"}, settings.Permissions.Ask)
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningDroppedPermission, report.Data().Warnings[0].Code)
+}
+
+func TestIDE_Materialize_ToolPermissions(t *testing.T) {
+	g := NewIDEProvider(
+		WithToolPermissions(PermissionAllow,
+			ToolPermission{Tool: ToolWebFetch, Specifier: "domain:pkg.go.dev"},
+			ToolPermission{Tool: ToolWebSearch},
+			ToolPermission{Tool: ToolGlob},
+			ToolPermission{Tool: ToolGrep},
+		),
+		WithToolPermissions(PermissionAsk, ToolPermission{Tool: ToolEdit, Specifier: "go.mod"}),
+		WithToolPermissions(PermissionDeny, ToolPermission{Tool: ToolEdit, Specifier: ".env"}),
+	)
+	perms := adcp.Permissions_builder{Allow: []*adcp.OperationPermission{
+		adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build(),
+	}}.Build()
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{Permissions: perms}.Build())
+	require.NoError(t, err)
+	var settings struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+			Ask   []string `json:"ask"`
+			Deny  []string `json:"deny"`
+		} `json:"permissions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(res.GetEntries()[0].GetFile().GetContent()), &settings))
+	assert.Equal(t, []string{"Bash(go test:*)", "WebFetch(domain:pkg.go.dev)", "WebSearch", "Glob", "Grep"}, settings.Permissions.Allow)
+	assert.Equal(t, []string{"Edit(go.mod)"}, settings.Permissions.Ask)
+	assert.Equal(t, []string{"Edit(.env)"}, settings.Permissions.Deny)
+
+	_, err = NewIDEProvider(WithToolPermissions("maybe", ToolPermission{Tool: ToolGrep})).Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	assert.ErrorContains(t, err, `unknown permission list "maybe"`)
+}
+
+func TestIDE_Materialize_MCPToolPermissions(t *testing.T) {
+	g := NewIDEProvider(
+		WithMCPToolPermissions(PermissionAllow, "github", "get_issue", "list_issues"),
+		WithMCPToolPermissions(PermissionDeny, "github", "merge_pull_request"),
+	)
+	ide := adcp.Ide_builder{Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+		"github": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "github-mcp"}.Build()}.Build(),
+		"docs":   adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://docs.example.com/mcp"}.Build()}.Build(),
+	}}.Build()}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	var settings struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+			Deny  []string `json:"deny"`
+		} `json:"permissions"`
+		EnabledMcpjsonServers []string `json:"enabledMcpjsonServers"`
+	}
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == SettingsLocal {
+			require.NoError(t, json.Unmarshal([]byte(e.GetFile().GetContent()), &settings))
+		}
+	}
+	assert.Equal(t, []string{"mcp__docs", "mcp__github__get_issue", "mcp__github__list_issues"}, settings.Permissions.Allow)
+	assert.Equal(t, []string{"mcp__github__merge_pull_request"}, settings.Permissions.Deny)
+	assert.Equal(t, []string{"docs", "github"}, settings.EnabledMcpjsonServers)
+}
+
+func TestIDE_Materialize_AdditionalDirectories(t *testing.T) {
+	existing := `{"permissions": {"additionalDirectories": ["../docs"]}}`
+	out := materializeSettings(t, existing, WithAdditionalDirectories("../shared", "../docs", "", "../shared"))
+
+	var settings struct {
+		Permissions struct {
+			AdditionalDirectories []string `json:"additionalDirectories"`
+		} `json:"permissions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &settings))
+	assert.Equal(t, []string{"../docs", "../shared"}, settings.Permissions.AdditionalDirectories)
+}
+
+func TestIDE_Materialize_ProjectMCPServers(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		mode     ProjectMCPServers
+		want     *bool
+	}{
+		{name: "enable all", mode: EnableAllProjectMCPServers, want: boolPtr(true)},
+		{name: "enable listed", existing: `{"enableAllProjectMcpServers": true}`, mode: EnableListedProjectMCPServers, want: boolPtr(false)},
+		{name: "keep existing", existing: `{"enableAllProjectMcpServers": false}`, mode: KeepProjectMCPServersSetting, want: boolPtr(false)},
+		{name: "keep absent", mode: KeepProjectMCPServersSetting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := materializeSettings(t, tt.existing, WithProjectMCPServers(tt.mode))
+			var settings struct {
+				EnableAllProjectMcpServers *bool `json:"enableAllProjectMcpServers"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(out), &settings))
+			assert.Equal(t, tt.want, settings.EnableAllProjectMcpServers)
+		})
+	}
+}
+
+func TestIDE_Materialize_Env(t *testing.T) {
+	existing := `{"env": {"ANTHROPIC_MODEL": "claude-sonnet-4-5", "MY_TOKEN": "secret"}}`
+	out := materializeSettings(t, existing, WithEnv(map[string]string{
+		"BASH_DEFAULT_TIMEOUT_MS": "300000",
+		"ANTHROPIC_MODEL":         "claude-opus-4-1",
+	}))
+
+	var settings struct {
+		Env map[string]string `json:"env"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &settings))
+	assert.Equal(t, map[string]string{
+		"ANTHROPIC_MODEL":         "claude-opus-4-1",
+		"BASH_DEFAULT_TIMEOUT_MS": "300000",
+		"MY_TOKEN":                "secret",
+	}, settings.Env)
+	assert.Equal(t, out, materializeSettings(t, out, WithEnv(map[string]string{"BASH_DEFAULT_TIMEOUT_MS": "300000"})))
+}
+
+func TestIDE_Materialize_InvalidEnv(t *testing.T) {
+	_, err := NewIDEProvider(WithEnv(map[string]string{" ": "x"})).
+		Materialize(core.WithFS(context.Background(), fstest.MapFS{}), adcp.Ide_builder{}.Build())
+	assert.ErrorContains(t, err, "environment variable must have a name")
+}
+
+func TestIDE_Materialize_PrunesStaleEntries(t *testing.T) {
+	text := func(s string) *adcp.CommandFrom { return adcp.CommandFrom_builder{Text: strPtr(s)}.Build() }
+	ide := func(commands ...string) *adcp.Ide {
+		var entries []*adcp.Command
+		for _, name := range commands {
+			entries = append(entries, adcp.Command_builder{Name: name, From: text(name)}.Build())
+		}
+		return adcp.Ide_builder{Commands: adcp.Commands_builder{Entries: entries}.Build()}.Build()
+	}
+	materialize := func(fsys fstest.MapFS, ide *adcp.Ide) {
+		res, err := NewIDEProvider().Materialize(core.WithFS(context.Background(), fsys), ide)
+		require.NoError(t, err)
+		for _, e := range res.GetEntries() {
+			fsys[e.GetFile().GetPath()] = &fstest.MapFile{Data: []byte(e.GetFile().GetContent())}
+		}
+	}
+	fsys := fstest.MapFS{
+		SettingsLocal: &fstest.MapFile{Data: []byte(`{"permissions": {"allow": ["Bash(ls)"]}}`)},
+	}
+
+	materialize(fsys, ide("review", "status"))
+	materialize(fsys, ide("review"))
+
+	var settings struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+		} `json:"permissions"`
+	}
+	require.NoError(t, json.Unmarshal(fsys[SettingsLocal].Data, &settings))
+	assert.Equal(t, []string{"Bash(ls)", "SlashCommand(/review)"}, settings.Permissions.Allow)
+
+	materialize(fsys, ide())
+	require.NoError(t, json.Unmarshal(fsys[SettingsLocal].Data, &settings))
+	assert.Equal(t, []string{"Bash(ls)"}, settings.Permissions.Allow)
+}
+
+func TestIDE_Materialize_Plugin(t *testing.T) {
+	g := NewIDEProvider(
+		WithPlugin(Plugin{Name: "review", Version: "1.2.0", Author: "Platform", Dir: "plugins/review"}),
+		WithHooks(Hook{Event: HookPostToolUse, Matcher: "Edit", Command: "gofmt -w ."}),
+		WithEnv(map[string]string{"A": "1"}),
+		shared.WithAgents(shared.Agent{Name: "reviewer", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}),
+	)
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+		}}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"docs": adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://docs.example.com/mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	existing := `{"name": "review", "homepage": "https://example.com"}`
+	report := core.NewReport()
+	fsys := fstest.MapFS{"plugins/review/.claude-plugin/plugin.json": {Data: []byte(existing)}}
+	ctx := core.WithReport(core.WithFS(context.Background(), fsys), report)
+
+	res, err := g.Materialize(ctx, ide)
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, e := range res.GetEntries() {
+		files[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.ElementsMatch(t, []string{
+		"plugins/review/.claude-plugin/plugin.json",
+		"plugins/review/commands/review.md",
+		"plugins/review/commands/.adcp-manifest.json",
+		"plugins/review/agents/reviewer.md",
+		"plugins/review/hooks/hooks.json",
+		"plugins/review/.mcp.json",
+	}, slices.Collect(maps.Keys(files)))
+	assert.JSONEq(t, `{"name": "review", "homepage": "https://example.com", "version": "1.2.0", "author": {"name": "Platform"}}`,
+		files["plugins/review/.claude-plugin/plugin.json"])
+	assert.JSONEq(t, `{"hooks": {"PostToolUse": [{"matcher": "Edit", "hooks": [{"type": "command", "command": "gofmt -w ."}]}]}}`,
+		files["plugins/review/hooks/hooks.json"])
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningUnsupportedFeature, report.Data().Warnings[0].Code)
+	assert.False(t, g.(recipes.CapabilityProvider).Capabilities().Permissions)
+}
+
+func TestIDE_Materialize_InvalidPlugin(t *testing.T) {
+	_, err := NewIDEProvider(WithPlugin(Plugin{Name: "code review"})).
+		Materialize(core.WithFS(context.Background(), fstest.MapFS{}), adcp.Ide_builder{}.Build())
+	assert.ErrorContains(t, err, `invalid plugin name "code review"`)
+}
+
+func TestIDE_Materialize_DisabledMCPServers(t *testing.T) {
+	g := NewIDEProvider(WithDisabledMCPServers("legacy", "docs"), shared.WithRemovedMCPServers("old"))
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"docs":   adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://docs.example.com/mcp"}.Build()}.Build(),
+			"github": adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	fsys := fstest.MapFS{
+		SettingsLocal: {Data: []byte(`{"enabledMcpjsonServers": ["legacy", "mine"]}`)},
+		".mcp.json":   {Data: []byte(`{"mcpServers": {"old": {"command": "old"}, "legacy": {"command": "legacy"}}}`)},
+	}
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, e := range res.GetEntries() {
+		files[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	var settings struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+		} `json:"permissions"`
+		EnabledMcpjsonServers  []string `json:"enabledMcpjsonServers"`
+		DisabledMcpjsonServers []string `json:"disabledMcpjsonServers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(files[SettingsLocal]), &settings))
+	assert.Equal(t, []string{"mcp__github"}, settings.Permissions.Allow)
+	assert.Equal(t, []string{"mine", "github"}, settings.EnabledMcpjsonServers)
+	assert.Equal(t, []string{"docs", "legacy"}, settings.DisabledMcpjsonServers)
+
+	var mcp struct {
+		McpServers map[string]json.RawMessage `json:"mcpServers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(files[".mcp.json"]), &mcp))
+	assert.ElementsMatch(t, []string{"legacy", "docs", "github"}, slices.Collect(maps.Keys(mcp.McpServers)))
+}
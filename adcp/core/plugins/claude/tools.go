@@ -0,0 +1,93 @@
+package claude
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Claude Code tools that permission rules can refer to besides Bash, Read and Write.
+const (
+	ToolEdit      = "Edit"
+	ToolGlob      = "Glob"
+	ToolGrep      = "Grep"
+	ToolWebFetch  = "WebFetch"
+	ToolWebSearch = "WebSearch"
+)
+
+// Permission lists of Claude settings.
+const (
+	PermissionAllow = "allow"
+	PermissionAsk   = "ask"
+	PermissionDeny  = "deny"
+)
+
+// ToolPermission is a permission rule for a Claude Code tool that adcp.OperationPermission cannot
+// express yet, e.g. {Tool: ToolWebFetch, Specifier: "domain:go.dev"} for "WebFetch(domain:go.dev)".
+// Without a specifier, the rule matches every use of the tool.
+type ToolPermission struct {
+	Tool      string
+	Specifier string
+}
+
+// String formats the rule as written in the settings.
+func (p ToolPermission) String() string {
+	if p.Specifier == "" {
+		return p.Tool
+	}
+	return fmt.Sprintf("%s(%s)", p.Tool, p.Specifier)
+}
+
+// WithToolPermissions adds rules to a permission list of the settings file: PermissionAllow,
+// PermissionAsk or PermissionDeny.
+func WithToolPermissions(list string, perms ...ToolPermission) Option {
+	return withSettings(func(s *settings) {
+		if s.tools == nil {
+			s.tools = map[string][]ToolPermission{}
+		}
+		s.tools[list] = append(s.tools[list], perms...)
+	})
+}
+
+// WithMCPToolPermissions adds rules for individual tools of an MCP server, e.g. "mcp__github__get_issue",
+// to a permission list. Servers with tool rules are no longer allowed as a whole, which Claude
+// settings otherwise do for every MCP server of the recipe.
+func WithMCPToolPermissions(list, server string, tools ...string) Option {
+	return withSettings(func(s *settings) {
+		if s.tools == nil {
+			s.tools = map[string][]ToolPermission{}
+		}
+		if s.mcpToolServers == nil {
+			s.mcpToolServers = map[string]bool{}
+		}
+		s.mcpToolServers[server] = true
+		for _, tool := range tools {
+			s.tools[list] = append(s.tools[list], ToolPermission{Tool: mcpToolRule(server, tool)})
+		}
+	})
+}
+
+// mcpToolRule returns the permission rule for tool of an MCP server.
+func mcpToolRule(server, tool string) string {
+	return fmt.Sprintf("mcp__%s__%s", server, tool)
+}
+
+// ValidateToolPermission checks a tool rule like ValidatePermission does for recipe permissions.
+func ValidateToolPermission(p ToolPermission) []string {
+	if strings.TrimSpace(p.Tool) == "" {
+		return []string{"tool name is empty; the rule is ignored"}
+	}
+	if p.Specifier == "" {
+		return nil
+	}
+	switch p.Tool {
+	case ToolEdit, ToolGlob, ToolGrep:
+		return validatePathPattern(p.Tool, p.Specifier)
+	case ToolWebFetch:
+		if !strings.HasPrefix(p.Specifier, "domain:") {
+			return []string{fmt.Sprintf("%s: WebFetch rules match by domain; use 'domain:<host>'", p)}
+		}
+	case ToolWebSearch:
+		return []string{fmt.Sprintf("%s: WebSearch takes no specifier; use WebSearch to match all searches", p)}
+	}
+	return nil
+}
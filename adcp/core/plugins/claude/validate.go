@@ -0,0 +1,73 @@
+package claude
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+var (
+	windowsPathRe = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+	braceGlobRe   = regexp.MustCompile(`\{[^}]*,[^}]*\}`)
+)
+
+// ValidatePermission checks a permission against Claude's documented rule syntax and returns
+// warnings for patterns that are accepted by Claude but will most likely never match at runtime.
+// An empty result means no problems were detected.
+func ValidatePermission(p *adcp.OperationPermission) []string {
+	if p == nil || !p.HasType() {
+		return nil
+	}
+	switch p.WhichType() {
+	case adcp.OperationPermission_Bash_case:
+		return validateBashPattern(p.GetBash())
+	case adcp.OperationPermission_Read_case:
+		return validatePathPattern("Read", p.GetRead())
+	case adcp.OperationPermission_Write_case:
+		return validatePathPattern("Write", p.GetWrite())
+	default:
+		return nil
+	}
+}
+
+// validateBashPattern checks Bash rules. Claude matches Bash commands exactly or by prefix using a trailing ":*".
+func validateBashPattern(pattern string) []string {
+	if strings.TrimSpace(pattern) == "" {
+		return []string{"Bash pattern is empty; use Bash without a specifier to match all commands"}
+	}
+	var warnings []string
+	body := strings.TrimSuffix(pattern, ":*")
+	if strings.Contains(body, ":*") {
+		warnings = append(warnings, fmt.Sprintf("Bash(%s): prefix matcher ':*' is only supported at the end of the pattern", pattern))
+	} else if strings.Contains(body, "*") {
+		warnings = append(warnings, fmt.Sprintf("Bash(%s): wildcards are not supported; use a trailing ':*' for prefix matching", pattern))
+	}
+	if pattern != strings.TrimSpace(pattern) {
+		warnings = append(warnings, fmt.Sprintf("Bash(%s): leading or trailing whitespace will prevent matches", pattern))
+	}
+	return warnings
+}
+
+// validatePathPattern checks Read/Write rules, which follow gitignore-style path matching.
+func validatePathPattern(tool, pattern string) []string {
+	if strings.TrimSpace(pattern) == "" {
+		return []string{fmt.Sprintf("%s pattern is empty", tool)}
+	}
+	var warnings []string
+	if strings.HasPrefix(pattern, "/") && !strings.HasPrefix(pattern, "//") {
+		warnings = append(warnings, fmt.Sprintf("%s(%s): a single leading '/' is relative to the settings file; use '//%s' for an absolute path",
+			tool, pattern, strings.TrimPrefix(pattern, "/")))
+	}
+	if windowsPathRe.MatchString(pattern) || strings.Contains(pattern, `\`) {
+		warnings = append(warnings, fmt.Sprintf("%s(%s): use forward slashes; Windows-style paths are not matched", tool, pattern))
+	}
+	if braceGlobRe.MatchString(pattern) {
+		warnings = append(warnings, fmt.Sprintf("%s(%s): brace expansion is not supported in gitignore-style patterns", tool, pattern))
+	}
+	if strings.Contains(pattern, "***") {
+		warnings = append(warnings, fmt.Sprintf("%s(%s): use '**' to match across directories", tool, pattern))
+	}
+	return warnings
+}
@@ -0,0 +1,102 @@
+package claude
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		perm     *adcp.OperationPermission
+		wantWarn []string
+	}{
+		{name: "bash prefix", perm: adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build()},
+		{name: "bash exact", perm: adcp.OperationPermission_builder{Bash: strPtr("npm run build")}.Build()},
+		{
+			name:     "bash wildcard",
+			perm:     adcp.OperationPermission_builder{Bash: strPtr("git * --force")}.Build(),
+			wantWarn: []string{"wildcards are not supported"},
+		},
+		{
+			name:     "bash prefix in the middle",
+			perm:     adcp.OperationPermission_builder{Bash: strPtr("git:* push")}.Build(),
+			wantWarn: []string{"only supported at the end"},
+		},
+		{name: "read home", perm: adcp.OperationPermission_builder{Read: strPtr("~/.zshrc")}.Build()},
+		{name: "read absolute", perm: adcp.OperationPermission_builder{Read: strPtr("//etc/hosts")}.Build()},
+		{
+			name:     "read single slash",
+			perm:     adcp.OperationPermission_builder{Read: strPtr("/etc/hosts")}.Build(),
+			wantWarn: []string{"use '//etc/hosts'"},
+		},
+		{
+			name:     "write windows path",
+			perm:     adcp.OperationPermission_builder{Write: strPtr(`C:\repo\out`)}.Build(),
+			wantWarn: []string{"forward slashes"},
+		},
+		{
+			name:     "write brace glob",
+			perm:     adcp.OperationPermission_builder{Write: strPtr("src/**/*.{ts,tsx}")}.Build(),
+			wantWarn: []string{"brace expansion"},
+		},
+		{
+			name:     "read empty",
+			perm:     adcp.OperationPermission_builder{Read: strPtr("")}.Build(),
+			wantWarn: []string{"Read pattern is empty"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := ValidatePermission(tt.perm)
+			assert.Len(t, warnings, len(tt.wantWarn))
+			for i, want := range tt.wantWarn {
+				if i < len(warnings) {
+					assert.Contains(t, warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateToolPermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		perm     ToolPermission
+		wantWarn []string
+	}{
+		{name: "web fetch domain", perm: ToolPermission{Tool: ToolWebFetch, Specifier: "domain:go.dev"}},
+		{name: "web search", perm: ToolPermission{Tool: ToolWebSearch}},
+		{name: "edit path", perm: ToolPermission{Tool: ToolEdit, Specifier: "docs/**"}},
+		{
+			name:     "web fetch url",
+			perm:     ToolPermission{Tool: ToolWebFetch, Specifier: "https://go.dev"},
+			wantWarn: []string{"use 'domain:<host>'"},
+		},
+		{
+			name:     "web search specifier",
+			perm:     ToolPermission{Tool: ToolWebSearch, Specifier: "golang"},
+			wantWarn: []string{"takes no specifier"},
+		},
+		{
+			name:     "grep windows path",
+			perm:     ToolPermission{Tool: ToolGrep, Specifier: `src\pkg`},
+			wantWarn: []string{"forward slashes"},
+		},
+		{name: "empty tool", perm: ToolPermission{Specifier: "x"}, wantWarn: []string{"tool name is empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := ValidateToolPermission(tt.perm)
+			require.Len(t, warnings, len(tt.wantWarn))
+			for i, want := range tt.wantWarn {
+				assert.Contains(t, warnings[i], want)
+			}
+		})
+	}
+}
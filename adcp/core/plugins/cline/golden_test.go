@@ -0,0 +1,11 @@
+package cline
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/plugintest"
+)
+
+func TestGolden(t *testing.T) {
+	plugintest.Run(t, NewIDEProvider(), "testdata/golden")
+}
@@ -0,0 +1,70 @@
+// Package cline materializes recipes for Cline: rules as Markdown files in .clinerules, commands as
+// workflows in .clinerules/workflows and MCP servers in Cline's cline_mcp_settings.json format.
+package cline
+
+import (
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultMCPConfigPath is where MCP servers are written by default. Cline itself reads
+// cline_mcp_settings.json from the extension's global storage, e.g.
+// ~/.config/Code/User/globalStorage/saoudrizwan.claude-dev/settings on Linux; use
+// WithMCPConfigPath and persist relative to that directory's parent to install servers there.
+const DefaultMCPConfigPath = ".cline/cline_mcp_settings.json"
+
+// Option configures the Cline IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithMCPConfigPath writes MCP servers to path instead of DefaultMCPConfigPath.
+func WithMCPConfigPath(path string) Option {
+	return shared.WithMCPPath(path)
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into the MCP config.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// NewIDEProvider returns a provider for Cline. Workflows have no frontmatter, so command metadata
+// is ignored.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".clinerules/workflows"),
+		shared.WithMCPPath(DefaultMCPConfigPath),
+		shared.WithMCPServerFunc(mcpServer),
+		shared.WithContextPath(shared.DestinationRules, ".clinerules"),
+		shared.WithContextPath(shared.DestinationMemory, ".clinerules/project.md"),
+		shared.WithContextPath(shared.DestinationDocs, ".cline/docs"),
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// mcpServerConfig is a server in Cline's format. Remote servers have the type streamableHttp, and
+// every server carries the disabled and autoApprove fields Cline writes itself.
+type mcpServerConfig struct {
+	Type        string            `json:"type,omitempty"`
+	Command     string            `json:"command,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Disabled    bool              `json:"disabled"`
+	AutoApprove []string          `json:"autoApprove"`
+}
+
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) any {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return mcpServerConfig{Type: "streamableHttp", URL: url, Headers: opts.Headers, AutoApprove: []string{}}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return mcpServerConfig{Command: command, Args: args, Env: env, AutoApprove: []string{}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cline
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Mcp(t *testing.T) {
+	g := NewIDEProvider(
+		WithMCPConfigPath("settings/cline_mcp_settings.json"),
+		WithMCPServerOptions(map[string]shared.MCPServerOptions{
+			"github":  {Headers: map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}},
+			"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+		}),
+	)
+	fsys := fstest.MapFS{"settings/cline_mcp_settings.json": {Data: []byte(`{"mcpServers": {"existing": {"command": "node", "disabled": true, "autoApprove": ["read"]}}}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "settings/cline_mcp_settings.json", res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{"mcpServers": {
+		"existing": {"command": "node", "disabled": true, "autoApprove": ["read"]},
+		"devplan": {"command": "devplan", "args": ["mcp"], "env": {"DEVPLAN_API_KEY": "key"}, "disabled": false, "autoApprove": []},
+		"github": {"type": "streamableHttp", "url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${env:GITHUB_TOKEN}"}, "disabled": false, "autoApprove": []}
+	}}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*shared.IDE)
+
+	got, err := g.MapContextPath("@rules/style.md")
+	require.NoError(t, err)
+	assert.Equal(t, ".clinerules/style.md", got)
+
+	got, err = g.MapContextPath("@memory")
+	require.NoError(t, err)
+	assert.Equal(t, ".clinerules/project.md", got)
+}
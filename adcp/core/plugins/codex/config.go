@@ -0,0 +1,110 @@
+package codex
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// mcpServersKey is the table of config.toml holding MCP servers.
+const mcpServersKey = "mcp_servers"
+
+// materializeConfig merges the servers of mcp into the configuration at ConfigPath. Other settings
+// are kept, but comments and formatting of the existing file are not.
+func (i *IDE) materializeConfig(ctx context.Context, mcp *adcp.Mcp) (*adcp.MaterializedResult_Entry, error) {
+	doc := map[string]any{}
+	if existing := i.readConfig(ctx); existing != "" {
+		if _, err := toml.Decode(existing, &doc); err != nil {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningInvalidExistingFile,
+				Path:    i.ConfigPath,
+				Message: "Existing file is not valid TOML; its content is discarded and the file is replaced",
+			})
+			doc = map[string]any{}
+		}
+	}
+	serverOptions, err := shared.ExpandServerOptions(ctx, i.MCPServerOptions)
+	if err != nil {
+		return nil, err
+	}
+	servers, _ := doc[mcpServersKey].(map[string]any)
+	if servers == nil {
+		servers = map[string]any{}
+	}
+	for _, name := range slices.Sorted(maps.Keys(mcp.GetServers())) {
+		s := mcp.GetServers()[name]
+		if s == nil || !s.HasType() {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningIgnoredMCPServer,
+				Path:    i.ConfigPath,
+				Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+			})
+			continue
+		}
+		if srv := mcpServer(s, serverOptions[name]); srv != nil {
+			servers[name] = srv
+		}
+	}
+	doc[mcpServersKey] = servers
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal codex config: %w", err)
+	}
+	if _, ok := core.HomeRelative(i.ConfigPath); ok {
+		return shared.UserFileEntry(i.ConfigPath, buf.String()), nil
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.ConfigPath, Content: buf.String()}.Build(),
+	}.Build(), nil
+}
+
+// readConfig returns the existing configuration at ConfigPath, or "" if there is none. A
+// home-relative ConfigPath is read from ConfigFS or the user root rather than from the project.
+func (i *IDE) readConfig(ctx context.Context) string {
+	if _, ok := core.HomeRelative(i.ConfigPath); ok {
+		return shared.ReadExistingUserFile(ctx, i.ConfigFS, i.ConfigPath)
+	}
+	return shared.ReadExistingFile(ctx, i.ConfigPath)
+}
+
+// mcpServer converts s into a server table of config.toml.
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) map[string]any {
+	srv := map[string]any{}
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if s.GetHttp().GetUrl() == "" {
+			return nil
+		}
+		srv["url"] = s.GetHttp().GetUrl()
+		if len(opts.Headers) > 0 {
+			srv["http_headers"] = opts.Headers
+		}
+		// Codex reads the token from the variable itself.
+		if opts.BearerTokenEnv != "" && opts.Headers["Authorization"] == "" {
+			srv["bearer_token_env_var"] = opts.BearerTokenEnv
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command == "" {
+			return nil
+		}
+		srv["command"] = command
+		if len(args) > 0 {
+			srv["args"] = args
+		}
+		if len(env) > 0 {
+			srv["env"] = env
+		}
+	}
+	return srv
+}
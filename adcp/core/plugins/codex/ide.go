@@ -0,0 +1,190 @@
+// Package codex materializes recipes for the OpenAI Codex CLI: context and guidance for commands
+// in AGENTS.md, and MCP servers merged into Codex's TOML configuration.
+package codex
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+const agentsPath = "AGENTS.md"
+
+// DefaultConfigPath is where MCP servers are written by default: Codex reads them from
+// ~/.codex/config.toml only. The path is a user-level file (see core.HomePrefix), merged with the
+// user's existing configuration while the rest of the recipe is materialized into the project. It
+// is only written when the caller enables user-level files with core.WithUserRoot; otherwise MCP
+// servers are skipped with a warning.
+const DefaultConfigPath = core.HomePrefix + ".codex/config.toml"
+
+// IDE materializes recipes for Codex. Context is handled by the embedded shared.IDE; commands and
+// MCP servers are rendered by IDE itself.
+type IDE struct {
+	*shared.IDE
+	// ConfigPath is the Codex configuration file MCP servers are merged into.
+	ConfigPath string
+	// ConfigFS is the home directory an existing configuration at a home-relative ConfigPath is
+	// read from, independently of the project files read for AGENTS.md. Defaults to the root set
+	// by core.WithUserRoot.
+	ConfigFS fs.FS
+	// AgentInstructions are written into the managed section of AGENTS.md, before the commands.
+	AgentInstructions string
+}
+
+// Option configures the Codex IDE provider.
+type Option func(ide *IDE)
+
+// WithConfigPath merges MCP servers into path instead of DefaultConfigPath, e.g.
+// ".codex/config.toml" when materializing into the home directory.
+func WithConfigPath(path string) Option {
+	return func(ide *IDE) {
+		ide.ConfigPath = path
+	}
+}
+
+// WithConfigFS reads the existing configuration at a home-relative ConfigPath from fsys instead
+// of the user root, e.g. an in-memory snapshot in tests.
+func WithConfigFS(fsys fs.FS) Option {
+	return func(ide *IDE) {
+		ide.ConfigFS = fsys
+	}
+}
+
+// WithAgentInstructions adds instructions to the managed section of AGENTS.md.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *IDE) {
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio), headers and bearer tokens (HTTP) to servers written into the
+// configuration.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithSharedOptions applies options of package shared to AGENTS.md and the context files, e.g.
+// shared.WithContextPath.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for Codex. Codex has no project-level commands, so commands
+// are described in the managed section of AGENTS.md, where the agent picks them up by name.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithAgentsMDPath(agentsPath),
+			shared.WithManagedMemory(true),
+			shared.WithContextPath(shared.DestinationRules, ".codex/rules"),
+			shared.WithContextPath(shared.DestinationMemory, agentsPath),
+			shared.WithContextPath(shared.DestinationDocs, ".codex/docs"),
+		),
+		ConfigPath: DefaultConfigPath,
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds the Codex configuration to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	paths := i.IDE.ManagedPaths()
+	if i.ConfigPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: i.ConfigPath, Merge: core.MergeTOML})
+	}
+	return paths
+}
+
+// Capabilities reports that Codex supports commands (as guidance), MCP servers, memory files,
+// rules and docs.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	return recipes.Capabilities{
+		MCPTransports: []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP},
+		Commands:      true,
+		MemoryFiles:   i.ContextPaths[shared.DestinationMemory] != "",
+		Rules:         i.ContextPaths[shared.DestinationRules] != "",
+		Docs:          i.ContextPaths[shared.DestinationDocs] != "",
+	}
+}
+
+// OverridePaths returns a copy of the IDE with the paths of the embedded shared.IDE overridden,
+// except for shared.PathMCP, which replaces ConfigPath. i is never changed.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	rest := maps.Clone(overrides)
+	if p, ok := rest[shared.PathMCP]; ok {
+		if p == "" {
+			return nil, fmt.Errorf("%w: empty path for %s", shared.ErrUnsupportedPathOverride, shared.PathMCP)
+		}
+		c.ConfigPath = p
+		delete(rest, shared.PathMCP)
+	}
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, rest)
+}
+
+// Materialize produces the managed section of AGENTS.md with the instructions and commands, and
+// merges MCP servers into ConfigPath.
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	guidance, err := i.commandsGuidance(ctx, ide.GetCommands())
+	if err != nil {
+		return nil, err
+	}
+	agents := *i.IDE
+	agents.AgentInstructions = strings.TrimSpace(strings.Join([]string{i.AgentInstructions, guidance}, "\n\n"))
+	res, err := agents.Materialize(ctx, adcp.Ide_builder{Permissions: ide.GetPermissions()}.Build())
+	if err != nil {
+		return nil, err
+	}
+	entries := res.GetEntries()
+
+	if ide.HasMcp() && i.configEnabled(ctx) {
+		entry, err := i.materializeConfig(ctx, ide.GetMcp())
+		if err != nil {
+			return nil, err
+		}
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: i.ConfigPath, Merge: core.MergeTOML})
+		entries = append(entries, entry)
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// configEnabled reports whether ConfigPath is set and, if it is a user-level file, whether such
+// files can be written.
+func (i *IDE) configEnabled(ctx context.Context) bool {
+	if i.ConfigPath == "" {
+		return false
+	}
+	if _, ok := core.HomeRelative(i.ConfigPath); ok {
+		return shared.UserFilesEnabled(ctx, i.ConfigPath, recipes.FeatureMCP, "MCP servers")
+	}
+	return true
+}
+
+// commandsGuidance renders commands as a Markdown section telling the agent how to run them.
+func (i *IDE) commandsGuidance(ctx context.Context, commands *adcp.Commands) (string, error) {
+	names, err := i.ResolveCommandNames(commands)
+	if err != nil || len(names) == 0 {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("## Commands\n\nWhen asked to run one of the following commands by name, follow its instructions.\n")
+	for idx, c := range commands.GetEntries() {
+		body, err := shared.FetchCommandContent(ctx, c.GetFrom())
+		if err != nil {
+			return "", fmt.Errorf("failed to materialize command %s: %w", names[idx], err)
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", names[idx], strings.TrimRight(body, "\n"))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,175 @@
+package codex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/BurntSushi/toml"
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Config(t *testing.T) {
+	g := NewIDEProvider(
+		WithConfigPath(".codex/config.toml"),
+		WithMCPServerOptions(map[string]shared.MCPServerOptions{
+			"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+			"github":  {Headers: map[string]string{"Authorization": "Bearer token"}},
+			"linear":  {BearerTokenEnv: "LINEAR_TOKEN"},
+		}),
+	)
+	existing := "model = \"o3\"\n\n[mcp_servers.existing]\ncommand = \"tool\"\n"
+	fsys := fstest.MapFS{".codex/config.toml": {Data: []byte(existing)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"linear":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://mcp.linear.app/mcp"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp --stdio"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".codex/config.toml", res.GetEntries()[0].GetFile().GetPath())
+
+	var parsed struct {
+		Model      string `toml:"model"`
+		MCPServers map[string]struct {
+			Command     string            `toml:"command"`
+			Args        []string          `toml:"args"`
+			Env         map[string]string `toml:"env"`
+			URL         string            `toml:"url"`
+			HTTPHeaders map[string]string `toml:"http_headers"`
+			BearerEnv   string            `toml:"bearer_token_env_var"`
+		} `toml:"mcp_servers"`
+	}
+	_, err = toml.Decode(res.GetEntries()[0].GetFile().GetContent(), &parsed)
+	require.NoError(t, err)
+	assert.Equal(t, "o3", parsed.Model)
+	assert.Equal(t, "tool", parsed.MCPServers["existing"].Command)
+	assert.Equal(t, "devplan", parsed.MCPServers["devplan"].Command)
+	assert.Equal(t, []string{"mcp", "--stdio"}, parsed.MCPServers["devplan"].Args)
+	assert.Equal(t, map[string]string{"DEVPLAN_API_KEY": "key"}, parsed.MCPServers["devplan"].Env)
+	assert.Equal(t, "https://api.githubcopilot.com/mcp/", parsed.MCPServers["github"].URL)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token"}, parsed.MCPServers["github"].HTTPHeaders)
+	assert.Equal(t, "LINEAR_TOKEN", parsed.MCPServers["linear"].BearerEnv)
+}
+
+func TestIDE_Materialize_InvalidConfig(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithUserRoot(core.WithReport(context.Background(), report), t.TempDir())
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	home := fstest.MapFS{".codex/config.toml": {Data: []byte("not = [toml")}}
+	res, err := NewIDEProvider(WithConfigFS(home)).Materialize(ctx, ide)
+	require.NoError(t, err)
+	assert.Equal(t, "[mcp_servers]\n[mcp_servers.devplan]\ncommand = \"devplan\"\n", res.GetEntries()[0].GetFile().GetContent())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningInvalidExistingFile, report.Data().Warnings[0].Code)
+}
+
+func TestIDE_Materialize_HomeConfig(t *testing.T) {
+	project := fstest.MapFS{
+		"AGENTS.md":          {Data: []byte("# Project\n\nHand-written notes.\n")},
+		".codex/config.toml": {Data: []byte("model = \"project\"\n")},
+	}
+	home := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".codex"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codex", "config.toml"), []byte("model = \"o3\"\n"), 0o644))
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.")}.Build()}.Build(),
+		}}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	ctx := core.WithUserRoot(core.WithFS(context.Background(), project), home)
+	res, err := NewIDEProvider().Materialize(ctx, ide)
+	require.NoError(t, err)
+	files := map[string]*adcp.FullFileContent{}
+	for _, e := range res.GetEntries() {
+		files[e.GetFile().GetPath()] = e.GetFile()
+	}
+	require.Len(t, files, 2)
+	assert.True(t, strings.HasPrefix(files["AGENTS.md"].GetContent(), "# Project\n\nHand-written notes.\n"), "project AGENTS.md is kept")
+	assert.Contains(t, files["AGENTS.md"].GetContent(), "### review\n")
+	assert.Equal(t, "model = \"o3\"\n\n[mcp_servers]\n[mcp_servers.devplan]\ncommand = \"devplan\"\n", files["~/.codex/config.toml"].GetContent())
+	assert.True(t, core.IsUserFile(files["~/.codex/config.toml"]))
+
+	root := t.TempDir()
+	require.NoError(t, core.PersistMaterializedResult(ctx, root, res))
+	assert.FileExists(t, filepath.Join(root, "AGENTS.md"))
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "config.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[mcp_servers.devplan]")
+
+	// Without user-level files, MCP servers are skipped with a warning.
+	report := core.NewReport()
+	res, err = NewIDEProvider().Materialize(core.WithReport(core.WithFS(context.Background(), project), report), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "AGENTS.md", res.GetEntries()[0].GetFile().GetPath())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningSkippedUserFile, report.Data().Warnings[0].Code)
+	assert.Equal(t, "mcp", report.Data().Warnings[0].Feature)
+}
+
+func TestIDE_Materialize_AgentsMD(t *testing.T) {
+	g := NewIDEProvider(WithAgentInstructions("Run `make test` before committing."))
+	fsys := fstest.MapFS{"AGENTS.md": {Data: []byte("# Project\n")}}
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+			adcp.Command_builder{Name: "status", From: adcp.CommandFrom_builder{Text: strPtr("Summarize the status.")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "AGENTS.md", res.GetEntries()[0].GetFile().GetPath())
+	assert.Equal(t, "# Project\n\n<!-- adcp:begin -->\nRun `make test` before committing.\n\n"+
+		"## Commands\n\nWhen asked to run one of the following commands by name, follow its instructions.\n\n"+
+		"### review\n\nReview the diff.\n\n### status\n\nSummarize the status.\n<!-- adcp:end -->\n",
+		res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_InvalidCommandName(t *testing.T) {
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "Review PR", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := NewIDEProvider().Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "### Review PR\n", "names are preserved by default")
+
+	_, err = NewIDEProvider(WithSharedOptions(shared.WithCommandNamePolicy(shared.CommandNamePolicyError))).Materialize(context.Background(), ide)
+	require.Error(t, err)
+
+	res, err = NewIDEProvider(WithSharedOptions(shared.WithCommandNamePolicy(shared.CommandNamePolicySlugify))).Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "### review-pr\n")
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,242 @@
+// Package continuedev materializes recipes for Continue: MCP servers and prompts merged into
+// .continue/config.yaml and rules as Markdown files in .continue/rules, which Continue loads
+// alongside the configuration. It is selected with the IDE type "continue".
+package continuedev
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultConfigPath is the workspace configuration prompts and MCP servers are merged into.
+const DefaultConfigPath = ".continue/config.yaml"
+
+// DefaultConfigName names configurations created from scratch. Existing names are kept.
+const DefaultConfigName = "adcp"
+
+// IDE materializes recipes for Continue. Context is handled by the embedded shared.IDE; commands
+// and MCP servers are merged into ConfigPath by IDE itself.
+type IDE struct {
+	*shared.IDE
+	// ConfigPath is the Continue configuration file prompts and MCP servers are merged into.
+	// Comments and entries not generated from the recipe are kept.
+	ConfigPath string
+	// ConfigName is the name of a configuration created from scratch.
+	ConfigName string
+}
+
+// Option configures the Continue IDE provider.
+type Option func(ide *IDE)
+
+// WithConfigPath merges prompts and MCP servers into path instead of DefaultConfigPath, e.g.
+// ".continue/config.yaml" when materializing into the home directory.
+func WithConfigPath(path string) Option {
+	return func(ide *IDE) {
+		ide.ConfigPath = path
+	}
+}
+
+// WithConfigName names configurations created from scratch name instead of DefaultConfigName.
+func WithConfigName(name string) Option {
+	return func(ide *IDE) {
+		ide.ConfigName = name
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into the configuration.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithCommandMetadata sets per-command metadata. Prompts support the description field.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithSharedOptions applies options of package shared to the context files, e.g.
+// shared.WithManagedMemory.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for Continue.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithContextPath(shared.DestinationRules, ".continue/rules"),
+			shared.WithContextPath(shared.DestinationMemory, ".continue/rules/project.md"),
+			shared.WithContextPath(shared.DestinationDocs, ".continue/docs"),
+		),
+		ConfigPath: DefaultConfigPath,
+		ConfigName: DefaultConfigName,
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds the Continue configuration to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	paths := i.IDE.ManagedPaths()
+	if i.ConfigPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: i.ConfigPath, Merge: core.MergeYAML})
+	}
+	return paths
+}
+
+// Capabilities reports that Continue supports commands (as prompts), MCP servers and memory files.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	caps := i.IDE.Capabilities()
+	if i.ConfigPath != "" {
+		caps.Commands = true
+		caps.MCPTransports = []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}
+	}
+	return caps
+}
+
+// OverridePaths returns a copy of the IDE with context paths overridden. Prompts and MCP servers
+// follow ConfigPath; set it with WithConfigPath.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// Materialize merges commands as prompts and MCP servers into ConfigPath and produces the files of
+// the embedded shared.IDE for everything else.
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	res, err := i.IDE.Materialize(ctx, adcp.Ide_builder{Permissions: ide.GetPermissions()}.Build())
+	if err != nil {
+		return nil, err
+	}
+	entries := res.GetEntries()
+
+	if (ide.HasCommands() || ide.HasMcp()) && i.ConfigPath != "" {
+		entry, err := i.materializeConfig(ctx, ide)
+		if err != nil {
+			return nil, err
+		}
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: i.ConfigPath, Merge: core.MergeYAML})
+		entries = append(entries, entry)
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// prompt is an entry of the prompts list of config.yaml.
+type prompt struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Prompt      string `yaml:"prompt"`
+}
+
+// mcpServer is an entry of the mcpServers list of config.yaml.
+type mcpServer struct {
+	Name           string            `yaml:"name"`
+	Type           string            `yaml:"type,omitempty"`
+	Command        string            `yaml:"command,omitempty"`
+	Args           []string          `yaml:"args,omitempty"`
+	Env            map[string]string `yaml:"env,omitempty"`
+	URL            string            `yaml:"url,omitempty"`
+	RequestOptions *requestOptions   `yaml:"requestOptions,omitempty"`
+}
+
+type requestOptions struct {
+	Headers map[string]string `yaml:"headers"`
+}
+
+// materializeConfig merges prompts and MCP servers into the configuration at ConfigPath, replacing
+// entries of the same name.
+func (i *IDE) materializeConfig(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult_Entry, error) {
+	doc, ok := merge.ParseYAMLDocument(shared.ReadExistingFile(ctx, i.ConfigPath))
+	if !ok {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningInvalidExistingFile,
+			Path:    i.ConfigPath,
+			Message: "Existing file is not a valid YAML mapping; its content is discarded and the file is replaced",
+		})
+	}
+	// Continue requires these fields; existing values are kept.
+	for _, kv := range [][2]string{{"name", i.ConfigName}, {"version", "0.0.1"}, {"schema", "v1"}} {
+		if err := doc.SetDefault(kv[0], kv[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	names, err := i.ResolveCommandNames(ide.GetCommands())
+	if err != nil {
+		return nil, err
+	}
+	for idx, c := range ide.GetCommands().GetEntries() {
+		body, err := shared.FetchCommandContent(ctx, c.GetFrom())
+		if err != nil {
+			return nil, fmt.Errorf("failed to materialize command %s: %w", names[idx], err)
+		}
+		p := prompt{Name: names[idx], Description: i.CommandMetadata[c.GetName()].Description, Prompt: body}
+		if err := doc.UpsertNamed("prompts", "name", p.Name, p); err != nil {
+			return nil, fmt.Errorf("failed to set prompt %s: %w", p.Name, err)
+		}
+	}
+
+	serverOptions, err := shared.ExpandServerOptions(ctx, i.MCPServerOptions)
+	if err != nil {
+		return nil, err
+	}
+	servers := ide.GetMcp().GetServers()
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		srv := newMCPServer(name, servers[name], serverOptions[name])
+		if srv == nil {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningIgnoredMCPServer,
+				Path:    i.ConfigPath,
+				Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+			})
+			continue
+		}
+		if err := doc.UpsertNamed("mcpServers", "name", name, srv); err != nil {
+			return nil, fmt.Errorf("failed to set mcp server %s: %w", name, err)
+		}
+	}
+
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal continue config: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.ConfigPath, Content: content}.Build(),
+	}.Build(), nil
+}
+
+// newMCPServer converts s into an mcpServers entry, or returns nil if s has no usable transport.
+func newMCPServer(name string, s *adcp.McpServer, opts shared.MCPServerOptions) *mcpServer {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		url := s.GetHttp().GetUrl()
+		if url == "" {
+			return nil
+		}
+		srv := &mcpServer{Name: name, Type: "streamable-http", URL: url}
+		if len(opts.Headers) > 0 {
+			srv.RequestOptions = &requestOptions{Headers: opts.Headers}
+		}
+		return srv
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command == "" {
+			return nil
+		}
+		return &mcpServer{Name: name, Command: command, Args: args, Env: env}
+	}
+	return nil
+}
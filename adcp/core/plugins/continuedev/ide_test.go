@@ -0,0 +1,104 @@
+package continuedev
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Config(t *testing.T) {
+	g := NewIDEProvider(
+		WithCommandMetadata(map[string]shared.CommandMetadata{"review": {Description: "Review the diff"}}),
+		WithMCPServerOptions(map[string]shared.MCPServerOptions{
+			"github":  {Headers: map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}},
+			"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+		}),
+	)
+	fsys := fstest.MapFS{DefaultConfigPath: {Data: []byte(`name: Team # shared
+version: 1.0.0
+schema: v1
+models:
+  - name: Local
+    provider: ollama
+mcpServers:
+  - name: devplan
+    command: old
+`)}}
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\nBe brief.\n")}.Build()}.Build(),
+		}}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, DefaultConfigPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.Equal(t, `name: Team # shared
+version: 1.0.0
+schema: v1
+models:
+  - name: Local
+    provider: ollama
+mcpServers:
+  - name: devplan
+    command: devplan
+    args:
+      - mcp
+    env:
+      DEVPLAN_API_KEY: key
+  - name: github
+    type: streamable-http
+    url: https://api.githubcopilot.com/mcp/
+    requestOptions:
+      headers:
+        Authorization: Bearer ${env:GITHUB_TOKEN}
+prompts:
+  - name: review
+    description: Review the diff
+    prompt: |
+      Review the diff.
+      Be brief.
+`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_InvalidConfig(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithReport(core.WithFS(context.Background(), fstest.MapFS{DefaultConfigPath: {Data: []byte("- not a mapping\n")}}), report)
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := NewIDEProvider(WithConfigName("backend")).Materialize(ctx, ide)
+	require.NoError(t, err)
+	assert.Equal(t, "name: backend\nversion: 0.0.1\nschema: v1\nmcpServers:\n  - name: devplan\n    command: devplan\n",
+		res.GetEntries()[0].GetFile().GetContent())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningInvalidExistingFile, report.Data().Warnings[0].Code)
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*IDE)
+
+	got, err := g.MapContextPath("@rules/style.md")
+	require.NoError(t, err)
+	assert.Equal(t, ".continue/rules/style.md", got)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
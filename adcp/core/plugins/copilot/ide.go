@@ -0,0 +1,53 @@
+// Package copilot materializes recipes for GitHub Copilot in VS Code: repository instructions in
+// .github/copilot-instructions.md, commands as prompt files in .github/prompts and MCP servers in
+// .vscode/mcp.json.
+package copilot
+
+import (
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+)
+
+const instructionsPath = ".github/copilot-instructions.md"
+
+// Option configures the Copilot IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithInstructions generates a managed section with the given instructions in
+// .github/copilot-instructions.md, keeping the rest of the file intact.
+func WithInstructions(instructions string) Option {
+	return func(ide *shared.IDE) {
+		ide.AgentsMDPath = instructionsPath
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into .vscode/mcp.json.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// WithCommandMetadata sets per-command metadata. Prompt files support the description and model
+// frontmatter fields; commands with an argument hint get an ${input:arguments} placeholder.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return shared.WithCommandMetadata(meta)
+}
+
+// NewIDEProvider returns a provider for GitHub Copilot. VS Code reads MCP servers from the
+// "servers" key of .vscode/mcp.json.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".github/prompts"),
+		shared.WithCommandExtension(".prompt.md"),
+		shared.WithMCPPath(".vscode/mcp.json"),
+		shared.WithMCPServersKey("servers"),
+		shared.WithCommandFrontmatterFields(shared.FieldDescription, shared.FieldModel),
+		shared.WithCommandArgumentsPlaceholder("${input:arguments}"),
+		shared.WithAgentsMDPath(instructionsPath),
+		shared.WithManagedMemory(true),
+		shared.WithContextPath(shared.DestinationRules, ".github/instructions"),
+		shared.WithContextPath(shared.DestinationMemory, instructionsPath),
+		shared.WithContextPath(shared.DestinationDocs, ".github/docs"),
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
@@ -0,0 +1,106 @@
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Mcp(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "${env:DEVPLAN_API_KEY}"}},
+	}))
+	fsys := fstest.MapFS{".vscode/mcp.json": {Data: []byte(`{"inputs": [], "servers": {"existing": {"type": "http", "url": "https://example.com"}}}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".vscode/mcp.json", res.GetEntries()[0].GetFile().GetPath())
+
+	var parsed struct {
+		Inputs  []any `json:"inputs"`
+		Servers map[string]struct {
+			Type    string            `json:"type"`
+			Command string            `json:"command"`
+			Args    []string          `json:"args"`
+			Env     map[string]string `json:"env"`
+		} `json:"servers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(res.GetEntries()[0].GetFile().GetContent()), &parsed))
+	assert.NotNil(t, parsed.Inputs)
+	assert.Equal(t, "http", parsed.Servers["existing"].Type)
+	assert.Equal(t, "stdio", parsed.Servers["devplan"].Type)
+	assert.Equal(t, "devplan", parsed.Servers["devplan"].Command)
+	assert.Equal(t, []string{"mcp"}, parsed.Servers["devplan"].Args)
+	assert.Equal(t, map[string]string{"DEVPLAN_API_KEY": "${env:DEVPLAN_API_KEY}"}, parsed.Servers["devplan"].Env)
+}
+
+func TestIDE_Materialize_PromptFiles(t *testing.T) {
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{
+		"review": {Description: "Review the current diff", ArgumentHint: "[focus]", AllowedTools: []string{"Bash(git diff:*)"}},
+	}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "---\ndescription: Review the current diff\n---\n\nReview the diff.\n\n${input:arguments}\n", m[".github/prompts/review.prompt.md"])
+}
+
+func TestIDE_Materialize_Instructions(t *testing.T) {
+	g := NewIDEProvider(WithInstructions("Run `make test` before committing."))
+	fsys := fstest.MapFS{".github/copilot-instructions.md": {Data: []byte("# Team notes\n")}}
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".github/copilot-instructions.md", res.GetEntries()[0].GetFile().GetPath())
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "# Team notes\n")
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "<!-- adcp:begin -->\nRun `make test` before committing.\n<!-- adcp:end -->\n")
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*shared.IDE)
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "@memory", want: ".github/copilot-instructions.md"},
+		{in: "@rules/go.instructions.md", want: ".github/instructions/go.instructions.md"},
+		{in: "docs/guide.md", want: "docs/guide.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := g.MapContextPath(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
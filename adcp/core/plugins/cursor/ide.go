@@ -0,0 +1,157 @@
+// Package cursor materializes recipes for the Cursor IDE: rules as .cursor/rules/*.mdc files with
+// frontmatter, commands in .cursor/commands, MCP servers in .cursor/mcp.json and project guidance
+// in AGENTS.md. For the Cursor CLI, including its permissions, see package cursorcli.
+package cursor
+
+import (
+	"bufio"
+	"path"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/frontmatter"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+)
+
+const (
+	rulesDir = ".cursor/rules"
+	ruleExt  = ".mdc"
+)
+
+// RuleMetadata configures when Cursor applies a rule.
+type RuleMetadata struct {
+	// Description tells the agent when the rule is relevant. Defaults to the first heading of the rule.
+	Description string
+	// Globs attaches the rule to matching files, e.g. "src/**/*.ts".
+	Globs []string
+	// AlwaysApply includes the rule in every request.
+	AlwaysApply bool
+}
+
+// IDE materializes recipes for Cursor. Context entries written below .cursor/rules become .mdc
+// rules with frontmatter; everything else is handled by the embedded shared.IDE.
+type IDE struct {
+	*shared.IDE
+	// RuleMetadata holds optional per-rule frontmatter keyed by the rule's path below .cursor/rules
+	// without extension, e.g. "go/style" for "@rules/go/style.md". Rules without metadata are
+	// always applied.
+	RuleMetadata map[string]RuleMetadata
+}
+
+// Option configures the Cursor IDE provider.
+type Option func(ide *IDE)
+
+// WithRuleMetadata sets per-rule frontmatter, keyed like IDE.RuleMetadata.
+func WithRuleMetadata(meta map[string]RuleMetadata) Option {
+	return func(ide *IDE) {
+		ide.RuleMetadata = meta
+	}
+}
+
+// WithAgentInstructions generates a managed section with the given instructions in AGENTS.md.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *IDE) {
+		ide.AgentsMDPath = "AGENTS.md"
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into .cursor/mcp.json.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithCommandMetadata sets per-command metadata. Cursor supports the description and model
+// frontmatter fields; commands with an argument hint get an $ARGUMENTS placeholder.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithSharedOptions applies options of package shared, e.g. shared.WithCommandNamePolicy for
+// commands in .cursor/commands.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for the Cursor IDE. Permissions are materialized by the Cursor
+// CLI provider (see package cursorcli).
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithCommandsFolder(".cursor/commands"),
+			shared.WithMCPPath(".cursor/mcp.json"),
+			shared.WithEnvReference(shared.VSCodeEnvReference),
+			shared.WithCommandFrontmatterFields(shared.FieldDescription, shared.FieldModel),
+			shared.WithCommandArgumentsPlaceholder("$ARGUMENTS"),
+			shared.WithAgentsMDPath("AGENTS.md"),
+			shared.WithManagedMemory(true),
+			shared.WithContextPath(shared.DestinationRules, rulesDir),
+			shared.WithContextPath(shared.DestinationMemory, "AGENTS.md"),
+			shared.WithContextPath(shared.DestinationDocs, ".cursor/docs"),
+		),
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// OverridePaths returns a copy of the IDE with commands, MCP or context paths overridden. Rules
+// moved away from .cursor/rules keep the .mdc extension but get no frontmatter.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// MapContextPath resolves logical context paths like shared.IDE does, giving Markdown rules the
+// .mdc extension Cursor expects, e.g. "@rules/style.md" becomes ".cursor/rules/style.mdc".
+func (i *IDE) MapContextPath(p string) (string, error) {
+	mapped, err := i.IDE.MapContextPath(p)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(p, "@"+shared.DestinationRules+"/") && path.Ext(mapped) == ".md" {
+		mapped = strings.TrimSuffix(mapped, ".md") + ruleExt
+	}
+	return mapped, nil
+}
+
+// RenderContext adds frontmatter to rules below .cursor/rules. Fields already present in the
+// rule's own frontmatter are kept.
+func (i *IDE) RenderContext(p, content string) (string, error) {
+	name, ok := strings.CutPrefix(p, rulesDir+"/")
+	if !ok || path.Ext(name) != ruleExt {
+		return content, nil
+	}
+	existing, body, err := frontmatter.Parse(content)
+	if err != nil {
+		// Leave rules with frontmatter we cannot read as they are.
+		return content, nil
+	}
+	meta, ok := i.RuleMetadata[strings.TrimSuffix(name, ruleExt)]
+	if !ok {
+		meta.AlwaysApply = true
+	}
+	if meta.Description == "" {
+		meta.Description = firstHeading(body)
+	}
+	fm := frontmatter.New().
+		Set("description", meta.Description).
+		Set("globs", strings.Join(meta.Globs, ",")).
+		Set("alwaysApply", meta.AlwaysApply).
+		Merge(existing)
+	return fm.Render(body), nil
+}
+
+// firstHeading returns the text of the first Markdown heading in body, or "".
+func firstHeading(body string) string {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package cursor
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_MapContextPath(t *testing.T) {
+	ide := NewIDEProvider().(*IDE)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"@rules/style.md", ".cursor/rules/style.mdc"},
+		{"@rules/go/testing.md", ".cursor/rules/go/testing.mdc"},
+		{"@rules/legacy.mdc", ".cursor/rules/legacy.mdc"},
+		{"@memory", "AGENTS.md"},
+		{"@docs/api.md", ".cursor/docs/api.md"},
+		{"docs/plain.md", "docs/plain.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ide.MapContextPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIDE_RenderContext(t *testing.T) {
+	ide := NewIDEProvider(WithRuleMetadata(map[string]RuleMetadata{
+		"go/testing": {Globs: []string{"**/*_test.go", "testdata/**"}},
+		"review":     {Description: "Apply when reviewing changes"},
+	})).(*IDE)
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name:    "always applied by default",
+			path:    ".cursor/rules/style.mdc",
+			content: "# Go style\n\nUse gofmt.\n",
+			want:    "---\ndescription: Go style\nalwaysApply: true\n---\n\n# Go style\n\nUse gofmt.\n",
+		},
+		{
+			name:    "globs from metadata",
+			path:    ".cursor/rules/go/testing.mdc",
+			content: "Use testify.\n",
+			want:    "---\nglobs: \"**/*_test.go,testdata/**\"\nalwaysApply: false\n---\n\nUse testify.\n",
+		},
+		{
+			name:    "description from metadata",
+			path:    ".cursor/rules/review.mdc",
+			content: "# Review\n\nCheck tests.\n",
+			want:    "---\ndescription: Apply when reviewing changes\nalwaysApply: false\n---\n\n# Review\n\nCheck tests.\n",
+		},
+		{
+			name:    "existing frontmatter wins",
+			path:    ".cursor/rules/style.mdc",
+			content: "---\nalwaysApply: false\nglobs: \"*.go\"\n---\n# Go style\n",
+			want:    "---\ndescription: Go style\nalwaysApply: false\nglobs: \"*.go\"\n---\n\n# Go style\n",
+		},
+		{
+			name:    "not a rule",
+			path:    "AGENTS.md",
+			content: "# Project\n",
+			want:    "# Project\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ide.RenderContext(tt.path, tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRecipe_Materialize_Rules(t *testing.T) {
+	r := recipes.New(NewIDEProvider(), recipes.WithFS(fstest.MapFS{"AGENTS.md": {Data: []byte("Hand-written.\n")}}))
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "@rules/style.md", From: adcp.ContextFrom_builder{Text: strPtr("# Style\n\nUse gofmt.\n")}.Build()}.Build(),
+			adcp.ContextEntry_builder{Path: "@memory", From: adcp.ContextFrom_builder{Text: strPtr("# Project\n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, map[string]string{
+		".cursor/rules/style.mdc": "---\ndescription: Style\nalwaysApply: true\n---\n\n# Style\n\nUse gofmt.\n",
+		"AGENTS.md":               "Hand-written.\n\n" + shared.ManagedMemoryBegin + "\n# Project\n" + shared.ManagedMemoryEnd + "\n",
+	}, m)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
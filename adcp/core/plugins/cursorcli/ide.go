@@ -2,24 +2,296 @@ package cursorcli
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
 	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
-func NewIDEProvider() recipes.IDEProvider {
-	return &shared.IDE{
-		CommandsFolder:     ".cursor/commands",
-		MCPServersJSONPath: ".cursor/mcp.json",
-		Settings:           &settings{},
+const (
+	cliConfigPath      = ".cursor/cli.json"
+	ignorePath         = ".cursorignore"
+	indexingIgnorePath = ".cursorindexingignore"
+)
+
+// IDE materializes recipes for the Cursor CLI. Commands, MCP servers, context and permissions are
+// handled by the embedded shared.IDE; the ignore files are written by IDE itself, so they do not
+// depend on the embedded IDE's Settings.
+type IDE struct {
+	*shared.IDE
+	// IgnorePatterns are gitignore-style patterns written into the managed block of .cursorignore.
+	IgnorePatterns []string
+	// IgnoreDeniedReads adds the paths of read-deny permissions to IgnorePatterns.
+	IgnoreDeniedReads bool
+	// IndexingIgnorePatterns are written into the managed block of .cursorindexingignore.
+	IndexingIgnorePatterns []string
+}
+
+// Option configures the Cursor CLI IDE provider.
+type Option func(ide *IDE)
+
+// WithAgentInstructions generates a managed section with the given instructions in AGENTS.md,
+// which Cursor reads as project-wide agent guidance.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *IDE) {
+		ide.AgentsMDPath = "AGENTS.md"
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into .cursor/mcp.json.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithIgnorePatterns adds gitignore-style patterns to the managed block of .cursorignore,
+// hiding matching files from Cursor entirely.
+func WithIgnorePatterns(patterns ...string) Option {
+	return func(ide *IDE) {
+		ide.IgnorePatterns = append(ide.IgnorePatterns, patterns...)
+	}
+}
+
+// WithIgnoreDeniedReads adds the paths of read-deny permissions to the managed block of
+// .cursorignore, hiding them from Cursor entirely rather than only from the CLI agent.
+func WithIgnoreDeniedReads() Option {
+	return func(ide *IDE) {
+		ide.IgnoreDeniedReads = true
+	}
+}
+
+// WithIndexingIgnorePatterns adds gitignore-style patterns to the managed block of .cursorindexingignore,
+// excluding matching files from indexing while keeping them accessible to the agent.
+func WithIndexingIgnorePatterns(patterns ...string) Option {
+	return func(ide *IDE) {
+		ide.IndexingIgnorePatterns = append(ide.IndexingIgnorePatterns, patterns...)
 	}
 }
 
+// WithCommandMetadata sets per-command metadata. Cursor supports the description and model
+// frontmatter fields; commands with an argument hint get an $ARGUMENTS placeholder.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithSharedOptions applies options of package shared, e.g. shared.WithSettings to translate
+// permissions differently; the ignore files are written regardless.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for the Cursor CLI.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithCommandsFolder(".cursor/commands"),
+			shared.WithMCPPath(".cursor/mcp.json"),
+			shared.WithEnvReference(shared.VSCodeEnvReference),
+			shared.WithSettings(&settings{}),
+			shared.WithCommandFrontmatterFields(shared.FieldDescription, shared.FieldModel),
+			shared.WithCommandArgumentsPlaceholder("$ARGUMENTS"),
+			shared.WithContextPath(shared.DestinationRules, ".cursor/rules"),
+			shared.WithContextPath(shared.DestinationMemory, "AGENTS.md"),
+			shared.WithAgentsMDPath("AGENTS.md"),
+			shared.WithManagedMemory(true),
+			shared.WithContextPath(shared.DestinationDocs, ".cursor/docs"),
+		),
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds the ignore files to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	return append(i.IDE.ManagedPaths(),
+		recipes.ManagedPath{Pattern: ignorePath, Merge: core.MergeManagedBlock},
+		recipes.ManagedPath{Pattern: indexingIgnorePath, Merge: core.MergeManagedBlock},
+	)
+}
+
+// OverridePaths returns a copy of the IDE with commands, MCP or context paths overridden. The CLI
+// configuration and the ignore files stay at the project root, where Cursor reads them.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// Materialize produces the files of the embedded shared.IDE and merges IgnorePatterns and
+// IndexingIgnorePatterns into the ignore files.
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	res, err := i.IDE.Materialize(ctx, ide)
+	if err != nil {
+		return nil, err
+	}
+	if i.FS != nil {
+		ctx = core.WithFS(ctx, i.FS)
+	}
+	entries := res.GetEntries()
+	ignore := i.IgnorePatterns
+	if i.IgnoreDeniedReads {
+		for _, p := range ide.GetPermissions().GetDeny() {
+			if p.HasRead() && p.GetRead() != "" {
+				ignore = append(ignore, p.GetRead())
+			}
+		}
+	}
+	if e := materializeIgnoreFile(ctx, ignorePath, ignore); e != nil {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: ignorePath, Merge: core.MergeManagedBlock})
+		entries = append(entries, e)
+	}
+	if e := materializeIgnoreFile(ctx, indexingIgnorePath, i.IndexingIgnorePatterns); e != nil {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: indexingIgnorePath, Merge: core.MergeManagedBlock})
+		entries = append(entries, e)
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// settings translates recipe permissions into the CLI configuration.
 type settings struct {
 	shared.IDESettings
 }
 
-func (s *settings) Update(_ context.Context, _ shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
-	return nil, nil
+// ManagedPaths reports the CLI configuration.
+func (s *settings) ManagedPaths() []recipes.ManagedPath {
+	return []recipes.ManagedPath{{Pattern: cliConfigPath, Merge: core.MergeJSON}}
+}
+
+// SupportsPermissions reports that Cursor CLI settings translate recipe permissions.
+func (s *settings) SupportsPermissions() bool {
+	return true
+}
+
+func (s *settings) Update(ctx context.Context, input shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
+	entries, err := materializePermissions(ctx, input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: e.GetFile().GetPath(), Merge: core.MergeJSON})
+	}
+	return entries, nil
+}
+
+// materializeIgnoreFile merges patterns into the managed block of the ignore file at path. Without
+// patterns, a managed block left by a previous run is removed; files without one are left alone.
+func materializeIgnoreFile(ctx context.Context, path string, patterns []string) *adcp.MaterializedResult_Entry {
+	patterns = merge.UniqueStrings(nil, patterns)
+	existing := shared.ReadExistingFile(ctx, path)
+	var content string
+	if len(patterns) == 0 {
+		if _, ok := shared.ManagedSection(existing, shared.ManagedLinesBegin, shared.ManagedLinesEnd); !ok {
+			return nil
+		}
+		content = shared.RemoveManagedBlockWithMarkers(existing, shared.ManagedLinesBegin, shared.ManagedLinesEnd)
+	} else {
+		content = shared.MergeManagedBlockWithMarkers(existing, strings.Join(patterns, "\n"),
+			shared.ManagedLinesBegin, shared.ManagedLinesEnd)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: path, Content: content}.Build(),
+	}.Build()
+}
+
+func materializePermissions(ctx context.Context, perms *adcp.Permissions) ([]*adcp.MaterializedResult_Entry, error) {
+	if len(perms.GetAllow()) == 0 && len(perms.GetDeny()) == 0 {
+		return nil, nil
+	}
+
+	for _, p := range append(perms.GetAllow(), perms.GetDeny()...) {
+		formatted := formatPermission(p)
+		switch {
+		case formatted == "":
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningDroppedPermission,
+				Path:    cliConfigPath,
+				Message: "Permission without a supported kind (bash, read or write) is ignored",
+			})
+		case strings.HasSuffix(formatted, "()"):
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningSuspiciousPermission,
+				Path:    cliConfigPath,
+				Message: fmt.Sprintf("%s: pattern is empty and will never match", formatted),
+			})
+		}
+	}
+	existingContent := shared.ReadExistingJSON(ctx, cliConfigPath)
+
+	content, err := buildCLIConfigJSON(perms, existingContent)
+	if err != nil {
+		return nil, err
+	}
+	return []*adcp.MaterializedResult_Entry{
+		adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: cliConfigPath, Content: content}.Build(),
+		}.Build(),
+	}, nil
+}
+
+func buildCLIConfigJSON(perms *adcp.Permissions, existingContent string) (string, error) {
+	// Parse existing content if provided. If parsing fails, start fresh.
+	doc, _ := merge.ParseDocument(existingContent)
+
+	newAllow := make([]string, 0)
+	for _, p := range perms.GetAllow() {
+		if formatted := formatPermission(p); formatted != "" {
+			newAllow = append(newAllow, formatted)
+		}
+	}
+	newDeny := make([]string, 0)
+	for _, p := range perms.GetDeny() {
+		if formatted := formatPermission(p); formatted != "" {
+			newDeny = append(newDeny, formatted)
+		}
+	}
+
+	// Merge with existing permissions (deduplicate). Like for Claude, lists without new entries
+	// are left alone rather than created empty.
+	permissions := merge.NewObject()
+	if len(newAllow) > 0 {
+		if err := permissions.Set("allow", newAllow); err != nil {
+			return "", err
+		}
+	}
+	if len(newDeny) > 0 {
+		if err := permissions.Set("deny", newDeny); err != nil {
+			return "", err
+		}
+	}
+	patch := merge.NewObject()
+	if err := patch.Set("permissions", permissions); err != nil {
+		return "", err
+	}
+	if err := merge.Deep(doc.Object, patch, nil); err != nil {
+		return "", fmt.Errorf("failed to merge cursor cli config json: %w", err)
+	}
+	out, err := doc.String()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor cli config json: %w", err)
+	}
+	return out, nil
+}
+
+// formatPermission converts a permission into Cursor CLI syntax.
+// Claude-style Bash prefix matchers ("git commit:*") become Shell rules on the command prefix ("Shell(git commit)").
+func formatPermission(p *adcp.OperationPermission) string {
+	if !p.HasType() {
+		return ""
+	}
+	switch p.WhichType() {
+	case adcp.OperationPermission_Bash_case:
+		return fmt.Sprintf("Shell(%s)", strings.TrimSuffix(p.GetBash(), ":*"))
+	case adcp.OperationPermission_Read_case:
+		return fmt.Sprintf("Read(%s)", p.GetRead())
+	case adcp.OperationPermission_Write_case:
+		return fmt.Sprintf("Write(%s)", p.GetWrite())
+	default:
+		return ""
+	}
 }
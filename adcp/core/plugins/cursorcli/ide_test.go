@@ -0,0 +1,259 @@
+package cursorcli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Permissions(t *testing.T) {
+	g := NewIDEProvider()
+
+	ide := adcp.Ide_builder{
+		Permissions: adcp.Permissions_builder{
+			Allow: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build(),
+				adcp.OperationPermission_builder{Read: strPtr("src/**")}.Build(),
+			},
+			Deny: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Write: strPtr("**/secrets/**")}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	var content string
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == ".cursor/cli.json" {
+			content = e.GetFile().GetContent()
+		}
+	}
+	require.NotEmpty(t, content)
+
+	var parsed struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+			Deny  []string `json:"deny"`
+		} `json:"permissions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(content), &parsed))
+	assert.Equal(t, []string{"Shell(go test)", "Read(src/**)"}, parsed.Permissions.Allow)
+	assert.Equal(t, []string{"Write(**/secrets/**)"}, parsed.Permissions.Deny)
+}
+
+func TestIDE_Materialize_NoPermissions(t *testing.T) {
+	g := NewIDEProvider()
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	assert.Empty(t, res.GetEntries())
+}
+
+func TestBuildCLIConfigJSON_MergeWithExisting(t *testing.T) {
+	existing := `{"permissions": {"allow": ["Shell(ls)", "Shell(go test)"], "deny": []}}`
+	perms := adcp.Permissions_builder{
+		Allow: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build()},
+	}.Build()
+
+	out, err := buildCLIConfigJSON(perms, existing)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+			Deny  []string `json:"deny"`
+		} `json:"permissions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &parsed))
+	assert.Equal(t, []string{"Shell(ls)", "Shell(go test)"}, parsed.Permissions.Allow)
+	assert.Empty(t, parsed.Permissions.Deny)
+}
+
+func TestIDE_Materialize_DenyOnlyPermissions(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithReport(context.Background(), report)
+	res, err := NewIDEProvider().Materialize(ctx, adcp.Ide_builder{
+		Permissions: adcp.Permissions_builder{
+			Deny: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Read: strPtr(".env")}.Build(),
+				adcp.OperationPermission_builder{Bash: strPtr("")}.Build(),
+			},
+		}.Build(),
+	}.Build())
+	require.NoError(t, err)
+
+	var content string
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == ".cursor/cli.json" {
+			content = e.GetFile().GetContent()
+		}
+	}
+	assert.JSONEq(t, `{"permissions": {"deny": ["Read(.env)", "Shell()"]}}`, content)
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningSuspiciousPermission, report.Data().Warnings[0].Code)
+	assert.Contains(t, report.Data().Warnings[0].Message, "Shell()")
+}
+
+func TestIDE_Materialize_AgentInstructions(t *testing.T) {
+	g := NewIDEProvider(WithAgentInstructions("Run `make test` before committing."))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "AGENTS.md", res.GetEntries()[0].GetFile().GetPath())
+	assert.Equal(t, "<!-- adcp:begin -->\nRun `make test` before committing.\n<!-- adcp:end -->\n", res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestRecipe_Materialize_MemoryKeepsAgentsMD(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte("# Project\n\nHand-written.\n"), 0o644))
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "@memory", From: adcp.ContextFrom_builder{Text: strPtr("Use tabs.")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{}.Build(),
+	}.Build()
+	r := recipes.New(NewIDEProvider(WithAgentInstructions("Run `make test` before committing.")), recipes.WithFS(os.DirFS(root)))
+
+	for range 2 {
+		res, err := r.Materialize(context.Background(), recipe)
+		require.NoError(t, err)
+		require.NoError(t, core.PersistMaterializedResult(context.Background(), root, res))
+
+		data, err := os.ReadFile(filepath.Join(root, "AGENTS.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Project\n\nHand-written.\n\n"+
+			"<!-- adcp:memory:begin -->\nUse tabs.\n<!-- adcp:memory:end -->\n\n"+
+			"<!-- adcp:begin -->\nRun `make test` before committing.\n<!-- adcp:end -->\n", string(data))
+	}
+}
+
+func TestIDE_Materialize_McpEnvAndHeaders(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"github":  {Headers: map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}},
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "${env:DEVPLAN_API_KEY}"}},
+	}))
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	var content string
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == ".cursor/mcp.json" {
+			content = e.GetFile().GetContent()
+		}
+	}
+	require.NotEmpty(t, content)
+
+	var parsed struct {
+		McpServers map[string]struct {
+			Env     map[string]string `json:"env"`
+			Headers map[string]string `json:"headers"`
+		} `json:"mcpServers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(content), &parsed))
+	assert.Equal(t, map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}, parsed.McpServers["github"].Headers)
+	assert.Equal(t, map[string]string{"DEVPLAN_API_KEY": "${env:DEVPLAN_API_KEY}"}, parsed.McpServers["devplan"].Env)
+}
+
+func TestIDE_Materialize_IgnoreFiles(t *testing.T) {
+	ide := adcp.Ide_builder{
+		Permissions: adcp.Permissions_builder{
+			Deny: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Read: strPtr(".env")}.Build(),
+			},
+		}.Build(),
+	}.Build()
+	materialize := func(t *testing.T, ctx context.Context, opts ...Option) map[string]string {
+		res, err := NewIDEProvider(opts...).Materialize(ctx, ide)
+		require.NoError(t, err)
+		m := map[string]string{}
+		for _, e := range res.GetEntries() {
+			m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+		}
+		return m
+	}
+
+	m := materialize(t, context.Background(), WithIgnorePatterns("dist/", ".adcp/"), WithIndexingIgnorePatterns("vendor/"))
+	assert.Equal(t, "# adcp:begin\ndist/\n.adcp/\n# adcp:end\n", m[".cursorignore"], "read-deny paths are not ignored by default")
+	assert.Equal(t, "# adcp:begin\nvendor/\n# adcp:end\n", m[".cursorindexingignore"])
+
+	m = materialize(t, context.Background(), WithIgnorePatterns("dist/"), WithIgnoreDeniedReads())
+	assert.Equal(t, "# adcp:begin\ndist/\n.env\n# adcp:end\n", m[".cursorignore"])
+
+	// The ignore files do not depend on the settings translating permissions.
+	m = materialize(t, context.Background(), WithSharedOptions(shared.WithSettings(nil)), WithIgnorePatterns("dist/"))
+	assert.Equal(t, "# adcp:begin\ndist/\n# adcp:end\n", m[".cursorignore"])
+	assert.NotContains(t, m, ".cursor/cli.json")
+
+	// Without patterns, the block of a previous run is removed and hand-written patterns are kept.
+	ctx := core.WithFS(context.Background(), fstest.MapFS{
+		".cursorignore":         {Data: []byte("node_modules/\n\n# adcp:begin\ndist/\n# adcp:end\n")},
+		".cursorindexingignore": {Data: []byte("vendor/\n")},
+	})
+	m = materialize(t, ctx)
+	assert.Equal(t, "node_modules/\n", m[".cursorignore"])
+	assert.NotContains(t, m, ".cursorindexingignore", "files without a managed block are left alone")
+}
+
+func TestIDE_Materialize_CommandMetadata(t *testing.T) {
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{
+		"review": {
+			Description:  "Review the current diff",
+			ArgumentHint: "[focus]",
+			AllowedTools: []string{"Bash(git diff:*)"},
+			Model:        "gpt-5",
+		},
+	}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "---\ndescription: Review the current diff\nmodel: gpt-5\n---\n\nReview the diff.\n\n$ARGUMENTS\n", m[".cursor/commands/review.md"])
+}
+
+func TestIDE_Materialize_CommandEmbeddedFrontmatter(t *testing.T) {
+	body := "---\ndescription: Review the current diff\nargument-hint: \"[focus]\"\nallowed-tools:\n  - Bash(git diff:*)\n---\n\nReview the diff.\n"
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: &body}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := NewIDEProvider().Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ndescription: Review the current diff\n---\n\nReview the diff.\n\n$ARGUMENTS\n", res.GetEntries()[0].GetFile().GetContent())
+}
+
+func strPtr(s string) *string {
+	return &s
+}
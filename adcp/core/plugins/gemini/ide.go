@@ -0,0 +1,102 @@
+// Package gemini materializes recipes for Gemini CLI: context in GEMINI.md, commands as TOML files
+// in .gemini/commands and MCP servers in .gemini/settings.json.
+package gemini
+
+import (
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+const (
+	memoryPath   = "GEMINI.md"
+	settingsPath = ".gemini/settings.json"
+)
+
+// commandTemplate renders commands in Gemini CLI's TOML format.
+var commandTemplate = shared.MustParseCommandTemplate("gemini", `{{ with .Metadata.Description }}description = {{ tomlString . }}
+{{ end }}prompt = {{ tomlMultiline .Body }}
+`)
+
+// Option configures the Gemini CLI IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithInstructions generates a managed section with the given instructions in GEMINI.md.
+func WithInstructions(instructions string) Option {
+	return func(ide *shared.IDE) {
+		ide.AgentsMDPath = memoryPath
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio), headers, bearer tokens and OAuth client settings (HTTP) to
+// servers written into .gemini/settings.json.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// WithCommandMetadata sets per-command metadata. Gemini CLI commands support a description;
+// commands with an argument hint get an {{args}} placeholder.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return shared.WithCommandMetadata(meta)
+}
+
+// NewIDEProvider returns a provider for Gemini CLI.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".gemini/commands"),
+		shared.WithCommandExtension(".toml"),
+		shared.WithCommandTemplate(commandTemplate),
+		shared.WithCommandArgumentsPlaceholder("{{args}}"),
+		shared.WithMCPPath(settingsPath),
+		shared.WithMCPServerFunc(mcpServer),
+		shared.WithEnvReference(shared.EnvReference),
+		shared.WithAgentsMDPath(memoryPath),
+		shared.WithManagedMemory(true),
+		shared.WithContextPath(shared.DestinationRules, ".gemini/rules"),
+		shared.WithContextPath(shared.DestinationMemory, memoryPath),
+		shared.WithContextPath(shared.DestinationDocs, ".gemini/docs"),
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// mcpServerConfig is a server in Gemini CLI's format, which has no type and names the URL of
+// streamable HTTP servers httpUrl.
+type mcpServerConfig struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	HTTPURL string            `json:"httpUrl,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	OAuth   *oauthConfig      `json:"oauth,omitempty"`
+}
+
+// oauthConfig holds the OAuth client settings of an HTTP server.
+type oauthConfig struct {
+	Enabled      bool     `json:"enabled"`
+	ClientID     string   `json:"clientId,omitempty"`
+	ClientSecret string   `json:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) any {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			srv := mcpServerConfig{HTTPURL: url, Headers: opts.Headers}
+			if o := opts.OAuth; o != nil {
+				srv.OAuth = &oauthConfig{Enabled: true, ClientID: o.ClientID, Scopes: o.Scopes}
+				if o.ClientSecretEnv != "" {
+					srv.OAuth.ClientSecret = shared.EnvReference(o.ClientSecretEnv)
+				}
+			}
+			return srv
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return mcpServerConfig{Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Settings(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"github": {Headers: map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}"}},
+	}))
+	fsys := fstest.MapFS{".gemini/settings.json": {Data: []byte(`{"theme": "GitHub", "mcpServers": {"existing": {"command": "tool"}}}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".gemini/settings.json", res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{"theme": "GitHub", "mcpServers": {
+		"existing": {"command": "tool"},
+		"devplan": {"command": "devplan", "args": ["mcp"]},
+		"github": {"httpUrl": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${GITHUB_TOKEN}"}}
+	}}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_MCPAuth(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"github": {BearerTokenEnv: "GITHUB_TOKEN"},
+		"acme": {OAuth: &shared.MCPOAuth{
+			ClientID:        "adcp",
+			ClientSecretEnv: "ACME_CLIENT_SECRET",
+			Scopes:          []string{"read", "write"},
+		}},
+	}))
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github": adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"acme":   adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://mcp.acme.dev"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fstest.MapFS{}), ide)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"mcpServers": {
+		"acme": {"httpUrl": "https://mcp.acme.dev", "oauth": {
+			"enabled": true, "clientId": "adcp", "clientSecret": "${ACME_CLIENT_SECRET}", "scopes": ["read", "write"]
+		}},
+		"github": {"httpUrl": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${GITHUB_TOKEN}"}}
+	}}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_Commands(t *testing.T) {
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{
+		"review": {Description: `Review the "current" diff`, ArgumentHint: "[focus]"},
+	}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+			adcp.Command_builder{Name: "status", From: adcp.CommandFrom_builder{Text: strPtr(`Run "git status".`)}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "description = \"Review the \\\"current\\\" diff\"\nprompt = \"\"\"\nReview the diff.\n\n{{args}}\n\"\"\"\n", m[".gemini/commands/review.toml"])
+	assert.Equal(t, "prompt = \"\"\"\nRun \"git status\".\"\"\"\n", m[".gemini/commands/status.toml"])
+}
+
+func TestIDE_Materialize_Instructions(t *testing.T) {
+	g := NewIDEProvider(WithInstructions("Use the Makefile targets."))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "GEMINI.md", res.GetEntries()[0].GetFile().GetPath())
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "Use the Makefile targets.")
+}
+
+func strPtr(s string) *string {
+	return &s
+}
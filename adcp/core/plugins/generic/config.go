@@ -0,0 +1,89 @@
+package generic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is returned when a Config cannot describe an IDE.
+var ErrInvalidConfig = errors.New("invalid generic IDE config")
+
+// Config describes where an IDE reads commands, MCP servers, context and permissions. Empty paths
+// disable the corresponding feature.
+type Config struct {
+	// CommandsFolder receives one file per command, e.g. ".acme/commands".
+	CommandsFolder string `yaml:"commandsFolder"`
+	// CommandExtension is the extension of command files. Defaults to ".md".
+	CommandExtension string `yaml:"commandExtension"`
+	// AgentsFolder receives one file per subagent (see shared.WithAgents), e.g. ".acme/agents".
+	AgentsFolder string `yaml:"agentsFolder"`
+	// SkillsFolder receives one directory per skill (see shared.WithSkills), e.g. ".acme/skills".
+	SkillsFolder string `yaml:"skillsFolder"`
+	// MCPPath is the JSON file MCP servers are merged into, e.g. ".acme/mcp.json".
+	MCPPath string `yaml:"mcpPath"`
+	// MCPServersKey is the top-level key holding servers in MCPPath. Defaults to "mcpServers".
+	MCPServersKey string `yaml:"mcpServersKey"`
+	// MemoryPath, RulesPath and DocsPath are the locations of the @memory, @rules and @docs context
+	// destinations.
+	MemoryPath string `yaml:"memoryPath"`
+	RulesPath  string `yaml:"rulesPath"`
+	DocsPath   string `yaml:"docsPath"`
+	// Settings describes how permissions are written. Without it, permissions are not materialized.
+	Settings *SettingsConfig `yaml:"settings"`
+}
+
+// SettingsConfig describes a JSON settings file holding permissions as lists of strings.
+type SettingsConfig struct {
+	// Path is the settings file, e.g. ".acme/settings.json".
+	Path string `yaml:"path"`
+	// AllowKey and DenyKey are the dot-separated keys of the allow and deny lists. They default to
+	// "permissions.allow" and "permissions.deny".
+	AllowKey string `yaml:"allowKey"`
+	DenyKey  string `yaml:"denyKey"`
+	// BashFormat, ReadFormat and WriteFormat turn the pattern of a permission into a list entry,
+	// with "%s" standing for the pattern, e.g. "Bash(%s)". Permissions of a kind without a format
+	// are dropped with a warning.
+	BashFormat  string `yaml:"bashFormat"`
+	ReadFormat  string `yaml:"readFormat"`
+	WriteFormat string `yaml:"writeFormat"`
+}
+
+// ParseConfig parses a Config from YAML or JSON. Unknown fields are rejected.
+func ParseConfig(data []byte) (Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate reports settings that cannot be materialized.
+func (c Config) Validate() error {
+	s := c.Settings
+	if s == nil {
+		return nil
+	}
+	if s.Path == "" {
+		return fmt.Errorf("%w: settings.path is required", ErrInvalidConfig)
+	}
+	for _, f := range []struct{ name, format string }{
+		{"bashFormat", s.BashFormat},
+		{"readFormat", s.ReadFormat},
+		{"writeFormat", s.WriteFormat},
+	} {
+		if f.format != "" && strings.Count(f.format, "%s") != 1 {
+			return fmt.Errorf("%w: settings.%s must contain %%s exactly once", ErrInvalidConfig, f.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package generic
+
+import (
+	"os"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/plugintest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGolden(t *testing.T) {
+	data, err := os.ReadFile("testdata/acme.yaml")
+	require.NoError(t, err)
+	cfg, err := ParseConfig(data)
+	require.NoError(t, err)
+	plugintest.Run(t, NewIDEProvider(cfg), "testdata/golden")
+}
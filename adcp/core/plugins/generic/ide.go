@@ -0,0 +1,147 @@
+// Package generic materializes recipes for IDEs described by data rather than code: a Config names
+// the commands folder, MCP file, context locations and permission formats, so that niche tools can
+// be targeted without a dedicated plugin.
+package generic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Option configures the generic provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// NewIDEProvider returns a provider for the IDE described by cfg, which should be valid (see
+// Config.Validate).
+func NewIDEProvider(cfg Config, opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(cfg.CommandsFolder),
+		shared.WithCommandExtension(cfg.CommandExtension),
+		shared.WithAgentsFolder(cfg.AgentsFolder),
+		shared.WithSkillsFolder(cfg.SkillsFolder),
+		shared.WithMCPPath(cfg.MCPPath),
+		shared.WithMCPServersKey(cfg.MCPServersKey),
+	}
+	for _, c := range []struct{ destination, path string }{
+		{shared.DestinationMemory, cfg.MemoryPath},
+		{shared.DestinationRules, cfg.RulesPath},
+		{shared.DestinationDocs, cfg.DocsPath},
+	} {
+		if c.path != "" {
+			defaults = append(defaults, shared.WithContextPath(c.destination, c.path))
+		}
+	}
+	if cfg.Settings != nil {
+		defaults = append(defaults, shared.WithSettings(&settings{config: *cfg.Settings}))
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// settings merges permissions into the allow and deny lists of a JSON settings file.
+type settings struct {
+	config SettingsConfig
+}
+
+// ManagedPaths reports the settings file.
+func (s *settings) ManagedPaths() []recipes.ManagedPath {
+	return []recipes.ManagedPath{{Pattern: s.config.Path, Merge: core.MergeJSON}}
+}
+
+// SupportsPermissions reports that permissions are written into the settings file.
+func (s *settings) SupportsPermissions() bool {
+	return true
+}
+
+func (s *settings) Update(ctx context.Context, input shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
+	perms := input.Permissions
+	allow := s.format(ctx, perms.GetAllow())
+	deny := s.format(ctx, perms.GetDeny())
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	doc, _ := merge.ParseDocument(shared.ReadExistingJSON(ctx, s.config.Path))
+	patch := merge.NewObject()
+	if err := setPath(patch, keyOrDefault(s.config.AllowKey, "permissions.allow"), allow); err != nil {
+		return nil, err
+	}
+	if err := setPath(patch, keyOrDefault(s.config.DenyKey, "permissions.deny"), deny); err != nil {
+		return nil, err
+	}
+	// Lists are merged with the existing ones, keeping entries added by users.
+	if err := merge.Deep(doc.Object, patch, nil); err != nil {
+		return nil, fmt.Errorf("failed to merge settings json: %w", err)
+	}
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings json: %w", err)
+	}
+	core.RecordPlannedChange(ctx, core.PlannedChange{Path: s.config.Path, Merge: core.MergeJSON})
+	return []*adcp.MaterializedResult_Entry{
+		adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: s.config.Path, Content: content}.Build(),
+		}.Build(),
+	}, nil
+}
+
+// format converts perms into list entries, warning about kinds without a format.
+func (s *settings) format(ctx context.Context, perms []*adcp.OperationPermission) []string {
+	var entries []string
+	for _, p := range perms {
+		var format, pattern string
+		switch p.WhichType() {
+		case adcp.OperationPermission_Bash_case:
+			format, pattern = s.config.BashFormat, p.GetBash()
+		case adcp.OperationPermission_Read_case:
+			format, pattern = s.config.ReadFormat, p.GetRead()
+		case adcp.OperationPermission_Write_case:
+			format, pattern = s.config.WriteFormat, p.GetWrite()
+		}
+		if format == "" {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningDroppedPermission,
+				Path:    s.config.Path,
+				Message: fmt.Sprintf("Permission of kind %q has no format in the IDE config and is ignored", p.WhichType()),
+			})
+			continue
+		}
+		entries = append(entries, strings.Replace(format, "%s", pattern, 1))
+	}
+	return entries
+}
+
+// setPath sets values under the dot-separated key of o, creating intermediate objects. Empty values
+// are not set.
+func setPath(o *merge.Object, key string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	parts := strings.Split(key, ".")
+	objects := []*merge.Object{o}
+	for _, part := range parts[:len(parts)-1] {
+		objects = append(objects, objects[len(objects)-1].Nested(part))
+	}
+	if err := objects[len(objects)-1].Set(parts[len(parts)-1], values); err != nil {
+		return err
+	}
+	// Nested returns detached copies, so they are stored back from the innermost one.
+	for i := len(objects) - 1; i > 0; i-- {
+		if err := objects[i-1].Set(parts[i-1], objects[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func keyOrDefault(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
+}
@@ -0,0 +1,95 @@
+package generic
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Config
+		wantErr string
+	}{
+		{
+			name: "json",
+			data: `{"commandsFolder": ".acme/commands", "settings": {"path": ".acme/settings.json", "bashFormat": "Bash(%s)"}}`,
+			want: Config{
+				CommandsFolder: ".acme/commands",
+				Settings:       &SettingsConfig{Path: ".acme/settings.json", BashFormat: "Bash(%s)"},
+			},
+		},
+		{name: "empty", data: "", want: Config{}},
+		{name: "unknown field", data: "commandFolder: x\n", wantErr: "field commandFolder not found"},
+		{name: "settings without path", data: "settings: {bashFormat: 'Bash(%s)'}\n", wantErr: "settings.path is required"},
+		{name: "format without placeholder", data: "settings: {path: s.json, readFormat: Read}\n", wantErr: "settings.readFormat must contain %s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrInvalidConfig)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestIDE_Materialize_Settings(t *testing.T) {
+	g := NewIDEProvider(Config{Settings: &SettingsConfig{
+		Path:       "settings.json",
+		AllowKey:   "tools.allowed",
+		DenyKey:    "tools.blocked",
+		BashFormat: "run %s",
+	}})
+	fsys := fstest.MapFS{"settings.json": {Data: []byte(`{"theme": "dark", "tools": {"allowed": ["run make"]}}`)}}
+	report := core.NewReport()
+	ctx := core.WithReport(core.WithFS(context.Background(), fsys), report)
+
+	res, err := g.Materialize(ctx, adcp.Ide_builder{
+		Permissions: adcp.Permissions_builder{
+			Allow: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Bash: strPtr("go test ./...")}.Build(),
+				adcp.OperationPermission_builder{Read: strPtr("src/**")}.Build(),
+			},
+			Deny: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: strPtr("rm -rf")}.Build()},
+		}.Build(),
+	}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.JSONEq(t, `{
+		"theme": "dark",
+		"tools": {"allowed": ["run make", "run go test ./..."], "blocked": ["run rm -rf"]}
+	}`, res.GetEntries()[0].GetFile().GetContent())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningDroppedPermission, report.Data().Warnings[0].Code)
+}
+
+func TestIDE_Capabilities(t *testing.T) {
+	caps := NewIDEProvider(Config{}).(recipes.CapabilityProvider).Capabilities()
+	assert.Equal(t, recipes.Capabilities{}, caps)
+
+	caps = NewIDEProvider(Config{
+		CommandsFolder: ".acme/commands",
+		MCPPath:        ".acme/mcp.json",
+		MemoryPath:     "ACME.md",
+		Settings:       &SettingsConfig{Path: "s.json"},
+	}).(recipes.CapabilityProvider).Capabilities()
+	assert.True(t, caps.Commands)
+	assert.True(t, caps.MemoryFiles)
+	assert.True(t, caps.Permissions)
+	assert.NotEmpty(t, caps.MCPTransports)
+}
@@ -0,0 +1,238 @@
+// Package goose materializes recipes for Goose: hints in .goosehints, commands as Goose recipes in
+// .goose/recipes, MCP servers as extensions merged into Goose's config.yaml and denied paths in
+// .gooseignore.
+package goose
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+const (
+	// DefaultConfigPath is where extensions are merged by default. Goose reads
+	// ~/.config/goose/config.yaml; to install them there, read existing files from the home
+	// directory with WithSharedOptions(shared.WithFS(shared.HomeFS())) and persist relative to the
+	// home directory. Without the FS, the existing configuration would be overwritten.
+	DefaultConfigPath = ".config/goose/config.yaml"
+	// HintsPath is where memory context entries are written.
+	HintsPath = ".goosehints"
+	// ignorePath lists files Goose must not read or modify.
+	ignorePath = ".gooseignore"
+	// extensionTimeout is the timeout in seconds Goose itself uses for new extensions.
+	extensionTimeout = 300
+)
+
+// commandTemplate renders commands as Goose recipes, which Goose requires to have a description.
+var commandTemplate = shared.MustParseCommandTemplate("goose", `version: 1.0.0
+title: {{ yaml .Name }}
+description: {{ with .Metadata.Description }}{{ yaml . }}{{ else }}{{ yaml (printf "Run the %s command" .Name) }}{{ end }}
+prompt: {{ yamlLiteral .Body }}
+`)
+
+// IDE materializes recipes for Goose. Everything but its capabilities is handled by the embedded
+// shared.IDE, with extensions and permissions applied through its settings.
+type IDE struct {
+	*shared.IDE
+}
+
+// Option configures the Goose IDE provider.
+type Option func(ide *IDE)
+
+// WithConfigPath merges extensions into path instead of DefaultConfigPath.
+func WithConfigPath(path string) Option {
+	return func(ide *IDE) {
+		ide.Settings.(*settings).configPath = path
+	}
+}
+
+// WithMCPServerOptions adds envs (stdio) and headers (HTTP) to extensions.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithCommandMetadata sets per-command metadata. The description becomes the recipe's description.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithSharedOptions applies options of package shared, e.g. shared.WithFS(shared.HomeFS()) to merge
+// extensions into the existing configuration in the home directory.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for Goose.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{IDE: shared.NewIDE(
+		shared.WithCommandsFolder(".goose/recipes"),
+		shared.WithCommandExtension(".yaml"),
+		shared.WithCommandTemplate(commandTemplate),
+		shared.WithContextPath(shared.DestinationMemory, HintsPath),
+		shared.WithContextPath(shared.DestinationDocs, ".goose/docs"),
+		// The settings convert extensions with the options of the IDE they belong to.
+		func(ide *shared.IDE) {
+			ide.Settings = &settings{ide: ide, configPath: DefaultConfigPath}
+		},
+	)}
+	return shared.Configure(ide, opts...)
+}
+
+// Capabilities reports that Goose supports commands, MCP servers, permissions and memory files.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	caps := i.IDE.Capabilities()
+	if i.Settings.(*settings).configPath != "" {
+		caps.MCPTransports = []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}
+	}
+	return caps
+}
+
+// OverridePaths returns a copy of the IDE with the commands, hints or docs path overridden.
+// Extensions follow the configuration path; set it with WithConfigPath.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// settings merges MCP servers as extensions into configPath and turns denied read and write
+// permissions into .gooseignore entries.
+type settings struct {
+	ide        *shared.IDE
+	configPath string
+}
+
+// ManagedPaths reports the configuration, if any, and .gooseignore.
+func (s *settings) ManagedPaths() []recipes.ManagedPath {
+	var paths []recipes.ManagedPath
+	if s.configPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: s.configPath, Merge: core.MergeYAML})
+	}
+	return append(paths, recipes.ManagedPath{Pattern: ignorePath, Merge: core.MergeManagedBlock})
+}
+
+// SupportsPermissions reports that denied paths are translated; other permissions are dropped
+// with a warning, as Goose permissions apply to whole tools.
+func (s *settings) SupportsPermissions() bool {
+	return true
+}
+
+func (s *settings) Update(ctx context.Context, input shared.SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
+	var entries []*adcp.MaterializedResult_Entry
+	if input.Ide.HasMcp() && s.configPath != "" {
+		entry, err := s.materializeExtensions(ctx, input.Ide.GetMcp())
+		if err != nil {
+			return nil, err
+		}
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: s.configPath, Merge: core.MergeYAML})
+		entries = append(entries, entry)
+	}
+
+	var ignore []string
+	for _, p := range input.Permissions.GetDeny() {
+		switch p.WhichType() {
+		case adcp.OperationPermission_Read_case:
+			ignore = append(ignore, p.GetRead())
+			continue
+		case adcp.OperationPermission_Write_case:
+			ignore = append(ignore, p.GetWrite())
+			continue
+		}
+		dropPermission(ctx, p)
+	}
+	for _, p := range input.Permissions.GetAllow() {
+		dropPermission(ctx, p)
+	}
+	if ignore = merge.UniqueStrings(nil, ignore); len(ignore) > 0 {
+		content := shared.MergeManagedBlockWithMarkers(shared.ReadExistingFile(ctx, ignorePath), strings.Join(ignore, "\n"),
+			shared.ManagedLinesBegin, shared.ManagedLinesEnd)
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: ignorePath, Merge: core.MergeManagedBlock})
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: ignorePath, Content: content}.Build(),
+		}.Build())
+	}
+	return entries, nil
+}
+
+func dropPermission(ctx context.Context, p *adcp.OperationPermission) {
+	core.Warn(ctx, core.Warning{
+		Code:    core.WarningDroppedPermission,
+		Message: fmt.Sprintf("Permission %s %q is ignored: Goose only supports denying file access", p.WhichType(), p.GetBash()+p.GetRead()+p.GetWrite()),
+	})
+}
+
+// extension is an MCP server in Goose's extensions format.
+type extension struct {
+	Enabled bool              `yaml:"enabled"`
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`
+	Cmd     string            `yaml:"cmd,omitempty"`
+	Args    []string          `yaml:"args,omitempty"`
+	Envs    map[string]string `yaml:"envs,omitempty"`
+	URI     string            `yaml:"uri,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Timeout int               `yaml:"timeout"`
+}
+
+func (s *settings) materializeExtensions(ctx context.Context, mcp *adcp.Mcp) (*adcp.MaterializedResult_Entry, error) {
+	doc, ok := merge.ParseYAMLDocument(shared.ReadExistingFile(ctx, s.configPath))
+	if !ok {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningInvalidExistingFile,
+			Path:    s.configPath,
+			Message: "Existing file is not a valid YAML mapping; its content is discarded and the file is replaced",
+		})
+	}
+	serverOptions, err := shared.ExpandServerOptions(ctx, s.ide.MCPServerOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(mcp.GetServers())) {
+		ext := newExtension(name, mcp.GetServers()[name], serverOptions[name])
+		if ext == nil {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningIgnoredMCPServer,
+				Path:    s.configPath,
+				Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+			})
+			continue
+		}
+		if err := doc.SetIn(ext, "extensions", name); err != nil {
+			return nil, fmt.Errorf("failed to set extension %s: %w", name, err)
+		}
+	}
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal goose config: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: s.configPath, Content: content}.Build(),
+	}.Build(), nil
+}
+
+// newExtension converts s into an extension, or returns nil if s has no usable transport.
+func newExtension(name string, s *adcp.McpServer, opts shared.MCPServerOptions) *extension {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return &extension{Enabled: true, Name: name, Type: "streamable_http", URI: url, Headers: opts.Headers, Timeout: extensionTimeout}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return &extension{Enabled: true, Name: name, Type: "stdio", Cmd: command, Args: args, Envs: env, Timeout: extensionTimeout}
+		}
+	}
+	return nil
+}
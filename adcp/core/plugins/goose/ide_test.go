@@ -0,0 +1,105 @@
+package goose
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Extensions(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+	}))
+	fsys := fstest.MapFS{DefaultConfigPath: {Data: []byte(`GOOSE_PROVIDER: anthropic
+extensions:
+  developer: # built-in
+    enabled: true
+    name: developer
+    type: builtin
+`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, DefaultConfigPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.Equal(t, `GOOSE_PROVIDER: anthropic
+extensions:
+  developer: # built-in
+    enabled: true
+    name: developer
+    type: builtin
+  devplan:
+    enabled: true
+    name: devplan
+    type: stdio
+    cmd: devplan
+    args:
+      - mcp
+    envs:
+      DEVPLAN_API_KEY: key
+    timeout: 300
+  github:
+    enabled: true
+    name: github
+    type: streamable_http
+    uri: https://api.githubcopilot.com/mcp/
+    timeout: 300
+`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_CommandsAndPermissions(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithReport(context.Background(), report)
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{"review": {Description: "Review the diff"}}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n\nBe brief: no nits.\n")}.Build()}.Build(),
+			adcp.Command_builder{Name: "status", From: adcp.CommandFrom_builder{Text: strPtr("Summarize.")}.Build()}.Build(),
+		}}.Build(),
+		Permissions: adcp.Permissions_builder{
+			Allow: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: strPtr("make test")}.Build()},
+			Deny: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Read: strPtr(".env")}.Build(),
+				adcp.OperationPermission_builder{Write: strPtr("secrets/**")}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(ctx, ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "version: 1.0.0\ntitle: review\ndescription: Review the diff\nprompt: |\n  Review the diff.\n\n  Be brief: no nits.\n", m[".goose/recipes/review.yaml"])
+	assert.Equal(t, "version: 1.0.0\ntitle: status\ndescription: Run the status command\nprompt: |\n  Summarize.\n", m[".goose/recipes/status.yaml"])
+	assert.Contains(t, m[".gooseignore"], ".env\nsecrets/**\n")
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningDroppedPermission, report.Data().Warnings[0].Code)
+	assert.Contains(t, report.Data().Warnings[0].Message, `bash "make test"`)
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	got, err := NewIDEProvider().(*IDE).MapContextPath("@memory")
+	require.NoError(t, err)
+	assert.Equal(t, HintsPath, got)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
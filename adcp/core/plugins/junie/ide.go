@@ -0,0 +1,67 @@
+// Package junie materializes recipes for JetBrains IDEs: guidelines for Junie in
+// .junie/guidelines.md, project rules for AI Assistant in .aiassistant/rules and MCP servers in
+// Junie's project-level .junie/mcp/mcp.json.
+package junie
+
+import (
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// GuidelinesPath is where memory context entries are written. Junie reads it with every task.
+const GuidelinesPath = ".junie/guidelines.md"
+
+// DefaultMCPConfigPath is the project-level MCP configuration of Junie.
+const DefaultMCPConfigPath = ".junie/mcp/mcp.json"
+
+// Option configures the Junie IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithMCPConfigPath writes MCP servers to path instead of DefaultMCPConfigPath, e.g.
+// ".junie/mcp.json" when materializing the user-level configuration into the home directory.
+func WithMCPConfigPath(path string) Option {
+	return shared.WithMCPPath(path)
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into the MCP config.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// NewIDEProvider returns a provider for Junie and AI Assistant. Neither has file-based commands
+// or project-level permission settings, so those parts of a recipe are not materialized.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithMCPPath(DefaultMCPConfigPath),
+		shared.WithMCPServerFunc(mcpServer),
+		shared.WithContextPath(shared.DestinationRules, ".aiassistant/rules"),
+		shared.WithContextPath(shared.DestinationMemory, GuidelinesPath),
+		shared.WithContextPath(shared.DestinationDocs, ".junie/docs"),
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// mcpServerConfig is a server in Junie's format, which has no type field.
+type mcpServerConfig struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) any {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return mcpServerConfig{URL: url, Headers: opts.Headers}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return mcpServerConfig{Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
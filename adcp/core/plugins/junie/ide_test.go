@@ -0,0 +1,68 @@
+package junie
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Mcp(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+	}))
+	fsys := fstest.MapFS{DefaultMCPConfigPath: {Data: []byte(`{"mcpServers": {"existing": {"command": "node"}}}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, DefaultMCPConfigPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{"mcpServers": {
+		"existing": {"command": "node"},
+		"devplan": {"command": "devplan", "args": ["mcp"], "env": {"DEVPLAN_API_KEY": "key"}},
+		"github": {"url": "https://api.githubcopilot.com/mcp/"}
+	}}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestRecipe_Materialize_Guidelines(t *testing.T) {
+	report := core.NewReport()
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "@memory", From: adcp.ContextFrom_builder{Text: strPtr("Use Gradle.\n")}.Build()}.Build(),
+			adcp.ContextEntry_builder{Path: "@rules/kotlin.md", From: adcp.ContextFrom_builder{Text: strPtr("Prefer val.\n")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+			}}.Build(),
+		}.Build(),
+	}.Build()
+
+	res, err := recipes.New(NewIDEProvider()).Materialize(core.WithReport(context.Background(), report), recipe)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Equal(t, []string{GuidelinesPath, ".aiassistant/rules/kotlin.md"}, paths)
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningUnsupportedFeature, report.Data().Warnings[0].Code)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,146 @@
+package opencode
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+const (
+	actionAllow = "allow"
+	actionDeny  = "deny"
+)
+
+// mcpServer is an MCP server in opencode.json. Local servers take the command line as an array.
+type mcpServer struct {
+	Type        string            `json:"type"`
+	Command     []string          `json:"command,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Enabled     bool              `json:"enabled"`
+}
+
+// materializeConfig merges MCP servers and permissions into the configuration at ConfigPath.
+func (i *IDE) materializeConfig(ctx context.Context, mcp *adcp.Mcp, perms *adcp.Permissions) (*adcp.MaterializedResult_Entry, error) {
+	doc, _ := merge.ParseDocument(shared.ReadExistingJSON(ctx, i.ConfigPath))
+	if !doc.Has("$schema") {
+		if err := doc.Set("$schema", schemaURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if mcp != nil {
+		serverOptions, err := shared.ExpandServerOptions(ctx, i.MCPServerOptions)
+		if err != nil {
+			return nil, err
+		}
+		servers := doc.Nested("mcp")
+		for _, name := range slices.Sorted(maps.Keys(mcp.GetServers())) {
+			srv := newMCPServer(mcp.GetServers()[name], serverOptions[name])
+			if srv == nil {
+				core.Warn(ctx, core.Warning{
+					Code:    core.WarningIgnoredMCPServer,
+					Path:    i.ConfigPath,
+					Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+				})
+				continue
+			}
+			if err := servers.Set(name, srv); err != nil {
+				return nil, fmt.Errorf("failed to set mcp server %s: %w", name, err)
+			}
+		}
+		if err := doc.Set("mcp", servers); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(perms.GetAllow()) > 0 || len(perms.GetDeny()) > 0 {
+		permission := doc.Nested("permission")
+		if err := i.mergePermissions(ctx, permission, perms.GetAllow(), actionAllow); err != nil {
+			return nil, err
+		}
+		// Deny rules are merged last, so they win over allow rules for the same pattern.
+		if err := i.mergePermissions(ctx, permission, perms.GetDeny(), actionDeny); err != nil {
+			return nil, err
+		}
+		if err := doc.Set("permission", permission); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal opencode config: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.ConfigPath, Content: content}.Build(),
+	}.Build(), nil
+}
+
+// mergePermissions sets action for the pattern of each permission in the tool's pattern map, e.g.
+// {"bash": {"git commit *": "allow"}}. A tool configured with a single action, e.g. "bash": "ask",
+// keeps it as the fallback for all other patterns ("*").
+func (i *IDE) mergePermissions(ctx context.Context, permission *merge.Object, perms []*adcp.OperationPermission, action string) error {
+	for _, p := range perms {
+		tool, pattern := mapPermission(p)
+		if tool == "" {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningDroppedPermission,
+				Path:    i.ConfigPath,
+				Message: "Permission without a supported kind (bash, read or write) is ignored",
+			})
+			continue
+		}
+		patterns := permission.Nested(tool)
+		var fallback string
+		if permission.Get(tool, &fallback) && !patterns.Has("*") {
+			if err := patterns.Set("*", fallback); err != nil {
+				return err
+			}
+		}
+		if err := patterns.Set(pattern, action); err != nil {
+			return fmt.Errorf("failed to set %s permission %s: %w", tool, pattern, err)
+		}
+		if err := permission.Set(tool, patterns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapPermission returns the OpenCode tool and pattern of p, or "" if p has no supported kind.
+// Claude-style Bash prefix matchers ("git commit:*") become wildcard patterns ("git commit *").
+func mapPermission(p *adcp.OperationPermission) (tool, pattern string) {
+	switch p.WhichType() {
+	case adcp.OperationPermission_Bash_case:
+		return "bash", shared.BashPrefixToGlob(p.GetBash())
+	case adcp.OperationPermission_Read_case:
+		return "read", p.GetRead()
+	case adcp.OperationPermission_Write_case:
+		return "edit", p.GetWrite()
+	}
+	return "", ""
+}
+
+// newMCPServer converts s into an opencode.json server, or returns nil if s has no usable transport.
+func newMCPServer(s *adcp.McpServer, opts shared.MCPServerOptions) *mcpServer {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return &mcpServer{Type: "remote", URL: url, Headers: opts.Headers, Enabled: true}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return &mcpServer{Type: "local", Command: append([]string{command}, args...), Environment: env, Enabled: true}
+		}
+	}
+	return nil
+}
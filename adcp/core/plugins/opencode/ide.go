@@ -0,0 +1,151 @@
+// Package opencode materializes recipes for OpenCode: commands in .opencode/command, guidance in
+// AGENTS.md, and MCP servers and permissions merged into opencode.json.
+package opencode
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultConfigPath is the project-level OpenCode configuration.
+const DefaultConfigPath = "opencode.json"
+
+// schemaURL is set as $schema of configurations created from scratch.
+const schemaURL = "https://opencode.ai/config.json"
+
+// IDE materializes recipes for OpenCode. Commands and context are handled by the embedded
+// shared.IDE; MCP servers and permissions are merged into ConfigPath by IDE itself.
+type IDE struct {
+	*shared.IDE
+	// ConfigPath is the OpenCode configuration MCP servers and permissions are merged into.
+	// Settings not managed by adcp are kept.
+	ConfigPath string
+}
+
+// Option configures the OpenCode IDE provider.
+type Option func(ide *IDE)
+
+// WithConfigPath merges into path instead of DefaultConfigPath, e.g. ".config/opencode/opencode.json"
+// when materializing into the home directory.
+func WithConfigPath(path string) Option {
+	return func(ide *IDE) {
+		ide.ConfigPath = path
+	}
+}
+
+// WithAgentInstructions generates a managed section with the given instructions in AGENTS.md.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *IDE) {
+		ide.AgentsMDPath = "AGENTS.md"
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds environment variables (local) and headers (remote) to MCP servers.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithCommandMetadata sets per-command metadata. OpenCode supports the description and model
+// frontmatter fields; commands with an argument hint get an $ARGUMENTS placeholder.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithSharedOptions applies options of package shared to commands and context files, e.g.
+// shared.WithCommandNamePolicy.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for OpenCode.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithCommandsFolder(".opencode/command"),
+			shared.WithCommandFrontmatterFields(shared.FieldDescription, shared.FieldModel),
+			shared.WithCommandArgumentsPlaceholder("$ARGUMENTS"),
+			shared.WithAgentsMDPath("AGENTS.md"),
+			shared.WithManagedMemory(true),
+			shared.WithContextPath(shared.DestinationRules, ".opencode/rules"),
+			shared.WithContextPath(shared.DestinationMemory, "AGENTS.md"),
+			shared.WithContextPath(shared.DestinationDocs, ".opencode/docs"),
+		),
+		ConfigPath: DefaultConfigPath,
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds opencode.json to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	paths := i.IDE.ManagedPaths()
+	if i.ConfigPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: i.ConfigPath, Merge: core.MergeJSON})
+	}
+	return paths
+}
+
+// Capabilities reports that OpenCode supports commands, MCP servers, permissions and memory files.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	caps := i.IDE.Capabilities()
+	if i.ConfigPath != "" {
+		caps.MCPTransports = []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}
+		caps.Permissions = true
+	}
+	return caps
+}
+
+// OverridePaths returns a copy of the IDE with the paths of the embedded shared.IDE overridden,
+// except for shared.PathMCP, which replaces ConfigPath. i is never changed.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	rest := maps.Clone(overrides)
+	if p, ok := rest[shared.PathMCP]; ok {
+		if p == "" {
+			return nil, fmt.Errorf("%w: empty path for %s", shared.ErrUnsupportedPathOverride, shared.PathMCP)
+		}
+		c.ConfigPath = p
+		delete(rest, shared.PathMCP)
+	}
+	ide, err := i.IDE.WithPathOverrides(rest)
+	if err != nil {
+		return nil, err
+	}
+	c.IDE = ide
+	return &c, nil
+}
+
+// Materialize produces the files of the embedded shared.IDE and merges MCP servers and
+// permissions into ConfigPath.
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	res, err := i.IDE.Materialize(ctx, adcp.Ide_builder{Commands: ide.GetCommands()}.Build())
+	if err != nil {
+		return nil, err
+	}
+	entries := res.GetEntries()
+
+	perms := ide.GetPermissions()
+	hasPerms := len(perms.GetAllow()) > 0 || len(perms.GetDeny()) > 0
+	if (ide.HasMcp() || hasPerms) && i.ConfigPath != "" {
+		entry, err := i.materializeConfig(ctx, ide.GetMcp(), perms)
+		if err != nil {
+			return nil, err
+		}
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: i.ConfigPath, Merge: core.MergeJSON})
+		entries = append(entries, entry)
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
@@ -0,0 +1,88 @@
+package opencode
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Config(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"github":  {Headers: map[string]string{"Authorization": "Bearer {env:GITHUB_TOKEN}"}},
+		"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+	}))
+	fsys := fstest.MapFS{DefaultConfigPath: {Data: []byte(`{
+  "theme": "opencode",
+  "permission": {"bash": "ask", "webfetch": "allow"}
+}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+		Permissions: adcp.Permissions_builder{
+			Allow: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Bash: strPtr("git commit:*")}.Build(),
+				adcp.OperationPermission_builder{Bash: strPtr("make test")}.Build(),
+				adcp.OperationPermission_builder{Write: strPtr("src/**")}.Build(),
+			},
+			Deny: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{Read: strPtr(".env")}.Build(),
+				adcp.OperationPermission_builder{Bash: strPtr("rm:*")}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, DefaultConfigPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{
+		"theme": "opencode",
+		"permission": {
+			"bash": {"*": "ask", "git commit *": "allow", "make test": "allow", "rm *": "deny"},
+			"webfetch": "allow",
+			"edit": {"src/**": "allow"},
+			"read": {".env": "deny"}
+		},
+		"$schema": "https://opencode.ai/config.json",
+		"mcp": {
+			"devplan": {"type": "local", "command": ["devplan", "mcp"], "environment": {"DEVPLAN_API_KEY": "key"}, "enabled": true},
+			"github": {"type": "remote", "url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer {env:GITHUB_TOKEN}"}, "enabled": true}
+		}
+	}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_Commands(t *testing.T) {
+	g := NewIDEProvider(
+		WithAgentInstructions("Run `go test ./...`."),
+		WithCommandMetadata(map[string]shared.CommandMetadata{"review": {Description: "Review the diff", ArgumentHint: "[file]"}}),
+	)
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "---\ndescription: Review the diff\n---\n\nReview the diff.\n\n$ARGUMENTS\n", m[".opencode/command/review.md"])
+	assert.Contains(t, m["AGENTS.md"], "Run `go test ./...`.")
+	assert.NotContains(t, m, DefaultConfigPath)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
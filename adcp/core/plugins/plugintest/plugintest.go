@@ -0,0 +1,165 @@
+// Package plugintest runs a canonical set of recipes against an IDE provider and compares the
+// materialized output with golden files, giving every provider conformance coverage.
+//
+// Typical usage from a provider package:
+//
+//	func TestGolden(t *testing.T) {
+//		plugintest.Run(t, NewIDEProvider(), "testdata/golden")
+//	}
+//
+// Set ADCP_UPDATE_GOLDEN (see UpdateEnv) to (re)write the golden files:
+//
+//	ADCP_UPDATE_GOLDEN=1 go test ./plugins/...
+package plugintest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// UpdateEnv is the environment variable that makes Compare rewrite golden files instead of
+// checking them when set to a non-empty value.
+const UpdateEnv = "ADCP_UPDATE_GOLDEN"
+
+// updating reports whether golden files should be rewritten.
+func updating() bool {
+	return os.Getenv(UpdateEnv) != ""
+}
+
+// Case is a canonical IDE configuration used for conformance testing.
+type Case struct {
+	Name string
+	Ide  *adcp.Ide
+}
+
+// Cases returns the canonical set of IDE configurations. They only use sources that are
+// available offline (inline text and shell commands).
+func Cases() []Case {
+	return []Case{
+		{Name: "empty", Ide: adcp.Ide_builder{}.Build()},
+		{
+			Name: "commands",
+			Ide: adcp.Ide_builder{
+				Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+					command("review", adcp.CommandFrom_builder{Text: ptr("Review the current diff.\n")}),
+					command("status", adcp.CommandFrom_builder{Cmd: ptr("printf 'Summarize the repository status.\\n'")}),
+				}}.Build(),
+			}.Build(),
+		},
+		{
+			Name: "mcp",
+			Ide: adcp.Ide_builder{
+				Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+					"github": adcp.McpServer_builder{
+						Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build(),
+					}.Build(),
+					"devplan": adcp.McpServer_builder{
+						Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp --stdio"}.Build(),
+					}.Build(),
+				}}.Build(),
+			}.Build(),
+		},
+		{
+			Name: "permissions",
+			Ide: adcp.Ide_builder{
+				Permissions: adcp.Permissions_builder{
+					Allow: []*adcp.OperationPermission{
+						adcp.OperationPermission_builder{Bash: ptr("go test:*")}.Build(),
+						adcp.OperationPermission_builder{Read: ptr("src/**")}.Build(),
+					},
+					Deny: []*adcp.OperationPermission{
+						adcp.OperationPermission_builder{Read: ptr(".env")}.Build(),
+						adcp.OperationPermission_builder{Write: ptr("**/secrets/**")}.Build(),
+					},
+				}.Build(),
+			}.Build(),
+		},
+	}
+}
+
+// Run materializes every canonical case with provider in an empty working directory and compares
+// the result with <goldenDir>/<case>.golden. User-level files are enabled with an empty user root
+// (see core.WithUserRoot), so they are covered too. It changes the working directory of the test,
+// so it must not be used from parallel tests.
+func Run(t *testing.T, provider recipes.IDEProvider, goldenDir string) {
+	t.Helper()
+	goldenDir, err := filepath.Abs(goldenDir)
+	if err != nil {
+		t.Fatalf("failed to resolve golden dir: %v", err)
+	}
+	for _, c := range Cases() {
+		t.Run(c.Name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			ctx := core.WithUserRoot(context.Background(), t.TempDir())
+			res, err := provider.Materialize(ctx, c.Ide)
+			if err != nil {
+				t.Fatalf("materialize failed: %v", err)
+			}
+			Compare(t, filepath.Join(goldenDir, c.Name+".golden"), Format(res))
+		})
+	}
+}
+
+// Format renders a materialized result as text, one section per file, ordered by path.
+func Format(res *adcp.MaterializedResult) string {
+	files := map[string]string{}
+	var paths []string
+	for _, e := range res.GetEntries() {
+		if !e.HasFile() {
+			continue
+		}
+		p := e.GetFile().GetPath()
+		if _, ok := files[p]; !ok {
+			paths = append(paths, p)
+		}
+		files[p] = e.GetFile().GetContent()
+	}
+	slices.Sort(paths)
+
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString("=== " + p + " ===\n")
+		b.WriteString(files[p])
+		if !strings.HasSuffix(files[p], "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Compare checks got against the golden file at path, rewriting it when UpdateEnv is set.
+func Compare(t *testing.T, path, got string) {
+	t.Helper()
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if string(want) != got {
+		t.Errorf("output does not match %s (run with %s=1 to accept)\n--- want\n%s\n--- got\n%s", path, UpdateEnv, want, got)
+	}
+}
+
+func command(name string, from adcp.CommandFrom_builder) *adcp.Command {
+	return adcp.Command_builder{Name: name, From: from.Build()}.Build()
+}
+
+func ptr(s string) *string {
+	return &s
+}
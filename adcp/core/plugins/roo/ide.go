@@ -0,0 +1,166 @@
+// Package roo materializes recipes for Roo Code: rules in .roo/rules, commands as custom modes in
+// .roomodes and MCP servers in .roo/mcp.json.
+package roo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultModesPath is the project-level custom modes file.
+const DefaultModesPath = ".roomodes"
+
+// DefaultModeGroups are the tool groups granted to generated modes by default.
+var DefaultModeGroups = []string{"read", "edit", "command", "mcp"}
+
+// validSlugRe matches the mode slugs Roo Code accepts.
+var validSlugRe = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// IDE materializes recipes for Roo Code. Commands become custom modes, which Roo offers in its mode
+// picker; rules, MCP servers and memory are handled by the embedded shared.IDE.
+type IDE struct {
+	*shared.IDE
+	// ModesPath is the custom modes file commands are merged into. Modes not generated from
+	// the recipe are kept.
+	ModesPath string
+	// ModeGroups are the tool groups granted to generated modes, e.g. "read" or "browser".
+	ModeGroups []string
+}
+
+// Option configures the Roo Code IDE provider.
+type Option func(ide *IDE)
+
+// WithModeGroups grants groups instead of DefaultModeGroups to generated modes.
+func WithModeGroups(groups ...string) Option {
+	return func(ide *IDE) {
+		ide.ModeGroups = groups
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into .roo/mcp.json.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithCommandMetadata sets per-command metadata. The description becomes the mode's whenToUse.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithSharedOptions applies options of package shared to rules, memory and MCP servers, e.g.
+// shared.WithScope.
+func WithSharedOptions(opts ...shared.Option) Option {
+	return shared.ApplyShared[*IDE](opts...)
+}
+
+// NewIDEProvider returns a provider for Roo Code.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithMCPPath(".roo/mcp.json"),
+			shared.WithMCPServerFunc(mcpServer),
+			shared.WithManagedMemory(true),
+			shared.WithContextPath(shared.DestinationRules, ".roo/rules"),
+			shared.WithContextPath(shared.DestinationMemory, "AGENTS.md"),
+			shared.WithContextPath(shared.DestinationDocs, ".roo/docs"),
+		),
+		ModesPath:  DefaultModesPath,
+		ModeGroups: DefaultModeGroups,
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds .roomodes to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	paths := i.IDE.ManagedPaths()
+	if i.ModesPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: i.ModesPath, Merge: core.MergeJSON})
+	}
+	return paths
+}
+
+// Capabilities reports that Roo Code supports commands (as modes), MCP servers and memory files.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	caps := i.IDE.Capabilities()
+	caps.Commands = i.ModesPath != ""
+	return caps
+}
+
+// OverridePaths returns a copy of the IDE with rule, memory, docs or MCP paths overridden.
+// Commands are merged into ModesPath, which cannot be overridden.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// Materialize merges commands as custom modes into ModesPath and produces the files of the
+// embedded shared.IDE for everything else.
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	res, err := i.IDE.Materialize(ctx, adcp.Ide_builder{Mcp: ide.GetMcp(), Permissions: ide.GetPermissions()}.Build())
+	if err != nil {
+		return nil, err
+	}
+	entries := res.GetEntries()
+
+	if ide.HasCommands() && i.ModesPath != "" {
+		entry, err := i.materializeModes(ctx, ide.GetCommands())
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			core.RecordPlannedChange(ctx, core.PlannedChange{Path: i.ModesPath, Merge: core.MergeJSON})
+			entries = append(entries, entry)
+		}
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// mcpServerConfig is a server in Roo Code's format, where remote servers have the type
+// streamable-http.
+type mcpServerConfig struct {
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) any {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return mcpServerConfig{Type: "streamable-http", URL: url, Headers: opts.Headers}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return mcpServerConfig{Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
+
+// modeSlug turns a resolved command name into a mode slug, which may only contain ASCII letters,
+// digits and dashes.
+func modeSlug(name string) (string, error) {
+	slug := strings.ReplaceAll(name, "_", "-")
+	if !validSlugRe.MatchString(slug) {
+		return "", fmt.Errorf("invalid mode slug %q: Roo Code only accepts ASCII letters, digits and '-'; use shared.CommandNamePolicyTransliterate", name)
+	}
+	return slug, nil
+}
@@ -0,0 +1,98 @@
+package roo
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Modes(t *testing.T) {
+	g := NewIDEProvider(
+		WithModeGroups("read", "command"),
+		WithCommandMetadata(map[string]shared.CommandMetadata{
+			"review": {Description: "Use when reviewing a diff"},
+		}),
+	)
+	fsys := fstest.MapFS{".roomodes": {Data: []byte(`{
+  "customModes": [
+    {"slug": "docs", "name": "Docs", "roleDefinition": "You write docs.", "groups": ["read"]},
+    {"slug": "review", "name": "Old review", "roleDefinition": "Outdated.", "groups": []}
+  ]
+}`)}}
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+			adcp.Command_builder{Name: "ship_it", From: adcp.CommandFrom_builder{Text: strPtr("Ship it.")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".roomodes", res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{"customModes": [
+		{"slug": "docs", "name": "Docs", "roleDefinition": "You write docs.", "groups": ["read"]},
+		{"slug": "review", "name": "review", "roleDefinition": "You carry out the review command of this project.",
+		 "whenToUse": "Use when reviewing a diff", "customInstructions": "Review the diff.", "groups": ["read", "command"]},
+		{"slug": "ship-it", "name": "ship_it", "roleDefinition": "You carry out the ship_it command of this project.",
+		 "customInstructions": "Ship it.", "groups": ["read", "command"]}
+	]}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_InvalidSlug(t *testing.T) {
+	g := NewIDEProvider(WithSharedOptions(shared.WithCommandNamePolicy(shared.CommandNamePolicySlugify)))
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "レビュー", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	_, err := g.Materialize(context.Background(), ide)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mode slug")
+
+	g = NewIDEProvider(WithSharedOptions(shared.WithCommandNamePolicy(shared.CommandNamePolicyTransliterate)))
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), `"slug": "rebyu"`)
+}
+
+func TestIDE_Materialize_Mcp(t *testing.T) {
+	g := NewIDEProvider(WithMCPServerOptions(map[string]shared.MCPServerOptions{
+		"github": {Headers: map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}},
+	}))
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".roo/mcp.json", res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{"mcpServers": {
+		"devplan": {"command": "devplan", "args": ["mcp"]},
+		"github": {"type": "streamable-http", "url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${env:GITHUB_TOKEN}"}}
+	}}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*IDE)
+
+	got, err := g.MapContextPath("@rules/style.md")
+	require.NoError(t, err)
+	assert.Equal(t, ".roo/rules/style.md", got)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
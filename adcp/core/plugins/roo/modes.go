@@ -0,0 +1,113 @@
+package roo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// customMode is a mode in the .roomodes format.
+type customMode struct {
+	Slug               string   `json:"slug"`
+	Name               string   `json:"name"`
+	RoleDefinition     string   `json:"roleDefinition"`
+	WhenToUse          string   `json:"whenToUse,omitempty"`
+	CustomInstructions string   `json:"customInstructions,omitempty"`
+	Groups             []string `json:"groups"`
+}
+
+// materializeModes merges a mode per command into ModesPath. Existing modes with the slug of a
+// command are replaced in place; other modes are kept.
+func (i *IDE) materializeModes(ctx context.Context, commands *adcp.Commands) (*adcp.MaterializedResult_Entry, error) {
+	names, err := i.ResolveCommandNames(commands)
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+	modes := make([]customMode, 0, len(names))
+	for idx, c := range commands.GetEntries() {
+		slug, err := modeSlug(names[idx])
+		if err != nil {
+			return nil, err
+		}
+		body, err := shared.FetchCommandContent(ctx, c.GetFrom())
+		if err != nil {
+			return nil, fmt.Errorf("failed to materialize command %s: %w", names[idx], err)
+		}
+		desc := i.CommandMetadata[c.GetName()].Description
+		modes = append(modes, customMode{
+			Slug:               slug,
+			Name:               names[idx],
+			RoleDefinition:     fmt.Sprintf("You carry out the %s command of this project.", names[idx]),
+			WhenToUse:          desc,
+			CustomInstructions: strings.TrimRight(body, "\n"),
+			Groups:             i.ModeGroups,
+		})
+	}
+
+	doc, _ := merge.ParseDocument(shared.ReadExistingJSON(ctx, i.ModesPath))
+	var existing []json.RawMessage
+	// A customModes value that is not an array is replaced.
+	doc.Get("customModes", &existing)
+	merged, err := mergeModes(existing, modes)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Set("customModes", merged); err != nil {
+		return nil, err
+	}
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal custom modes: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.ModesPath, Content: content}.Build(),
+	}.Build(), nil
+}
+
+// mergeModes replaces the existing modes that share a slug with one of modes and appends the rest.
+func mergeModes(existing []json.RawMessage, modes []customMode) ([]json.RawMessage, error) {
+	bySlug := make(map[string]customMode, len(modes))
+	for _, m := range modes {
+		bySlug[m.Slug] = m
+	}
+	encode := func(m customMode) (json.RawMessage, error) {
+		data, err := json.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal mode %s: %w", m.Slug, err)
+		}
+		delete(bySlug, m.Slug)
+		return data, nil
+	}
+	out := make([]json.RawMessage, 0, len(existing)+len(modes))
+	for _, raw := range existing {
+		var mode struct {
+			Slug string `json:"slug"`
+		}
+		if json.Unmarshal(raw, &mode) == nil {
+			if m, ok := bySlug[mode.Slug]; ok {
+				data, err := encode(m)
+				if err != nil {
+					return nil, err
+				}
+				raw = data
+			}
+		}
+		out = append(out, raw)
+	}
+	for _, m := range modes {
+		if _, ok := bySlug[m.Slug]; !ok {
+			continue
+		}
+		data, err := encode(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+	return out, nil
+}
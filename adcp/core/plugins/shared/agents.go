@@ -0,0 +1,71 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/frontmatter"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Agent describes a subagent, which is not part of the recipe model yet. It is written to
+// <AgentsFolder>/<Name>.md with its properties as frontmatter and its prompt as the body.
+type Agent struct {
+	// Name identifies the agent and names its file. It follows the rules of command names.
+	Name        string
+	Description string
+	// Tools limits the tools available to the agent; empty inherits all tools.
+	Tools []string
+	// Model selects the model of the agent, e.g. "sonnet" or "inherit".
+	Model string
+	// From is the source of the agent's system prompt, like the source of a command.
+	From *adcp.CommandFrom
+}
+
+// Frontmatter field names supported for agents, in addition to FieldDescription and FieldModel.
+const (
+	FieldName  = "name"
+	FieldTools = "tools"
+)
+
+// materializeAgents writes Agents into AgentsFolder.
+func (i *IDE) materializeAgents(ctx context.Context) ([]*adcp.MaterializedResult_Entry, error) {
+	var entries []*adcp.MaterializedResult_Entry
+	seen := map[string]bool{}
+	for _, a := range i.Agents {
+		name, err := i.resolveCommandName(a.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid agent: %w", err)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate agent name %q", name)
+		}
+		seen[name] = true
+		if a.From == nil {
+			return nil, fmt.Errorf("agent %s must have a 'from' source", name)
+		}
+		core.Logger(ctx).Debug("Materializing agent", "name", name, "source", a.From.WhichType().String())
+		body, err := FetchCommandContent(ctx, a.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to materialize agent %s: %w", name, err)
+		}
+		fm := frontmatter.New().
+			Set(FieldName, name).
+			Set(FieldDescription, a.Description).
+			Set(FieldTools, strings.Join(a.Tools, ", ")).
+			Set(FieldModel, a.Model)
+		p := path.Join(i.AgentsFolder, name+".md")
+		change := core.PlannedChange{Path: p, Merge: core.MergeReplace}
+		if a.From.WhichType() == adcp.CommandFrom_Cmd_case {
+			change.Note = "command not executed: " + a.From.GetCmd()
+		}
+		core.RecordPlannedChange(ctx, change)
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: p, Content: fm.Render(body)}.Build(),
+		}.Build())
+	}
+	return entries, nil
+}
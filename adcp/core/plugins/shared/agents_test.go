@@ -0,0 +1,72 @@
+package shared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Agents(t *testing.T) {
+	text := func(s string) *adcp.CommandFrom { return adcp.CommandFrom_builder{Text: &s}.Build() }
+
+	tests := []struct {
+		name    string
+		agents  []Agent
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "body only",
+			agents: []Agent{{Name: "helper", From: text("Help.")}},
+			want:   map[string]string{".agents/helper.md": "---\nname: helper\n---\n\nHelp."},
+		},
+		{
+			name:    "invalid name",
+			agents:  []Agent{{Name: "code:reviewer", From: text("Review.")}},
+			wantErr: "invalid agent",
+		},
+		{
+			name:    "duplicate name",
+			agents:  []Agent{{Name: "a", From: text("1")}, {Name: "a", From: text("2")}},
+			wantErr: `duplicate agent name "a"`,
+		},
+		{
+			name:    "missing source",
+			agents:  []Agent{{Name: "a"}},
+			wantErr: "agent a must have a 'from' source",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ide := &IDE{AgentsFolder: ".agents", Agents: tt.agents}
+			res, err := ide.Materialize(context.Background(), &adcp.Ide{})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got := map[string]string{}
+			for _, e := range res.GetEntries() {
+				got[e.GetFile().GetPath()] = e.GetFile().GetContent()
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIDE_Materialize_AgentsUnsupported(t *testing.T) {
+	report := core.NewReport()
+	ctx := core.WithReport(context.Background(), report)
+	ide := &IDE{Agents: []Agent{{Name: "helper"}}}
+
+	res, err := ide.Materialize(ctx, &adcp.Ide{})
+	require.NoError(t, err)
+	assert.Empty(t, res.GetEntries())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, recipes.FeatureAgents, report.Data().Warnings[0].Feature)
+}
@@ -0,0 +1,34 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+func BenchmarkBuildMcpJSON(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		servers := map[string]*adcp.McpServer{}
+		for i := range n {
+			if i%2 == 0 {
+				servers[fmt.Sprintf("http-%d", i)] = adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: fmt.Sprintf("https://mcp%d.example.com/mcp", i)}.Build()}.Build()
+			} else {
+				servers[fmt.Sprintf("stdio-%d", i)] = adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: fmt.Sprintf("npx -y server-%d --stdio", i)}.Build()}.Build()
+			}
+		}
+		mcp := adcp.Mcp_builder{Servers: servers}.Build()
+		existing, err := buildMcpJSON(mcp, "mcpServers", StandardMCPServer, nil, nil, "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("servers=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := buildMcpJSON(mcp, "mcpServers", StandardMCPServer, nil, nil, existing); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
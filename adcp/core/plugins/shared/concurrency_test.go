@@ -0,0 +1,68 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_CommandsFetchedConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("body " + r.URL.Path))
+	}))
+	defer ts.Close()
+
+	var cmds []*adcp.Command
+	for n := range 10 {
+		cmds = append(cmds, adcp.Command_builder{
+			Name: fmt.Sprintf("cmd%d", n),
+			From: adcp.CommandFrom_builder{Github: adcp.GitReference_builder{Path: fmt.Sprintf("%s/%d", ts.URL, n)}.Build()}.Build(),
+		}.Build())
+	}
+
+	g := &IDE{CommandsFolder: ".claude/commands", CommandConcurrency: 3}
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: cmds}.Build(),
+	}.Build())
+	require.NoError(t, err)
+
+	// Order is preserved regardless of completion order.
+	for n := range 10 {
+		assert.Equal(t, fmt.Sprintf(".claude/commands/cmd%d.md", n), res.GetEntries()[n].GetFile().GetPath())
+		assert.Equal(t, fmt.Sprintf("body /%d", n), res.GetEntries()[n].GetFile().GetContent())
+	}
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+}
+
+func TestIDE_Materialize_CommandErrorsAggregated(t *testing.T) {
+	g := &IDE{CommandsFolder: ".claude/commands"}
+	_, err := g.Materialize(context.Background(), adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "ok", From: adcp.CommandFrom_builder{Text: strPtr("fine")}.Build()}.Build(),
+			adcp.Command_builder{Name: "bad1", From: adcp.CommandFrom_builder{Cmd: strPtr("exit 1")}.Build()}.Build(),
+			adcp.Command_builder{Name: "bad2", From: adcp.CommandFrom_builder{Cmd: strPtr("exit 2")}.Build()}.Build(),
+		}}.Build(),
+	}.Build())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to materialize command bad1")
+	assert.Contains(t, err.Error(), "failed to materialize command bad2")
+}
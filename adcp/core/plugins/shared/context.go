@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+)
+
+// Logical context destinations a recipe can target instead of IDE-specific paths.
+// A context entry path of "@rules/style.md" is written to "style.md" under the IDE's rules location,
+// while "@memory" alone refers to the IDE's memory file itself.
+const (
+	DestinationRules  = "rules"
+	DestinationMemory = "memory"
+	DestinationDocs   = "docs"
+)
+
+// logicalPathPrefix marks a context path as a logical destination.
+const logicalPathPrefix = "@"
+
+// MapContextPath resolves a logical context path against ContextPaths.
+// Paths that are not logical are returned unchanged.
+func (i *IDE) MapContextPath(p string) (string, error) {
+	if !strings.HasPrefix(p, logicalPathPrefix) {
+		return p, nil
+	}
+	dest, rest, _ := strings.Cut(strings.TrimPrefix(p, logicalPathPrefix), "/")
+	base, ok := i.ContextPaths[dest]
+	if !ok {
+		return "", fmt.Errorf("context destination %q is not supported by this IDE", dest)
+	}
+	if rest == "" {
+		return base, nil
+	}
+	return path.Join(base, rest), nil
+}
+
+// MergeContext merges content into the managed section of the memory file when ManagedMemory is
+// set. If the memory file is also AgentsMDPath, content gets a section of its own, delimited by
+// ManagedMemoryBegin and ManagedMemoryEnd, next to the agent instructions. Other files are replaced
+// with content.
+func (i *IDE) MergeContext(ctx context.Context, p, content string) (string, error) {
+	memory := i.ContextPaths[DestinationMemory]
+	if !i.ManagedMemory || memory == "" || p != core.NormalizePath(memory) {
+		return content, nil
+	}
+	if i.FS != nil {
+		ctx = core.WithFS(ctx, i.FS)
+	}
+	content, err := core.BlobsFrom(ctx).Resolve(content)
+	if err != nil {
+		return "", err
+	}
+	core.RecordPlannedChange(ctx, core.PlannedChange{Path: p, Merge: core.MergeManagedBlock})
+	existing := ReadExistingFile(ctx, p)
+	if i.AgentsMDPath != "" && p == core.NormalizePath(i.AgentsMDPath) {
+		return MergeManagedBlockWithMarkers(existing, content, ManagedMemoryBegin, ManagedMemoryEnd), nil
+	}
+	return MergeManagedBlock(existing, content), nil
+}
@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_MapContextPath(t *testing.T) {
+	ide := &IDE{ContextPaths: map[string]string{
+		DestinationRules:  ".claude/rules",
+		DestinationMemory: "CLAUDE.md",
+	}}
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "docs/plain.md", want: "docs/plain.md"},
+		{in: "@rules/style.md", want: ".claude/rules/style.md"},
+		{in: "@rules/go/testing.md", want: ".claude/rules/go/testing.md"},
+		{in: "@memory", want: "CLAUDE.md"},
+		{in: "@docs/api.md", wantErr: `context destination "docs" is not supported`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ide.MapContextPath(tt.in)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIDE_MergeContext(t *testing.T) {
+	ide := &IDE{
+		ContextPaths:  map[string]string{DestinationMemory: "CLAUDE.md"},
+		ManagedMemory: true,
+		FS: fstest.MapFS{
+			"CLAUDE.md": {Data: []byte("# Notes\n\nHand-written.\n\n" + ManagedBlockBegin + "\nold\n" + ManagedBlockEnd + "\n")},
+		},
+	}
+
+	got, err := ide.MergeContext(context.Background(), "CLAUDE.md", "new\n")
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n\nHand-written.\n\n"+ManagedBlockBegin+"\nnew\n"+ManagedBlockEnd+"\n", got)
+
+	got, err = ide.MergeContext(context.Background(), "docs/other.md", "new\n")
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", got, "only the memory file is merged")
+
+	ide.ManagedMemory = false
+	got, err = ide.MergeContext(context.Background(), "CLAUDE.md", "new\n")
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", got)
+}
+
+func TestIDE_MergeContext_AgentsMD(t *testing.T) {
+	instructions := ManagedBlockBegin + "\nRun make test.\n" + ManagedBlockEnd + "\n"
+	ide := &IDE{
+		ContextPaths:  map[string]string{DestinationMemory: "AGENTS.md"},
+		AgentsMDPath:  "AGENTS.md",
+		ManagedMemory: true,
+		FS:            fstest.MapFS{"AGENTS.md": {Data: []byte("# Project\n\n" + instructions)}},
+	}
+
+	got, err := ide.MergeContext(context.Background(), "AGENTS.md", "Use tabs.\n")
+	require.NoError(t, err)
+	assert.Equal(t, "# Project\n\n"+instructions+"\n"+ManagedMemoryBegin+"\nUse tabs.\n"+ManagedMemoryEnd+"\n", got,
+		"memory gets a section of its own next to the agent instructions")
+}
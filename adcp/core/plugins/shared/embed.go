@@ -0,0 +1,47 @@
+package shared
+
+import "github.com/devplaninc/adcp-core/adcp/core/recipes"
+
+// Embedder is a provider that embeds an IDE built by NewIDE and adds IDE-specific outputs, e.g.
+// Codex with its config.toml.
+type Embedder interface {
+	SharedIDE() *IDE
+}
+
+// SharedIDE returns i, so that providers embedding an IDE implement Embedder.
+func (i *IDE) SharedIDE() *IDE {
+	return i
+}
+
+// Configure applies the provider options opts to p and then the Scope of its IDE, which the
+// options may have changed through ApplyShared. Providers embedding an IDE return
+// Configure(&IDE{IDE: NewIDE(defaults...)}, opts...) from their constructor.
+func Configure[P Embedder, O ~func(P)](p P, opts ...O) P {
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.SharedIDE().applyScope()
+	return p
+}
+
+// ApplyShared returns a provider option applying opts to the IDE embedded in the provider, for
+// providers whose options are not Options of this package.
+func ApplyShared[P Embedder](opts ...Option) func(P) {
+	return func(p P) {
+		for _, opt := range opts {
+			opt(p.SharedIDE())
+		}
+	}
+}
+
+// OverrideEmbeddedPaths overrides the paths of the IDE embedded in p, a copy of a provider made by
+// its OverridePaths, with overrides (see IDE.WithPathOverrides). embedded points to the field
+// holding the IDE in p, which is replaced, so that the original provider is never changed.
+func OverrideEmbeddedPaths(p recipes.IDEProvider, embedded **IDE, overrides map[string]string) (recipes.IDEProvider, error) {
+	ide, err := (*embedded).WithPathOverrides(overrides)
+	if err != nil {
+		return nil, err
+	}
+	*embedded = ide
+	return p, nil
+}
@@ -0,0 +1,52 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wrapper is a provider embedding an IDE, like the providers of Codex or Roo Code.
+type wrapper struct {
+	*IDE
+	ConfigPath string
+}
+
+type wrapperOption func(*wrapper)
+
+func TestConfigure(t *testing.T) {
+	newWrapper := func(opts ...wrapperOption) *wrapper {
+		return Configure(&wrapper{
+			IDE: NewIDE(
+				WithCommandsFolder(".acme/commands"),
+				WithContextPath(DestinationMemory, "ACME.md"),
+				WithUserPaths(map[string]string{DestinationMemory: ".acme/ACME.md"}),
+			),
+			ConfigPath: ".acme/config.json",
+		}, opts...)
+	}
+
+	project := newWrapper(func(w *wrapper) { w.ConfigPath = ".acme.json" })
+	assert.Equal(t, ".acme.json", project.ConfigPath)
+	assert.Equal(t, ".acme/commands", project.CommandsFolder)
+
+	user := newWrapper(ApplyShared[*wrapper](WithScope(ScopeUser)))
+	assert.Empty(t, user.CommandsFolder, "the scope set by provider options is applied")
+	assert.Equal(t, map[string]string{DestinationMemory: ".acme/ACME.md"}, user.ContextPaths)
+}
+
+func TestOverrideEmbeddedPaths(t *testing.T) {
+	w := &wrapper{IDE: NewIDE(WithContextPath(DestinationDocs, ".acme/docs")), ConfigPath: ".acme.json"}
+
+	c := *w
+	p, err := OverrideEmbeddedPaths(&c, &c.IDE, map[string]string{DestinationDocs: "docs"})
+	require.NoError(t, err)
+	require.IsType(t, &wrapper{}, p)
+	assert.Equal(t, "docs", p.(*wrapper).ContextPaths[DestinationDocs])
+	assert.Equal(t, ".acme.json", p.(*wrapper).ConfigPath)
+	assert.Equal(t, ".acme/docs", w.ContextPaths[DestinationDocs], "the original provider is unchanged")
+
+	_, err = OverrideEmbeddedPaths(&c, &c.IDE, map[string]string{PathCommands: "commands"})
+	assert.ErrorIs(t, err, ErrUnsupportedPathOverride)
+}
@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+)
+
+// ReadExistingFile returns the current content of path, or "" if it cannot be read,
+// and logs whether generated content will be merged into an existing file.
+func ReadExistingFile(ctx context.Context, path string) string {
+	if _, ok := core.HomeRelative(path); ok {
+		return ReadExistingUserFile(ctx, nil, path)
+	}
+	log := core.Logger(ctx).With("path", path)
+	data, err := core.ReadFile(ctx, path)
+	if err != nil {
+		log.Debug("No existing file, creating it")
+		return ""
+	}
+	log.Debug("Merging into existing file", "bytes", len(data))
+	return string(data)
+}
+
+// ReadExistingJSON is like ReadExistingFile, but additionally warns when the existing content is not
+// valid JSON, with or without comments, in which case merging starts from an empty document and the
+// file gets replaced.
+func ReadExistingJSON(ctx context.Context, path string) string {
+	content := ReadExistingFile(ctx, path)
+	if content != "" && !json.Valid([]byte(content)) && !json.Valid(merge.StripJSONComments([]byte(content))) {
+		core.Warn(ctx, core.Warning{
+			Code:    core.WarningInvalidExistingFile,
+			Path:    path,
+			Message: "Existing file is not valid JSON; its content is discarded and the file is replaced",
+		})
+	}
+	return content
+}
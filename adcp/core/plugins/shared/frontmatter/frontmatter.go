@@ -0,0 +1,216 @@
+// Package frontmatter builds and merges the YAML frontmatter blocks used by commands, rules,
+// subagents and skills across IDE providers.
+package frontmatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const delimiter = "---"
+
+// Frontmatter is an ordered set of YAML frontmatter fields. Keys render in insertion order,
+// so output is stable across runs.
+type Frontmatter struct {
+	keys   []string
+	values map[string]any
+}
+
+// New returns an empty frontmatter.
+func New() *Frontmatter {
+	return &Frontmatter{values: map[string]any{}}
+}
+
+// Set stores value under key, keeping the key's position if it already exists.
+// Supported values are strings, string slices, booleans and integers.
+// Empty strings and empty slices remove the key instead.
+func (f *Frontmatter) Set(key string, value any) *Frontmatter {
+	if isEmpty(value) {
+		f.Delete(key)
+		return f
+	}
+	if _, exists := f.values[key]; !exists {
+		f.keys = append(f.keys, key)
+	}
+	f.values[key] = value
+	return f
+}
+
+// Get returns the value stored under key.
+func (f *Frontmatter) Get(key string) (any, bool) {
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// Delete removes key.
+func (f *Frontmatter) Delete(key string) {
+	if _, exists := f.values[key]; !exists {
+		return
+	}
+	delete(f.values, key)
+	for i, k := range f.keys {
+		if k == key {
+			f.keys = append(f.keys[:i], f.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in render order.
+func (f *Frontmatter) Keys() []string {
+	return append([]string(nil), f.keys...)
+}
+
+// Len returns the number of fields.
+func (f *Frontmatter) Len() int {
+	return len(f.keys)
+}
+
+// Merge copies all fields of other into f; fields of other win on conflict.
+func (f *Frontmatter) Merge(other *Frontmatter) *Frontmatter {
+	if other == nil {
+		return f
+	}
+	for _, k := range other.keys {
+		f.Set(k, other.values[k])
+	}
+	return f
+}
+
+// Filter removes every field whose key is not in allowed.
+func (f *Frontmatter) Filter(allowed []string) *Frontmatter {
+	keep := map[string]bool{}
+	for _, k := range allowed {
+		keep[k] = true
+	}
+	for _, k := range f.Keys() {
+		if !keep[k] {
+			f.Delete(k)
+		}
+	}
+	return f
+}
+
+// String renders the frontmatter block including delimiters, or "" when there are no fields.
+func (f *Frontmatter) String() string {
+	if f == nil || len(f.keys) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(delimiter + "\n")
+	for _, k := range f.keys {
+		switch v := f.values[k].(type) {
+		case []string:
+			b.WriteString(k + ":\n")
+			for _, item := range v {
+				b.WriteString("  - " + Scalar(item) + "\n")
+			}
+		case string:
+			b.WriteString(k + ": " + Scalar(v) + "\n")
+		default:
+			b.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+		}
+	}
+	b.WriteString(delimiter + "\n")
+	return b.String()
+}
+
+// Render returns body prefixed with the frontmatter block and a blank line.
+// Without fields, body is returned unchanged.
+func (f *Frontmatter) Render(body string) string {
+	fm := f.String()
+	if fm == "" {
+		return body
+	}
+	return fm + "\n" + body
+}
+
+// Parse splits content into its frontmatter and body. Content without frontmatter yields an
+// empty Frontmatter and the unchanged content. Nested mappings are not supported.
+func Parse(content string) (*Frontmatter, string, error) {
+	f := New()
+	rest, ok := strings.CutPrefix(content, delimiter+"\n")
+	if !ok {
+		return f, content, nil
+	}
+	end := strings.Index(rest, "\n"+delimiter)
+	if end < 0 {
+		return f, content, nil
+	}
+	block := rest[:end+1]
+	body := strings.TrimPrefix(rest[end+1+len(delimiter):], "\n")
+	body = strings.TrimPrefix(body, "\n")
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(block), &node); err != nil {
+		return nil, "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if len(node.Content) == 0 {
+		return f, body, nil
+	}
+	mapping := node.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("invalid frontmatter: expected a mapping")
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, val := mapping.Content[i].Value, mapping.Content[i+1]
+		switch val.Kind {
+		case yaml.SequenceNode:
+			var items []string
+			for _, item := range val.Content {
+				items = append(items, item.Value)
+			}
+			f.Set(key, items)
+		case yaml.ScalarNode:
+			switch val.Tag {
+			case "!!bool":
+				b, _ := strconv.ParseBool(val.Value)
+				f.Set(key, b)
+			case "!!int":
+				n, _ := strconv.Atoi(val.Value)
+				f.Set(key, n)
+			default:
+				f.Set(key, val.Value)
+			}
+		default:
+			return nil, "", fmt.Errorf("invalid frontmatter: unsupported value for %s", key)
+		}
+	}
+	return f, body, nil
+}
+
+// Scalar returns s as a plain YAML scalar when safe, otherwise as a double-quoted string.
+func Scalar(s string) string {
+	if s == "" || s != strings.TrimSpace(s) ||
+		strings.ContainsAny(s[:1], "!&*[]{}|>'\"%@`#,?:-") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t") ||
+		isReserved(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// isReserved reports whether s would be read back as a non-string YAML value.
+func isReserved(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
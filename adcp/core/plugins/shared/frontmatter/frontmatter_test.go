@@ -0,0 +1,56 @@
+package frontmatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontmatter_Render(t *testing.T) {
+	f := New().
+		Set("description", "Review: the diff").
+		Set("globs", []string{"**/*.go", "*.md"}).
+		Set("alwaysApply", false).
+		Set("model", "")
+
+	assert.Equal(t, `---
+description: "Review: the diff"
+globs:
+  - "**/*.go"
+  - "*.md"
+alwaysApply: false
+---
+
+Body`, f.Render("Body"))
+	assert.Equal(t, "Body", New().Render("Body"))
+}
+
+func TestFrontmatter_ParseMergeRoundTrip(t *testing.T) {
+	content := "---\ndescription: Old\ntools:\n  - Read\n  - Grep\nalwaysApply: true\n---\n\nBody text\n"
+	f, body, err := Parse(content)
+	require.NoError(t, err)
+	assert.Equal(t, "Body text\n", body)
+	assert.Equal(t, []string{"description", "tools", "alwaysApply"}, f.Keys())
+
+	f.Merge(New().Set("description", "New").Set("model", "sonnet"))
+	assert.Equal(t, "---\ndescription: New\ntools:\n  - Read\n  - Grep\nalwaysApply: true\nmodel: sonnet\n---\n\nBody text\n", f.Render(body))
+
+	f.Filter([]string{"description", "model"})
+	assert.Equal(t, []string{"description", "model"}, f.Keys())
+}
+
+func TestParse_NoFrontmatter(t *testing.T) {
+	f, body, err := Parse("# Title\n")
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.Len())
+	assert.Equal(t, "# Title\n", body)
+}
+
+func TestScalar(t *testing.T) {
+	assert.Equal(t, "plain", Scalar("plain"))
+	assert.Equal(t, `"[message]"`, Scalar("[message]"))
+	assert.Equal(t, `"true"`, Scalar("true"))
+	assert.Equal(t, `"1.5"`, Scalar("1.5"))
+	assert.Equal(t, `""`, Scalar(""))
+}
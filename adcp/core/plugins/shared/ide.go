@@ -2,26 +2,140 @@ package shared
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
 	"strings"
+	"sync"
+	"text/template"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/frontmatter"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp-core/adcp/core/utils"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
-// IDE is responsible for materializing Claude Code specific IDE configuration files.
+// IDE materializes recipes into the files of an IDE: commands, agents and skills in folders,
+// context at the IDE's conventional locations, MCP servers in a JSON file and anything else, such as
+// permissions, through Settings. Providers build it with NewIDE and the locations and formats of
+// their IDE. Without Settings that support permissions, recipe permissions are not materialized
+// (see Capabilities).
 type IDE struct {
 	CommandsFolder     string
 	MCPServersJSONPath string
-	Settings           IDESettings
+	// MCPServersKey is the top-level key holding servers in MCPServersJSONPath. Defaults to "mcpServers".
+	MCPServersKey string
+	// MCPServerFunc converts servers into this IDE's format. Defaults to StandardMCPServer.
+	MCPServerFunc MCPServerFunc
+	Settings      IDESettings
+	// CommandMetadata holds optional per-command metadata keyed by command name.
+	// When present, it is rendered as YAML frontmatter at the top of the command file.
+	CommandMetadata map[string]CommandMetadata
+	// CommandFrontmatterFields limits which metadata fields are rendered as frontmatter.
+	// Nil renders all supported fields.
+	CommandFrontmatterFields []string
+	// CommandExtension is the extension of generated command files. Defaults to ".md".
+	CommandExtension string
+	// CommandTemplate, when set, renders the full command file from CommandTemplateData
+	// instead of writing the frontmatter followed by the body.
+	CommandTemplate *template.Template
+	// CommandConcurrency bounds how many command bodies are fetched at once. Defaults to 8.
+	CommandConcurrency int
+	// CommandNamePolicy controls how command names are turned into file names. Defaults to
+	// CommandNamePolicyPreserve.
+	CommandNamePolicy CommandNamePolicy
+	// CommandArgumentsPlaceholder is appended to the body of commands that declare an argument hint
+	// but do not reference the placeholder themselves.
+	CommandArgumentsPlaceholder string
+	// AgentsFolder is where Agents are written, e.g. ".claude/agents". When empty, the IDE has no
+	// subagents.
+	AgentsFolder string
+	// Agents are subagent definitions that are not part of the recipe model yet.
+	Agents []Agent
+	// SkillsFolder is where Skills are written, one directory per skill, e.g. ".claude/skills".
+	// When empty, the IDE has no skills.
+	SkillsFolder string
+	// Skills are skill packages that are not part of the recipe model yet.
+	Skills []Skill
+	// AgentsMDPath is the location of the AGENTS.md file for IDEs that honor the convention.
+	// When empty or without AgentInstructions, no AGENTS.md output is produced.
+	AgentsMDPath string
+	// AgentInstructions is written into the managed section of AgentsMDPath.
+	AgentInstructions string
+	// ContextPaths maps logical context destinations (see DestinationRules and friends)
+	// to this IDE's conventional locations.
+	ContextPaths map[string]string
+	// ManagedMemory writes context targeting the memory file into its managed section (see
+	// MergeManagedBlock) instead of replacing the file, keeping hand-written content.
+	ManagedMemory bool
+	// Scope selects project or user-level locations; see NewIDE.
+	Scope Scope
+	// UserPaths are the user-level locations of outputs used in ScopeUser, keyed like OverridePaths.
+	UserPaths map[string]string
+	// MCPServerOptions holds optional per-server settings keyed by MCP server name
+	// that are not part of the recipe model yet.
+	MCPServerOptions map[string]MCPServerOptions
+	// EnvReference formats a reference to an environment variable that the IDE expands in its
+	// MCP configuration, e.g. EnvReference or VSCodeEnvReference. When nil, bearer tokens of
+	// MCPServerOptions are not supported.
+	EnvReference func(name string) string
+	// RemovedMCPServers are deleted from MCPServersJSONPath, e.g. servers a recipe no longer
+	// declares. Removing a server the recipe declares is an error.
+	RemovedMCPServers []string
+	// Logger receives debug logs about fetches, merges and generated files.
+	// Defaults to the logger carried by the context (see core.WithLogger).
+	Logger *slog.Logger
+	// FS is read for existing files before merging. Defaults to the filesystem carried by the
+	// context (see core.WithFS), or the working directory.
+	FS fs.FS
+}
+
+// MCPServerOptions holds additional MCP server settings. Env and header values may reference
+// secrets as "${secret:NAME}" (see core.ExpandSecrets); note that resolved secrets are written
+// into the MCP configuration file in plain text.
+type MCPServerOptions struct {
+	// Env is passed to stdio servers as environment variables.
+	Env map[string]string
+	// Headers are sent with every request to HTTP servers.
+	Headers map[string]string
+	// BearerTokenEnv names the environment variable holding a token sent to HTTP servers as
+	// "Authorization: Bearer <token>". Only a reference to the variable is written (see
+	// IDE.EnvReference), never the token. An Authorization header in Headers takes precedence.
+	BearerTokenEnv string
+	// OAuth holds OAuth client settings of HTTP servers, for IDEs that take them in their
+	// configuration. Other IDEs discover OAuth from the server and ignore them.
+	OAuth *MCPOAuth
+}
+
+// Frontmatter field names supported for commands.
+const (
+	FieldDescription  = "description"
+	FieldArgumentHint = "argument-hint"
+	FieldAllowedTools = "allowed-tools"
+	FieldModel        = "model"
+)
+
+// CommandMetadata describes optional command properties supported by IDEs as frontmatter.
+type CommandMetadata struct {
+	Description  string
+	ArgumentHint string
+	AllowedTools []string
+	Model        string
 }
 
 type SettingsInput struct {
 	Permissions    *adcp.Permissions
 	MCPServerNames []string
 	CommandNames   []string
+	// Ide is the full IDE configuration being materialized. Must not be modified.
+	Ide *adcp.Ide
+	// GenerationContext holds recipe-wide data such as prefetched content. May be nil. Must not be modified.
+	GenerationContext *core.GenerationContext
 }
 
 type IDESettings interface {
@@ -36,26 +150,125 @@ func (n *noOpSettings) Update(context.Context, SettingsInput) ([]*adcp.Materiali
 	return nil, nil
 }
 
-// Materialize converts an Ide configuration into a set of materialized files for Claude Code.
+// Capabilities reports the features this IDE configuration can represent.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	caps := recipes.Capabilities{
+		Commands:    i.CommandsFolder != "",
+		MemoryFiles: i.ContextPaths[DestinationMemory] != "",
+		Rules:       i.ContextPaths[DestinationRules] != "",
+		Docs:        i.ContextPaths[DestinationDocs] != "",
+		Agents:      i.AgentsFolder != "",
+		Skills:      i.SkillsFolder != "",
+	}
+	if i.MCPServersJSONPath != "" {
+		caps.MCPTransports = []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}
+	}
+	if ps, ok := i.Settings.(interface{ SupportsPermissions() bool }); ok {
+		caps.Permissions = ps.SupportsPermissions()
+	}
+	if hs, ok := i.Settings.(interface{ SupportsHooks() bool }); ok {
+		caps.Hooks = hs.SupportsHooks()
+	}
+	// Settings may write MCP servers into the IDE's settings file instead of MCPServersJSONPath.
+	if ms, ok := i.Settings.(interface{ SupportsMCP() bool }); ok && ms.SupportsMCP() {
+		caps.MCPTransports = []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}
+	}
+	return caps
+}
+
+// HasHooks reports whether Settings are configured with hooks.
+func (i *IDE) HasHooks() bool {
+	hc, ok := i.Settings.(interface{ HasHooks() bool })
+	return ok && hc.HasHooks()
+}
+
+// Materialize converts an Ide configuration into a set of materialized files for the IDE.
 // It produces:
-// - <CommandsFolder>/<name>.md files for each command
+// - <CommandsFolder>/<name><CommandExtension> files for each command
+// - <CommandsFolder>/.adcp-manifest.json listing the generated command files
+// - <AgentsFolder>/<name>.md files for each agent
+// - <SkillsFolder>/<name>/SKILL.md and supporting files for each skill
 // - <MCPServersJSONPath> for MCP server definitions
 // - settings updated/created by IDESettings
+//
+// In best-effort mode (see core.WithBestEffort), failed commands are skipped and returned as a
+// *core.MultiError together with the result.
 func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
 	if ide == nil {
 		return nil, fmt.Errorf("ide cannot be nil")
 	}
+	if i.Logger != nil {
+		ctx = core.WithLogger(ctx, i.Logger)
+	}
+	if i.FS != nil {
+		ctx = core.WithFS(ctx, i.FS)
+	}
 
 	var entries []*adcp.MaterializedResult_Entry
+	// In best-effort mode, failed commands are skipped and reported together with the result.
+	failures := &core.MultiError{}
 
-	// Commands -> <CommandsFolder>/commands/<name>.md
-	if ide.HasCommands() {
-		cmdEntries, err := i.materializeCommands(ctx, ide.GetCommands())
-		if err != nil {
+	commandNames, err := i.resolveCommandNames(ide.GetCommands())
+	if err != nil {
+		return nil, err
+	}
+
+	// Commands -> <CommandsFolder>/<name>.md. Without a folder, the IDE has no commands and recipes
+	// report them as unsupported (see Capabilities).
+	if ide.HasCommands() && i.CommandsFolder != "" {
+		cmdEntries, err := i.materializeCommands(ctx, ide.GetCommands(), commandNames)
+		if err != nil && !core.IsBestEffort(ctx) {
 			return nil, err
 		}
+		failures.Append(err)
 		entries = append(entries, cmdEntries...)
 	}
+	if i.CommandsFolder != "" {
+		var files []string
+		for _, name := range commandNames {
+			files = append(files, i.commandFileName(name))
+		}
+		manifest, err := i.materializeCommandsManifest(ctx, files)
+		if err != nil {
+			return nil, err
+		}
+		if manifest != nil {
+			core.RecordPlannedChange(ctx, core.PlannedChange{Path: manifest.GetFile().GetPath(), Merge: core.MergeReplace})
+			entries = append(entries, manifest)
+		}
+	}
+
+	if len(i.Agents) > 0 {
+		if i.AgentsFolder == "" {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningUnsupportedFeature,
+				Feature: recipes.FeatureAgents,
+				Message: "IDE does not support subagents; agents are ignored",
+			})
+		} else {
+			agentEntries, err := i.materializeAgents(ctx)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, agentEntries...)
+		}
+	}
+
+	if len(i.Skills) > 0 {
+		if i.SkillsFolder == "" {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningUnsupportedFeature,
+				Feature: recipes.FeatureSkills,
+				Message: "IDE does not support skills; skills are ignored",
+			})
+		} else {
+			skillEntries, err := i.materializeSkills(ctx)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, skillEntries...)
+		}
+	}
 
 	// Extract MCP server names for permissions
 	var mcpServerNames []string
@@ -63,15 +276,7 @@ func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.Materialize
 		for name := range ide.GetMcp().GetServers() {
 			mcpServerNames = append(mcpServerNames, name)
 		}
-	}
-	// Extract command names for permissions
-	var commandNames []string
-	if ide.HasCommands() {
-		for _, c := range ide.GetCommands().GetEntries() {
-			if c != nil && c.GetName() != "" {
-				commandNames = append(commandNames, c.GetName())
-			}
-		}
+		slices.Sort(mcpServerNames)
 	}
 	ideSett := i.Settings
 	if ideSett == nil {
@@ -81,71 +286,206 @@ func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.Materialize
 		Permissions:    ide.GetPermissions(),
 		MCPServerNames: mcpServerNames,
 		CommandNames:   commandNames,
+
+		Ide:               ide,
+		GenerationContext: core.GenerationContextFrom(ctx),
 	})
 	if err != nil {
 		return nil, err
 	}
 	entries = append(entries, settingEntries...)
 
-	mcpEntries, err := i.materializeMcp(ide.GetMcp())
+	mcpEntries, err := i.materializeMcp(ctx, ide.GetMcp())
 	if err != nil {
 		return nil, err
 	}
+	for _, e := range mcpEntries {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: e.GetFile().GetPath(), Merge: core.MergeJSON})
+	}
 	entries = append(entries, mcpEntries...)
 
-	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+	if agentsEntry := i.materializeAgentsMD(ctx); agentsEntry != nil {
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: agentsEntry.GetFile().GetPath(), Merge: core.MergeManagedBlock})
+		entries = append(entries, agentsEntry)
+	}
+
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), failures.ErrorOrNil()
 }
 
-func (i *IDE) materializeCommands(ctx context.Context, commands *adcp.Commands) ([]*adcp.MaterializedResult_Entry, error) {
-	var entries []*adcp.MaterializedResult_Entry
+// defaultCommandConcurrency bounds concurrent command fetches when CommandConcurrency is not set.
+const defaultCommandConcurrency = 8
+
+// materializeCommands fetches command bodies concurrently (bounded by CommandConcurrency) and
+// returns entries in command order. Failures of all commands are reported together as a *core.MultiError;
+// in best-effort mode the entries of successful commands are returned alongside it.
+func (i *IDE) materializeCommands(ctx context.Context, commands *adcp.Commands, names []string) ([]*adcp.MaterializedResult_Entry, error) {
 	if commands == nil {
-		return entries, nil
+		return nil, nil
 	}
 	cmds := commands.GetEntries()
-	for _, c := range cmds {
-		name := c.GetName()
-		if name == "" {
-			return nil, fmt.Errorf("command name cannot be empty")
-		}
+	for idx, c := range cmds {
 		if !c.HasFrom() {
-			return nil, fmt.Errorf("command %s must have a 'from' source", name)
+			return nil, fmt.Errorf("command %s must have a 'from' source", names[idx])
 		}
+	}
 
-		content, err := i.fetchCommandContent(ctx, c.GetFrom())
-		if err != nil {
-			return nil, fmt.Errorf("failed to materialize command %s: %w", name, err)
+	limit := i.CommandConcurrency
+	if limit <= 0 {
+		limit = defaultCommandConcurrency
+	}
+	sem := make(chan struct{}, limit)
+	results := make([]*adcp.MaterializedResult_Entry, len(cmds))
+	errs := make([]error, len(cmds))
+	var wg sync.WaitGroup
+	for idx, c := range cmds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = fmt.Errorf("failed to materialize command %s: %w", names[idx], ctx.Err())
+				return
+			}
+			observer := core.ObserverFrom(ctx)
+			observer.EntryStarted(core.PhaseIDE, names[idx])
+			results[idx], errs[idx] = i.materializeCommand(ctx, c, names[idx])
+			observer.EntryFinished(core.PhaseIDE, names[idx], errs[idx])
+		}()
+	}
+	wg.Wait()
+	multi := &core.MultiError{}
+	var entries []*adcp.MaterializedResult_Entry
+	for idx, c := range cmds {
+		if errs[idx] != nil {
+			multi.Append(&core.EntryError{
+				Path:   path.Join(i.CommandsFolder, i.commandFileName(names[idx])),
+				Source: c.GetFrom().WhichType().String(),
+				Err:    errs[idx],
+			})
+			continue
+		}
+		entries = append(entries, results[idx])
+		change := core.PlannedChange{Path: results[idx].GetFile().GetPath(), Merge: core.MergeReplace}
+		if c.GetFrom().WhichType() == adcp.CommandFrom_Cmd_case {
+			change.Note = "command not executed: " + c.GetFrom().GetCmd()
 		}
+		core.RecordPlannedChange(ctx, change)
+	}
+	if err := multi.ErrorOrNil(); err != nil && !core.IsBestEffort(ctx) {
+		return nil, err
+	}
+	return entries, multi.ErrorOrNil()
+}
 
-		path := fmt.Sprintf("%v/%s.md", i.CommandsFolder, name)
-		entries = append(entries, adcp.MaterializedResult_Entry_builder{
-			File: adcp.FullFileContent_builder{Path: path, Content: content}.Build(),
-		}.Build())
+func (i *IDE) materializeCommand(ctx context.Context, c *adcp.Command, name string) (*adcp.MaterializedResult_Entry, error) {
+	core.Logger(ctx).Debug("Materializing command", "name", name, "source", c.GetFrom().WhichType().String())
+	content, err := i.fetchCommandContent(ctx, c.GetFrom())
+	if err != nil {
+		return nil, fmt.Errorf("failed to materialize command %s: %w", name, err)
 	}
-	return entries, nil
+
+	path := fmt.Sprintf("%v/%s", i.CommandsFolder, i.commandFileName(name))
+	content, err = i.renderCommand(name, i.CommandMetadata[c.GetName()], content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render command %s: %w", name, err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: path, Content: content}.Build(),
+	}.Build(), nil
 }
 
-func (i *IDE) materializeMcp(mcp *adcp.Mcp) ([]*adcp.MaterializedResult_Entry, error) {
-	if mcp == nil || i.MCPServersJSONPath == "" {
+func (i *IDE) materializeMcp(ctx context.Context, mcp *adcp.Mcp) ([]*adcp.MaterializedResult_Entry, error) {
+	if i.MCPServersJSONPath == "" || (mcp == nil && len(i.RemovedMCPServers) == 0) {
+		return nil, nil
+	}
+	_, userFile := core.HomeRelative(i.MCPServersJSONPath)
+	if userFile && !UserFilesEnabled(ctx, i.MCPServersJSONPath, recipes.FeatureMCP, "MCP servers") {
 		return nil, nil
 	}
+	if mcp == nil {
+		// Servers can only be removed from an existing file.
+		if ReadExistingFile(ctx, i.MCPServersJSONPath) == "" {
+			return nil, nil
+		}
+		mcp = adcp.Mcp_builder{}.Build()
+	}
 	var entries []*adcp.MaterializedResult_Entry
-	// Read existing file content if it exists
-	existingContent := ""
-	if data, err := os.ReadFile(i.MCPServersJSONPath); err == nil {
-		existingContent = string(data)
+	for _, name := range slices.Sorted(maps.Keys(mcp.GetServers())) {
+		if s := mcp.GetServers()[name]; s == nil || !s.HasType() {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningIgnoredMCPServer,
+				Path:    i.MCPServersJSONPath,
+				Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+			})
+		}
 	}
+	serverOptions, err := ExpandServerOptions(ctx, i.MCPServerOptions)
+	if err != nil {
+		return nil, err
+	}
+	serverOptions = i.bearerTokenHeaders(ctx, mcp, serverOptions)
+	existingContent := ReadExistingJSON(ctx, i.MCPServersJSONPath)
 
-	mcpContent, err := buildMcpJSON(mcp, existingContent)
+	key := i.MCPServersKey
+	if key == "" {
+		key = "mcpServers"
+	}
+	convert := i.MCPServerFunc
+	if convert == nil {
+		convert = StandardMCPServer
+	}
+	mcpContent, err := buildMcpJSON(mcp, key, convert, serverOptions, i.RemovedMCPServers, existingContent)
 	if err != nil {
 		return nil, err
 	}
+	if userFile {
+		return append(entries, UserFileEntry(i.MCPServersJSONPath, mcpContent)), nil
+	}
 	entries = append(entries, adcp.MaterializedResult_Entry_builder{
 		File: adcp.FullFileContent_builder{Path: i.MCPServersJSONPath, Content: mcpContent}.Build(),
 	}.Build())
 	return entries, nil
 }
 
+// ExpandServerOptions resolves secret references (see core.ExpandSecrets) in env and header values.
+func ExpandServerOptions(ctx context.Context, opts map[string]MCPServerOptions) (map[string]MCPServerOptions, error) {
+	expanded := make(map[string]MCPServerOptions, len(opts))
+	for name, o := range opts {
+		env, err := core.ExpandSecretsMap(ctx, o.Env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand env of MCP server %s: %w", name, err)
+		}
+		headers, err := core.ExpandSecretsMap(ctx, o.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand headers of MCP server %s: %w", name, err)
+		}
+		o.Env, o.Headers = env, headers
+		expanded[name] = o
+	}
+	return expanded, nil
+}
+
+// materializeAgentsMD merges AgentInstructions into the managed section of AGENTS.md,
+// preserving any hand-written content around it.
+func (i *IDE) materializeAgentsMD(ctx context.Context) *adcp.MaterializedResult_Entry {
+	if i.AgentsMDPath == "" || strings.TrimSpace(i.AgentInstructions) == "" {
+		return nil
+	}
+	content := MergeManagedBlock(ReadExistingFile(ctx, i.AgentsMDPath), i.AgentInstructions)
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.AgentsMDPath, Content: content}.Build(),
+	}.Build()
+}
+
 func (i *IDE) fetchCommandContent(ctx context.Context, from *adcp.CommandFrom) (string, error) {
+	return FetchCommandContent(ctx, from)
+}
+
+// FetchCommandContent returns the body of a command from its source, e.g. for providers that
+// render commands themselves. In dry-run mode, commands are not executed.
+func FetchCommandContent(ctx context.Context, from *adcp.CommandFrom) (string, error) {
 	if from == nil || !from.HasType() {
 		return "", fmt.Errorf("command 'from' source cannot be nil")
 	}
@@ -154,6 +494,10 @@ func (i *IDE) fetchCommandContent(ctx context.Context, from *adcp.CommandFrom) (
 	case adcp.CommandFrom_Text_case:
 		return from.GetText(), nil
 	case adcp.CommandFrom_Cmd_case:
+		if core.IsDryRun(ctx) {
+			// Commands may have side effects, so a dry run renders a placeholder instead.
+			return fmt.Sprintf("<output of %s>\n", from.GetCmd()), nil
+		}
 		return utils.ExecuteCommand(ctx, from.GetCmd())
 	case adcp.CommandFrom_Github_case:
 		return utils.FetchGithub(ctx, from.GetGithub())
@@ -162,77 +506,188 @@ func (i *IDE) fetchCommandContent(ctx context.Context, from *adcp.CommandFrom) (
 	}
 }
 
+// renderCommand applies metadata to a command body: frontmatter restricted to CommandFrontmatterFields
+// and, when the command takes arguments, the arguments placeholder. Frontmatter embedded in the body
+// provides defaults for meta. If CommandTemplate is set, it renders the final file content.
+func (i *IDE) renderCommand(name string, meta CommandMetadata, body string) (string, error) {
+	embedded, body := splitCommandFrontmatter(body)
+	meta = meta.withDefaults(embedded)
+	if meta.ArgumentHint != "" && i.CommandArgumentsPlaceholder != "" && !strings.Contains(body, i.CommandArgumentsPlaceholder) {
+		body = strings.TrimRight(body, "\n") + "\n\n" + i.CommandArgumentsPlaceholder + "\n"
+	}
+	frontmatter := buildFrontmatter(meta, embedded, i.CommandFrontmatterFields)
+	if i.CommandTemplate == nil {
+		return frontmatter + body, nil
+	}
+	var buf strings.Builder
+	err := i.CommandTemplate.Execute(&buf, CommandTemplateData{
+		Name:        name,
+		Body:        body,
+		Frontmatter: frontmatter,
+		Metadata:    meta,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// commandFileName returns the file name for a resolved command name.
+func (i *IDE) commandFileName(name string) string {
+	ext := i.CommandExtension
+	if ext == "" {
+		ext = ".md"
+	}
+	return name + ext
+}
+
+// buildFrontmatter renders command metadata, after other fields of the embedded frontmatter (which
+// may be nil), as a YAML frontmatter block. Only fields listed in allowed are rendered; nil allows
+// all fields. Returns an empty string when no allowed metadata fields are set.
+func buildFrontmatter(meta CommandMetadata, embedded *frontmatter.Frontmatter, allowed []string) string {
+	fm := frontmatter.New().Merge(embedded).
+		Set(FieldDescription, meta.Description).
+		Set(FieldArgumentHint, meta.ArgumentHint).
+		Set(FieldAllowedTools, strings.Join(meta.AllowedTools, ", ")).
+		Set(FieldModel, meta.Model)
+	if allowed != nil {
+		fm.Filter(allowed)
+	}
+	if fm.Len() == 0 {
+		return ""
+	}
+	return fm.String() + "\n"
+}
+
+// splitCommandFrontmatter separates the frontmatter embedded in a command body, so that commands
+// can carry their own metadata. Bodies without valid frontmatter are returned unchanged with nil
+// frontmatter.
+func splitCommandFrontmatter(body string) (*frontmatter.Frontmatter, string) {
+	fm, rest, err := frontmatter.Parse(body)
+	if err != nil || fm.Len() == 0 {
+		return nil, body
+	}
+	return fm, rest
+}
+
+// withDefaults returns m with fields that are not set taken from fm, which may be nil. Allowed
+// tools may be a list or a comma-separated string.
+func (m CommandMetadata) withDefaults(fm *frontmatter.Frontmatter) CommandMetadata {
+	if fm == nil {
+		return m
+	}
+	str := func(key string) string {
+		v, _ := fm.Get(key)
+		s, _ := v.(string)
+		return s
+	}
+	if m.Description == "" {
+		m.Description = str(FieldDescription)
+	}
+	if m.ArgumentHint == "" {
+		m.ArgumentHint = str(FieldArgumentHint)
+	}
+	if m.Model == "" {
+		m.Model = str(FieldModel)
+	}
+	if len(m.AllowedTools) == 0 {
+		switch v, _ := fm.Get(FieldAllowedTools); tools := v.(type) {
+		case []string:
+			m.AllowedTools = tools
+		case string:
+			for _, t := range strings.Split(tools, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					m.AllowedTools = append(m.AllowedTools, t)
+				}
+			}
+		}
+	}
+	return m
+}
+
 type mcpServerConfig struct {
 	Type    string            `json:"type,omitempty"`
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
 	Url     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
-type mcpJson struct {
-	McpServers map[string]mcpServerConfig `json:"mcpServers"`
+// MCPServerFunc converts an MCP server of the recipe into the JSON value written under its name.
+// Returning nil skips the server.
+type MCPServerFunc func(server *adcp.McpServer, opts MCPServerOptions) any
+
+// StandardMCPServer converts server into the format shared by Claude Code, Cursor and VS Code:
+// {"type": "stdio", "command", "args", "env"} or {"type": "http", "url", "headers"}.
+func StandardMCPServer(s *adcp.McpServer, opts MCPServerOptions) any {
+	var srv mcpServerConfig
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if s.GetHttp() != nil {
+			srv.Type = "http"
+			srv.Url = s.GetHttp().GetUrl()
+			if len(opts.Headers) > 0 {
+				srv.Headers = opts.Headers
+			}
+		}
+	case adcp.McpServer_Stdio_case:
+		if s.GetStdio() != nil {
+			srv.Type = "stdio"
+			var env map[string]string
+			srv.Command, srv.Args, env = StdioCommand(s.GetStdio(), opts)
+			// Always include an env object for stdio servers
+			srv.Env = map[string]string{}
+			for k, v := range env {
+				srv.Env[k] = v
+			}
+		}
+	}
+	// If we set at least a type, keep the server
+	if srv.Type == "" && srv.Url == "" && srv.Command == "" {
+		return nil
+	}
+	return srv
 }
 
-func buildMcpJSON(mcp *adcp.Mcp, existingContent string) (string, error) {
+// buildMcpJSON merges the servers of mcp, converted by convert, into the object under key in
+// existingContent, and deletes the removed servers from it.
+func buildMcpJSON(mcp *adcp.Mcp, key string, convert MCPServerFunc, serverOptions map[string]MCPServerOptions, removed []string, existingContent string) (string, error) {
 	if mcp == nil {
 		return "", fmt.Errorf("mcp cannot be nil")
 	}
 
-	var cm mcpJson
+	// Parse existing content if provided, keeping unrelated keys and formatting.
+	// If parsing fails, start fresh.
+	doc, _ := merge.ParseDocument(existingContent)
+	servers := doc.Nested(key)
 
-	// Parse existing content if provided
-	if existingContent != "" {
-		if err := json.Unmarshal([]byte(existingContent), &cm); err != nil {
-			// If parsing fails, start fresh
-			cm = mcpJson{}
+	for _, name := range removed {
+		if _, declared := mcp.GetServers()[name]; declared {
+			return "", fmt.Errorf("mcp server %s cannot be both declared and removed", name)
 		}
+		servers.Delete(name)
 	}
 
-	// Ensure the map is initialized
-	if cm.McpServers == nil {
-		cm.McpServers = map[string]mcpServerConfig{}
-	}
-
-	// Add or update servers from the new configuration
-	for name, s := range mcp.GetServers() {
+	// Add or update servers from the new configuration, in a stable order
+	for _, name := range slices.Sorted(maps.Keys(mcp.GetServers())) {
+		s := mcp.GetServers()[name]
 		if s == nil || !s.HasType() {
 			continue
 		}
-		var srv mcpServerConfig
-		switch s.WhichType() {
-		case adcp.McpServer_Http_case:
-			if s.GetHttp() != nil {
-				srv.Type = "http"
-				srv.Url = s.GetHttp().GetUrl()
-			}
-		case adcp.McpServer_Stdio_case:
-			if s.GetStdio() != nil {
-				srv.Type = "stdio"
-				cmd := s.GetStdio().GetCommand()
-				// Split command into the executable and args by whitespace
-				if cmd != "" {
-					parts := strings.Fields(cmd)
-					if len(parts) > 0 {
-						srv.Command = parts[0]
-						if len(parts) > 1 {
-							srv.Args = parts[1:]
-						}
-					}
-				}
-				// Always include an env object for stdio servers
-				srv.Env = map[string]string{}
+		if srv := convert(s, serverOptions[name]); srv != nil {
+			if err := servers.Set(name, srv); err != nil {
+				return "", fmt.Errorf("failed to set mcp server %s: %w", name, err)
 			}
 		}
-		// If we set at least a type, keep the server
-		if srv.Type != "" || srv.Url != "" || srv.Command != "" {
-			cm.McpServers[name] = srv
-		}
+	}
+	if err := doc.Set(key, servers); err != nil {
+		return "", err
 	}
 
-	b, err := json.MarshalIndent(&cm, "", "  ")
+	out, err := doc.String()
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal mcp json: %w", err)
 	}
-	return string(b), nil
+	return out, nil
 }
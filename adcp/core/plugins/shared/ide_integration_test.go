@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,11 +29,7 @@ func TestIDE_Materialize_Mcp_MergeWithExisting(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing MCP file
 	existingMcp := `{
@@ -57,7 +54,7 @@ func TestIDE_Materialize_Mcp_MergeWithExisting(t *testing.T) {
 	}.Build()
 
 	// Execute
-	res, err := g.Materialize(context.Background(), ide)
+	res, err := g.Materialize(ctx, ide)
 	require.NoError(t, err)
 
 	var mcpContent string
@@ -105,11 +102,7 @@ func TestIDE_Materialize_Mcp_InvalidExistingJSON(t *testing.T) {
 	claudeDir := filepath.Join(tempDir, ".claude")
 	require.NoError(t, os.MkdirAll(claudeDir, 0755))
 
-	// Change to temp directory for the test
-	origDir, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Chdir(tempDir))
-	defer func() { _ = os.Chdir(origDir) }()
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
 
 	// Create existing MCP file with invalid JSON
 	invalidJSON := `{ "mcpServers": { "test": }`
@@ -124,7 +117,7 @@ func TestIDE_Materialize_Mcp_InvalidExistingJSON(t *testing.T) {
 	}.Build()
 
 	// Execute - should not error, just start fresh
-	res, err := g.Materialize(context.Background(), ide)
+	res, err := g.Materialize(ctx, ide)
 	require.NoError(t, err)
 
 	var mcpContent string
@@ -154,3 +147,40 @@ func TestIDE_Materialize_Mcp_InvalidExistingJSON(t *testing.T) {
 	assert.Equal(t, "devplan", parsed.McpServers["devplan"].Command)
 	assert.Equal(t, []string{"mcp"}, parsed.McpServers["devplan"].Args)
 }
+
+func TestIDE_Orphans_FromPreviousManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	commandsDir := filepath.Join(tempDir, ".claude", "commands")
+	require.NoError(t, os.MkdirAll(commandsDir, 0755))
+
+	ctx := core.WithFS(context.Background(), os.DirFS(tempDir))
+
+	// A previous run generated "old" and "keep"; "manual" was written by hand.
+	manifest := `{"generatedBy": "adcp", "commands": ["keep.md", "old.md"]}`
+	require.NoError(t, os.WriteFile(filepath.Join(commandsDir, ".adcp-manifest.json"), []byte(manifest), 0644))
+
+	g := getIDEInteg()
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "keep", From: adcp.CommandFrom_builder{Text: strPtr("k")}.Build()}.Build(),
+			adcp.Command_builder{Name: "new", From: adcp.CommandFrom_builder{Text: strPtr("n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	assert.Equal(t, []string{".claude/commands/old.md"}, g.Orphans(ctx, ide))
+
+	res, err := g.Materialize(ctx, ide)
+	require.NoError(t, err)
+	var manifestContent string
+	for _, e := range res.GetEntries() {
+		if e.GetFile().GetPath() == ".claude/commands/.adcp-manifest.json" {
+			manifestContent = e.GetFile().GetContent()
+		}
+	}
+	require.NotEmpty(t, manifestContent)
+	var parsed struct {
+		Commands []string `json:"commands"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(manifestContent), &parsed))
+	assert.Equal(t, []string{"keep.md", "new.md"}, parsed.Commands)
+}
@@ -1,10 +1,16 @@
 package shared
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"log/slog"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,3 +70,192 @@ func TestIDE_Materialize_Mcp(t *testing.T) {
 	assert.Equal(t, "devplan", parsed.McpServers["devplan"].Command)
 	assert.Equal(t, []string{"mcp"}, parsed.McpServers["devplan"].Args)
 }
+
+func strPtr(s string) *string {
+	return &s
+}
+
+type recordingSettings struct {
+	input SettingsInput
+}
+
+func (r *recordingSettings) Update(_ context.Context, input SettingsInput) ([]*adcp.MaterializedResult_Entry, error) {
+	r.input = input
+	return nil, nil
+}
+
+func TestIDE_Materialize_SettingsInput(t *testing.T) {
+	settings := &recordingSettings{}
+	g := getIDE()
+	g.Settings = settings
+
+	genCtx := &core.GenerationContext{Prefetched: map[string]*adcp.FetchedData{}}
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "run", From: adcp.CommandFrom_builder{Text: strPtr("x")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	_, err := g.Materialize(core.WithGenerationContext(context.Background(), genCtx), ide)
+	require.NoError(t, err)
+	assert.Same(t, ide, settings.input.Ide)
+	assert.Same(t, genCtx, settings.input.GenerationContext)
+	assert.Equal(t, []string{"run"}, settings.input.CommandNames)
+}
+
+func TestIDE_Materialize_DryRun(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+	g := getIDE()
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "gen", From: adcp.CommandFrom_builder{Cmd: strPtr("touch " + marker)}.Build()}.Build(),
+		}}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	plan := &core.Plan{}
+	res, err := g.Materialize(core.WithDryRun(context.Background(), plan), ide)
+	require.NoError(t, err)
+	assert.NoFileExists(t, marker)
+	assert.Equal(t, "<output of touch "+marker+">\n", res.GetEntries()[0].GetFile().GetContent())
+
+	changes := plan.Changes()
+	require.Len(t, changes, 3)
+	assert.Equal(t, core.MergeReplace, changes[0].Merge)
+	assert.Contains(t, changes[0].Note, "command not executed")
+	assert.Equal(t, core.MergeReplace, changes[1].Merge)
+	assert.Equal(t, core.PlannedChange{Path: ".mcp.json", Merge: core.MergeJSON}, changes[2])
+}
+
+func TestIDE_Materialize_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	g := getIDE()
+	g.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "run", From: adcp.CommandFrom_builder{Cmd: strPtr("echo hi")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	_, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Materializing command")
+	assert.Contains(t, buf.String(), "Executing command")
+	assert.Contains(t, buf.String(), `cmd="echo hi"`)
+}
+
+func TestNewIDE_FS(t *testing.T) {
+	fsys := fstest.MapFS{".mcp.json": {Data: []byte(`{"mcpServers": {"existing": {"type": "http", "url": "https://example.com"}}}`)}}
+	g := NewIDE(WithFS(fsys), WithCommandConcurrency(2))
+	g.MCPServersJSONPath = ".mcp.json"
+	assert.Equal(t, 2, g.CommandConcurrency)
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan"}.Build()}.Build(),
+		}}.Build(),
+	}.Build())
+	require.NoError(t, err)
+	content := res.GetEntries()[0].GetFile().GetContent()
+	assert.Contains(t, content, `"existing"`)
+	assert.Contains(t, content, `"devplan"`)
+}
+
+func TestIDE_Capabilities(t *testing.T) {
+	caps := getIDE().Capabilities()
+	assert.True(t, caps.Commands)
+	assert.False(t, caps.Permissions)
+	assert.Equal(t, []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}, caps.MCPTransports)
+
+	caps = (&IDE{}).Capabilities()
+	assert.False(t, caps.Commands)
+	assert.Empty(t, caps.MCPTransports)
+}
+
+func TestIDE_Materialize_McpSecrets(t *testing.T) {
+	t.Setenv("ADCP_TEST_API_KEY", "s3cret")
+	g := getIDE()
+	g.MCPServerOptions = map[string]MCPServerOptions{
+		"devplan": {Env: map[string]string{"API_KEY": "${secret:API_KEY}"}},
+		"github":  {Headers: map[string]string{"Authorization": "Bearer ${secret:API_KEY}"}},
+	}
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithSecrets(context.Background(), core.EnvSecrets{Prefix: "ADCP_TEST_"}), ide)
+	require.NoError(t, err)
+	content := res.GetEntries()[0].GetFile().GetContent()
+	assert.Contains(t, content, `"API_KEY": "s3cret"`)
+	assert.Contains(t, content, `"Authorization": "Bearer s3cret"`)
+
+	_, err = g.Materialize(context.Background(), ide)
+	assert.ErrorIs(t, err, core.ErrSecretNotFound)
+}
+
+func TestIDE_Materialize_McpBearerToken(t *testing.T) {
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"linear":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://mcp.linear.app/mcp"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	opts := map[string]MCPServerOptions{
+		"github":  {BearerTokenEnv: "GITHUB_TOKEN"},
+		"linear":  {BearerTokenEnv: "LINEAR_TOKEN", Headers: map[string]string{"Authorization": "Basic abc"}},
+		"devplan": {BearerTokenEnv: "DEVPLAN_TOKEN"},
+	}
+	var parsed struct {
+		McpServers map[string]struct {
+			Headers map[string]string `json:"headers"`
+		} `json:"mcpServers"`
+	}
+
+	g := getIDE()
+	g.MCPServerOptions = opts
+	g.EnvReference = VSCodeEnvReference
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(res.GetEntries()[0].GetFile().GetContent()), &parsed))
+	assert.Equal(t, map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}, parsed.McpServers["github"].Headers)
+	assert.Equal(t, map[string]string{"Authorization": "Basic abc"}, parsed.McpServers["linear"].Headers)
+	assert.Empty(t, parsed.McpServers["devplan"].Headers)
+
+	g.EnvReference = nil
+	report := core.NewReport()
+	res, err = g.Materialize(core.WithReport(context.Background(), report), ide)
+	require.NoError(t, err)
+	assert.NotContains(t, res.GetEntries()[0].GetFile().GetContent(), "GITHUB_TOKEN")
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningUnsupportedFeature, report.Data().Warnings[0].Code)
+}
+
+func TestIDE_Materialize_RemovedMcpServers(t *testing.T) {
+	g := getIDE()
+	g.RemovedMCPServers = []string{"old", "missing"}
+	fsys := fstest.MapFS{".mcp.json": {Data: []byte(`{"mcpServers": {"old": {"command": "old"}, "mine": {"command": "mine"}}}`)}}
+
+	res, err := g.Materialize(core.WithFS(context.Background(), fsys), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.JSONEq(t, `{"mcpServers": {"mine": {"command": "mine"}}}`, res.GetEntries()[0].GetFile().GetContent())
+
+	res, err = g.Materialize(core.WithFS(context.Background(), fstest.MapFS{}), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	assert.Empty(t, res.GetEntries(), "no file is created only to remove servers")
+
+	g.RemovedMCPServers = []string{"devplan"}
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	_, err = g.Materialize(core.WithFS(context.Background(), fsys), ide)
+	assert.ErrorContains(t, err, "mcp server devplan cannot be both declared and removed")
+}
@@ -0,0 +1,86 @@
+package shared
+
+import "strings"
+
+// Markers delimiting the section of a hand-edited markdown file that adcp owns.
+const (
+	ManagedBlockBegin = "<!-- adcp:begin -->"
+	ManagedBlockEnd   = "<!-- adcp:end -->"
+)
+
+// Markers delimiting the section of memory context in a file that also has a managed block of agent
+// instructions, such as AGENTS.md (see IDE.MergeContext).
+const (
+	ManagedMemoryBegin = "<!-- adcp:memory:begin -->"
+	ManagedMemoryEnd   = "<!-- adcp:memory:end -->"
+)
+
+// Markers delimiting the adcp-owned section of files using '#' line comments, such as ignore files.
+const (
+	ManagedLinesBegin = "# adcp:begin"
+	ManagedLinesEnd   = "# adcp:end"
+)
+
+// MergeManagedBlock returns existing with its managed block replaced by content.
+// If existing has no managed block, one is appended after the hand-written content.
+// Everything outside the markers is left untouched.
+func MergeManagedBlock(existing, content string) string {
+	return MergeManagedBlockWithMarkers(existing, content, ManagedBlockBegin, ManagedBlockEnd)
+}
+
+// MergeManagedBlockWithMarkers is MergeManagedBlock using custom begin/end markers.
+func MergeManagedBlockWithMarkers(existing, content, beginMarker, endMarker string) string {
+	block := beginMarker + "\n" + strings.TrimRight(content, "\n") + "\n" + endMarker
+
+	begin := strings.Index(existing, beginMarker)
+	if begin >= 0 {
+		if end := strings.Index(existing[begin:], endMarker); end >= 0 {
+			end += begin + len(endMarker)
+			return existing[:begin] + block + existing[end:]
+		}
+	}
+
+	if strings.TrimSpace(existing) == "" {
+		return block + "\n"
+	}
+	return strings.TrimRight(existing, "\n") + "\n\n" + block + "\n"
+}
+
+// ManagedSection returns the content between beginMarker and endMarker in content, and false if
+// content has no such section.
+func ManagedSection(content, beginMarker, endMarker string) (string, bool) {
+	begin := strings.Index(content, beginMarker)
+	if begin < 0 {
+		return "", false
+	}
+	body := content[begin+len(beginMarker):]
+	end := strings.Index(body, endMarker)
+	if end < 0 {
+		return "", false
+	}
+	return strings.TrimPrefix(body[:end], "\n"), true
+}
+
+// RemoveManagedBlockWithMarkers returns existing without its section delimited by beginMarker and
+// endMarker, including the blank line separating it from hand-written content. Content without
+// such a section is returned unchanged.
+func RemoveManagedBlockWithMarkers(existing, beginMarker, endMarker string) string {
+	begin := strings.Index(existing, beginMarker)
+	if begin < 0 {
+		return existing
+	}
+	end := strings.Index(existing[begin:], endMarker)
+	if end < 0 {
+		return existing
+	}
+	end += begin + len(endMarker)
+	before := strings.TrimRight(existing[:begin], "\n")
+	after := strings.TrimLeft(existing[end:], "\n")
+	switch {
+	case before == "":
+		return after
+	case after == "":
+		return before + "\n"
+	}
+	return before + "\n\n" + after
+}
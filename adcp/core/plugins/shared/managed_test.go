@@ -0,0 +1,94 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeManagedBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		content  string
+		want     string
+	}{
+		{
+			name:    "empty file",
+			content: "Use gofmt.",
+			want:    "<!-- adcp:begin -->\nUse gofmt.\n<!-- adcp:end -->\n",
+		},
+		{
+			name:     "appends after hand-written content",
+			existing: "# Project\n\nHand written.\n",
+			content:  "Use gofmt.\n",
+			want:     "# Project\n\nHand written.\n\n<!-- adcp:begin -->\nUse gofmt.\n<!-- adcp:end -->\n",
+		},
+		{
+			name:     "replaces existing block only",
+			existing: "# Project\n\n<!-- adcp:begin -->\nOld.\n<!-- adcp:end -->\n\nFooter.\n",
+			content:  "New.",
+			want:     "# Project\n\n<!-- adcp:begin -->\nNew.\n<!-- adcp:end -->\n\nFooter.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeManagedBlock(tt.existing, tt.content)
+			assert.Equal(t, tt.want, got)
+			// Re-running must be idempotent.
+			assert.Equal(t, got, MergeManagedBlock(got, tt.content))
+		})
+	}
+}
+
+func TestMergeManagedBlockWithMarkers(t *testing.T) {
+	existing := "node_modules/\n# adcp:begin\nold/\n# adcp:end\n"
+	got := MergeManagedBlockWithMarkers(existing, "dist/\n.env", ManagedLinesBegin, ManagedLinesEnd)
+	assert.Equal(t, "node_modules/\n# adcp:begin\ndist/\n.env\n# adcp:end\n", got)
+}
+
+func TestManagedSection(t *testing.T) {
+	body, ok := ManagedSection("# Project\n\n<!-- adcp:begin -->\nUse gofmt.\n<!-- adcp:end -->\n", ManagedBlockBegin, ManagedBlockEnd)
+	assert.True(t, ok)
+	assert.Equal(t, "Use gofmt.\n", body)
+
+	_, ok = ManagedSection("# Project\n", ManagedBlockBegin, ManagedBlockEnd)
+	assert.False(t, ok)
+}
+
+func TestRemoveManagedBlockWithMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name:     "only the block",
+			existing: "# adcp:begin\ndist/\n# adcp:end\n",
+			want:     "",
+		},
+		{
+			name:     "after hand-written content",
+			existing: "node_modules/\n\n# adcp:begin\ndist/\n# adcp:end\n",
+			want:     "node_modules/\n",
+		},
+		{
+			name:     "between hand-written content",
+			existing: "node_modules/\n# adcp:begin\ndist/\n# adcp:end\n\n.idea/\n",
+			want:     "node_modules/\n\n.idea/\n",
+		},
+		{
+			name:     "without block",
+			existing: "node_modules/\n",
+			want:     "node_modules/\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RemoveManagedBlockWithMarkers(tt.existing, ManagedLinesBegin, ManagedLinesEnd)
+			assert.Equal(t, tt.want, got)
+			// Merging the block back in restores a file with the block.
+			assert.Contains(t, MergeManagedBlockWithMarkers(got, "dist/", ManagedLinesBegin, ManagedLinesEnd), "# adcp:begin\ndist/\n# adcp:end\n")
+		})
+	}
+}
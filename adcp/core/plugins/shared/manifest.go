@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// commandsManifestPath returns the core.Manifest inside CommandsFolder listing the command files
+// adcp generated. Only files listed there are ever considered for cleanup, so hand-written
+// commands are never touched.
+func (i *IDE) commandsManifestPath() string {
+	return path.Join(i.CommandsFolder, core.ManifestName)
+}
+
+// readCommandsManifest returns the command files recorded by a previous materialization, if any.
+func (i *IDE) readCommandsManifest(ctx context.Context) []string {
+	data, err := core.ReadFile(ctx, i.commandsManifestPath())
+	if err != nil {
+		return nil
+	}
+	var m core.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m.Commands
+}
+
+// materializeCommandsManifest records the generated command files so later runs can detect orphans.
+// No manifest is written when there are no commands and none were generated before.
+func (i *IDE) materializeCommandsManifest(ctx context.Context, files []string) (*adcp.MaterializedResult_Entry, error) {
+	if len(files) == 0 && len(i.readCommandsManifest(ctx)) == 0 {
+		return nil, nil
+	}
+	files = slices.Clone(files)
+	slices.Sort(files)
+	content, err := merge.Marshal(core.Manifest{GeneratedBy: core.ManifestGeneratedBy, Commands: files})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal commands manifest: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.commandsManifestPath(), Content: content}.Build(),
+	}.Build(), nil
+}
+
+// Orphans returns paths of command files generated by a previous materialization that the given
+// Ide configuration no longer produces. MaterializedResult cannot express deletions, so callers
+// remove these explicitly, e.g. with core.RemoveFiles. The manifest is read from FS, or else the
+// filesystem carried by ctx (see core.WithFS).
+func (i *IDE) Orphans(ctx context.Context, ide *adcp.Ide) []string {
+	current := map[string]bool{}
+	for _, c := range ide.GetCommands().GetEntries() {
+		if name, err := i.resolveCommandName(c.GetName()); err == nil {
+			current[i.commandFileName(name)] = true
+		}
+	}
+	var orphans []string
+	if i.FS != nil {
+		ctx = core.WithFS(ctx, i.FS)
+	}
+	for _, f := range i.readCommandsManifest(ctx) {
+		if !current[f] && fs.ValidPath(f) {
+			orphans = append(orphans, path.Join(i.CommandsFolder, f))
+		}
+	}
+	return orphans
+}
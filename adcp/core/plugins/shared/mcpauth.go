@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// MCPOAuth holds the OAuth client settings of an HTTP MCP server.
+type MCPOAuth struct {
+	// ClientID identifies the client registered with the authorization server.
+	ClientID string
+	// ClientSecretEnv names the environment variable holding the client secret, if any. Only a
+	// reference to the variable is written, never the secret.
+	ClientSecretEnv string
+	// Scopes are the scopes requested during authorization.
+	Scopes []string
+}
+
+// EnvReference formats a reference to an environment variable as "${NAME}", as expanded by
+// Claude Code and Gemini CLI.
+func EnvReference(name string) string {
+	return "${" + name + "}"
+}
+
+// VSCodeEnvReference formats a reference to an environment variable as "${env:NAME}", as expanded
+// by VS Code and Cursor.
+func VSCodeEnvReference(name string) string {
+	return "${env:" + name + "}"
+}
+
+// bearerTokenHeaders returns opts with an Authorization header referencing the bearer token of
+// every HTTP server of mcp that has one. Without EnvReference, bearer tokens are ignored with a
+// warning.
+func (i *IDE) bearerTokenHeaders(ctx context.Context, mcp *adcp.Mcp, opts map[string]MCPServerOptions) map[string]MCPServerOptions {
+	for _, name := range slices.Sorted(maps.Keys(opts)) {
+		o := opts[name]
+		s := mcp.GetServers()[name]
+		if o.BearerTokenEnv == "" || s.WhichType() != adcp.McpServer_Http_case || o.Headers["Authorization"] != "" {
+			continue
+		}
+		if i.EnvReference == nil {
+			core.Warn(ctx, core.Warning{
+				Code:    core.WarningUnsupportedFeature,
+				Path:    i.MCPServersJSONPath,
+				Feature: recipes.FeatureMCP,
+				Message: fmt.Sprintf("IDE cannot reference environment variables; bearer token of MCP server %s is ignored", name),
+			})
+			continue
+		}
+		headers := maps.Clone(o.Headers)
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers["Authorization"] = "Bearer " + i.EnvReference(o.BearerTokenEnv)
+		o.Headers = headers
+		opts[name] = o
+	}
+	return opts
+}
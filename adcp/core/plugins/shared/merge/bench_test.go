@@ -0,0 +1,58 @@
+package merge
+
+import (
+	"fmt"
+	"testing"
+)
+
+func BenchmarkUniqueStrings(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		existing := make([]string, n)
+		added := make([]string, n)
+		for i := range n {
+			existing[i] = fmt.Sprintf("Bash(npm run task-%d:*)", i)
+			added[i] = fmt.Sprintf("Bash(npm run task-%d:*)", i+n/2)
+		}
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				UniqueStrings(existing, added)
+			}
+		})
+	}
+}
+
+func BenchmarkDeep(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		existing := make([]string, n)
+		added := make([]string, n)
+		for i := range n {
+			existing[i] = fmt.Sprintf("Bash(npm run task-%d:*)", i)
+			added[i] = fmt.Sprintf("Bash(npm run task-%d:*)", i+n/2)
+		}
+		base := NewObject()
+		perms := NewObject()
+		_ = perms.Set("allow", existing)
+		_ = base.Set("permissions", perms)
+		content, err := base.MarshalIndent(DefaultIndent)
+		if err != nil {
+			b.Fatal(err)
+		}
+		patchPerms := NewObject()
+		_ = patchPerms.Set("allow", added)
+		patch := NewObject()
+		_ = patch.Set("permissions", patchPerms)
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				doc, _ := ParseDocument(content)
+				if err := Deep(doc.Object, patch, &Tracker{}); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := doc.String(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
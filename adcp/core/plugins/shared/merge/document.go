@@ -0,0 +1,115 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// span locates a value in the source of a Document.
+type span struct {
+	start, end int
+}
+
+// source is the content a Document was parsed from, used to keep unchanged parts byte for byte.
+type source struct {
+	data []byte
+	keys []string
+	// values holds the compact top-level values as parsed, to detect changed ones.
+	values map[string]json.RawMessage
+	spans  map[string]span
+}
+
+// parseSource records the location of every top-level value of data. It returns nil if data is not
+// a JSON object, or spans a single line, where keeping it would mix formatting styles.
+func parseSource(data []byte) *source {
+	if !bytes.Contains(data, []byte("\n")) {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	src := &source{data: data, values: map[string]json.RawMessage{}, spans: map[string]span{}}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil
+		}
+		if _, dup := src.spans[key]; dup {
+			return nil
+		}
+		end := int(dec.InputOffset())
+		src.keys = append(src.keys, key)
+		src.values[key] = compact(raw)
+		src.spans[key] = span{start: end - len(raw), end: end}
+	}
+	if len(src.keys) == 0 {
+		return nil
+	}
+	return src
+}
+
+// splice renders o by copying the source and replacing only the top-level values that changed, so
+// that unchanged values keep their exact formatting. New keys are appended after the last one. It
+// returns false if keys were removed or reordered, in which case the document is rendered anew.
+func (s *source) splice(o *Object, f Format) (string, bool) {
+	if s == nil || len(o.keys) < len(s.keys) || !slices.Equal(o.keys[:len(s.keys)], s.keys) {
+		return "", false
+	}
+	indent := f.Indent
+	if indent == "" {
+		indent = DefaultIndent
+	}
+	newline := "\n"
+	if f.CRLF {
+		newline = "\r\n"
+	}
+	render := func(raw json.RawMessage) (string, bool) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, indent, indent); err != nil {
+			return "", false
+		}
+		return strings.ReplaceAll(buf.String(), "\n", newline), true
+	}
+
+	var out strings.Builder
+	cursor := 0
+	for _, key := range s.keys {
+		value := o.values[key]
+		if bytes.Equal(value, s.values[key]) {
+			continue
+		}
+		rendered, ok := render(value)
+		if !ok {
+			return "", false
+		}
+		sp := s.spans[key]
+		out.Write(s.data[cursor:sp.start])
+		out.WriteString(rendered)
+		cursor = sp.end
+	}
+	last := s.spans[s.keys[len(s.keys)-1]].end
+	out.Write(s.data[cursor:last])
+	for _, key := range o.keys[len(s.keys):] {
+		encodedKey, err := marshalStable(key)
+		if err != nil {
+			return "", false
+		}
+		rendered, ok := render(o.values[key])
+		if !ok {
+			return "", false
+		}
+		out.WriteString("," + newline + indent)
+		out.Write(encodedKey)
+		out.WriteString(": " + rendered)
+	}
+	out.Write(s.data[last:])
+	return out.String(), true
+}
@@ -0,0 +1,77 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Format controls how generated JSON is rendered. Output is deterministic: map keys are sorted,
+// struct fields and Object keys keep their declaration order, and HTML characters are not escaped,
+// so the same input yields byte-identical files on every platform.
+type Format struct {
+	// Indent is the indentation unit. Empty means DefaultIndent.
+	Indent string
+	// CRLF renders line breaks as "\r\n" instead of "\n".
+	CRLF bool
+	// TrailingNewline appends a line break after the closing brace.
+	TrailingNewline bool
+}
+
+// DefaultFormat is the format used for newly created files.
+var DefaultFormat = Format{Indent: DefaultIndent, TrailingNewline: true}
+
+// DetectFormat returns the format of existing JSON content, so rewriting it does not introduce
+// formatting-only changes.
+func DetectFormat(data []byte) Format {
+	return Format{
+		Indent:          DetectIndent(data),
+		CRLF:            bytes.Contains(data, []byte("\r\n")),
+		TrailingNewline: bytes.HasSuffix(data, []byte("\n")),
+	}
+}
+
+// Marshal renders v as indented JSON using the format.
+func (f Format) Marshal(v any) (string, error) {
+	raw, err := marshalStable(v)
+	if err != nil {
+		return "", err
+	}
+	indent := f.Indent
+	if indent == "" {
+		indent = DefaultIndent
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", indent); err != nil {
+		return "", err
+	}
+	if f.TrailingNewline {
+		buf.WriteByte('\n')
+	}
+	out := buf.String()
+	if f.CRLF {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	return out, nil
+}
+
+// Marshal renders v with DefaultFormat.
+func Marshal(v any) (string, error) {
+	return DefaultFormat.Marshal(v)
+}
+
+// marshalStable encodes v in compact form without HTML escaping, so characters such as '&' and '<'
+// in commands or URLs are kept verbatim.
+func marshalStable(v any) ([]byte, error) {
+	if o, ok := v.(*Object); ok {
+		// Already compact; skip the encoder's validation pass over the whole document.
+		return o.MarshalJSON()
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
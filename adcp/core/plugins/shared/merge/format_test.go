@@ -0,0 +1,61 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_Marshal(t *testing.T) {
+	ordered := NewObject()
+	require.NoError(t, ordered.Set("z", 1))
+	require.NoError(t, ordered.Set("a", map[string]any{"y": "b && c", "x": "<tag>"}))
+
+	tests := []struct {
+		name   string
+		format Format
+		value  any
+		want   string
+	}{
+		{
+			name:   "sorted map keys",
+			format: DefaultFormat,
+			value:  map[string]int{"b": 2, "a": 1},
+			want:   "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+		},
+		{
+			name:   "object keeps order and html is not escaped",
+			format: Format{Indent: "\t"},
+			value:  ordered,
+			want:   "{\n\t\"z\": 1,\n\t\"a\": {\n\t\t\"x\": \"<tag>\",\n\t\t\"y\": \"b && c\"\n\t}\n}",
+		},
+		{
+			name:   "crlf",
+			format: Format{CRLF: true, TrailingNewline: true},
+			value:  map[string]int{"a": 1},
+			want:   "{\r\n  \"a\": 1\r\n}\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.format.Marshal(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, Format{Indent: "    ", CRLF: true, TrailingNewline: true}, DetectFormat([]byte("{\r\n    \"a\": 1\r\n}\r\n")))
+	assert.Equal(t, Format{Indent: DefaultIndent}, DetectFormat([]byte(`{"a": 1}`)))
+}
+
+func TestDocument_PreservesCRLF(t *testing.T) {
+	doc, ok := ParseDocument("{\r\n  \"a\": 1\r\n}\r\n")
+	require.True(t, ok)
+	require.NoError(t, doc.Set("b", 2))
+	out, err := doc.String()
+	require.NoError(t, err)
+	assert.Equal(t, "{\r\n  \"a\": 1,\r\n  \"b\": 2\r\n}\r\n", out)
+}
@@ -0,0 +1,33 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", `{"a": 1}`, `{"a": 1}`},
+		{"line comment", "{\n  // theme\n  \"a\": 1\n}", "{\n  \n  \"a\": 1\n}"},
+		{"block comment", `{/* x */"a": 1}`, `{"a": 1}`},
+		{"trailing commas", "{\"a\": [1, 2,],\n}", "{\"a\": [1, 2]\n}"},
+		{"comment markers in strings", `{"url": "https://example.com/*x*/", "q": "a\"//b"}`, `{"url": "https://example.com/*x*/", "q": "a\"//b"}`},
+		{"unterminated block", `{"a": 1} /* x`, `{"a": 1} `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(StripJSONComments([]byte(tt.in))))
+		})
+	}
+}
+
+func TestParseDocument_JSONC(t *testing.T) {
+	doc, ok := ParseDocument("{\n  // Zed settings\n  \"theme\": \"One Dark\",\n}\n")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"theme"}, doc.Keys())
+}
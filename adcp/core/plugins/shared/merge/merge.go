@@ -0,0 +1,278 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Document is an existing JSON file parsed for merging, along with the formatting to restore on output.
+type Document struct {
+	*Object
+	format Format
+	// source keeps the parsed content, so that values adcp does not change stay byte for byte
+	// identical. It is nil for new documents and for content with comments.
+	source *source
+}
+
+// ParseDocument parses existing file content, which may contain comments (see StripJSONComments).
+// Empty or invalid content yields an empty document, and ok reports whether existing content was
+// parsed successfully.
+func ParseDocument(existingContent string) (doc *Document, ok bool) {
+	doc = &Document{Object: NewObject(), format: Format{Indent: DefaultIndent}}
+	if existingContent == "" {
+		return doc, true
+	}
+	parsed, err := ParseObject([]byte(existingContent))
+	if err != nil {
+		// Settings of some IDEs allow comments, which are lost on merge but keep their settings.
+		if parsed, err = ParseObject(StripJSONComments([]byte(existingContent))); err != nil {
+			return doc, false
+		}
+	} else {
+		doc.source = parseSource([]byte(existingContent))
+	}
+	doc.Object = parsed
+	doc.format = DetectFormat([]byte(existingContent))
+	return doc, true
+}
+
+// String renders the document with the formatting of the content it was parsed from. Top-level
+// values that were not changed are copied from that content byte for byte, as long as no keys were
+// removed; otherwise the whole document is rendered with the detected format.
+func (d *Document) String() (string, error) {
+	if out, ok := d.source.splice(d.Object, d.format); ok {
+		return out, nil
+	}
+	return d.format.Marshal(d.Object)
+}
+
+// UniqueStrings merges two string slices, removing duplicates while keeping
+// existing items first and in their original order.
+func UniqueStrings(existing, new []string) []string {
+	seen := make(map[string]struct{}, len(existing)+len(new))
+	result := make([]string, 0, len(existing)+len(new))
+
+	// Add existing items first
+	for _, s := range existing {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			result = append(result, s)
+		}
+	}
+
+	// Add new items that aren't duplicates
+	for _, s := range new {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// Tracker records the JSON paths and list entries adcp contributed during merges.
+// Paths use dot notation, e.g. "permissions.allow".
+type Tracker struct {
+	Paths map[string][]string
+}
+
+// Record marks path as managed, optionally with the list entries contributed under it.
+func (t *Tracker) Record(path string, values ...string) {
+	if t == nil {
+		return
+	}
+	if t.Paths == nil {
+		t.Paths = map[string][]string{}
+	}
+	t.Paths[path] = UniqueStrings(t.Paths[path], values)
+}
+
+// Deep merges patch into dst. Nested objects are merged recursively, arrays are unioned with
+// duplicates removed (existing entries first), and any other value in patch replaces the existing one.
+// Keys present only in dst are preserved. Contributions are recorded in tracker when it is not nil.
+func Deep(dst, patch *Object, tracker *Tracker) error {
+	return deep(dst, patch, tracker, "")
+}
+
+func deep(dst, patch *Object, tracker *Tracker, prefix string) error {
+	for _, key := range patch.keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		src := patch.values[key]
+		existing, exists := dst.values[key]
+		switch {
+		case exists && isKind(existing, '{') && isKind(src, '{'):
+			nestedDst, err := ParseObject(existing)
+			if err != nil {
+				return err
+			}
+			nestedPatch, err := ParseObject(src)
+			if err != nil {
+				return err
+			}
+			if err := deep(nestedDst, nestedPatch, tracker, path); err != nil {
+				return err
+			}
+			merged, err := nestedDst.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			dst.values[key] = merged
+		case exists && isKind(existing, '[') && isKind(src, '['):
+			merged, contributed, err := unionArrays(existing, src)
+			if err != nil {
+				return err
+			}
+			dst.values[key] = merged
+			tracker.Record(path, contributed...)
+		default:
+			if _, exists := dst.values[key]; !exists {
+				dst.keys = append(dst.keys, key)
+			}
+			dst.values[key] = src
+			record(tracker, path, src)
+		}
+	}
+	return nil
+}
+
+// record marks path as managed with the entries of src if it is an array, and the paths nested in
+// src if it is an object.
+func record(tracker *Tracker, path string, src json.RawMessage) {
+	if tracker == nil {
+		return
+	}
+	var values []string
+	switch {
+	case isKind(src, '['):
+		var items []json.RawMessage
+		if err := json.Unmarshal(src, &items); err == nil {
+			for _, item := range items {
+				values = append(values, string(item))
+			}
+		}
+	case isKind(src, '{'):
+		if nested, err := ParseObject(src); err == nil {
+			for _, key := range nested.keys {
+				record(tracker, path+"."+key, nested.values[key])
+			}
+		}
+	}
+	tracker.Record(path, values...)
+}
+
+// Prune removes entries from the array at path of dst, a dot-separated path as recorded by
+// Tracker. Entries are raw JSON values, also as recorded by Tracker, e.g. `"Bash(ls)"`. Missing
+// paths, values other than arrays and entries not present are ignored.
+func Prune(dst *Object, path string, entries []string) error {
+	_, err := prune(dst, path, entries)
+	return err
+}
+
+// prune reports whether dst was changed, so that unchanged values keep their exact bytes.
+func prune(dst *Object, path string, entries []string) (bool, error) {
+	key, rest, nested := strings.Cut(path, ".")
+	raw, ok := dst.values[key]
+	if !ok {
+		return false, nil
+	}
+	if nested {
+		if !isKind(raw, '{') {
+			return false, nil
+		}
+		obj, err := ParseObject(raw)
+		if err != nil {
+			return false, err
+		}
+		changed, err := prune(obj, rest, entries)
+		if err != nil || !changed {
+			return false, err
+		}
+		merged, err := obj.MarshalJSON()
+		if err != nil {
+			return false, err
+		}
+		dst.values[key] = merged
+		return true, nil
+	}
+	if !isKind(raw, '[') {
+		return false, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return false, err
+	}
+	kept := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		if !slices.Contains(entries, string(compact(item))) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(items) {
+		return false, nil
+	}
+	pruned, err := json.Marshal(kept)
+	if err != nil {
+		return false, err
+	}
+	dst.values[key] = pruned
+	return true, nil
+}
+
+// unionArrays appends items of src not already present in dst. Both arrays are compact (see
+// Object), so items are compared byte for byte.
+func unionArrays(dst, src json.RawMessage) (json.RawMessage, []string, error) {
+	var existing, patch []json.RawMessage
+	if err := json.Unmarshal(dst, &existing); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(src, &patch); err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]struct{}, len(existing)+len(patch))
+	out := make([]byte, 0, len(dst)+len(src))
+	out = append(out, '[')
+	for _, item := range existing {
+		seen[string(item)] = struct{}{}
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		out = append(out, item...)
+	}
+	contributed := make([]string, 0, len(patch))
+	for _, item := range patch {
+		key := string(item)
+		contributed = append(contributed, key)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		out = append(out, item...)
+	}
+	return append(out, ']'), contributed, nil
+}
+
+// compact returns raw without insignificant whitespace, or raw itself if it has none.
+func compact(raw json.RawMessage) json.RawMessage {
+	if !bytes.ContainsAny(raw, " \t\r\n") {
+		return raw
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+func isKind(raw json.RawMessage, delim byte) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && trimmed[0] == delim
+}
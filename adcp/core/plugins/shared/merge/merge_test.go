@@ -0,0 +1,123 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_PreservesOrderAndFormatting(t *testing.T) {
+	existing := "{\n\t\"b\": 1,\n\t\"a\": {\n\t\t\"x\": true\n\t}\n}\n"
+	doc, ok := ParseDocument(existing)
+	require.True(t, ok)
+
+	require.NoError(t, doc.Set("c", "new"))
+	out, err := doc.String()
+	require.NoError(t, err)
+	assert.Equal(t, "{\n\t\"b\": 1,\n\t\"a\": {\n\t\t\"x\": true\n\t},\n\t\"c\": \"new\"\n}\n", out)
+}
+
+func TestParseDocument_Invalid(t *testing.T) {
+	doc, ok := ParseDocument(`{ "a": `)
+	assert.False(t, ok)
+	assert.Empty(t, doc.Keys())
+}
+
+func TestDeep(t *testing.T) {
+	dst, err := ParseObject([]byte(`{"keep": 1, "perms": {"allow": ["a", "b"], "mode": "x"}, "list": [1]}`))
+	require.NoError(t, err)
+	patch, err := ParseObject([]byte(`{"perms": {"allow": ["b", "c"], "mode": "y"}, "list": "scalar", "added": {"k": "v"}}`))
+	require.NoError(t, err)
+
+	var tracker Tracker
+	require.NoError(t, Deep(dst, patch, &tracker))
+
+	b, err := dst.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"keep":1,"perms":{"allow":["a","b","c"],"mode":"y"},"list":"scalar","added":{"k":"v"}}`, string(b))
+	assert.Equal(t, []string{"keep", "perms", "list", "added"}, dst.Keys())
+	assert.Equal(t, []string{`"b"`, `"c"`}, tracker.Paths["perms.allow"])
+	assert.Contains(t, tracker.Paths, "perms.mode")
+	assert.Contains(t, tracker.Paths, "added")
+	assert.Contains(t, tracker.Paths, "added.k")
+}
+
+func TestPrune(t *testing.T) {
+	dst, err := ParseObject([]byte(`{"perms": {"allow": ["a", "b", {"c": 1}], "mode": "x"}, "list": [1, 2], "scalar": "a"}`))
+	require.NoError(t, err)
+
+	require.NoError(t, Prune(dst, "perms.allow", []string{`"a"`, `{"c":1}`, `"missing"`}))
+	require.NoError(t, Prune(dst, "list", []string{"2"}))
+	require.NoError(t, Prune(dst, "scalar", []string{`"a"`}))
+	require.NoError(t, Prune(dst, "missing.allow", []string{`"a"`}))
+
+	b, err := dst.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"perms":{"allow":["b"],"mode":"x"},"list":[1],"scalar":"a"}`, string(b))
+}
+
+func TestUniqueStrings(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, UniqueStrings([]string{"a", "b", "a"}, []string{"c", "b"}))
+	assert.Equal(t, []string{}, UniqueStrings(nil, nil))
+}
+
+func TestDocument_KeepsUnchangedValuesByteForByte(t *testing.T) {
+	existing := "{\n" +
+		"  \"hooks\": {\"PreToolUse\": [ {\"matcher\": \"Bash\"} ]},\n" +
+		"  \"permissions\": {\n    \"allow\": [\"Bash(ls)\"]\n  },\n" +
+		"  \"statusLine\":   {\"type\": \"command\"}\n" +
+		"}\n"
+	tests := []struct {
+		name   string
+		change func(t *testing.T, doc *Document)
+		want   string
+	}{
+		{
+			name:   "no changes",
+			change: func(*testing.T, *Document) {},
+			want:   existing,
+		},
+		{
+			name: "unchanged merge",
+			change: func(t *testing.T, doc *Document) {
+				patch, err := ParseObject([]byte(`{"permissions": {"allow": ["Bash(ls)"]}}`))
+				require.NoError(t, err)
+				require.NoError(t, Deep(doc.Object, patch, nil))
+			},
+			want: existing,
+		},
+		{
+			name: "changed and added values",
+			change: func(t *testing.T, doc *Document) {
+				patch, err := ParseObject([]byte(`{"permissions": {"allow": ["Bash(go test:*)"]}, "env": {"A": "1"}}`))
+				require.NoError(t, err)
+				require.NoError(t, Deep(doc.Object, patch, nil))
+			},
+			want: "{\n" +
+				"  \"hooks\": {\"PreToolUse\": [ {\"matcher\": \"Bash\"} ]},\n" +
+				"  \"permissions\": {\n    \"allow\": [\n      \"Bash(ls)\",\n      \"Bash(go test:*)\"\n    ]\n  },\n" +
+				"  \"statusLine\":   {\"type\": \"command\"},\n" +
+				"  \"env\": {\n    \"A\": \"1\"\n  }\n" +
+				"}\n",
+		},
+		{
+			name:   "removed key renders the whole document",
+			change: func(_ *testing.T, doc *Document) { doc.Delete("hooks") },
+			want: "{\n" +
+				"  \"permissions\": {\n    \"allow\": [\n      \"Bash(ls)\"\n    ]\n  },\n" +
+				"  \"statusLine\": {\n    \"type\": \"command\"\n  }\n" +
+				"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, ok := ParseDocument(existing)
+			require.True(t, ok)
+			tt.change(t, doc)
+			out, err := doc.String()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
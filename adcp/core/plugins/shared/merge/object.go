@@ -0,0 +1,169 @@
+// Package merge provides the JSON and YAML merge primitives IDE providers use to update
+// configuration files that users may also edit by hand.
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultIndent is used when the indentation of an existing document cannot be detected.
+const DefaultIndent = "  "
+
+// Object is a JSON object that remembers the key order of the document it was parsed from.
+// Values are kept as compact raw JSON so keys adcp does not manage survive a merge untouched,
+// and so marshaling and comparing values needs no further reformatting.
+type Object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// NewObject returns an empty object.
+func NewObject() *Object {
+	return &Object{values: map[string]json.RawMessage{}}
+}
+
+// ParseObject parses data as a JSON object, preserving key order.
+func ParseObject(data []byte) (*Object, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("expected JSON object")
+	}
+	o := NewObject()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected object key, got %v", tok)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if _, exists := o.values[key]; !exists {
+			o.keys = append(o.keys, key)
+		}
+		o.values[key] = compact(raw)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Keys returns the object's keys in document order.
+func (o *Object) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Has reports whether key is present.
+func (o *Object) Has(key string) bool {
+	_, ok := o.values[key]
+	return ok
+}
+
+// Raw returns the raw JSON stored under key.
+func (o *Object) Raw(key string) (json.RawMessage, bool) {
+	raw, ok := o.values[key]
+	return raw, ok
+}
+
+// Get decodes the value stored under key into v. Returns false if the key is absent or cannot be decoded.
+func (o *Object) Get(key string, v any) bool {
+	raw, ok := o.values[key]
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
+
+// Nested returns the nested object stored under key, or an empty object if the key is absent
+// or does not hold an object.
+func (o *Object) Nested(key string) *Object {
+	if raw, ok := o.values[key]; ok {
+		if nested, err := ParseObject(raw); err == nil {
+			return nested
+		}
+	}
+	return NewObject()
+}
+
+// Set stores v under key, keeping the key's position if it already exists and appending it otherwise.
+func (o *Object) Set(key string, v any) error {
+	raw, err := marshalStable(v)
+	if err != nil {
+		return err
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = raw
+	return nil
+}
+
+// Delete removes key from the object.
+func (o *Object) Delete(key string) {
+	if _, exists := o.values[key]; !exists {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// MarshalJSON writes the object in compact form, keeping key order.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	size := 2
+	for _, k := range o.keys {
+		size += len(k) + len(o.values[k]) + 4
+	}
+	var buf bytes.Buffer
+	buf.Grow(size)
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(k); err != nil {
+			return nil, err
+		}
+		// Encode terminates the key with a newline.
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		buf.Write(o.values[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalIndent renders the object using the given indentation.
+func (o *Object) MarshalIndent(indent string) (string, error) {
+	return Format{Indent: indent}.Marshal(o)
+}
+
+// DetectIndent returns the indentation used by the first indented line of data,
+// falling back to DefaultIndent when none can be detected.
+func DetectIndent(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) == len(line) || len(trimmed) == 0 {
+			continue
+		}
+		return string(line[:len(line)-len(trimmed)])
+	}
+	return DefaultIndent
+}
@@ -0,0 +1,190 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLDocument is an existing YAML file parsed for merging. Unlike JSON documents, YAML files keep
+// their comments, key order and unmanaged entries, as they are edited by hand more often.
+type YAMLDocument struct {
+	doc  *yaml.Node
+	root *yaml.Node
+}
+
+// ParseYAMLDocument parses existing file content, which must hold a mapping. Empty or invalid
+// content yields an empty document, and ok reports whether existing content was parsed successfully.
+func ParseYAMLDocument(existingContent string) (doc *YAMLDocument, ok bool) {
+	empty := func() *YAMLDocument {
+		root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		return &YAMLDocument{doc: &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, root: root}
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(existingContent), &node); err != nil {
+		return empty(), false
+	}
+	if node.Kind == 0 {
+		// Empty or comment-only content.
+		return empty(), true
+	}
+	if len(node.Content) != 1 || node.Content[0].Kind != yaml.MappingNode {
+		return empty(), false
+	}
+	return &YAMLDocument{doc: &node, root: node.Content[0]}, true
+}
+
+// SetDefault sets key to value unless the document already has it.
+func (d *YAMLDocument) SetDefault(key string, value any) error {
+	if d.lookup(key) != nil {
+		return nil
+	}
+	node, err := encodeYAML(value)
+	if err != nil {
+		return err
+	}
+	d.root.Content = append(d.root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node)
+	return nil
+}
+
+// Set sets key to value, replacing any existing value.
+func (d *YAMLDocument) Set(key string, value any) error {
+	node, err := encodeYAML(value)
+	if err != nil {
+		return err
+	}
+	if old := d.lookup(key); old != nil {
+		node.LineComment = old.LineComment
+	}
+	d.set(key, node)
+	return nil
+}
+
+// SetIn sets the value under the path of keys, e.g. SetIn(v, "extensions", "github"), creating
+// mappings along the way and replacing values that are not mappings. Siblings are kept.
+func (d *YAMLDocument) SetIn(value any, keys ...string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("yaml path cannot be empty")
+	}
+	node, err := encodeYAML(value)
+	if err != nil {
+		return err
+	}
+	parent := d.root
+	for _, key := range keys[:len(keys)-1] {
+		child := mappingValue(parent, key)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setMappingValue(parent, key, child)
+		}
+		parent = child
+	}
+	if old := mappingValue(parent, keys[len(keys)-1]); old != nil {
+		node.HeadComment, node.LineComment = old.HeadComment, old.LineComment
+	}
+	setMappingValue(parent, keys[len(keys)-1], node)
+	return nil
+}
+
+// AppendUnique adds values missing from the sequence of scalars under key, keeping existing items
+// first. A single scalar under key is turned into a sequence; anything else is replaced.
+func (d *YAMLDocument) AppendUnique(key string, values ...string) {
+	seq := d.lookup(key)
+	switch {
+	case seq != nil && seq.Kind == yaml.ScalarNode:
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{seq}}
+		d.set(key, seq)
+	case seq == nil || seq.Kind != yaml.SequenceNode:
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		d.set(key, seq)
+	}
+	seen := map[string]bool{}
+	for _, item := range seq.Content {
+		seen[item.Value] = true
+	}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+		}
+	}
+}
+
+// UpsertNamed sets the item of the sequence under key whose nameKey field equals name to value,
+// or appends value when there is no such item. A key holding anything but a sequence is replaced.
+func (d *YAMLDocument) UpsertNamed(key, nameKey, name string, value any) error {
+	node, err := encodeYAML(value)
+	if err != nil {
+		return err
+	}
+	seq := d.lookup(key)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		d.set(key, seq)
+	}
+	for idx, item := range seq.Content {
+		if n := mappingValue(item, nameKey); n != nil && n.Kind == yaml.ScalarNode && n.Value == name {
+			// Keep comments attached to the replaced item.
+			node.HeadComment, node.LineComment, node.FootComment = item.HeadComment, item.LineComment, item.FootComment
+			seq.Content[idx] = node
+			return nil
+		}
+	}
+	seq.Content = append(seq.Content, node)
+	return nil
+}
+
+// String renders the document with two-space indentation.
+func (d *YAMLDocument) String() (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d.doc); err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (d *YAMLDocument) lookup(key string) *yaml.Node {
+	return mappingValue(d.root, key)
+}
+
+func (d *YAMLDocument) set(key string, value *yaml.Node) {
+	setMappingValue(d.root, key, value)
+}
+
+// setMappingValue stores value under key in the mapping n, appending the key if it is new.
+func setMappingValue(n *yaml.Node, key string, value *yaml.Node) {
+	for idx := 0; idx+1 < len(n.Content); idx += 2 {
+		if n.Content[idx].Value == key {
+			n.Content[idx+1] = value
+			return
+		}
+	}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// mappingValue returns the value stored under key if n is a mapping, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(n.Content); idx += 2 {
+		if n.Content[idx].Value == key {
+			return n.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+func encodeYAML(value any) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode yaml value: %w", err)
+	}
+	return &node, nil
+}
@@ -0,0 +1,109 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAMLDocument_UpsertNamed(t *testing.T) {
+	existing := `# Team config
+name: Team
+models:
+  - provider: ollama # local
+prompts:
+  # kept as is
+  - name: docs
+    prompt: Write docs.
+  # replaced
+  - name: review
+    prompt: Old.
+`
+	doc, ok := ParseYAMLDocument(existing)
+	require.True(t, ok)
+
+	type prompt struct {
+		Name   string `yaml:"name"`
+		Prompt string `yaml:"prompt"`
+	}
+	require.NoError(t, doc.SetDefault("name", "adcp"))
+	require.NoError(t, doc.SetDefault("schema", "v1"))
+	require.NoError(t, doc.UpsertNamed("prompts", "name", "review", prompt{Name: "review", Prompt: "Review the diff.\nBe brief.\n"}))
+	require.NoError(t, doc.UpsertNamed("prompts", "name", "status", prompt{Name: "status", Prompt: "Status."}))
+	out, err := doc.String()
+	require.NoError(t, err)
+	assert.Equal(t, `# Team config
+name: Team
+models:
+  - provider: ollama # local
+prompts:
+  # kept as is
+  - name: docs
+    prompt: Write docs.
+  # replaced
+  - name: review
+    prompt: |
+      Review the diff.
+      Be brief.
+  - name: status
+    prompt: Status.
+schema: v1
+`, out)
+}
+
+func TestParseYAMLDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		ok      bool
+	}{
+		{"empty", "", true},
+		{"comment only", "# nothing yet\n", true},
+		{"invalid", "a: [", false},
+		{"not a mapping", "- a\n- b\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, ok := ParseYAMLDocument(tt.content)
+			assert.Equal(t, tt.ok, ok)
+			require.NoError(t, doc.UpsertNamed("items", "name", "a", map[string]string{"name": "a"}))
+			out, err := doc.String()
+			require.NoError(t, err)
+			assert.Equal(t, "items:\n  - name: a\n", out)
+		})
+	}
+}
+
+func TestYAMLDocument_SetAndAppendUnique(t *testing.T) {
+	doc, ok := ParseYAMLDocument("model: gpt-4o # default\nread: CONVENTIONS.md\n")
+	require.True(t, ok)
+
+	require.NoError(t, doc.Set("model", "sonnet"))
+	require.NoError(t, doc.Set("auto-commits", false))
+	doc.AppendUnique("read", "CONVENTIONS.md", "docs/style.md")
+	out, err := doc.String()
+	require.NoError(t, err)
+	assert.Equal(t, "model: sonnet # default\nread:\n  - CONVENTIONS.md\n  - docs/style.md\nauto-commits: false\n", out)
+}
+
+func TestYAMLDocument_SetIn(t *testing.T) {
+	doc, ok := ParseYAMLDocument("GOOSE_MODE: smart_approve\nextensions:\n  developer: # built-in\n    enabled: true\n")
+	require.True(t, ok)
+
+	require.NoError(t, doc.SetIn(map[string]string{"cmd": "devplan"}, "extensions", "devplan"))
+	require.NoError(t, doc.SetIn(false, "extensions", "developer", "enabled"))
+	require.NoError(t, doc.SetIn("x", "new", "nested"))
+	assert.Error(t, doc.SetIn("x"))
+	out, err := doc.String()
+	require.NoError(t, err)
+	assert.Equal(t, `GOOSE_MODE: smart_approve
+extensions:
+  developer: # built-in
+    enabled: false
+  devplan:
+    cmd: devplan
+new:
+  nested: x
+`, out)
+}
@@ -0,0 +1,112 @@
+package shared
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// CommandNamePolicy controls how command names that are not valid file names for IDEs are handled.
+type CommandNamePolicy int
+
+const (
+	// CommandNamePolicyPreserve keeps command names as given and only rejects names that cannot be
+	// written as files on every supported OS (see core.NonPortablePath), e.g. "aux" or "a:b".
+	// Uppercase letters and spaces are kept, and "/" namespaces commands into subfolders, e.g.
+	// "frontend/review" is written to <CommandsFolder>/frontend/review.md.
+	CommandNamePolicyPreserve CommandNamePolicy = iota
+	// CommandNamePolicyError rejects command names other than lowercase letters, digits, '-' and
+	// '_', e.g. "Review" or "frontend/review".
+	CommandNamePolicyError
+	// CommandNamePolicySlugify rewrites invalid command names into valid ones,
+	// e.g. "Review PR/Diff" becomes "review-pr-diff" and "コード レビュー" becomes "コード-レビュー".
+	CommandNamePolicySlugify
+	// CommandNamePolicyTransliterate rewrites command names into ASCII-only ones (see
+	// core.Transliterate), e.g. "コード レビュー" becomes "kodo-rebyu", for IDEs or file systems
+	// that handle non-ASCII file names poorly.
+	CommandNamePolicyTransliterate
+)
+
+// reservedNameSuffix is appended to slugified command names reserved on Windows, e.g. "aux-cmd".
+const reservedNameSuffix = "-cmd"
+
+var (
+	validCommandNameRe = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{Lm}\p{Nd}][\p{Ll}\p{Lo}\p{Lm}\p{Nd}\p{Mn}\p{Mc}_-]*$`)
+	invalidNameCharsRe = regexp.MustCompile(`[^\p{Ll}\p{Lo}\p{Lm}\p{Nd}\p{Mn}\p{Mc}_-]+`)
+	invalidASCIIRe     = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+// resolveCommandName validates name according to the IDE's CommandNamePolicy
+// and returns the name to use for the command file and permissions.
+// Names are normalized to NFC first, so they resolve to the same file on every OS.
+func (i *IDE) resolveCommandName(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("command name cannot be empty")
+	}
+	name = core.NormalizePath(name)
+	switch i.CommandNamePolicy {
+	case CommandNamePolicySlugify:
+		return slugifyCommandName(name, strings.ToLower(name), invalidNameCharsRe)
+	case CommandNamePolicyTransliterate:
+		return slugifyCommandName(name, strings.ToLower(core.Transliterate(name)), invalidASCIIRe)
+	case CommandNamePolicyPreserve:
+		if !fs.ValidPath(name) || name == "." {
+			return "", fmt.Errorf("invalid command name %q: empty, '.' and '..' path segments are not allowed", name)
+		}
+		if reason := core.NonPortablePath(name); reason != "" {
+			return "", fmt.Errorf("invalid command name %q: %s", name, reason)
+		}
+		return name, nil
+	}
+	if !validCommandNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid command name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	if core.IsReservedName(name) {
+		return "", fmt.Errorf("invalid command name %q: reserved file name on Windows", name)
+	}
+	return name, nil
+}
+
+// slugifyCommandName replaces runs of characters matching invalid in candidate with dashes.
+func slugifyCommandName(name, candidate string, invalid *regexp.Regexp) (string, error) {
+	slug := strings.Trim(invalid.ReplaceAllString(candidate, "-"), "-_")
+	// Marks cannot start a name, as they combine with the preceding character.
+	slug = strings.TrimLeftFunc(slug, func(r rune) bool { return unicode.In(r, unicode.Mn, unicode.Mc) })
+	if slug == "" {
+		return "", fmt.Errorf("invalid command name %q: nothing left after sanitization", name)
+	}
+	if core.IsReservedName(slug) {
+		slug += reservedNameSuffix
+	}
+	return slug, nil
+}
+
+// ResolveCommandNames resolves the names of all commands like Materialize does, e.g. for
+// providers that render commands themselves.
+func (i *IDE) ResolveCommandNames(commands *adcp.Commands) ([]string, error) {
+	return i.resolveCommandNames(commands)
+}
+
+// resolveCommandNames resolves the names of all commands, in order, rejecting names that
+// collide once resolved (e.g. "Deploy" and "deploy" under the slugify policy).
+func (i *IDE) resolveCommandNames(commands *adcp.Commands) ([]string, error) {
+	var names []string
+	seen := map[string]string{}
+	for _, c := range commands.GetEntries() {
+		name, err := i.resolveCommandName(c.GetName())
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[name]; ok {
+			return nil, fmt.Errorf("commands %q and %q both resolve to %q", prev, c.GetName(), name)
+		}
+		seen[name] = c.GetName()
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,123 @@
+package shared
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_ResolveCommandName(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  CommandNamePolicy
+		want    string
+		wantErr bool
+	}{
+		{name: "review-pr", want: "review-pr"},
+		{name: "Review", want: "Review"},
+		{name: "Review PR", want: "Review PR"},
+		{name: "frontend/review", want: "frontend/review"},
+		{name: "a:b", wantErr: true},
+		{name: "../escape", wantErr: true},
+		{name: "/abs", wantErr: true},
+		{name: "ops//deploy", wantErr: true},
+		{name: "trailing.", wantErr: true},
+		{name: "review-pr", policy: CommandNamePolicyError, want: "review-pr"},
+		{name: "run_tests2", policy: CommandNamePolicyError, want: "run_tests2"},
+		{name: "Review PR", policy: CommandNamePolicyError, wantErr: true},
+		{name: "ops/deploy", policy: CommandNamePolicyError, wantErr: true},
+		{name: "Review PR", policy: CommandNamePolicySlugify, want: "review-pr"},
+		{name: "ops/deploy", policy: CommandNamePolicySlugify, want: "ops-deploy"},
+		{name: "  Café Check!  ", policy: CommandNamePolicySlugify, want: "café-check"},
+		{name: "  Café Check!  ", policy: CommandNamePolicyTransliterate, want: "cafe-check"},
+		{name: "レビュー", policy: CommandNamePolicyError, want: "レビュー"},
+		// Decomposed (NFD) as typed on macOS, resolving to the same name as on Linux.
+		{name: "レ\u30d2\u3099ュー", want: "レビュー"},
+		{name: "コード レビュー", policy: CommandNamePolicyError, wantErr: true},
+		{name: "コード レビュー", policy: CommandNamePolicySlugify, want: "コード-レビュー"},
+		{name: "コード レビュー", policy: CommandNamePolicyTransliterate, want: "kodo-rebyu"},
+		{name: "日本語", policy: CommandNamePolicyTransliterate, wantErr: true},
+		{name: "aux", wantErr: true},
+		{name: "aux", policy: CommandNamePolicyError, wantErr: true},
+		{name: "AUX", policy: CommandNamePolicySlugify, want: "aux-cmd"},
+		{name: "???", policy: CommandNamePolicySlugify, wantErr: true},
+		{name: "", policy: CommandNamePolicySlugify, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ide := &IDE{CommandNamePolicy: tt.policy}
+			got, err := ide.resolveCommandName(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIDE_Materialize_SlugifiedCommandNames(t *testing.T) {
+	g := getIDE()
+	g.CommandsFolder = ".claude/commands"
+	g.CommandNamePolicy = CommandNamePolicySlugify
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "Review PR", From: adcp.CommandFrom_builder{Text: strPtr("review")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Contains(t, paths, ".claude/commands/review-pr.md")
+
+	// Names colliding after sanitization are rejected.
+	ide = adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "Deploy", From: adcp.CommandFrom_builder{Text: strPtr("a")}.Build()}.Build(),
+			adcp.Command_builder{Name: "deploy", From: adcp.CommandFrom_builder{Text: strPtr("b")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	_, err = g.Materialize(context.Background(), ide)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both resolve to")
+}
+
+func TestIDE_Materialize_PreservedCommandNames(t *testing.T) {
+	g := getIDE()
+	g.CommandsFolder = ".claude/commands"
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "Review", From: adcp.CommandFrom_builder{Text: strPtr("review")}.Build()}.Build(),
+			adcp.Command_builder{Name: "frontend/review", From: adcp.CommandFrom_builder{Text: strPtr("frontend")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Contains(t, paths, ".claude/commands/Review.md")
+	assert.Contains(t, paths, ".claude/commands/frontend/review.md")
+
+	// Namespaced commands are cleaned up like the others once removed from the recipe.
+	ctx := core.WithFS(context.Background(), fstest.MapFS{
+		".claude/commands/.adcp-manifest.json": {Data: []byte(`{"generatedBy": "adcp", "commands": ["Review.md", "frontend/review.md"]}`)},
+	})
+	assert.Equal(t, []string{".claude/commands/frontend/review.md"}, g.Orphans(ctx, adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: ide.GetCommands().GetEntries()[:1]}.Build(),
+	}.Build()))
+}
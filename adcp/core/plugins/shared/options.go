@@ -0,0 +1,227 @@
+package shared
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"text/template"
+)
+
+// Option configures an IDE.
+type Option func(ide *IDE)
+
+// NewIDE returns an IDE configured by opts, with the paths of its Scope.
+func NewIDE(opts ...Option) *IDE {
+	ide := &IDE{}
+	for _, opt := range opts {
+		opt(ide)
+	}
+	ide.applyScope()
+	return ide
+}
+
+// WithLogger sets the logger used while materializing IDE configuration.
+func WithLogger(logger *slog.Logger) Option {
+	return func(ide *IDE) {
+		ide.Logger = logger
+	}
+}
+
+// WithCommandConcurrency bounds the number of commands fetched concurrently.
+func WithCommandConcurrency(n int) Option {
+	return func(ide *IDE) {
+		ide.CommandConcurrency = n
+	}
+}
+
+// WithCommandNamePolicy sets how invalid command names are handled.
+func WithCommandNamePolicy(policy CommandNamePolicy) Option {
+	return func(ide *IDE) {
+		ide.CommandNamePolicy = policy
+	}
+}
+
+// WithFS sets the filesystem existing files are read from before merging.
+func WithFS(fsys fs.FS) Option {
+	return func(ide *IDE) {
+		ide.FS = fsys
+	}
+}
+
+// WithCommandsFolder writes commands into folder. An empty folder disables commands.
+func WithCommandsFolder(folder string) Option {
+	return func(ide *IDE) {
+		ide.CommandsFolder = folder
+	}
+}
+
+// WithMCPPath writes MCP servers into the JSON file at path. An empty path disables MCP servers.
+func WithMCPPath(path string) Option {
+	return func(ide *IDE) {
+		ide.MCPServersJSONPath = path
+	}
+}
+
+// WithRemovedMCPServers deletes the named servers from the MCP configuration, so that servers
+// decommissioned by a recipe do not linger.
+func WithRemovedMCPServers(names ...string) Option {
+	return func(ide *IDE) {
+		ide.RemovedMCPServers = append(ide.RemovedMCPServers, names...)
+	}
+}
+
+// WithMCPServersKey sets the top-level key holding servers in the MCP configuration, e.g. "servers".
+func WithMCPServersKey(key string) Option {
+	return func(ide *IDE) {
+		ide.MCPServersKey = key
+	}
+}
+
+// WithMCPServerFunc converts servers into the IDE's format instead of StandardMCPServer.
+func WithMCPServerFunc(fn MCPServerFunc) Option {
+	return func(ide *IDE) {
+		ide.MCPServerFunc = fn
+	}
+}
+
+// WithMCPServerOptions sets per-server options keyed by MCP server name, e.g. env and headers.
+func WithMCPServerOptions(opts map[string]MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// WithEnvReference sets how references to environment variables are written into the MCP
+// configuration, e.g. for bearer tokens (see MCPServerOptions.BearerTokenEnv).
+func WithEnvReference(ref func(name string) string) Option {
+	return func(ide *IDE) {
+		ide.EnvReference = ref
+	}
+}
+
+// WithSettings sets the IDE-specific settings hook, e.g. translating permissions.
+func WithSettings(settings IDESettings) Option {
+	return func(ide *IDE) {
+		ide.Settings = settings
+	}
+}
+
+// WithCommandMetadata sets per-command metadata keyed by command name, rendered as frontmatter
+// restricted to the IDE's CommandFrontmatterFields.
+func WithCommandMetadata(meta map[string]CommandMetadata) Option {
+	return func(ide *IDE) {
+		ide.CommandMetadata = meta
+	}
+}
+
+// WithCommandFrontmatterFields limits the metadata fields rendered as command frontmatter; no
+// fields disables frontmatter.
+func WithCommandFrontmatterFields(fields ...string) Option {
+	return func(ide *IDE) {
+		ide.CommandFrontmatterFields = append([]string{}, fields...)
+	}
+}
+
+// WithCommandExtension sets the extension of generated command files, e.g. ".toml".
+func WithCommandExtension(ext string) Option {
+	return func(ide *IDE) {
+		ide.CommandExtension = ext
+	}
+}
+
+// WithCommandTemplate renders command files from CommandTemplateData with tmpl (see
+// MustParseCommandTemplate).
+func WithCommandTemplate(tmpl *template.Template) Option {
+	return func(ide *IDE) {
+		ide.CommandTemplate = tmpl
+	}
+}
+
+// WithCommandArgumentsPlaceholder appends placeholder to commands with an argument hint that do
+// not reference it.
+func WithCommandArgumentsPlaceholder(placeholder string) Option {
+	return func(ide *IDE) {
+		ide.CommandArgumentsPlaceholder = placeholder
+	}
+}
+
+// WithAgentsFolder writes agents into folder. An empty folder disables agents.
+func WithAgentsFolder(folder string) Option {
+	return func(ide *IDE) {
+		ide.AgentsFolder = folder
+	}
+}
+
+// WithAgents adds subagent definitions, written to the IDE's AgentsFolder.
+func WithAgents(agents ...Agent) Option {
+	return func(ide *IDE) {
+		ide.Agents = append(ide.Agents, agents...)
+	}
+}
+
+// WithSkillsFolder writes skills into folder. An empty folder disables skills.
+func WithSkillsFolder(folder string) Option {
+	return func(ide *IDE) {
+		ide.SkillsFolder = folder
+	}
+}
+
+// WithSkills adds skill packages, written to the IDE's SkillsFolder.
+func WithSkills(skills ...Skill) Option {
+	return func(ide *IDE) {
+		ide.Skills = append(ide.Skills, skills...)
+	}
+}
+
+// WithContextPath sets the location of the logical context destination (see DestinationRules and
+// friends).
+func WithContextPath(destination, path string) Option {
+	return func(ide *IDE) {
+		if ide.ContextPaths == nil {
+			ide.ContextPaths = map[string]string{}
+		}
+		ide.ContextPaths[destination] = path
+	}
+}
+
+// WithAgentsMDPath sets the location of the AGENTS.md file agent instructions are written to. An
+// empty path disables agent instructions.
+func WithAgentsMDPath(path string) Option {
+	return func(ide *IDE) {
+		ide.AgentsMDPath = path
+	}
+}
+
+// WithAgentInstructions writes instructions into the managed section of the IDE's AgentsMDPath.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *IDE) {
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithManagedMemory sets whether context targeting the memory file only replaces its managed
+// section, keeping hand-written content.
+func WithManagedMemory(managed bool) Option {
+	return func(ide *IDE) {
+		ide.ManagedMemory = managed
+	}
+}
+
+// WithFileReader reads existing files with read instead of the filesystem, e.g. from a remote
+// workspace. read reports missing files with an error matching fs.ErrNotExist.
+func WithFileReader(read func(name string) ([]byte, error)) Option {
+	return func(ide *IDE) {
+		ide.FS = readerFS(read)
+	}
+}
+
+// readerFS adapts a read function to fs.ReadFileFS, which is all that existing files are read with.
+type readerFS func(name string) ([]byte, error)
+
+func (r readerFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: errors.ErrUnsupported}
+}
+
+func (r readerFS) ReadFile(name string) ([]byte, error) {
+	return r(name)
+}
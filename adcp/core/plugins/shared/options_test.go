@@ -0,0 +1,52 @@
+package shared
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIDE_Options(t *testing.T) {
+	s := &noOpSettings{}
+	ide := NewIDE(
+		WithCommandsFolder(".acme/commands"),
+		WithMCPPath(".acme/mcp.json"),
+		WithSettings(s),
+		WithCommandFrontmatterFields(FieldDescription),
+		WithCommandArgumentsPlaceholder("$ARGS"),
+		WithContextPath(DestinationRules, ".acme/rules"),
+	)
+	assert.Equal(t, ".acme/commands", ide.CommandsFolder)
+	assert.Equal(t, ".acme/mcp.json", ide.MCPServersJSONPath)
+	assert.Same(t, s, ide.Settings)
+	assert.Equal(t, []string{FieldDescription}, ide.CommandFrontmatterFields)
+	assert.Equal(t, "$ARGS", ide.CommandArgumentsPlaceholder)
+	assert.Equal(t, map[string]string{DestinationRules: ".acme/rules"}, ide.ContextPaths)
+
+	assert.NotNil(t, NewIDE(WithCommandFrontmatterFields()).CommandFrontmatterFields, "no fields disables frontmatter")
+}
+
+func TestWithFileReader(t *testing.T) {
+	files := map[string]string{".acme/mcp.json": `{"mcpServers": {"existing": {"command": "srv"}}}`}
+	ide := NewIDE(WithMCPPath(".acme/mcp.json"), WithFileReader(func(name string) ([]byte, error) {
+		if content, ok := files[name]; ok {
+			return []byte(content), nil
+		}
+		return nil, fs.ErrNotExist
+	}))
+
+	res, err := ide.Materialize(context.Background(), adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	content := res.GetEntries()[0].GetFile().GetContent()
+	assert.Contains(t, content, `"existing"`)
+	assert.Contains(t, content, `"devplan"`)
+}
@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+)
+
+// Outputs whose paths can be overridden, in addition to the context destinations (see
+// DestinationRules and friends).
+const (
+	PathCommands = "commands"
+	PathMCP      = "mcp"
+	PathAgents   = "agents"
+	PathSkills   = "skills"
+)
+
+// ErrUnsupportedPathOverride matches errors for path overrides of outputs an IDE does not produce.
+var ErrUnsupportedPathOverride = errors.New("unsupported path override")
+
+// OverridePaths returns a copy of the IDE with the default output paths replaced, keyed by
+// PathCommands, PathAgents, PathSkills, PathMCP or a context destination, e.g.
+// {"commands": ".claude/commands/team"} (see WithPathOverrides).
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	return i.WithPathOverrides(overrides)
+}
+
+// WithPathOverrides is like OverridePaths, for providers embedding an IDE. Only outputs the IDE
+// produces can be overridden, and paths cannot be empty. All keys are validated before the copy is
+// made; i is never changed.
+func (i *IDE) WithPathOverrides(overrides map[string]string) (*IDE, error) {
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if overrides[key] == "" {
+			return nil, fmt.Errorf("%w: empty path for %s", ErrUnsupportedPathOverride, key)
+		}
+		_, isContext := i.ContextPaths[key]
+		supported := isContext ||
+			key == PathCommands && i.CommandsFolder != "" ||
+			key == PathAgents && i.AgentsFolder != "" ||
+			key == PathSkills && i.SkillsFolder != "" ||
+			key == PathMCP && i.MCPServersJSONPath != ""
+		if !supported {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedPathOverride, key)
+		}
+	}
+	c := *i
+	c.ContextPaths = maps.Clone(i.ContextPaths)
+	for key, p := range overrides {
+		switch key {
+		case PathCommands:
+			c.CommandsFolder = p
+		case PathAgents:
+			c.AgentsFolder = p
+		case PathSkills:
+			c.SkillsFolder = p
+		case PathMCP:
+			c.MCPServersJSONPath = p
+		default:
+			c.ContextPaths[key] = p
+		}
+	}
+	return &c, nil
+}
+
+// ManagedPaths lists the commands, agents, skills, MCP servers, AGENTS.md and context locations of the IDE, followed
+// by the paths of Settings if it reports them with a ManagedPaths method. Only the command files
+// recorded in the commands manifest are generated (see Orphans).
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	var paths []recipes.ManagedPath
+	add := func(pattern, merge string) {
+		if pattern != "" && !slices.ContainsFunc(paths, func(p recipes.ManagedPath) bool { return p.Pattern == pattern }) {
+			paths = append(paths, recipes.ManagedPath{Pattern: pattern, Merge: merge})
+		}
+	}
+	if i.CommandsFolder != "" {
+		add(path.Join(i.CommandsFolder, i.commandFileName("*")), core.MergeReplace)
+		add(i.commandsManifestPath(), core.MergeReplace)
+	}
+	if i.AgentsFolder != "" {
+		add(path.Join(i.AgentsFolder, "*.md"), core.MergeReplace)
+	}
+	if i.SkillsFolder != "" {
+		add(path.Join(i.SkillsFolder, "**"), core.MergeReplace)
+	}
+	add(i.MCPServersJSONPath, core.MergeJSON)
+	add(i.AgentsMDPath, core.MergeManagedBlock)
+	for _, dest := range slices.Sorted(maps.Keys(i.ContextPaths)) {
+		if dest == DestinationMemory && i.ManagedMemory {
+			add(i.ContextPaths[dest], core.MergeManagedBlock)
+		} else if dest == DestinationMemory {
+			add(i.ContextPaths[dest], core.MergeReplace)
+		} else {
+			add(path.Join(i.ContextPaths[dest], "**"), core.MergeReplace)
+		}
+	}
+	if mp, ok := i.Settings.(recipes.ManagedPathsProvider); ok {
+		for _, p := range mp.ManagedPaths() {
+			add(p.Pattern, p.Merge)
+		}
+	}
+	return paths
+}
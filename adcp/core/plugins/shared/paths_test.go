@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_OverridePaths(t *testing.T) {
+	newIDE := func() *IDE {
+		return &IDE{
+			CommandsFolder:     ".claude/commands",
+			MCPServersJSONPath: ".mcp.json",
+			ContextPaths:       map[string]string{DestinationRules: ".claude/rules"},
+		}
+	}
+
+	ide := newIDE()
+	overridden, err := ide.OverridePaths(map[string]string{
+		PathCommands:     ".claude/commands/team",
+		PathMCP:          "config/mcp.json",
+		DestinationRules: ".claude/rules/team",
+	})
+	require.NoError(t, err)
+	got := overridden.(*IDE)
+	assert.Equal(t, ".claude/commands/team", got.CommandsFolder)
+	assert.Equal(t, "config/mcp.json", got.MCPServersJSONPath)
+	assert.Equal(t, ".claude/rules/team", got.ContextPaths[DestinationRules])
+	assert.Equal(t, newIDE(), ide, "the original IDE is unchanged")
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		wantErr   string
+	}{
+		{name: "unknown output", overrides: map[string]string{"hooks": ".hooks"}, wantErr: "hooks"},
+		{name: "context destination without a location", overrides: map[string]string{DestinationDocs: "docs"}, wantErr: DestinationDocs},
+		{name: "empty path", overrides: map[string]string{PathCommands: ""}, wantErr: "empty path for commands"},
+		{name: "valid and unknown outputs", overrides: map[string]string{PathCommands: "cmds", DestinationRules: "rules", "hooks": ".hooks"}, wantErr: "hooks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ide := newIDE()
+			_, err := ide.OverridePaths(tt.overrides)
+			require.ErrorIs(t, err, ErrUnsupportedPathOverride)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Equal(t, newIDE(), ide, "no override is applied")
+		})
+	}
+
+	noCommands := newIDE()
+	noCommands.CommandsFolder = ""
+	_, err = noCommands.OverridePaths(map[string]string{PathCommands: "cmds"})
+	assert.ErrorIs(t, err, ErrUnsupportedPathOverride)
+}
+
+type managedSettings struct {
+	noOpSettings
+}
+
+func (managedSettings) ManagedPaths() []recipes.ManagedPath {
+	return []recipes.ManagedPath{{Pattern: ".claude/settings.local.json", Merge: core.MergeJSON}}
+}
+
+func TestIDE_ManagedPaths(t *testing.T) {
+	ide := &IDE{
+		CommandsFolder:     ".claude/commands",
+		MCPServersJSONPath: ".mcp.json",
+		AgentsFolder:       ".claude/agents",
+		SkillsFolder:       ".claude/skills",
+		AgentsMDPath:       "AGENTS.md",
+		Settings:           &managedSettings{},
+		ContextPaths: map[string]string{
+			DestinationRules:  ".claude/rules",
+			DestinationMemory: "AGENTS.md",
+		},
+	}
+	assert.Equal(t, []recipes.ManagedPath{
+		{Pattern: ".claude/commands/*.md", Merge: core.MergeReplace},
+		{Pattern: ".claude/commands/.adcp-manifest.json", Merge: core.MergeReplace},
+		{Pattern: ".claude/agents/*.md", Merge: core.MergeReplace},
+		{Pattern: ".claude/skills/**", Merge: core.MergeReplace},
+		{Pattern: ".mcp.json", Merge: core.MergeJSON},
+		{Pattern: "AGENTS.md", Merge: core.MergeManagedBlock},
+		{Pattern: ".claude/rules/**", Merge: core.MergeReplace},
+		{Pattern: ".claude/settings.local.json", Merge: core.MergeJSON},
+	}, ide.ManagedPaths())
+
+	assert.Empty(t, (&IDE{}).ManagedPaths())
+}
@@ -0,0 +1,12 @@
+package shared
+
+import "strings"
+
+// BashPrefixToGlob converts a Claude-style Bash prefix matcher into the glob pattern most other
+// agents use, e.g. "git commit:*" becomes "git commit *". Other patterns are returned unchanged.
+func BashPrefixToGlob(pattern string) string {
+	if prefix, ok := strings.CutSuffix(pattern, ":*"); ok {
+		return prefix + " *"
+	}
+	return pattern
+}
@@ -0,0 +1,119 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Scope selects whether an IDE is configured for a project or for the user.
+type Scope int
+
+const (
+	// ScopeProject writes the project's configuration, relative to the project root.
+	ScopeProject Scope = iota
+	// ScopeUser writes the user's configuration, relative to the home directory, using the
+	// UserPaths of the IDE. Existing files are read from the home directory unless an FS is set
+	// (see HomeFS). Persist the result with root "~" (see core.PersistMaterializedResult).
+	ScopeUser
+)
+
+// WithScope selects the locations the IDE writes. Options that set paths are applied first, so
+// with ScopeUser, UserPaths take precedence over them.
+func WithScope(scope Scope) Option {
+	return func(ide *IDE) {
+		ide.Scope = scope
+	}
+}
+
+// WithUserPaths sets the user-level locations of the IDE's outputs, keyed like OverridePaths and
+// relative to the home directory, e.g. {"commands": ".claude/commands"}.
+func WithUserPaths(paths map[string]string) Option {
+	return func(ide *IDE) {
+		ide.UserPaths = paths
+	}
+}
+
+// HomeFS returns the user's home directory as a filesystem, so that existing user-level
+// configuration is merged rather than replaced. If the home directory cannot be resolved, every
+// read fails with that error instead of silently finding no files.
+func HomeFS() fs.FS {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		err = fmt.Errorf("failed to resolve home directory: %w", err)
+		return readerFS(func(string) ([]byte, error) { return nil, err })
+	}
+	return os.DirFS(home)
+}
+
+// UserFilesEnabled reports whether the user-level file at path, which starts with core.HomePrefix,
+// can be produced (see core.WithUserRoot). If not, it warns that what, e.g. "MCP servers", of
+// feature is not installed.
+func UserFilesEnabled(ctx context.Context, path, feature, what string) bool {
+	if core.UserRoot(ctx) != "" {
+		return true
+	}
+	core.Warn(ctx, core.Warning{
+		Code:    core.WarningSkippedUserFile,
+		Path:    path,
+		Feature: feature,
+		Message: fmt.Sprintf("Not installing %s: the IDE only reads %s, and user-level files are not enabled", what, path),
+	})
+	return false
+}
+
+// ReadExistingUserFile returns the existing user-level file at path, read from fsys if set, or else
+// under the root set by core.WithUserRoot. ReadExistingFile uses it for paths starting with
+// core.HomePrefix.
+func ReadExistingUserFile(ctx context.Context, fsys fs.FS, path string) string {
+	log := core.Logger(ctx).With("path", path)
+	var data []byte
+	var err error
+	if rel, ok := core.HomeRelative(path); ok && fsys != nil {
+		data, err = fs.ReadFile(fsys, rel)
+	} else {
+		data, err = core.ReadUserFile(ctx, path)
+	}
+	if err != nil {
+		log.Debug("No existing file, creating it")
+		return ""
+	}
+	log.Debug("Merging into existing file", "bytes", len(data))
+	return string(data)
+}
+
+// UserFileEntry returns an entry writing content to the user-level file at path, marked so that it
+// is persisted under the root set by core.WithUserRoot (see core.SetUserFile).
+func UserFileEntry(path, content string) *adcp.MaterializedResult_Entry {
+	file := adcp.FullFileContent_builder{Path: path, Content: content}.Build()
+	core.SetUserFile(file)
+	return adcp.MaterializedResult_Entry_builder{File: file}.Build()
+}
+
+// applyScope replaces the project paths of the IDE by its UserPaths in ScopeUser, and reads
+// existing files from the home directory unless an FS is set. Outputs without a user-level
+// location, including AGENTS.md, are disabled.
+func (i *IDE) applyScope() {
+	if i.Scope != ScopeUser {
+		return
+	}
+	if i.FS == nil {
+		i.FS = HomeFS()
+	}
+	i.CommandsFolder = i.UserPaths[PathCommands]
+	i.AgentsFolder = i.UserPaths[PathAgents]
+	i.SkillsFolder = i.UserPaths[PathSkills]
+	i.MCPServersJSONPath = i.UserPaths[PathMCP]
+	i.AgentsMDPath = ""
+	contextPaths := map[string]string{}
+	for dest := range i.ContextPaths {
+		if p := i.UserPaths[dest]; p != "" {
+			contextPaths[dest] = p
+		}
+	}
+	i.ContextPaths = contextPaths
+}
@@ -0,0 +1,32 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIDE_Scope(t *testing.T) {
+	opts := []Option{
+		WithCommandsFolder(".acme/commands"),
+		WithMCPPath(".acme/mcp.json"),
+		WithContextPath(DestinationMemory, "ACME.md"),
+		WithContextPath(DestinationDocs, ".acme/docs"),
+		func(ide *IDE) { ide.AgentsMDPath = "AGENTS.md" },
+		WithUserPaths(map[string]string{
+			PathCommands:      ".acme/commands",
+			PathMCP:           ".acme.json",
+			DestinationMemory: ".acme/ACME.md",
+		}),
+	}
+
+	project := NewIDE(opts...)
+	assert.Equal(t, ".acme/mcp.json", project.MCPServersJSONPath)
+	assert.Equal(t, "AGENTS.md", project.AgentsMDPath)
+
+	user := NewIDE(append(opts, WithScope(ScopeUser))...)
+	assert.Equal(t, ".acme/commands", user.CommandsFolder)
+	assert.Equal(t, ".acme.json", user.MCPServersJSONPath)
+	assert.Empty(t, user.AgentsMDPath)
+	assert.Equal(t, map[string]string{DestinationMemory: ".acme/ACME.md"}, user.ContextPaths)
+}
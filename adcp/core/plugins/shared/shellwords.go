@@ -0,0 +1,128 @@
+package shared
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// SplitCommand splits the command line of a stdio MCP server into the executable and its args.
+// Quotes and backslash escapes are honored like in a POSIX shell (see ParseCommandLine), so that
+// `npx -y "@scope/pkg with space"` keeps its last argument whole. Command lines with unterminated
+// quotes are split by whitespace. Leading environment assignments are not separated; use
+// StdioCommand for that.
+func SplitCommand(cmd string) (string, []string) {
+	words, err := ParseCommandLine(cmd)
+	if err != nil {
+		words = strings.Fields(cmd)
+	}
+	if len(words) == 0 {
+		return "", nil
+	}
+	if len(words) == 1 {
+		return words[0], nil
+	}
+	return words[0], words[1:]
+}
+
+// StdioCommand returns the executable, args and environment of server. Leading environment
+// assignments of its command line, as in `GITHUB_TOKEN=${GITHUB_TOKEN} github-mcp stdio`, are
+// moved into the environment, where opts.Env takes precedence over them.
+func StdioCommand(server *adcp.StdioMcpServer, opts MCPServerOptions) (command string, args []string, env map[string]string) {
+	command, args = SplitCommand(server.GetCommand())
+	for command != "" && isEnvAssignment(command) {
+		if env == nil {
+			env = map[string]string{}
+		}
+		name, value, _ := strings.Cut(command, "=")
+		env[name] = value
+		command = ""
+		if len(args) > 0 {
+			command, args = args[0], args[1:]
+		}
+	}
+	if len(args) == 0 {
+		args = nil
+	}
+	if env == nil {
+		return command, args, opts.Env
+	}
+	maps.Copy(env, opts.Env)
+	return command, args, env
+}
+
+// ParseCommandLine splits cmd into words like a POSIX shell, without expansions: whitespace
+// separates words, single quotes keep their content literally, double quotes keep it except for
+// backslash escapes of '"', '\', '$' and '`', and a backslash outside of quotes escapes the next
+// character.
+func ParseCommandLine(cmd string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	// inWord reports whether a word was started, which may be empty, e.g. "".
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("command line ends with an escape: %s", cmd)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command line: %s", quote, cmd)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// isEnvAssignment reports whether word assigns a variable, e.g. "DEBUG=1".
+func isEnvAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{cmd: "devplan mcp  --stdio", want: []string{"devplan", "mcp", "--stdio"}},
+		{cmd: `npx -y "@scope/pkg with space"`, want: []string{"npx", "-y", "@scope/pkg with space"}},
+		{cmd: `run 'it''s' "a \"b\" \n" c\ d ""`, want: []string{"run", "its", `a "b" \n`, "c d", ""}},
+		{cmd: `echo '$HOME \x'`, want: []string{"echo", `$HOME \x`}},
+		{cmd: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			got, err := ParseCommandLine(tt.cmd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, cmd := range []string{`npx "unterminated`, `npx 'unterminated`, `npx trailing\`} {
+		_, err := ParseCommandLine(cmd)
+		assert.Error(t, err, cmd)
+	}
+}
+
+func TestSplitCommand_UnterminatedQuote(t *testing.T) {
+	command, args := SplitCommand(`npx "pkg`)
+	assert.Equal(t, "npx", command)
+	assert.Equal(t, []string{`"pkg`}, args)
+}
+
+func TestStdioCommand(t *testing.T) {
+	server := adcp.StdioMcpServer_builder{Command: `DEBUG=1 TOKEN="${TOKEN}" github-mcp stdio --name "My Org"`}.Build()
+	command, args, env := StdioCommand(server, MCPServerOptions{Env: map[string]string{"DEBUG": "0", "OTHER": "x"}})
+	assert.Equal(t, "github-mcp", command)
+	assert.Equal(t, []string{"stdio", "--name", "My Org"}, args)
+	assert.Equal(t, map[string]string{"DEBUG": "0", "TOKEN": "${TOKEN}", "OTHER": "x"}, env)
+
+	command, args, env = StdioCommand(adcp.StdioMcpServer_builder{Command: "devplan --url=https://x"}.Build(), MCPServerOptions{})
+	assert.Equal(t, "devplan", command)
+	assert.Equal(t, []string{"--url=https://x"}, args)
+	assert.Nil(t, env)
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/frontmatter"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// skillFile is the file holding the instructions of a skill within its directory.
+const skillFile = "SKILL.md"
+
+// Skill describes a packaged agent behavior, which is not part of the recipe model yet. It is
+// written to <SkillsFolder>/<Name>/SKILL.md with its properties as frontmatter, next to its
+// supporting files.
+type Skill struct {
+	// Name identifies the skill and names its directory. It follows the rules of command names.
+	Name string
+	// Description tells the agent when to use the skill.
+	Description string
+	// AllowedTools lists tools the agent may use without asking while the skill is active.
+	AllowedTools []string
+	// From is the source of the skill's instructions, like the source of a command.
+	From *adcp.CommandFrom
+	// Files are supporting files, e.g. scripts or reference docs, keyed by their slash-separated
+	// path within the skill directory.
+	Files map[string]*adcp.CommandFrom
+}
+
+// materializeSkills writes Skills into SkillsFolder.
+func (i *IDE) materializeSkills(ctx context.Context) ([]*adcp.MaterializedResult_Entry, error) {
+	var entries []*adcp.MaterializedResult_Entry
+	seen := map[string]bool{}
+	for _, s := range i.Skills {
+		name, err := i.resolveCommandName(s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skill: %w", err)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate skill name %q", name)
+		}
+		seen[name] = true
+		if s.From == nil {
+			return nil, fmt.Errorf("skill %s must have a 'from' source", name)
+		}
+		core.Logger(ctx).Debug("Materializing skill", "name", name, "source", s.From.WhichType().String())
+		body, err := FetchCommandContent(ctx, s.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to materialize skill %s: %w", name, err)
+		}
+		fm := frontmatter.New().
+			Set(FieldName, name).
+			Set(FieldDescription, s.Description).
+			Set(FieldAllowedTools, strings.Join(s.AllowedTools, ", "))
+		entries = append(entries, skillEntry(ctx, path.Join(i.SkillsFolder, name, skillFile), fm.Render(body), s.From))
+
+		for _, rel := range slices.Sorted(maps.Keys(s.Files)) {
+			if !filepath.IsLocal(filepath.FromSlash(rel)) || path.Clean(rel) == skillFile {
+				return nil, fmt.Errorf("invalid file %q of skill %s: must be a relative path within the skill other than %s", rel, name, skillFile)
+			}
+			from := s.Files[rel]
+			if from == nil {
+				return nil, fmt.Errorf("file %s of skill %s must have a 'from' source", rel, name)
+			}
+			content, err := FetchCommandContent(ctx, from)
+			if err != nil {
+				return nil, fmt.Errorf("failed to materialize file %s of skill %s: %w", rel, name, err)
+			}
+			entries = append(entries, skillEntry(ctx, path.Join(i.SkillsFolder, name, rel), content, from))
+		}
+	}
+	return entries, nil
+}
+
+// skillEntry records the planned change of a skill file and returns its entry.
+func skillEntry(ctx context.Context, p, content string, from *adcp.CommandFrom) *adcp.MaterializedResult_Entry {
+	change := core.PlannedChange{Path: p, Merge: core.MergeReplace}
+	if from.WhichType() == adcp.CommandFrom_Cmd_case {
+		change.Note = "command not executed: " + from.GetCmd()
+	}
+	core.RecordPlannedChange(ctx, change)
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: p, Content: content}.Build(),
+	}.Build()
+}
@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Skills(t *testing.T) {
+	text := func(s string) *adcp.CommandFrom { return adcp.CommandFrom_builder{Text: &s}.Build() }
+
+	tests := []struct {
+		name    string
+		skills  []Skill
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "with supporting files",
+			skills: []Skill{{
+				Name:        "release",
+				Description: "Cut a release",
+				From:        text("Run scripts/tag.sh.\n"),
+				Files: map[string]*adcp.CommandFrom{
+					"scripts/tag.sh": text("git tag \"$1\"\n"),
+					"CHANGELOG.md":   text("# Changes\n"),
+				},
+			}},
+			want: map[string]string{
+				".skills/release/SKILL.md":       "---\nname: release\ndescription: Cut a release\n---\n\nRun scripts/tag.sh.\n",
+				".skills/release/CHANGELOG.md":   "# Changes\n",
+				".skills/release/scripts/tag.sh": "git tag \"$1\"\n",
+			},
+		},
+		{
+			name:    "duplicate name",
+			skills:  []Skill{{Name: "a", From: text("1")}, {Name: "a", From: text("2")}},
+			wantErr: `duplicate skill name "a"`,
+		},
+		{
+			name:    "missing source",
+			skills:  []Skill{{Name: "a"}},
+			wantErr: "skill a must have a 'from' source",
+		},
+		{
+			name:    "file outside the skill",
+			skills:  []Skill{{Name: "a", From: text("1"), Files: map[string]*adcp.CommandFrom{"../b/SKILL.md": text("2")}}},
+			wantErr: `invalid file "../b/SKILL.md" of skill a`,
+		},
+		{
+			name:    "file replacing the instructions",
+			skills:  []Skill{{Name: "a", From: text("1"), Files: map[string]*adcp.CommandFrom{"./SKILL.md": text("2")}}},
+			wantErr: `invalid file "./SKILL.md" of skill a`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ide := &IDE{SkillsFolder: ".skills", Skills: tt.skills}
+			res, err := ide.Materialize(context.Background(), &adcp.Ide{})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got := map[string]string{}
+			for _, e := range res.GetEntries() {
+				got[e.GetFile().GetPath()] = e.GetFile().GetContent()
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package shared
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/frontmatter"
+)
+
+// CommandTemplateData is passed to IDE.CommandTemplate when rendering a command file.
+type CommandTemplateData struct {
+	// Name is the resolved command name.
+	Name string
+	// Body is the fetched command content.
+	Body string
+	// Frontmatter is the rendered YAML frontmatter block, empty when the command has no metadata.
+	Frontmatter string
+	// Metadata is the command's metadata, zero when none is configured.
+	Metadata CommandMetadata
+}
+
+// ParseCommandTemplate parses a command wrapper template with helpers for common output formats:
+//   - yaml: renders a YAML scalar, quoting when needed
+//   - yamlLiteral: renders a YAML literal block scalar for a top-level key
+//   - tomlString: renders a TOML basic string
+//   - tomlMultiline: renders a TOML multi-line basic string
+func ParseCommandTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{
+		"yaml":          frontmatter.Scalar,
+		"yamlLiteral":   yamlLiteral,
+		"tomlString":    tomlString,
+		"tomlMultiline": tomlMultiline,
+	}).Parse(text)
+}
+
+// MustParseCommandTemplate is like ParseCommandTemplate but panics on error.
+// Intended for templates defined as package-level variables by providers.
+func MustParseCommandTemplate(name, text string) *template.Template {
+	return template.Must(ParseCommandTemplate(name, text))
+}
+
+func yamlLiteral(s string) string {
+	header := "|"
+	if strings.HasPrefix(s, " ") || strings.HasPrefix(s, "\n") {
+		// Leading whitespace would otherwise be taken as the block's indentation.
+		header = "|2"
+	}
+	s = strings.TrimRight(s, "\n")
+	var b strings.Builder
+	b.WriteString(header)
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString("\n")
+		if line != "" {
+			b.WriteString("  " + line)
+		}
+	}
+	return b.String()
+}
+
+func tomlString(s string) string {
+	return strconv.Quote(s)
+}
+
+func tomlMultiline(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"""`, `\"""`)
+	return "\"\"\"\n" + s + "\"\"\""
+}
@@ -0,0 +1,64 @@
+package shared
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestIDE_Materialize_CommandExtensionAndTemplate(t *testing.T) {
+	g := &IDE{
+		CommandsFolder:   ".gemini/commands",
+		CommandExtension: ".toml",
+		CommandTemplate: MustParseCommandTemplate("gemini", `description = {{ tomlString .Metadata.Description }}
+prompt = {{ tomlMultiline .Body }}
+`),
+		CommandMetadata: map[string]CommandMetadata{"plan": {Description: `Plan the "next" step`}},
+	}
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "plan", From: adcp.CommandFrom_builder{Text: strPtr("Write a plan.\n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "description = \"Plan the \\\"next\\\" step\"\nprompt = \"\"\"\nWrite a plan.\n\"\"\"\n", m[".gemini/commands/plan.toml"])
+	assert.Contains(t, m[".gemini/commands/.adcp-manifest.json"], "plan.toml")
+}
+
+func TestIDE_Materialize_PromptExtension(t *testing.T) {
+	g := &IDE{CommandsFolder: ".github/prompts", CommandExtension: ".prompt.md"}
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review.")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+	assert.Equal(t, ".github/prompts/review.prompt.md", res.GetEntries()[0].GetFile().GetPath())
+	assert.Equal(t, "Review.", res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestYAMLLiteral(t *testing.T) {
+	for _, body := range []string{"Review the diff.\n", "  indented\nsecond\n\nafter blank", "a: b # not a comment\n"} {
+		var doc struct {
+			Prompt string `yaml:"prompt"`
+		}
+		require.NoError(t, yaml.Unmarshal([]byte("prompt: "+yamlLiteral(body)+"\n"), &doc))
+		assert.Equal(t, strings.TrimRight(body, "\n")+"\n", doc.Prompt)
+	}
+}
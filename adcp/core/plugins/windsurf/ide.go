@@ -0,0 +1,85 @@
+// Package windsurf materializes recipes for Windsurf: rules in .windsurf/rules, commands as
+// workflows in .windsurf/workflows and MCP servers merged into ~/.codeium/windsurf/mcp_config.json.
+package windsurf
+
+import (
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultMCPConfigPath is where MCP servers are written by default: Windsurf only reads
+// ~/.codeium/windsurf/mcp_config.json. It is a user-level file (see core.HomePrefix), only written
+// when the caller enables user-level files with core.WithUserRoot; otherwise MCP servers are
+// skipped with a warning.
+const DefaultMCPConfigPath = core.HomePrefix + ".codeium/windsurf/mcp_config.json"
+
+// Option configures the Windsurf IDE provider. Options from package shared apply as well.
+type Option = shared.Option
+
+// WithMCPConfigPath writes MCP servers to path instead of DefaultMCPConfigPath, e.g.
+// ".codeium/windsurf/mcp_config.json" when materializing into the home directory.
+func WithMCPConfigPath(path string) Option {
+	return shared.WithMCPPath(path)
+}
+
+// WithAgentInstructions generates a managed section with the given instructions in AGENTS.md,
+// which Windsurf applies as project-wide guidance.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *shared.IDE) {
+		ide.AgentsMDPath = "AGENTS.md"
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to servers written into the MCP config.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return shared.WithMCPServerOptions(opts)
+}
+
+// WithCommandMetadata sets per-command metadata. Workflows support the description frontmatter field.
+func WithCommandMetadata(meta map[string]shared.CommandMetadata) Option {
+	return shared.WithCommandMetadata(meta)
+}
+
+// NewIDEProvider returns a provider for Windsurf.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	defaults := []Option{
+		shared.WithCommandsFolder(".windsurf/workflows"),
+		shared.WithMCPPath(DefaultMCPConfigPath),
+		shared.WithMCPServerFunc(mcpServer),
+		shared.WithCommandFrontmatterFields(shared.FieldDescription),
+		shared.WithAgentsMDPath("AGENTS.md"),
+		shared.WithManagedMemory(true),
+		shared.WithContextPath(shared.DestinationRules, ".windsurf/rules"),
+		shared.WithContextPath(shared.DestinationMemory, "AGENTS.md"),
+		shared.WithContextPath(shared.DestinationDocs, ".windsurf/docs"),
+	}
+	return shared.NewIDE(append(defaults, opts...)...)
+}
+
+// mcpServerConfig is a server in Windsurf's format, which has no type and names the URL of
+// remote servers serverUrl.
+type mcpServerConfig struct {
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	ServerURL string            `json:"serverUrl,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+func mcpServer(s *adcp.McpServer, opts shared.MCPServerOptions) any {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return mcpServerConfig{ServerURL: url, Headers: opts.Headers}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return mcpServerConfig{Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
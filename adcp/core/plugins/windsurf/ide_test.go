@@ -0,0 +1,97 @@
+package windsurf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Mcp(t *testing.T) {
+	g := NewIDEProvider(
+		WithMCPServerOptions(map[string]shared.MCPServerOptions{
+			"github":  {Headers: map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}},
+			"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+		}),
+	)
+	home := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".codeium", "windsurf"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"),
+		[]byte(`{"mcpServers": {"existing": {"serverUrl": "https://example.com"}}}`), 0o644))
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(core.WithUserRoot(context.Background(), home), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	file := res.GetEntries()[0].GetFile()
+	assert.Equal(t, "~/.codeium/windsurf/mcp_config.json", file.GetPath())
+	assert.True(t, core.IsUserFile(file), "the config Windsurf reads is persisted under the user root")
+	assert.JSONEq(t, `{"mcpServers": {
+		"existing": {"serverUrl": "https://example.com"},
+		"devplan": {"command": "devplan", "args": ["mcp"], "env": {"DEVPLAN_API_KEY": "key"}},
+		"github": {"serverUrl": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${env:GITHUB_TOKEN}"}}
+	}}`, file.GetContent())
+
+	// Without user-level files, no config Windsurf never loads is written.
+	report := core.NewReport()
+	res, err = g.Materialize(core.WithReport(context.Background(), report), ide)
+	require.NoError(t, err)
+	assert.Empty(t, res.GetEntries())
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningSkippedUserFile, report.Data().Warnings[0].Code)
+	assert.Contains(t, report.Data().Warnings[0].Message, "MCP servers")
+
+	// A project-level path is written as usual.
+	fsys := fstest.MapFS{".windsurf/mcp_config.json": {Data: []byte(`{"mcpServers": {}}`)}}
+	res, err = NewIDEProvider(WithMCPConfigPath(".windsurf/mcp_config.json")).Materialize(core.WithFS(context.Background(), fsys), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, ".windsurf/mcp_config.json", res.GetEntries()[0].GetFile().GetPath())
+	assert.False(t, core.IsUserFile(res.GetEntries()[0].GetFile()))
+}
+
+func TestIDE_Materialize_Workflows(t *testing.T) {
+	g := NewIDEProvider(WithCommandMetadata(map[string]shared.CommandMetadata{
+		"review": {Description: "Review the current diff", Model: "swe-1"},
+	}))
+
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review the diff.\n")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	res, err := g.Materialize(context.Background(), ide)
+	require.NoError(t, err)
+
+	m := map[string]string{}
+	for _, e := range res.GetEntries() {
+		m[e.GetFile().GetPath()] = e.GetFile().GetContent()
+	}
+	assert.Equal(t, "---\ndescription: Review the current diff\n---\n\nReview the diff.\n", m[".windsurf/workflows/review.md"])
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*shared.IDE)
+
+	got, err := g.MapContextPath("@rules/style.md")
+	require.NoError(t, err)
+	assert.Equal(t, ".windsurf/rules/style.md", got)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
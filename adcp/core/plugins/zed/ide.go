@@ -0,0 +1,217 @@
+// Package zed materializes recipes for the Zed editor: MCP servers as context servers and agent
+// settings merged into .zed/settings.json, and project guidance in AGENTS.md.
+package zed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared/merge"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// DefaultSettingsPath is the project-level Zed settings file.
+const DefaultSettingsPath = ".zed/settings.json"
+
+// contextServersKey holds MCP servers in Zed's settings.
+const contextServersKey = "context_servers"
+
+// IDE materializes recipes for Zed. Context is handled by the embedded shared.IDE; MCP servers and
+// agent settings are merged into SettingsPath by IDE itself.
+type IDE struct {
+	*shared.IDE
+	// SettingsPath is the Zed settings file servers and agent settings are merged into. Settings
+	// not managed by adcp are kept; comments are not.
+	SettingsPath string
+	// AgentSettings are deep-merged into the "agent" settings, e.g.
+	// {"default_model": {"provider": "anthropic", "model": "claude-sonnet-4"}}.
+	AgentSettings map[string]any
+}
+
+// Option configures the Zed IDE provider.
+type Option func(ide *IDE)
+
+// WithSettingsPath merges into path instead of DefaultSettingsPath, e.g. ".config/zed/settings.json"
+// when materializing into the home directory.
+func WithSettingsPath(path string) Option {
+	return func(ide *IDE) {
+		ide.SettingsPath = path
+	}
+}
+
+// WithAgentSettings deep-merges settings into Zed's "agent" settings.
+func WithAgentSettings(settings map[string]any) Option {
+	return func(ide *IDE) {
+		ide.AgentSettings = settings
+	}
+}
+
+// WithAgentInstructions generates a managed section with the given instructions in AGENTS.md,
+// which Zed's agent reads as project rules.
+func WithAgentInstructions(instructions string) Option {
+	return func(ide *IDE) {
+		ide.AgentsMDPath = "AGENTS.md"
+		ide.AgentInstructions = instructions
+	}
+}
+
+// WithMCPServerOptions adds env (stdio) and headers (HTTP) to context servers.
+func WithMCPServerOptions(opts map[string]shared.MCPServerOptions) Option {
+	return func(ide *IDE) {
+		ide.MCPServerOptions = opts
+	}
+}
+
+// NewIDEProvider returns a provider for Zed. Zed keeps its prompt library in its own database and
+// has no project-level permission settings, so commands and permissions are not materialized.
+func NewIDEProvider(opts ...Option) recipes.IDEProvider {
+	ide := &IDE{
+		IDE: shared.NewIDE(
+			shared.WithAgentsMDPath("AGENTS.md"),
+			shared.WithManagedMemory(true),
+			shared.WithContextPath(shared.DestinationMemory, "AGENTS.md"),
+			shared.WithContextPath(shared.DestinationDocs, ".zed/docs"),
+		),
+		SettingsPath: DefaultSettingsPath,
+	}
+	return shared.Configure(ide, opts...)
+}
+
+// ManagedPaths adds the Zed settings to the paths of the embedded shared.IDE.
+func (i *IDE) ManagedPaths() []recipes.ManagedPath {
+	paths := i.IDE.ManagedPaths()
+	if i.SettingsPath != "" {
+		paths = append(paths, recipes.ManagedPath{Pattern: i.SettingsPath, Merge: core.MergeJSON})
+	}
+	return paths
+}
+
+// Capabilities reports that Zed supports MCP servers, memory files and docs.
+func (i *IDE) Capabilities() recipes.Capabilities {
+	caps := recipes.Capabilities{
+		MemoryFiles: i.ContextPaths[shared.DestinationMemory] != "",
+		Rules:       i.ContextPaths[shared.DestinationRules] != "",
+		Docs:        i.ContextPaths[shared.DestinationDocs] != "",
+	}
+	if i.SettingsPath != "" {
+		caps.MCPTransports = []string{recipes.MCPTransportStdio, recipes.MCPTransportHTTP}
+	}
+	return caps
+}
+
+// OverridePaths returns a copy of the IDE with the memory or docs path overridden. MCP servers
+// follow SettingsPath; set it with WithSettingsPath.
+func (i *IDE) OverridePaths(overrides map[string]string) (recipes.IDEProvider, error) {
+	c := *i
+	return shared.OverrideEmbeddedPaths(&c, &c.IDE, overrides)
+}
+
+// Materialize merges MCP servers and AgentSettings into SettingsPath and produces the managed
+// section of AGENTS.md, if configured.
+func (i *IDE) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	if ide == nil {
+		return nil, fmt.Errorf("ide cannot be nil")
+	}
+	res, err := i.IDE.Materialize(ctx, adcp.Ide_builder{}.Build())
+	if err != nil {
+		return nil, err
+	}
+	entries := res.GetEntries()
+
+	if (ide.HasMcp() || len(i.AgentSettings) > 0) && i.SettingsPath != "" {
+		entry, err := i.materializeSettings(ctx, ide.GetMcp())
+		if err != nil {
+			return nil, err
+		}
+		core.RecordPlannedChange(ctx, core.PlannedChange{Path: i.SettingsPath, Merge: core.MergeJSON})
+		entries = append(entries, entry)
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), nil
+}
+
+// contextServer is a context server in Zed's settings.
+type contextServer struct {
+	Source  string            `json:"source,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func (i *IDE) materializeSettings(ctx context.Context, mcp *adcp.Mcp) (*adcp.MaterializedResult_Entry, error) {
+	doc, _ := merge.ParseDocument(shared.ReadExistingJSON(ctx, i.SettingsPath))
+
+	if mcp != nil {
+		serverOptions, err := shared.ExpandServerOptions(ctx, i.MCPServerOptions)
+		if err != nil {
+			return nil, err
+		}
+		servers := doc.Nested(contextServersKey)
+		for _, name := range slices.Sorted(maps.Keys(mcp.GetServers())) {
+			srv := newContextServer(mcp.GetServers()[name], serverOptions[name])
+			if srv == nil {
+				core.Warn(ctx, core.Warning{
+					Code:    core.WarningIgnoredMCPServer,
+					Path:    i.SettingsPath,
+					Message: fmt.Sprintf("MCP server %s has no transport (stdio or http) and is ignored", name),
+				})
+				continue
+			}
+			if err := servers.Set(name, srv); err != nil {
+				return nil, fmt.Errorf("failed to set context server %s: %w", name, err)
+			}
+		}
+		if err := doc.Set(contextServersKey, servers); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(i.AgentSettings) > 0 {
+		data, err := json.Marshal(i.AgentSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal agent settings: %w", err)
+		}
+		patch, err := merge.ParseObject(data)
+		if err != nil {
+			return nil, err
+		}
+		agent := doc.Nested("agent")
+		if err := merge.Deep(agent, patch, nil); err != nil {
+			return nil, fmt.Errorf("failed to merge agent settings: %w", err)
+		}
+		if err := doc.Set("agent", agent); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := doc.String()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal zed settings: %w", err)
+	}
+	return adcp.MaterializedResult_Entry_builder{
+		File: adcp.FullFileContent_builder{Path: i.SettingsPath, Content: content}.Build(),
+	}.Build(), nil
+}
+
+// newContextServer converts s into a context server, or returns nil if s has no usable transport.
+func newContextServer(s *adcp.McpServer, opts shared.MCPServerOptions) *contextServer {
+	switch s.WhichType() {
+	case adcp.McpServer_Http_case:
+		if url := s.GetHttp().GetUrl(); url != "" {
+			return &contextServer{URL: url, Headers: opts.Headers}
+		}
+	case adcp.McpServer_Stdio_case:
+		command, args, env := shared.StdioCommand(s.GetStdio(), opts)
+		if command != "" {
+			return &contextServer{Source: "custom", Command: command, Args: args, Env: env}
+		}
+	}
+	return nil
+}
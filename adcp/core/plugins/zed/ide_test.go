@@ -0,0 +1,73 @@
+package zed
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDE_Materialize_Settings(t *testing.T) {
+	g := NewIDEProvider(
+		WithAgentSettings(map[string]any{"default_model": map[string]any{"provider": "anthropic", "model": "claude-sonnet-4"}}),
+		WithMCPServerOptions(map[string]shared.MCPServerOptions{
+			"github":  {Headers: map[string]string{"Authorization": "Bearer ${env:GITHUB_TOKEN}"}},
+			"devplan": {Env: map[string]string{"DEVPLAN_API_KEY": "key"}},
+		}),
+	)
+	fsys := fstest.MapFS{DefaultSettingsPath: {Data: []byte(`{
+  // Project settings
+  "tab_size": 4,
+  "agent": {"enabled": true},
+  "context_servers": {"existing": {"source": "custom", "command": "node"}},
+}`)}}
+
+	ide := adcp.Ide_builder{
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	report := core.NewReport()
+	res, err := g.Materialize(core.WithReport(core.WithFS(context.Background(), fsys), report), ide)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Empty(t, report.Data().Warnings)
+	assert.Equal(t, DefaultSettingsPath, res.GetEntries()[0].GetFile().GetPath())
+	assert.JSONEq(t, `{
+		"tab_size": 4,
+		"agent": {"enabled": true, "default_model": {"model": "claude-sonnet-4", "provider": "anthropic"}},
+		"context_servers": {
+			"existing": {"source": "custom", "command": "node"},
+			"devplan": {"source": "custom", "command": "devplan", "args": ["mcp"], "env": {"DEVPLAN_API_KEY": "key"}},
+			"github": {"url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${env:GITHUB_TOKEN}"}}
+		}
+	}`, res.GetEntries()[0].GetFile().GetContent())
+}
+
+func TestIDE_Materialize_AgentsMD(t *testing.T) {
+	g := NewIDEProvider(WithAgentInstructions("Run `make test`."))
+
+	res, err := g.Materialize(context.Background(), adcp.Ide_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 1)
+	assert.Equal(t, "AGENTS.md", res.GetEntries()[0].GetFile().GetPath())
+	assert.Contains(t, res.GetEntries()[0].GetFile().GetContent(), "Run `make test`.")
+}
+
+func TestIDE_MapContextPath(t *testing.T) {
+	g := NewIDEProvider().(*IDE)
+
+	got, err := g.MapContextPath("@memory")
+	require.NoError(t, err)
+	assert.Equal(t, "AGENTS.md", got)
+
+	_, err = g.MapContextPath("@rules/style.md")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// mcpServerKeys are the top-level keys under which IDE configuration files list MCP servers.
+var mcpServerKeys = []string{"mcpServers", "servers"}
+
+// mcpURLKeys are the keys holding the endpoint of remote MCP servers.
+var mcpURLKeys = []string{"url", "serverUrl", "httpUrl"}
+
+// DenyAllowRules rejects JSON settings files whose permissions.allow list contains any of rules,
+// e.g. DenyAllowRules("Bash(*)", "Bash", "Shell(*)") forbids allowing every shell command.
+func DenyAllowRules(rules ...string) Policy {
+	return Func(func(_ context.Context, files []File) ([]Violation, error) {
+		var violations []Violation
+		for _, f := range jsonFiles(files) {
+			var doc struct {
+				Permissions struct {
+					Allow []any `json:"allow"`
+				} `json:"permissions"`
+			}
+			if json.Unmarshal([]byte(f.Content), &doc) != nil {
+				continue
+			}
+			for _, rule := range doc.Permissions.Allow {
+				if s, ok := rule.(string); ok && slices.Contains(rules, s) {
+					violations = append(violations, Violation{
+						Policy:  "deny-allow-rules",
+						Path:    f.Path,
+						Message: fmt.Sprintf("allow list contains forbidden rule %q", s),
+					})
+				}
+			}
+		}
+		return violations, nil
+	})
+}
+
+// AllowedMCPHosts rejects remote MCP servers whose URL host is not one of hosts. A host of the form
+// "*.example.com" matches every subdomain of example.com. Stdio servers are not affected.
+func AllowedMCPHosts(hosts ...string) Policy {
+	return Func(func(_ context.Context, files []File) ([]Violation, error) {
+		var violations []Violation
+		for _, f := range jsonFiles(files) {
+			var doc map[string]json.RawMessage
+			if json.Unmarshal([]byte(f.Content), &doc) != nil {
+				continue
+			}
+			for _, key := range mcpServerKeys {
+				var servers map[string]map[string]any
+				if json.Unmarshal(doc[key], &servers) != nil {
+					continue
+				}
+				for _, name := range slices.Sorted(maps.Keys(servers)) {
+					if msg := checkMCPHost(servers[name], hosts); msg != "" {
+						violations = append(violations, Violation{
+							Policy:  "allowed-mcp-hosts",
+							Path:    f.Path,
+							Message: fmt.Sprintf("MCP server %s %s", name, msg),
+						})
+					}
+				}
+			}
+		}
+		return violations, nil
+	})
+}
+
+// checkMCPHost describes why the URL of server is not allowed, or returns "" if it is.
+func checkMCPHost(server map[string]any, hosts []string) string {
+	for _, key := range mcpURLKeys {
+		raw, ok := server[key].(string)
+		if !ok {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return fmt.Sprintf("has an invalid URL %q", raw)
+		}
+		if !hostAllowed(u.Hostname(), hosts) {
+			return fmt.Sprintf("uses host %s, which is not allowed", u.Hostname())
+		}
+	}
+	return ""
+}
+
+func hostAllowed(host string, hosts []string) bool {
+	host = strings.ToLower(host)
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if suffix, ok := strings.CutPrefix(h, "*"); ok && strings.HasPrefix(suffix, ".") {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+			continue
+		}
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonFiles(files []File) []File {
+	var out []File
+	for _, f := range files {
+		if path.Ext(f.Path) == ".json" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
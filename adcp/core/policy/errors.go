@@ -0,0 +1,27 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrViolation matches errors returned when materialized output violates a policy.
+var ErrViolation = errors.New("policy violation")
+
+// ViolationError lists every violation found in a result. It matches ErrViolation.
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("%d policy violation(s): %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+func (e *ViolationError) Unwrap() error {
+	return ErrViolation
+}
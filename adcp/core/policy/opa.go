@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// DefaultOPAQuery is the query evaluated by OPA when none is set.
+const DefaultOPAQuery = "data.adcp.deny"
+
+// OPA evaluates Rego policies with the opa command line tool. The input document is
+// {"files": [{"path": ..., "content": ...}]}, and the query must produce a collection of
+// violations, each either a message string or an object with "message" and optionally "path":
+//
+//	package adcp
+//
+//	deny contains msg if {
+//		some f in input.files
+//		contains(f.content, "Bash(*)")
+//		msg := sprintf("%s allows every shell command", [f.path])
+//	}
+type OPA struct {
+	// Policies are the Rego files or directories to load.
+	Policies []string
+	// Query selects the violations. Defaults to DefaultOPAQuery.
+	Query string
+	// Command runs OPA. Defaults to []string{"opa"}.
+	Command []string
+}
+
+func (o OPA) Evaluate(ctx context.Context, files []File) ([]Violation, error) {
+	input, err := json.Marshal(map[string]any{"files": files})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OPA input: %w", err)
+	}
+	command := o.Command
+	if len(command) == 0 {
+		command = []string{"opa"}
+	}
+	query := o.Query
+	if query == "" {
+		query = DefaultOPAQuery
+	}
+	args := append(append([]string(nil), command[1:]...), "eval", "--format", "json", "--stdin-input")
+	for _, p := range o.Policies {
+		args = append(args, "--data", p)
+	}
+	args = append(args, query)
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run opa: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return parseOPAOutput(out, query)
+}
+
+// parseOPAOutput converts the output of "opa eval --format json" into violations.
+func parseOPAOutput(out []byte, query string) ([]Violation, error) {
+	var resp struct {
+		Result []struct {
+			Expressions []struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode opa output: %w", err)
+	}
+	var violations []Violation
+	for _, r := range resp.Result {
+		for _, e := range r.Expressions {
+			var values []json.RawMessage
+			if err := json.Unmarshal(e.Value, &values); err != nil {
+				return nil, fmt.Errorf("%s must produce a collection of violations: %w", query, err)
+			}
+			for _, raw := range values {
+				v := Violation{Policy: query}
+				if err := json.Unmarshal(raw, &v.Message); err != nil {
+					if err := json.Unmarshal(raw, &v); err != nil || v.Message == "" {
+						return nil, fmt.Errorf("%s produced an invalid violation %s", query, raw)
+					}
+					if v.Policy == "" {
+						v.Policy = query
+					}
+				}
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations, nil
+}
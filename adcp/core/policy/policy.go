@@ -0,0 +1,100 @@
+// Package policy evaluates materialized results against organizational rules before they are
+// persisted, e.g. rejecting settings that allow every shell command or MCP servers hosted outside
+// an approved list:
+//
+//	err := policy.Check(ctx, result,
+//		policy.DenyAllowRules("Bash(*)", "Shell(*)"),
+//		policy.AllowedMCPHosts("*.example.com"),
+//	)
+//
+// Policies see the final files, so they apply to every IDE provider. Rules can also be written in
+// Rego and evaluated with the OPA command line tool (see OPA).
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Violation describes a single breach of a policy.
+type Violation struct {
+	// Policy names the policy that was violated.
+	Policy string `json:"policy"`
+	// Path is the materialized file that violates the policy, if any.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return fmt.Sprintf("[%s] %s", v.Policy, v.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", v.Policy, v.Path, v.Message)
+}
+
+// Policy evaluates a materialized result. It returns the violations found; an error means the
+// policy could not be evaluated at all.
+type Policy interface {
+	Evaluate(ctx context.Context, files []File) ([]Violation, error)
+}
+
+// Func adapts a function to the Policy interface.
+type Func func(ctx context.Context, files []File) ([]Violation, error)
+
+func (f Func) Evaluate(ctx context.Context, files []File) ([]Violation, error) {
+	return f(ctx, files)
+}
+
+// File is a materialized file as seen by policies.
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Files returns the files of result with content resolved from the Blobs carried by ctx
+// (see core.WithBlobs).
+func Files(ctx context.Context, result *adcp.MaterializedResult) ([]File, error) {
+	blobs := core.BlobsFrom(ctx)
+	files := make([]File, 0, len(result.GetEntries()))
+	for _, e := range result.GetEntries() {
+		if !e.HasFile() {
+			continue
+		}
+		content, err := blobs.Resolve(e.GetFile().GetContent())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.GetFile().GetPath(), err)
+		}
+		files = append(files, File{Path: e.GetFile().GetPath(), Content: content})
+	}
+	return files, nil
+}
+
+// Check evaluates result against every policy. It returns a *ViolationError listing all
+// violations, or the first error of a policy that could not be evaluated.
+func Check(ctx context.Context, result *adcp.MaterializedResult, policies ...Policy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	files, err := Files(ctx, result)
+	if err != nil {
+		return err
+	}
+	log := core.Logger(ctx).With("op", "policy.Check")
+	var violations []Violation
+	for _, p := range policies {
+		found, err := p.Evaluate(ctx, files)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate policy: %w", err)
+		}
+		violations = append(violations, found...)
+	}
+	if len(violations) > 0 {
+		log.Debug("Policies rejected result", "violations", len(violations))
+		return &ViolationError{Violations: violations}
+	}
+	log.Debug("Policies passed", "policies", len(policies), "files", len(files))
+	return nil
+}
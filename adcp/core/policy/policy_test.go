@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func result(files map[string]string) *adcp.MaterializedResult {
+	var entries []*adcp.MaterializedResult_Entry
+	for p, c := range files {
+		entries = append(entries, adcp.MaterializedResult_Entry_builder{
+			File: adcp.FullFileContent_builder{Path: p, Content: c}.Build(),
+		}.Build())
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build()
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		policies []Policy
+		want     []Violation
+	}{
+		{
+			name:     "no policies",
+			files:    map[string]string{".claude/settings.local.json": `{"permissions": {"allow": ["Bash(*)"]}}`},
+			policies: nil,
+		},
+		{
+			name:     "forbidden allow rule",
+			files:    map[string]string{".claude/settings.local.json": `{"permissions": {"allow": ["Bash(go test:*)", "Bash(*)"], "deny": ["Bash(*)"]}}`},
+			policies: []Policy{DenyAllowRules("Bash(*)")},
+			want: []Violation{
+				{Policy: "deny-allow-rules", Path: ".claude/settings.local.json", Message: `allow list contains forbidden rule "Bash(*)"`},
+			},
+		},
+		{
+			name:     "allowed allow rules",
+			files:    map[string]string{".cursor/cli.json": `{"permissions": {"allow": ["Shell(go test)"]}}`},
+			policies: []Policy{DenyAllowRules("Shell(*)")},
+		},
+		{
+			name: "mcp hosts",
+			files: map[string]string{
+				".mcp.json":        `{"mcpServers": {"a": {"type": "http", "url": "https://api.example.com/mcp"}, "b": {"type": "http", "url": "https://mcp.other.test"}, "c": {"type": "stdio", "command": "devplan"}}}`,
+				".vscode/mcp.json": `{"servers": {"d": {"type": "http", "url": "https://EXAMPLE.com"}}}`,
+			},
+			policies: []Policy{AllowedMCPHosts("*.example.com")},
+			want: []Violation{
+				{Policy: "allowed-mcp-hosts", Path: ".mcp.json", Message: "MCP server b uses host mcp.other.test, which is not allowed"},
+				{Policy: "allowed-mcp-hosts", Path: ".vscode/mcp.json", Message: "MCP server d uses host EXAMPLE.com, which is not allowed"},
+			},
+		},
+		{
+			name:     "non-json files are ignored",
+			files:    map[string]string{"CLAUDE.md": `{"permissions": {"allow": ["Bash(*)"]}}`},
+			policies: []Policy{DenyAllowRules("Bash(*)")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(context.Background(), result(tt.files), tt.policies...)
+			if tt.want == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrViolation)
+			var violationErr *ViolationError
+			require.ErrorAs(t, err, &violationErr)
+			assert.ElementsMatch(t, tt.want, violationErr.Violations)
+		})
+	}
+}
+
+func TestCheck_PolicyError(t *testing.T) {
+	failing := Func(func(context.Context, []File) ([]Violation, error) {
+		return nil, assert.AnError
+	})
+	err := Check(context.Background(), result(nil), failing)
+	require.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, ErrViolation)
+}
+
+func TestParseOPAOutput(t *testing.T) {
+	out := `{"result": [{"expressions": [{"value": ["settings allow every command", {"path": ".mcp.json", "message": "unapproved server"}], "text": "data.adcp.deny"}]}]}`
+
+	violations, err := parseOPAOutput([]byte(out), "data.adcp.deny")
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{
+		{Policy: "data.adcp.deny", Message: "settings allow every command"},
+		{Policy: "data.adcp.deny", Path: ".mcp.json", Message: "unapproved server"},
+	}, violations)
+
+	_, err = parseOPAOutput([]byte(`{"result": [{"expressions": [{"value": true}]}]}`), "data.adcp.allow")
+	assert.Error(t, err)
+
+	violations, err = parseOPAOutput([]byte(`{}`), "data.adcp.deny")
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
@@ -0,0 +1,6 @@
+package prefetch
+
+import "errors"
+
+// ErrPrefetchTooLarge is returned when the output of a prefetch entry exceeds Processor.MaxSize.
+var ErrPrefetchTooLarge = errors.New("prefetch result too large")
@@ -2,14 +2,25 @@ package prefetch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp-core/adcp/core/utils"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
-type Processor struct{}
+// DefaultMaxSize bounds the output of a single prefetch entry when Processor.MaxSize is not set.
+const DefaultMaxSize = 64 << 20
+
+type Processor struct {
+	// MaxSize bounds the output of a single prefetch entry in bytes. Zero means DefaultMaxSize;
+	// a negative value removes the limit.
+	MaxSize int64
+}
 
 func (p *Processor) Process(ctx context.Context, prefetch *adcp.Prefetch) (map[string]*adcp.FetchedData, error) {
 	entries := prefetch.GetEntries()
@@ -18,24 +29,32 @@ func (p *Processor) Process(ctx context.Context, prefetch *adcp.Prefetch) (map[s
 	}
 
 	result := make(map[string]*adcp.FetchedData)
+	observer := core.ObserverFrom(ctx)
 
 	for i, entry := range entries {
 		if entry == nil {
 			return nil, fmt.Errorf("prefetch entry at index %d is nil", i)
 		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
 		// Process the entry based on its type
+		name := fmt.Sprintf("entry %d", i)
+		observer.EntryStarted(core.PhasePrefetch, name)
 		data, err := p.processEntry(ctx, entry)
+		observer.EntryFinished(core.PhasePrefetch, name, err)
 		if err != nil {
 			return nil, fmt.Errorf("failed to process entry at index %d: %w", i, err)
 		}
-		res := &adcp.PrefetchResult{}
-		u := protojson.UnmarshalOptions{DiscardUnknown: true}
-		if err := u.Unmarshal([]byte(data), res); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal prefetch result: %w", err)
+		if limit := p.maxSize(); limit > 0 && int64(len(data)) > limit {
+			return nil, fmt.Errorf("entry at index %d: %w: %d bytes exceed the limit of %d", i, ErrPrefetchTooLarge, len(data), limit)
 		}
-		for _, d := range res.GetData() {
+		err = decodeResult(data, func(d *adcp.FetchedData) {
 			result[d.GetId()] = d
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal prefetch result: %w", err)
 		}
 	}
 
@@ -49,6 +68,12 @@ func (p *Processor) processEntry(ctx context.Context, entry *adcp.PrefetchEntry)
 		if cmd == "" {
 			return "", fmt.Errorf("cmd cannot be empty")
 		}
+		if core.IsDryRun(ctx) {
+			// Commands may have side effects; a dry run fetches nothing, and context referring to
+			// prefetched data renders a placeholder instead.
+			core.Logger(ctx).Debug("Dry run, skipping prefetch command", "cmd", cmd)
+			return `{}`, nil
+		}
 		data, err := utils.ExecuteCommand(ctx, cmd)
 		if err != nil {
 			return "", fmt.Errorf("command execution failed: %w", err)
@@ -59,3 +84,75 @@ func (p *Processor) processEntry(ctx context.Context, entry *adcp.PrefetchEntry)
 		return "", fmt.Errorf("unknown or unset prefetch entry type [%v]", entry.WhichType())
 	}
 }
+
+func (p *Processor) maxSize() int64 {
+	if p.MaxSize == 0 {
+		return DefaultMaxSize
+	}
+	return p.MaxSize
+}
+
+// decodeResult decodes a JSON-encoded adcp.PrefetchResult one data element at a time, passing each to
+// fn, so that a large result is never held in memory both as JSON and as a decoded message tree.
+func decodeResult(data string, fn func(*adcp.FetchedData)) error {
+	dec := json.NewDecoder(strings.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	u := protojson.UnmarshalOptions{DiscardUnknown: true}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != "data" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == nil {
+			continue
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return fmt.Errorf("data must be an array")
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			d := &adcp.FetchedData{}
+			if err := u.Unmarshal(raw, d); err != nil {
+				return err
+			}
+			fn(d)
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after prefetch result")
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %v, got %v", delim, tok)
+	}
+	return nil
+}
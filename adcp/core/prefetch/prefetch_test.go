@@ -2,8 +2,10 @@ package prefetch
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -107,6 +109,25 @@ func TestProcessor_Process(t *testing.T) {
 			prefetch: prefetchWith(cmdEntry(`printf '{"data": [{"id": "multi", "data": "line1\\nline2"}]}'`)),
 			want:     map[string]string{"multi": "line1\nline2"},
 		},
+		{
+			name:     "unknown fields are ignored",
+			prefetch: prefetchWith(cmdEntry(`echo '{"meta": {"v": [1]}, "data": [{"id": "a", "data": "x", "extra": true}]}'`)),
+			want:     map[string]string{"a": "x"},
+		},
+		{
+			name:     "null data",
+			prefetch: prefetchWith(cmdEntry(`echo '{"data": null}'`)),
+		},
+		{
+			name:     "data is not an array",
+			prefetch: prefetchWith(cmdEntry(`echo '{"data": {"id": "a"}}'`)),
+			wantErr:  "failed to unmarshal prefetch result",
+		},
+		{
+			name:     "trailing data",
+			prefetch: prefetchWith(cmdEntry(`echo '{"data": []} {}'`)),
+			wantErr:  "failed to unmarshal prefetch result",
+		},
 	}
 
 	for _, tt := range tests {
@@ -176,6 +197,15 @@ func TestProcessor_ProcessEntry(t *testing.T) {
 	}
 }
 
+func TestProcessor_Process_DryRun(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	p := &Processor{}
+	result, err := p.Process(core.WithDryRun(context.Background(), nil), prefetchWith(cmdEntry("touch "+marker)))
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoFileExists(t, marker)
+}
+
 func TestProcessor_Process_CancelledContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -184,3 +214,14 @@ func TestProcessor_Process_CancelledContext(t *testing.T) {
 	_, err := p.Process(ctx, prefetchWith(cmdEntry(`sleep 10`)))
 	assert.Error(t, err)
 }
+
+func TestProcessor_Process_MaxSize(t *testing.T) {
+	p := &Processor{MaxSize: 16}
+	_, err := p.Process(context.Background(), prefetchWith(cmdEntry(`echo '{"data": [{"id": "a", "data": "too large"}]}'`)))
+	assert.ErrorIs(t, err, ErrPrefetchTooLarge)
+
+	p = &Processor{MaxSize: -1}
+	result, err := p.Process(context.Background(), prefetchWith(cmdEntry(`echo '{"data": [{"id": "a", "data": "unlimited"}]}'`)))
+	require.NoError(t, err)
+	assertResult(t, result, map[string]string{"a": "unlimited"})
+}
@@ -0,0 +1,183 @@
+// Package recipebuilder provides a fluent API for constructing recipes programmatically, e.g. in
+// server-side code generating recipes, without assembling the generated proto builders by hand:
+//
+//	recipe, err := recipebuilder.New().
+//		WithContextFile("AGENTS.md", recipebuilder.Github("https://github.com/org/repo/blob/main/AGENTS.md")).
+//		WithCommand("review", recipebuilder.Text("Review the staged changes.")).
+//		WithMCPStdio("devplan", "devplan mcp").
+//		WithAllow(recipebuilder.Bash("make test")).
+//		Build()
+package recipebuilder
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Builder accumulates the parts of a recipe. Its methods return the builder for chaining; problems
+// are collected and reported together by Build. The zero value is ready to use.
+type Builder struct {
+	prefetch    []*adcp.PrefetchEntry
+	context     []*adcp.ContextEntry
+	commands    []*adcp.Command
+	servers     map[string]*adcp.McpServer
+	allow, deny []*adcp.OperationPermission
+	errs        []error
+}
+
+// New returns an empty builder.
+func New() *Builder {
+	return &Builder{}
+}
+
+// WithPrefetch adds a prefetch entry whose command outputs data that context files can reference
+// with Prefetched.
+func (b *Builder) WithPrefetch(cmd string) *Builder {
+	if cmd == "" {
+		return b.fail("prefetch command cannot be empty")
+	}
+	b.prefetch = append(b.prefetch, adcp.PrefetchEntry_builder{Cmd: &cmd}.Build())
+	return b
+}
+
+// WithContextFile adds a file at path with content from src.
+func (b *Builder) WithContextFile(path string, src Source) *Builder {
+	switch {
+	case path == "":
+		return b.fail("context file path cannot be empty")
+	case !src.isSet():
+		return b.fail("context file %s has no source", path)
+	}
+	for _, e := range b.context {
+		if e.GetPath() == path {
+			return b.fail("duplicate context file %s", path)
+		}
+	}
+	b.context = append(b.context, adcp.ContextEntry_builder{Path: path, From: src.contextFrom()}.Build())
+	return b
+}
+
+// WithCommand adds a command called name with its body from src.
+func (b *Builder) WithCommand(name string, src Source) *Builder {
+	switch {
+	case name == "":
+		return b.fail("command name cannot be empty")
+	case !src.isSet():
+		return b.fail("command %s has no source", name)
+	}
+	for _, c := range b.commands {
+		if c.GetName() == name {
+			return b.fail("duplicate command %s", name)
+		}
+	}
+	from, err := src.commandFrom()
+	if err != nil {
+		return b.fail("command %s: %v", name, err)
+	}
+	b.commands = append(b.commands, adcp.Command_builder{Name: name, From: from}.Build())
+	return b
+}
+
+// WithMCPStdio adds an MCP server called name that runs command, e.g. "devplan mcp".
+func (b *Builder) WithMCPStdio(name, command string) *Builder {
+	if command == "" {
+		return b.fail("MCP server %s has no command", name)
+	}
+	return b.addServer(name, adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: command}.Build()}.Build())
+}
+
+// WithMCPHTTP adds an MCP server called name reachable at url.
+func (b *Builder) WithMCPHTTP(name, url string) *Builder {
+	if url == "" {
+		return b.fail("MCP server %s has no url", name)
+	}
+	return b.addServer(name, adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: url}.Build()}.Build())
+}
+
+func (b *Builder) addServer(name string, server *adcp.McpServer) *Builder {
+	if name == "" {
+		return b.fail("MCP server name cannot be empty")
+	}
+	if _, exists := b.servers[name]; exists {
+		return b.fail("duplicate MCP server %s", name)
+	}
+	if b.servers == nil {
+		b.servers = map[string]*adcp.McpServer{}
+	}
+	b.servers[name] = server
+	return b
+}
+
+// WithAllow allows the given operations, e.g. WithAllow(Bash("make test"), Read("docs/**")).
+func (b *Builder) WithAllow(perms ...*adcp.OperationPermission) *Builder {
+	b.allow = append(b.allow, perms...)
+	return b
+}
+
+// WithDeny denies the given operations.
+func (b *Builder) WithDeny(perms ...*adcp.OperationPermission) *Builder {
+	b.deny = append(b.deny, perms...)
+	return b
+}
+
+func (b *Builder) fail(format string, args ...any) *Builder {
+	b.errs = append(b.errs, fmt.Errorf(format, args...))
+	return b
+}
+
+// Build returns the recipe. Parts that were not added are left unset. All problems found while
+// building are returned together, wrapped in ErrInvalidRecipe.
+func (b *Builder) Build() (*adcp.Recipe, error) {
+	if len(b.errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecipe, errors.Join(b.errs...))
+	}
+	r := adcp.Recipe_builder{}
+	if len(b.prefetch) > 0 {
+		r.Prefetch = adcp.Prefetch_builder{Entries: slices.Clone(b.prefetch)}.Build()
+	}
+	if len(b.context) > 0 {
+		r.Context = adcp.Context_builder{Entries: slices.Clone(b.context)}.Build()
+	}
+	if len(b.commands) > 0 || len(b.servers) > 0 || len(b.allow) > 0 || len(b.deny) > 0 {
+		ide := adcp.Ide_builder{}
+		if len(b.commands) > 0 {
+			ide.Commands = adcp.Commands_builder{Entries: slices.Clone(b.commands)}.Build()
+		}
+		if len(b.servers) > 0 {
+			ide.Mcp = adcp.Mcp_builder{Servers: maps.Clone(b.servers)}.Build()
+		}
+		if len(b.allow) > 0 || len(b.deny) > 0 {
+			ide.Permissions = adcp.Permissions_builder{Allow: slices.Clone(b.allow), Deny: slices.Clone(b.deny)}.Build()
+		}
+		r.Ide = ide.Build()
+	}
+	return r.Build(), nil
+}
+
+// BuildExecutable returns the recipe wrapped into an executable recipe targeting ideType, e.g. "claude".
+func (b *Builder) BuildExecutable(ideType string) (*adcp.ExecutableRecipe, error) {
+	if ideType == "" {
+		return nil, fmt.Errorf("%w: IDE type cannot be empty", ErrInvalidRecipe)
+	}
+	recipe, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return adcp.ExecutableRecipe_builder{
+		Recipe:     recipe,
+		EntryPoint: adcp.EntryPoint_builder{IdeType: ideType}.Build(),
+	}.Build(), nil
+}
+
+// MustBuild is like Build but panics on error. It is meant for recipes fixed at compile time.
+func (b *Builder) MustBuild() *adcp.Recipe {
+	recipe, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return recipe
+}
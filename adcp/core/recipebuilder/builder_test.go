@@ -0,0 +1,112 @@
+package recipebuilder
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestBuilder_Build(t *testing.T) {
+	b := New().
+		WithPrefetch("devplan prefetch").
+		WithContextFile("AGENTS.md", GithubCommit("https://github.com/org/repo/blob/main/AGENTS.md", "abc")).
+		WithContextFile("docs/plan.md", Prefetched("plan")).
+		WithCommand("review", Text("Review the staged changes.")).
+		WithMCPStdio("devplan", "devplan mcp").
+		WithMCPHTTP("github", "https://api.githubcopilot.com/mcp/").
+		WithAllow(Bash("make test"), Read("docs/**")).
+		WithDeny(Write(".env"))
+	recipe, err := b.Build()
+	require.NoError(t, err)
+
+	want := adcp.Recipe_builder{
+		Prefetch: adcp.Prefetch_builder{Entries: []*adcp.PrefetchEntry{
+			adcp.PrefetchEntry_builder{Cmd: ptr("devplan prefetch")}.Build(),
+		}}.Build(),
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "AGENTS.md", From: adcp.ContextFrom_builder{Github: adcp.GitReference_builder{
+				Path:    "https://github.com/org/repo/blob/main/AGENTS.md",
+				Version: adcp.GitVersion_builder{Commit: ptr("abc")}.Build(),
+			}.Build()}.Build()}.Build(),
+			adcp.ContextEntry_builder{Path: "docs/plan.md", From: adcp.ContextFrom_builder{PrefetchId: ptr("plan")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: ptr("Review the staged changes.")}.Build()}.Build(),
+			}}.Build(),
+			Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+				"devplan": adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "devplan mcp"}.Build()}.Build(),
+				"github":  adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://api.githubcopilot.com/mcp/"}.Build()}.Build(),
+			}}.Build(),
+			Permissions: adcp.Permissions_builder{
+				Allow: []*adcp.OperationPermission{
+					adcp.OperationPermission_builder{Bash: ptr("make test")}.Build(),
+					adcp.OperationPermission_builder{Read: ptr("docs/**")}.Build(),
+				},
+				Deny: []*adcp.OperationPermission{adcp.OperationPermission_builder{Write: ptr(".env")}.Build()},
+			}.Build(),
+		}.Build(),
+	}.Build()
+	assert.True(t, proto.Equal(want, recipe), "got %v", recipe)
+
+	// Later changes to the builder do not affect recipes already built.
+	b.WithAllow(Bash("rm -rf /"))
+	assert.Len(t, recipe.GetIde().GetPermissions().GetAllow(), 2)
+}
+
+func TestBuilder_Build_Empty(t *testing.T) {
+	recipe, err := New().Build()
+	require.NoError(t, err)
+	assert.False(t, recipe.HasContext())
+	assert.False(t, recipe.HasIde())
+	assert.False(t, recipe.HasPrefetch())
+}
+
+func TestBuilder_Build_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(b *Builder)
+		wantErr string
+	}{
+		{name: "empty path", build: func(b *Builder) { b.WithContextFile("", Text("x")) }, wantErr: "path cannot be empty"},
+		{name: "missing source", build: func(b *Builder) { b.WithContextFile("a.md", Source{}) }, wantErr: "a.md has no source"},
+		{name: "duplicate context file", build: func(b *Builder) {
+			b.WithContextFile("a.md", Text("x")).WithContextFile("a.md", Text("y"))
+		}, wantErr: "duplicate context file a.md"},
+		{name: "duplicate command", build: func(b *Builder) {
+			b.WithCommand("fmt", Text("x")).WithCommand("fmt", Cmd("cat fmt.md"))
+		}, wantErr: "duplicate command fmt"},
+		{name: "prefetched command", build: func(b *Builder) { b.WithCommand("fmt", Prefetched("p")) }, wantErr: "not supported as a command source"},
+		{name: "duplicate server", build: func(b *Builder) {
+			b.WithMCPStdio("devplan", "devplan mcp").WithMCPHTTP("devplan", "https://example.com")
+		}, wantErr: "duplicate MCP server devplan"},
+		{name: "server without command", build: func(b *Builder) { b.WithMCPStdio("devplan", "") }, wantErr: "has no command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New()
+			tt.build(b)
+			_, err := b.Build()
+			assert.ErrorIs(t, err, ErrInvalidRecipe)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBuilder_BuildExecutable(t *testing.T) {
+	exec, err := New().WithContextFile("a.md", Text("a")).BuildExecutable("claude")
+	require.NoError(t, err)
+	assert.Equal(t, "claude", exec.GetEntryPoint().GetIdeType())
+	assert.Equal(t, "a.md", exec.GetRecipe().GetContext().GetEntries()[0].GetPath())
+
+	_, err = New().BuildExecutable("")
+	assert.ErrorIs(t, err, ErrInvalidRecipe)
+	assert.Panics(t, func() { New().WithCommand("", Text("x")).MustBuild() })
+}
@@ -0,0 +1,7 @@
+package recipebuilder
+
+import "errors"
+
+// ErrInvalidRecipe is returned by Build when the described recipe is invalid, e.g. has two
+// commands with the same name.
+var ErrInvalidRecipe = errors.New("invalid recipe")
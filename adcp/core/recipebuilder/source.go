@@ -0,0 +1,82 @@
+package recipebuilder
+
+import (
+	"fmt"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// Source is where the content of a context file or command comes from.
+type Source struct {
+	text       *string
+	cmd        *string
+	prefetchID *string
+	github     *adcp.GitReference
+}
+
+// Text is inline content.
+func Text(text string) Source {
+	return Source{text: &text}
+}
+
+// Cmd is the output of a shell command.
+func Cmd(cmd string) Source {
+	return Source{cmd: &cmd}
+}
+
+// Github is a file on GitHub, e.g. "https://github.com/org/repo/blob/main/AGENTS.md".
+func Github(url string) Source {
+	return Source{github: adcp.GitReference_builder{Path: url}.Build()}
+}
+
+// GithubTag is a file on GitHub at the given tag.
+func GithubTag(url, tag string) Source {
+	return Source{github: adcp.GitReference_builder{
+		Path:    url,
+		Version: adcp.GitVersion_builder{Tag: &tag}.Build(),
+	}.Build()}
+}
+
+// GithubCommit is a file on GitHub at the given commit. Pinned content can be reused by
+// incremental materialization (see recipes.WithState).
+func GithubCommit(url, commit string) Source {
+	return Source{github: adcp.GitReference_builder{
+		Path:    url,
+		Version: adcp.GitVersion_builder{Commit: &commit}.Build(),
+	}.Build()}
+}
+
+// Prefetched is the data of the prefetch entry with the given id. It is only supported for context files.
+func Prefetched(id string) Source {
+	return Source{prefetchID: &id}
+}
+
+func (s Source) isSet() bool {
+	return s.text != nil || s.cmd != nil || s.prefetchID != nil || s.github != nil
+}
+
+func (s Source) contextFrom() *adcp.ContextFrom {
+	return adcp.ContextFrom_builder{Text: s.text, Cmd: s.cmd, PrefetchId: s.prefetchID, Github: s.github}.Build()
+}
+
+func (s Source) commandFrom() (*adcp.CommandFrom, error) {
+	if s.prefetchID != nil {
+		return nil, fmt.Errorf("prefetched data is not supported as a command source")
+	}
+	return adcp.CommandFrom_builder{Text: s.text, Cmd: s.cmd, Github: s.github}.Build(), nil
+}
+
+// Bash allows or denies shell commands matching pattern.
+func Bash(pattern string) *adcp.OperationPermission {
+	return adcp.OperationPermission_builder{Bash: &pattern}.Build()
+}
+
+// Read allows or denies reading files matching pattern.
+func Read(pattern string) *adcp.OperationPermission {
+	return adcp.OperationPermission_builder{Read: &pattern}.Build()
+}
+
+// Write allows or denies writing files matching pattern.
+func Write(pattern string) *adcp.OperationPermission {
+	return adcp.OperationPermission_builder{Write: &pattern}.Build()
+}
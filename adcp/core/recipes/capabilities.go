@@ -0,0 +1,146 @@
+package recipes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// MCP transports an IDE provider may support.
+const (
+	MCPTransportStdio = "stdio"
+	MCPTransportHTTP  = "http"
+)
+
+// Recipe features reported in the Feature of unsupported-feature warnings.
+const (
+	FeatureCommands    = "commands"
+	FeaturePermissions = "permissions"
+	FeatureMCP         = "mcp"
+	FeatureRules       = "rules"
+	FeatureMemory      = "memory"
+	FeatureDocs        = "docs"
+	FeatureHooks       = "hooks"
+	FeatureAgents      = "agents"
+	FeatureSkills      = "skills"
+)
+
+// Capabilities describes which recipe features an IDE provider can represent.
+type Capabilities struct {
+	// MCPTransports lists the supported MCP server transports. Empty means MCP is not supported.
+	MCPTransports []string
+	Permissions   bool
+	Commands      bool
+	// Hooks reports whether the IDE runs hooks configured on its provider (see HookConfigurer).
+	Hooks bool
+	// MemoryFiles reports whether context entries may target the "@memory" destination.
+	MemoryFiles bool
+	// Rules reports whether context entries may target the "@rules" destination.
+	Rules bool
+	// Docs reports whether context entries may target the "@docs" destination.
+	Docs bool
+	// Agents reports whether the IDE supports subagent definitions.
+	Agents bool
+	// Skills reports whether the IDE supports skill packages.
+	Skills bool
+}
+
+// CapabilityProvider is an optional interface for IDE providers declaring their capabilities.
+// Providers that do not implement it are assumed to support everything.
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// HookConfigurer is an optional interface for IDE providers configured with hooks, which are not
+// part of the recipe model, e.g. through provider options.
+type HookConfigurer interface {
+	// HasHooks reports whether hooks are configured.
+	HasHooks() bool
+}
+
+// unsupportedHooks returns a warning if ide is configured with hooks the capabilities cannot
+// represent.
+func unsupportedHooks(ide IDEProvider, caps Capabilities) []core.Warning {
+	if hc, ok := ide.(HookConfigurer); ok && hc.HasHooks() && !caps.Hooks {
+		return []core.Warning{unsupportedFeature(FeatureHooks, "", "hooks are not supported and will be ignored")}
+	}
+	return nil
+}
+
+// unsupportedFeatures returns a warning for every part of ide the capabilities cannot represent.
+func unsupportedFeatures(ide *adcp.Ide, caps Capabilities) []core.Warning {
+	var issues []core.Warning
+	if len(ide.GetCommands().GetEntries()) > 0 && !caps.Commands {
+		issues = append(issues, unsupportedFeature(FeatureCommands, "", "commands are not supported and will be ignored"))
+	}
+	perms := ide.GetPermissions()
+	if (len(perms.GetAllow()) > 0 || len(perms.GetDeny()) > 0) && !caps.Permissions {
+		issues = append(issues, unsupportedFeature(FeaturePermissions, "", "permissions are not supported and will be ignored"))
+	}
+	servers := ide.GetMcp().GetServers()
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		transport := mcpTransport(servers[name])
+		if transport != "" && !slices.Contains(caps.MCPTransports, transport) {
+			issues = append(issues, unsupportedFeature(FeatureMCP, "",
+				fmt.Sprintf("MCP server %s uses unsupported transport %s and will be ignored", name, transport)))
+		}
+	}
+	return issues
+}
+
+// supportedContext returns c without the entries targeting a logical destination the capabilities
+// cannot represent, and a warning for each of them.
+func supportedContext(c *adcp.Context, caps Capabilities) (*adcp.Context, []core.Warning) {
+	supported := map[string]bool{
+		FeatureRules:  caps.Rules,
+		FeatureMemory: caps.MemoryFiles,
+		FeatureDocs:   caps.Docs,
+	}
+	messages := map[string]string{
+		FeatureRules:  "rules are not supported and will be ignored",
+		FeatureMemory: "memory files are not supported and will be ignored",
+		FeatureDocs:   "docs are not supported and will be ignored",
+	}
+	var entries []*adcp.ContextEntry
+	var issues []core.Warning
+	for _, entry := range c.GetEntries() {
+		p := entry.GetPath()
+		dest, logical := strings.CutPrefix(p, "@")
+		dest, _, _ = strings.Cut(dest, "/")
+		if ok, known := supported[dest]; logical && known && !ok {
+			issues = append(issues, unsupportedFeature(dest, p, messages[dest]))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(issues) == 0 {
+		return c, nil
+	}
+	return adcp.Context_builder{Entries: entries}.Build(), issues
+}
+
+func unsupportedFeature(feature, path, message string) core.Warning {
+	return core.Warning{Code: core.WarningUnsupportedFeature, Feature: feature, Path: path, Message: message}
+}
+
+func mcpTransport(s *adcp.McpServer) string {
+	if !s.HasType() {
+		return ""
+	}
+	switch s.WhichType() {
+	case adcp.McpServer_Stdio_case:
+		return MCPTransportStdio
+	case adcp.McpServer_Http_case:
+		return MCPTransportHTTP
+	default:
+		return ""
+	}
+}
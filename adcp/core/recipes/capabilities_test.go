@@ -0,0 +1,71 @@
+package recipes
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsupportedFeatures(t *testing.T) {
+	bash := "ls"
+	ide := adcp.Ide_builder{
+		Commands: adcp.Commands_builder{Entries: []*adcp.Command{adcp.Command_builder{Name: "run"}.Build()}}.Build(),
+		Permissions: adcp.Permissions_builder{
+			Allow: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: &bash}.Build()},
+		}.Build(),
+		Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+			"remote": adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://example.com"}.Build()}.Build(),
+			"local":  adcp.McpServer_builder{Stdio: adcp.StdioMcpServer_builder{Command: "srv"}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	assert.Empty(t, unsupportedFeatures(ide, Capabilities{
+		MCPTransports: []string{MCPTransportStdio, MCPTransportHTTP},
+		Permissions:   true,
+		Commands:      true,
+	}))
+
+	assert.Equal(t, []core.Warning{
+		{Code: core.WarningUnsupportedFeature, Feature: FeatureCommands, Message: "commands are not supported and will be ignored"},
+		{Code: core.WarningUnsupportedFeature, Feature: FeaturePermissions, Message: "permissions are not supported and will be ignored"},
+		{Code: core.WarningUnsupportedFeature, Feature: FeatureMCP, Message: "MCP server remote uses unsupported transport http and will be ignored"},
+	}, unsupportedFeatures(ide, Capabilities{MCPTransports: []string{MCPTransportStdio}}))
+}
+
+func TestSupportedContext(t *testing.T) {
+	text := "x"
+	entry := func(path string) *adcp.ContextEntry {
+		return adcp.ContextEntry_builder{Path: path, From: adcp.ContextFrom_builder{Text: &text}.Build()}.Build()
+	}
+	c := adcp.Context_builder{Entries: []*adcp.ContextEntry{
+		entry("@rules/style.md"), entry("@memory"), entry("docs/rulesets.md"),
+	}}.Build()
+
+	got, issues := supportedContext(c, Capabilities{Rules: true, MemoryFiles: true})
+	assert.Same(t, c, got)
+	assert.Empty(t, issues)
+
+	got, issues = supportedContext(c, Capabilities{MemoryFiles: true})
+	var paths []string
+	for _, e := range got.GetEntries() {
+		paths = append(paths, e.GetPath())
+	}
+	assert.Equal(t, []string{"@memory", "docs/rulesets.md"}, paths)
+	assert.Equal(t, []core.Warning{{
+		Code:    core.WarningUnsupportedFeature,
+		Feature: FeatureRules,
+		Path:    "@rules/style.md",
+		Message: "rules are not supported and will be ignored",
+	}}, issues)
+
+	got, issues = supportedContext(c, Capabilities{Rules: true})
+	assert.Len(t, got.GetEntries(), 2)
+	assert.Equal(t, []core.Warning{{
+		Code:    core.WarningUnsupportedFeature,
+		Feature: FeatureMemory,
+		Path:    "@memory",
+		Message: "memory files are not supported and will be ignored",
+	}}, issues)
+}
@@ -0,0 +1,105 @@
+package recipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalJSON encodes a recipe (or any other adcp message) as canonical JSON: lowerCamelCase field
+// names in field-number order, empty fields omitted, two-space indentation and a trailing newline.
+// Unlike protojson output, the result is byte-for-byte stable.
+func MarshalJSON(m proto.Message) ([]byte, error) {
+	raw, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", m.ProtoReflect().Descriptor().Name(), err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to indent json: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes JSON into m. Both lowerCamelCase and original proto field names are accepted;
+// unknown fields are rejected.
+func UnmarshalJSON(data []byte, m proto.Message) error {
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", m.ProtoReflect().Descriptor().Name(), err)
+	}
+	return nil
+}
+
+// MarshalYAML encodes m as canonical YAML with the same field naming, order and omission rules as MarshalJSON.
+func MarshalYAML(m proto.Message) ([]byte, error) {
+	data, err := MarshalJSON(m)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, so decoding into a node keeps the canonical key order.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert json to yaml: %w", err)
+	}
+	resetStyle(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalYAML decodes YAML into m, accepting the same field names as UnmarshalJSON.
+func UnmarshalYAML(data []byte, m proto.Message) error {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("failed to parse yaml: %w", err)
+	}
+	if v == nil {
+		v = map[string]any{}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to convert yaml to json: %w", err)
+	}
+	return UnmarshalJSON(raw, m)
+}
+
+// resetStyle switches flow-style nodes (as produced from JSON) to block style, renders multi-line
+// strings as literal blocks and drops quoting that YAML does not need.
+func resetStyle(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" && strings.Contains(strings.TrimRight(n.Value, "\n"), "\n") {
+		// Multi-line text such as command bodies stays readable as a literal block.
+		n.Style = yaml.LiteralStyle
+	} else if n.Kind == yaml.ScalarNode && n.Style == yaml.DoubleQuotedStyle {
+		n.Style = 0
+		// Keep quotes where the plain scalar would change type, e.g. "true" or "1".
+		if n.Tag == "!!str" {
+			var v any
+			if err := yaml.Unmarshal([]byte(n.Value), &v); err != nil || fmt.Sprint(v) != n.Value || !isString(v) {
+				n.Style = yaml.DoubleQuotedStyle
+			}
+		}
+	} else {
+		n.Style = 0
+	}
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+func isString(v any) bool {
+	_, ok := v.(string)
+	return ok
+}
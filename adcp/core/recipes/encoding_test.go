@@ -0,0 +1,97 @@
+package recipes_test
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func encodingRecipe() *adcp.Recipe {
+	return adcp.Recipe_builder{
+		Prefetch: adcp.Prefetch_builder{Entries: []*adcp.PrefetchEntry{
+			adcp.PrefetchEntry_builder{Cmd: strPtr("devplan prefetch")}.Build(),
+		}}.Build(),
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "AGENTS.md", From: adcp.ContextFrom_builder{Text: strPtr("true")}.Build()}.Build(),
+			adcp.ContextEntry_builder{Path: "docs/plan.md", From: adcp.ContextFrom_builder{PrefetchId: strPtr("plan")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{Text: strPtr("Review: the diff\n")}.Build()}.Build(),
+				adcp.Command_builder{Name: "plan", From: adcp.CommandFrom_builder{Text: strPtr("# Plan\n\nWrite a plan.\n")}.Build()}.Build(),
+			}}.Build(),
+			Permissions: adcp.Permissions_builder{
+				Allow: []*adcp.OperationPermission{adcp.OperationPermission_builder{Bash: strPtr("go test:*")}.Build()},
+			}.Build(),
+		}.Build(),
+	}.Build()
+}
+
+func TestMarshalYAML_RoundTrip(t *testing.T) {
+	recipe := encodingRecipe()
+
+	out, err := recipes.MarshalYAML(recipe)
+	require.NoError(t, err)
+	assert.Equal(t, `prefetch:
+  entries:
+    - cmd: devplan prefetch
+context:
+  entries:
+    - path: AGENTS.md
+      from:
+        text: "true"
+    - path: docs/plan.md
+      from:
+        prefetchId: plan
+ide:
+  commands:
+    entries:
+      - name: review
+        from:
+          text: "Review: the diff\n"
+      - name: plan
+        from:
+          text: |
+            # Plan
+
+            Write a plan.
+  permissions:
+    allow:
+      - bash: go test:*
+`, string(out))
+
+	parsed := &adcp.Recipe{}
+	require.NoError(t, recipes.UnmarshalYAML(out, parsed))
+	assert.True(t, proto.Equal(recipe, parsed))
+
+	again, err := recipes.MarshalYAML(parsed)
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+}
+
+func TestMarshalJSON_RoundTrip(t *testing.T) {
+	recipe := encodingRecipe()
+	out, err := recipes.MarshalJSON(recipe)
+	require.NoError(t, err)
+
+	parsed := &adcp.Recipe{}
+	require.NoError(t, recipes.UnmarshalJSON(out, parsed))
+	assert.True(t, proto.Equal(recipe, parsed))
+
+	again, err := recipes.MarshalJSON(parsed)
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+}
+
+func TestUnmarshalYAML_ProtoNamesAndUnknownFields(t *testing.T) {
+	parsed := &adcp.Recipe{}
+	require.NoError(t, recipes.UnmarshalYAML([]byte("context:\n  entries:\n    - path: a.md\n      from:\n        prefetch_id: x\n"), parsed))
+	assert.Equal(t, "x", parsed.GetContext().GetEntries()[0].GetFrom().GetPrefetchId())
+
+	err := recipes.UnmarshalYAML([]byte("ide:\n  comands: {}\n"), &adcp.Recipe{})
+	assert.ErrorContains(t, err, "comands")
+}
@@ -0,0 +1,11 @@
+package recipes
+
+import "errors"
+
+var (
+	// ErrNilRecipe is returned when Materialize is called without a recipe.
+	ErrNilRecipe = errors.New("recipe cannot be nil")
+	// ErrPathOverridesUnsupported is returned when path overrides are set for an IDE provider that
+	// does not implement PathOverrider.
+	ErrPathOverridesUnsupported = errors.New("IDE provider does not support path overrides")
+)
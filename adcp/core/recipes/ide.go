@@ -9,3 +9,49 @@ import (
 type IDEProvider interface {
 	Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error)
 }
+
+// ContextPathMapper is an optional interface for IDE providers that map logical context
+// destinations (e.g. "@rules/style.md") to the IDE's conventional locations.
+type ContextPathMapper interface {
+	MapContextPath(path string) (string, error)
+}
+
+// ContextRenderer is an optional interface for IDE providers that adapt the content of context
+// files to the IDE's format, e.g. adding frontmatter to rule files. It receives the mapped path.
+type ContextRenderer interface {
+	RenderContext(path, content string) (string, error)
+}
+
+// ContextMerger is an optional interface for IDE providers that merge context files into existing
+// files instead of replacing them, e.g. into the managed section of CLAUDE.md. It receives the
+// mapped path and rendered content, which may be a blob reference (see core.Blobs.Resolve), and
+// returns the content to write.
+type ContextMerger interface {
+	MergeContext(ctx context.Context, path, content string) (string, error)
+}
+
+// PathOverrider is an optional interface for IDE providers whose output paths can be changed, e.g.
+// to write commands to ".claude/commands/team" (see WithPathOverrides). Keys are provider-specific.
+// OverridePaths returns a copy of the provider writing to the overridden paths and leaves the
+// provider itself unchanged, so that it can be shared by concurrent materializations. If any key
+// is invalid, it fails without producing a copy.
+type PathOverrider interface {
+	OverridePaths(overrides map[string]string) (IDEProvider, error)
+}
+
+// ManagedPath is a file, or a glob of files, an IDE provider writes.
+type ManagedPath struct {
+	// Pattern is a slash-separated path relative to the target directory, or a glob as understood
+	// by path.Match, where "**" additionally matches any number of directories.
+	Pattern string
+	// Merge tells how matching files are written (see core.MergeReplace and friends). Files that
+	// are merged into may hold user content and must never be deleted as a whole.
+	Merge string
+}
+
+// ManagedPathsProvider is an optional interface for IDE providers listing the files they may write,
+// so that uninstall and cleanup tools never touch other files. A file matching a pattern is not
+// necessarily generated, e.g. a hand-written command next to generated ones.
+type ManagedPathsProvider interface {
+	ManagedPaths() []ManagedPath
+}
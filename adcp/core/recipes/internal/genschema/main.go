@@ -0,0 +1,33 @@
+// Command genschema writes the JSON Schemas of the recipe formats into a directory.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+)
+
+func main() {
+	out := flag.String("o", "schema", "output directory")
+	flag.Parse()
+
+	schemas := map[string]func() ([]byte, error){
+		"recipe.schema.json":            recipes.RecipeJSONSchema,
+		"executable-recipe.schema.json": recipes.ExecutableRecipeJSONSchema,
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+	for name, gen := range schemas {
+		data, err := gen()
+		if err != nil {
+			log.Fatalf("failed to generate %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(*out, name), data, 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
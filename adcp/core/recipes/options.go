@@ -0,0 +1,181 @@
+package recipes
+
+import (
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/githooks"
+	"github.com/devplaninc/adcp-core/adcp/core/policy"
+)
+
+// Option configures a Recipe.
+type Option func(r *Recipe)
+
+// New returns a Recipe materializing IDE configuration with ide.
+func New(ide IDEProvider, opts ...Option) *Recipe {
+	r := &Recipe{IDE: ide}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithLogger sets the logger used while materializing the recipe.
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Recipe) {
+		r.Logger = logger
+	}
+}
+
+// WithObserver sets the observer notified about materialization progress.
+func WithObserver(observer core.Observer) Option {
+	return func(r *Recipe) {
+		r.Observer = observer
+	}
+}
+
+// WithBestEffort skips failing entries and reports them together with the partial result.
+func WithBestEffort() Option {
+	return func(r *Recipe) {
+		r.BestEffort = true
+	}
+}
+
+// WithTimeout bounds the whole materialization of the recipe.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Recipe) {
+		r.Timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for remote fetches.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Recipe) {
+		r.HTTPClient = client
+	}
+}
+
+// WithDebugDump writes a redacted dump of the generation context to path, e.g. core.DefaultDebugDumpPath.
+func WithDebugDump(path string) Option {
+	return func(r *Recipe) {
+		r.DebugDumpPath = path
+	}
+}
+
+// WithReport fills report during materialization.
+func WithReport(report *core.Report) Option {
+	return func(r *Recipe) {
+		r.Report = report
+	}
+}
+
+// WithFS sets the filesystem existing target files are read from before merging.
+func WithFS(fsys fs.FS) Option {
+	return func(r *Recipe) {
+		r.FS = fsys
+	}
+}
+
+// WithMetrics sets the metrics receiving counters and durations.
+func WithMetrics(metrics core.Metrics) Option {
+	return func(r *Recipe) {
+		r.Metrics = metrics
+	}
+}
+
+// WithMaxConcurrency bounds how many commands and remote fetches run at once; a negative value
+// removes the limit.
+func WithMaxConcurrency(n int) Option {
+	return func(r *Recipe) {
+		r.MaxConcurrency = n
+	}
+}
+
+// WithState enables incremental materialization using the state file at path, e.g. core.DefaultStatePath.
+func WithState(path string) Option {
+	return func(r *Recipe) {
+		r.StatePath = path
+	}
+}
+
+// WithCommandInputs declares the files each command depends on, so its output can be reused from
+// the state while they are unchanged.
+func WithCommandInputs(inputs map[string][]string) Option {
+	return func(r *Recipe) {
+		r.CommandInputs = inputs
+	}
+}
+
+// WithBlobs streams large fetched content into blobs instead of holding it in memory.
+func WithBlobs(blobs *core.Blobs) Option {
+	return func(r *Recipe) {
+		r.Blobs = blobs
+	}
+}
+
+// WithMemoryBudget spills result content beyond budget bytes to blobs, which keep it on disk.
+func WithMemoryBudget(budget int64, blobs *core.Blobs) Option {
+	return func(r *Recipe) {
+		r.MemoryBudget = budget
+		r.Blobs = blobs
+	}
+}
+
+// WithMaxPrefetchSize bounds the output of a single prefetch entry; a negative value removes the limit.
+func WithMaxPrefetchSize(n int64) Option {
+	return func(r *Recipe) {
+		r.MaxPrefetchSize = n
+	}
+}
+
+// WithMaxEntrySize reports materialized files larger than n bytes as oversized; a negative value
+// disables the check.
+func WithMaxEntrySize(n int64) Option {
+	return func(r *Recipe) {
+		r.MaxEntrySize = n
+	}
+}
+
+// WithGitHooks materializes hooks into dir, relative to the repository root; an empty dir means
+// githooks.DefaultDir.
+func WithGitHooks(dir string, hooks ...githooks.Hook) Option {
+	return func(r *Recipe) {
+		r.GitHooksDir = dir
+		r.GitHooks = append(r.GitHooks, hooks...)
+	}
+}
+
+// WithSecrets sets the provider resolving credentials, e.g. core.EnvSecrets{} or a
+// core.ChainSecrets of several providers.
+func WithSecrets(provider core.SecretsProvider) Option {
+	return func(r *Recipe) {
+		r.Secrets = provider
+	}
+}
+
+// WithPrefetchEnv adds env to the environment of prefetch commands; values may reference secrets
+// as "${secret:NAME}".
+func WithPrefetchEnv(env map[string]string) Option {
+	return func(r *Recipe) {
+		r.PrefetchEnv = env
+	}
+}
+
+// WithPolicies rejects results violating any of policies before their entries are handed out,
+// e.g. WithPolicies(policy.DenyAllowRules("Bash(*)")).
+func WithPolicies(policies ...policy.Policy) Option {
+	return func(r *Recipe) {
+		r.Policies = append(r.Policies, policies...)
+	}
+}
+
+// WithPathOverrides replaces default output paths of the IDE provider, e.g.
+// WithPathOverrides(map[string]string{shared.PathCommands: ".claude/commands/team"}).
+func WithPathOverrides(overrides map[string]string) Option {
+	return func(r *Recipe) {
+		r.PathOverrides = overrides
+	}
+}
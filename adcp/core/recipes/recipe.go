@@ -2,54 +2,431 @@ package recipes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp-core/adcp/core/generators"
+	"github.com/devplaninc/adcp-core/adcp/core/githooks"
+	"github.com/devplaninc/adcp-core/adcp/core/policy"
 	"github.com/devplaninc/adcp-core/adcp/core/prefetch"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
 type Recipe struct {
 	IDE IDEProvider
+	// Logger receives debug logs for every stage of materialization. Defaults to the logger
+	// carried by the context (see core.WithLogger), or slog.Default().
+	Logger *slog.Logger
+	// Observer receives progress notifications. Defaults to the observer carried by the context
+	// (see core.WithObserver).
+	Observer core.Observer
+	// BestEffort skips entries that fail instead of aborting (see core.WithBestEffort).
+	BestEffort bool
+	// Timeout bounds the whole materialization, including prefetch, fetches and commands.
+	// Zero means no timeout besides the deadline of the context.
+	Timeout time.Duration
+	// HTTPClient is used for remote fetches. Defaults to the client carried by the context
+	// (see core.WithHTTPClient), or the shared keep-alive client core.DefaultHTTPClient().
+	HTTPClient *http.Client
+	// FS is read for existing target files before merging. Defaults to the working directory.
+	FS fs.FS
+	// DebugDumpPath, when set, receives a redacted dump of the generation context right after
+	// prefetch (see core.DefaultDebugDumpPath), so it is available even if later stages fail.
+	DebugDumpPath string
+	// Report, when set, is filled with compatibility information and the materialized files.
+	// Defaults to the report carried by the context (see core.WithReport).
+	Report *core.Report
+	// Metrics receives counters and durations for fetches, commands and the whole materialization.
+	// Defaults to the metrics carried by the context (see core.WithMetrics).
+	Metrics core.Metrics
+	// MaxConcurrency bounds how many commands and remote fetches run at once across prefetch,
+	// context entries and IDE commands. Zero uses the limiter carried by the context (see
+	// core.WithLimiter), or core.DefaultMaxConcurrency; a negative value removes the limit.
+	MaxConcurrency int
+	// StatePath, when set, enables incremental materialization: content of GitHub files pinned to a
+	// commit and of commands with declared inputs is cached there (see core.DefaultStatePath) and
+	// reused by later runs while their inputs are unchanged.
+	StatePath string
+	// CommandInputs declares the files each command, keyed by its command line, depends on.
+	// Only commands listed here are cached in the state (see core.WithCommandInputs).
+	CommandInputs map[string][]string
+	// Blobs, when set, receives large content of context entries fetched from commands or GitHub,
+	// which is then streamed to disk instead of being held in memory. Result entries reference
+	// such content; persist them with a context carrying the same Blobs (see core.WithBlobs)
+	// before closing it. Defaults to the Blobs carried by the context.
+	Blobs *core.Blobs
+	// MemoryBudget, when positive, caps the total size of entry content kept in memory by the
+	// result: as entries are produced, content beyond the budget is spilled to Blobs, which must
+	// then be set (here or in the context). Useful when materializing many recipes in one process.
+	MemoryBudget int64
+	// MaxPrefetchSize bounds the output of a single prefetch entry in bytes. Zero means
+	// prefetch.DefaultMaxSize; a negative value removes the limit.
+	MaxPrefetchSize int64
+	// MaxEntrySize is the size in bytes above which materialized files are reported with
+	// core.WarningOversizedEntry. Zero means DefaultMaxEntrySize; a negative value disables the check.
+	MaxEntrySize int64
+	// GitHooks are materialized as executable scripts into GitHooksDir after the IDE configuration.
+	GitHooks []githooks.Hook
+	// GitHooksDir is the hooks directory relative to the repository root. Defaults to githooks.DefaultDir.
+	GitHooksDir string
+	// Secrets resolves the credentials needed during materialization. Defaults to the provider
+	// carried by the context (see core.WithSecrets).
+	Secrets core.SecretsProvider
+	// PrefetchEnv is added to the environment of prefetch commands. Values may reference secrets
+	// as "${secret:NAME}" (see core.ExpandSecrets).
+	PrefetchEnv map[string]string
+	// PathOverrides replace default output paths of the IDE provider, which must implement
+	// PathOverrider. They apply to a copy of the provider made when materialization starts; the
+	// provider itself is left unchanged.
+	PathOverrides map[string]string
+	// Policies are evaluated over the complete result before any entry is handed out. When they
+	// are set, entries are held back until materialization ends, and a result violating a policy
+	// fails with a *policy.ViolationError, even in best-effort mode.
+	Policies []policy.Policy
 }
 
+var _ core.Materializer = (*Recipe)(nil)
+
+// EntryFunc receives materialized entries as soon as they are produced. Returning an error stops
+// materialization and is returned by MaterializeFunc.
+type EntryFunc func(entry *adcp.MaterializedResult_Entry) error
+
+// Materialize runs prefetch and materializes the recipe's context and IDE configuration.
+// In best-effort mode, it returns the entries that succeeded together with a *core.MultiError
+// listing every failure.
 func (r *Recipe) Materialize(ctx context.Context, recipe *adcp.Recipe) (*adcp.MaterializedResult, error) {
 	if recipe == nil {
-		return nil, fmt.Errorf("recipe cannot be nil")
+		return nil, ErrNilRecipe
+	}
+	blobs := r.Blobs
+	if blobs == nil {
+		blobs = core.BlobsFrom(ctx)
+	}
+	if r.MemoryBudget > 0 && blobs == nil {
+		return nil, fmt.Errorf("memory budget requires blobs to spill to")
+	}
+	var entries []*adcp.MaterializedResult_Entry
+	collect := r.spill(blobs, func(entry *adcp.MaterializedResult_Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	err := r.MaterializeFunc(ctx, recipe, collect)
+	if err != nil && !(r.BestEffort || core.IsBestEffort(ctx)) {
+		return nil, err
+	}
+	return adcp.MaterializedResult_builder{Entries: entries}.Build(), err
+}
+
+// MaterializeFunc is like Materialize, but passes entries to fn as soon as they are produced
+// instead of collecting them, e.g. to persist files while slow sources are still being fetched.
+// Context entries are passed one by one; IDE configuration entries once the IDE provider is done.
+// Entries passed to fn before a failure are not revoked. In best-effort mode, failures are
+// returned at the end as a *core.MultiError.
+func (r *Recipe) MaterializeFunc(ctx context.Context, recipe *adcp.Recipe, fn EntryFunc) error {
+	if recipe == nil {
+		return ErrNilRecipe
+	}
+	if r.Metrics != nil {
+		ctx = core.WithMetrics(ctx, r.Metrics)
+	}
+	start := time.Now()
+	var err error
+	if len(r.Policies) > 0 {
+		err = r.materializeChecked(ctx, recipe, fn)
+	} else {
+		err = r.materialize(ctx, recipe, fn)
+	}
+	core.MetricsFrom(ctx).ObserveDuration(core.MetricMaterializeDuration, time.Since(start),
+		map[string]string{"status": core.StatusTag(err)})
+	return err
+}
+
+// Entries returns an iterator over the entries of the recipe, produced like in MaterializeFunc.
+// A failure is yielded last, with a nil entry. Stopping the iteration stops materialization.
+func (r *Recipe) Entries(ctx context.Context, recipe *adcp.Recipe) iter.Seq2[*adcp.MaterializedResult_Entry, error] {
+	return func(yield func(*adcp.MaterializedResult_Entry, error) bool) {
+		err := r.MaterializeFunc(ctx, recipe, func(entry *adcp.MaterializedResult_Entry) error {
+			if !yield(entry, nil) {
+				return errStopIteration
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(nil, err)
+		}
+	}
+}
+
+// spill returns fn applied to entries whose content beyond MemoryBudget is moved to blobs as they
+// are produced, so that entries collected by fn stay within the budget. Without a budget or blobs,
+// fn is returned unchanged.
+func (r *Recipe) spill(blobs *core.Blobs, fn EntryFunc) EntryFunc {
+	if r.MemoryBudget <= 0 || blobs == nil {
+		return fn
+	}
+	spiller := blobs.NewSpiller(r.MemoryBudget)
+	return func(entry *adcp.MaterializedResult_Entry) error {
+		if err := spiller.Spill(entry); err != nil {
+			return err
+		}
+		return fn(entry)
+	}
+}
+
+// materializeChecked collects all entries and passes them to fn only once they satisfy r.Policies.
+func (r *Recipe) materializeChecked(ctx context.Context, recipe *adcp.Recipe, fn EntryFunc) error {
+	blobs := r.Blobs
+	if blobs == nil {
+		blobs = core.BlobsFrom(ctx)
+	}
+	var entries []*adcp.MaterializedResult_Entry
+	err := r.materialize(ctx, recipe, r.spill(blobs, func(entry *adcp.MaterializedResult_Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	if err != nil && !(r.BestEffort || core.IsBestEffort(ctx)) {
+		return err
+	}
+	if r.Blobs != nil {
+		ctx = core.WithBlobs(ctx, r.Blobs)
+	}
+	result := adcp.MaterializedResult_builder{Entries: entries}.Build()
+	if checkErr := policy.Check(ctx, result, r.Policies...); checkErr != nil {
+		return checkErr
+	}
+	for _, entry := range entries {
+		if fnErr := fn(entry); fnErr != nil {
+			return fnErr
+		}
+	}
+	return err
+}
+
+// errStopIteration aborts materialization when the consumer of Entries stops iterating.
+var errStopIteration = errors.New("iteration stopped")
+
+func (r *Recipe) materialize(ctx context.Context, recipe *adcp.Recipe, fn EntryFunc) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	if r.Logger != nil {
+		ctx = core.WithLogger(ctx, r.Logger)
+	}
+	if r.Observer != nil {
+		ctx = core.WithObserver(ctx, r.Observer)
+	}
+	if r.HTTPClient != nil {
+		ctx = core.WithHTTPClient(ctx, r.HTTPClient)
+	}
+	if r.FS != nil {
+		ctx = core.WithFS(ctx, r.FS)
+	}
+	if r.Report != nil {
+		ctx = core.WithReport(ctx, r.Report)
+	}
+	if r.BestEffort {
+		ctx = core.WithBestEffort(ctx)
+	}
+	switch {
+	case r.MaxConcurrency != 0:
+		ctx = core.WithLimiter(ctx, core.NewLimiter(r.MaxConcurrency))
+	case !core.HasLimiter(ctx):
+		ctx = core.WithLimiter(ctx, core.NewLimiter(core.DefaultMaxConcurrency))
 	}
+	if r.Blobs != nil {
+		ctx = core.WithBlobs(ctx, r.Blobs)
+	}
+	if r.CommandInputs != nil {
+		ctx = core.WithCommandInputs(ctx, r.CommandInputs)
+	}
+	if r.Secrets != nil {
+		ctx = core.WithSecrets(ctx, r.Secrets)
+	}
+	log := core.Logger(ctx).With("op", "Recipe.Materialize")
+	// Overrides apply to a copy of the provider for this run only.
+	provider := r.IDE
+	if len(r.PathOverrides) > 0 {
+		po, ok := r.IDE.(PathOverrider)
+		if !ok {
+			return ErrPathOverridesUnsupported
+		}
+		var err error
+		if provider, err = po.OverridePaths(r.PathOverrides); err != nil {
+			return fmt.Errorf("failed to override paths: %w", err)
+		}
+	}
+	var state *core.State
+	if r.StatePath != "" {
+		var err error
+		state, err = core.LoadState(ctx, r.StatePath)
+		if err != nil {
+			core.Warn(ctx, core.Warning{Code: core.WarningInvalidState, Path: r.StatePath, Message: err.Error()})
+		}
+		ctx = core.WithState(ctx, state)
+	}
+	observer := core.ObserverFrom(ctx)
+	failures := &core.MultiError{}
 	genCtx := &core.GenerationContext{}
 	if pf := recipe.GetPrefetch(); pf != nil {
-		p := prefetch.Processor{}
-		entries, err := p.Process(ctx, pf)
+		log.Debug("Processing prefetch", "entries", len(pf.GetEntries()))
+		observer.PhaseStarted(core.PhasePrefetch)
+		p := prefetch.Processor{MaxSize: r.MaxPrefetchSize}
+		entries, err := p.Process(core.WithCommandEnv(ctx, r.PrefetchEnv), pf)
 		if err != nil {
-			return nil, fmt.Errorf("failed to process prefetch: %w", err)
+			if !core.IsBestEffort(ctx) {
+				return fmt.Errorf("failed to process prefetch: %w", err)
+			}
+			// Entries that depend on missing prefetched data fail individually later.
+			failures.Append(&core.EntryError{Source: "prefetch", Err: err})
 		}
 		genCtx.Prefetched = entries
 	}
+	if r.DebugDumpPath != "" {
+		log.Debug("Writing debug dump", "path", r.DebugDumpPath, "generationContext", genCtx.String())
+		if err := core.WriteDebugDump(ctx, r.DebugDumpPath, genCtx); err != nil {
+			core.Warn(ctx, core.Warning{Code: core.WarningDebugDumpFailed, Path: r.DebugDumpPath, Message: err.Error()})
+		}
+	}
 
-	var resultEntries []*adcp.MaterializedResult_Entry
+	for _, issue := range Validate(recipe) {
+		core.Warn(ctx, issue)
+	}
+
+	count := 0
+	emit := func(entry *adcp.MaterializedResult_Entry) error {
+		count++
+		core.ReportFrom(ctx).AddFiles(entry.GetFile().GetPath())
+		r.checkSize(ctx, entry)
+		return fn(entry)
+	}
+
+	caps, hasCaps := Capabilities{}, false
+	if cp, ok := provider.(CapabilityProvider); ok {
+		caps, hasCaps = cp.Capabilities(), true
+		for _, issue := range unsupportedHooks(provider, caps) {
+			core.Warn(ctx, issue)
+		}
+	}
+
+	// Context merged into existing files is read back by the IDE provider, which may merge its own
+	// configuration into the same files, e.g. agent instructions into AGENTS.md.
+	var mergedMu sync.Mutex
+	mergedFiles := map[string]string{}
 
 	// Materialize context entries if present
 	if recipe.HasContext() {
+		recipeContext := recipe.GetContext()
+		if hasCaps {
+			var issues []core.Warning
+			recipeContext, issues = supportedContext(recipeContext, caps)
+			for _, issue := range issues {
+				core.Warn(ctx, issue)
+			}
+		}
+		log.Debug("Materializing context", "entries", len(recipeContext.GetEntries()))
+		observer.PhaseStarted(core.PhaseContext)
 		contextGen := &generators.Context{}
-		contextResult, err := contextGen.Materialize(ctx, recipe.GetContext(), genCtx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to materialize context: %w", err)
+		if mapper, ok := provider.(ContextPathMapper); ok {
+			contextGen.MapPath = mapper.MapContextPath
+		}
+		if renderer, ok := provider.(ContextRenderer); ok {
+			contextGen.Render = renderer.RenderContext
+		}
+		if merger, ok := provider.(ContextMerger); ok {
+			contextGen.Merge = func(ctx context.Context, p, content string) (string, error) {
+				merged, err := merger.MergeContext(ctx, p, content)
+				if err == nil && merged != content {
+					mergedMu.Lock()
+					mergedFiles[p] = merged
+					mergedMu.Unlock()
+				}
+				return merged, err
+			}
+		}
+		err := contextGen.MaterializeFunc(ctx, recipeContext, genCtx, emit)
+		var emitErr *generators.EmitError
+		if errors.As(err, &emitErr) {
+			return emitErr.Err
+		}
+		if err != nil && !core.IsBestEffort(ctx) {
+			return fmt.Errorf("failed to materialize context: %w", err)
 		}
-		resultEntries = append(resultEntries, contextResult.GetEntries()...)
+		failures.Append(err)
 	}
 
 	// Materialize IDE configuration if present
 	if recipe.HasIde() {
-		ideResult, err := r.IDE.Materialize(ctx, recipe.GetIde())
-		if err != nil {
-			return nil, fmt.Errorf("failed to materialize IDE configuration: %w", err)
+		if hasCaps {
+			for _, issue := range unsupportedFeatures(recipe.GetIde(), caps) {
+				core.Warn(ctx, issue)
+			}
+		}
+		log.Debug("Materializing IDE configuration")
+		observer.PhaseStarted(core.PhaseIDE)
+		ideCtx := core.WithGenerationContext(ctx, genCtx)
+		if len(mergedFiles) > 0 {
+			ideCtx = core.WithProducedFiles(ideCtx, mergedFiles)
+		}
+		ideResult, err := provider.Materialize(ideCtx, recipe.GetIde())
+		if err != nil && !core.IsBestEffort(ctx) {
+			return fmt.Errorf("failed to materialize IDE configuration: %w", err)
+		}
+		failures.Append(err)
+		for _, entry := range ideResult.GetEntries() {
+			if err := emit(entry); err != nil {
+				return err
+			}
 		}
-		resultEntries = append(resultEntries, ideResult.GetEntries()...)
 	}
 
-	return adcp.MaterializedResult_builder{
-		Entries: resultEntries,
-	}.Build(), nil
+	if len(r.GitHooks) > 0 {
+		log.Debug("Materializing git hooks", "hooks", len(r.GitHooks))
+		observer.PhaseStarted(core.PhaseGitHooks)
+		gen := &githooks.Generator{Dir: r.GitHooksDir}
+		hookEntries, err := gen.Materialize(ctx, r.GitHooks)
+		if err != nil && !core.IsBestEffort(ctx) {
+			return fmt.Errorf("failed to materialize git hooks: %w", err)
+		}
+		failures.Append(err)
+		for _, entry := range hookEntries {
+			if err := emit(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := state.Save(ctx, r.StatePath); err != nil {
+		core.Warn(ctx, core.Warning{Code: core.WarningStateNotSaved, Path: r.StatePath, Message: err.Error()})
+	}
+	log.Debug("Recipe materialized", "entries", count, "failures", len(failures.Errors))
+	return failures.ErrorOrNil()
+}
+
+// checkSize warns about entries larger than MaxEntrySize.
+func (r *Recipe) checkSize(ctx context.Context, entry *adcp.MaterializedResult_Entry) {
+	limit := r.MaxEntrySize
+	if limit == 0 {
+		limit = DefaultMaxEntrySize
+	}
+	if limit < 0 {
+		return
+	}
+	size, err := core.BlobsFrom(ctx).Size(entry.GetFile().GetContent())
+	if err != nil || size <= limit {
+		return
+	}
+	core.Warn(ctx, core.Warning{
+		Code:    core.WarningOversizedEntry,
+		Path:    entry.GetFile().GetPath(),
+		Message: fmt.Sprintf("File is %d bytes, more than the limit of %d", size, limit),
+	})
 }
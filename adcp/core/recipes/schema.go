@@ -0,0 +1,134 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate go run ./internal/genschema -o schema
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// RecipeJSONSchema returns a JSON Schema describing the recipe format, for editor completion and validation.
+func RecipeJSONSchema() ([]byte, error) {
+	return JSONSchema((&adcp.Recipe{}).ProtoReflect().Descriptor())
+}
+
+// ExecutableRecipeJSONSchema returns a JSON Schema describing the executable recipe format.
+func ExecutableRecipeJSONSchema() ([]byte, error) {
+	return JSONSchema((&adcp.ExecutableRecipe{}).ProtoReflect().Descriptor())
+}
+
+// JSONSchema derives a JSON Schema for the protojson encoding of md (see MarshalJSON).
+// Nested messages are emitted as $defs keyed by their full proto name; fields of a oneof are
+// mutually exclusive and one of them is required.
+func JSONSchema(md protoreflect.MessageDescriptor) ([]byte, error) {
+	b := &schemaBuilder{defs: map[string]any{}}
+	root := b.message(md)
+	root["$schema"] = jsonSchemaDialect
+	root["title"] = string(md.Name())
+	root["$defs"] = b.defs
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json schema: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+// message returns the schema of md's fields; referenced messages are added to defs.
+func (b *schemaBuilder) message(md protoreflect.MessageDescriptor) map[string]any {
+	props := map[string]any{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		props[f.JSONName()] = b.field(f)
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+
+	var allOf []any
+	oneofs := md.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		o := oneofs.Get(i)
+		if o.IsSynthetic() {
+			continue
+		}
+		var choices []any
+		for j := 0; j < o.Fields().Len(); j++ {
+			choices = append(choices, map[string]any{"required": []string{o.Fields().Get(j).JSONName()}})
+		}
+		allOf = append(allOf, map[string]any{"oneOf": choices})
+	}
+	if len(allOf) == 1 {
+		schema["oneOf"] = allOf[0].(map[string]any)["oneOf"]
+	} else if len(allOf) > 1 {
+		schema["allOf"] = allOf
+	}
+	return schema
+}
+
+func (b *schemaBuilder) field(f protoreflect.FieldDescriptor) any {
+	switch {
+	case f.IsMap():
+		return map[string]any{"type": "object", "additionalProperties": b.singular(f.MapValue())}
+	case f.IsList():
+		return map[string]any{"type": "array", "items": b.singular(f)}
+	default:
+		return b.singular(f)
+	}
+}
+
+func (b *schemaBuilder) singular(f protoreflect.FieldDescriptor) any {
+	switch f.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson encodes 64-bit integers as strings but accepts numbers as well.
+		return map[string]any{"type": []string{"integer", "string"}}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.EnumKind:
+		var names []string
+		values := f.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return map[string]any{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.ref(f.Message())
+	default:
+		return map[string]any{}
+	}
+}
+
+// ref returns a reference to md's definition, adding it to defs on first use.
+// Well-known types have custom JSON encodings and are left unconstrained.
+func (b *schemaBuilder) ref(md protoreflect.MessageDescriptor) any {
+	name := string(md.FullName())
+	if md.ParentFile().Package() == "google.protobuf" {
+		return map[string]any{}
+	}
+	if _, ok := b.defs[name]; !ok {
+		b.defs[name] = nil // placeholder for recursive messages
+		b.defs[name] = b.message(md)
+	}
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
@@ -0,0 +1,53 @@
+package recipes_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecipeJSONSchema_UpToDate(t *testing.T) {
+	for file, gen := range map[string]func() ([]byte, error){
+		"schema/recipe.schema.json":            recipes.RecipeJSONSchema,
+		"schema/executable-recipe.schema.json": recipes.ExecutableRecipeJSONSchema,
+	} {
+		got, err := gen()
+		require.NoError(t, err)
+		want, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s is stale, run go generate ./...", file)
+	}
+}
+
+func TestRecipeJSONSchema(t *testing.T) {
+	data, err := recipes.RecipeJSONSchema()
+	require.NoError(t, err)
+
+	var schema struct {
+		Schema     string                     `json:"$schema"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			OneOf      []struct {
+				Required []string `json:"required"`
+			} `json:"oneOf"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema.Schema)
+	assert.Contains(t, schema.Properties, "ide")
+	assert.Contains(t, schema.Properties, "context")
+
+	from := schema.Defs["adcp.context.ContextFrom"]
+	assert.Contains(t, from.Properties, "prefetchId")
+	var choices []string
+	for _, c := range from.OneOf {
+		choices = append(choices, c.Required...)
+	}
+	assert.Contains(t, choices, "github")
+	assert.Contains(t, choices, "cmd")
+}
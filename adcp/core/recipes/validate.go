@@ -0,0 +1,75 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/utils"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxEntrySize is the size in bytes above which materialized files are reported as
+// oversized when Recipe.MaxEntrySize is not set.
+const DefaultMaxEntrySize = 1 << 20
+
+// Validate checks recipe for likely mistakes that do not prevent materialization: permissions that
+// are both allowed and denied (core.WarningConflictingPermission) and GitHub sources that are not
+// pinned to a commit (core.WarningUnpinnedSource). Materialize reports these warnings as well.
+func Validate(recipe *adcp.Recipe) []core.Warning {
+	var issues []core.Warning
+	perms := recipe.GetIde().GetPermissions()
+	for _, allow := range perms.GetAllow() {
+		for _, deny := range perms.GetDeny() {
+			if allow.HasType() && proto.Equal(allow, deny) {
+				issues = append(issues, core.Warning{
+					Code:    core.WarningConflictingPermission,
+					Message: fmt.Sprintf("Permission %s is both allowed and denied; the deny rule wins", permissionString(allow)),
+				})
+				break
+			}
+		}
+	}
+
+	unpinned := func(path, source string, ref *adcp.GitReference) {
+		if !utils.IsPinnedToCommit(ref) {
+			issues = append(issues, core.Warning{
+				Code:    core.WarningUnpinnedSource,
+				Path:    path,
+				Message: fmt.Sprintf("%s is fetched from %s, which is not pinned to a commit", source, ref.GetPath()),
+			})
+		}
+	}
+	for _, entry := range recipe.GetContext().GetEntries() {
+		from := entry.GetFrom()
+		source := "Context " + entry.GetPath()
+		if from.HasGithub() {
+			unpinned(entry.GetPath(), source, from.GetGithub())
+		}
+		for _, item := range from.GetCombined().GetItems() {
+			if item.HasGithub() {
+				unpinned(entry.GetPath(), source, item.GetGithub())
+			}
+		}
+	}
+	for _, command := range recipe.GetIde().GetCommands().GetEntries() {
+		if command.GetFrom().HasGithub() {
+			unpinned("", "Command "+command.GetName(), command.GetFrom().GetGithub())
+		}
+	}
+	return issues
+}
+
+// permissionString describes p like IDE permission rules, e.g. "Bash(git push:*)".
+func permissionString(p *adcp.OperationPermission) string {
+	switch p.WhichType() {
+	case adcp.OperationPermission_Bash_case:
+		return fmt.Sprintf("Bash(%s)", p.GetBash())
+	case adcp.OperationPermission_Read_case:
+		return fmt.Sprintf("Read(%s)", p.GetRead())
+	case adcp.OperationPermission_Write_case:
+		return fmt.Sprintf("Write(%s)", p.GetWrite())
+	default:
+		return p.String()
+	}
+}
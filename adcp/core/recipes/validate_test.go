@@ -0,0 +1,56 @@
+package recipes_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/adcptest"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	sha := strings.Repeat("a", 40)
+	ide := adcptest.Ide(adcptest.IdeSpec{
+		Commands: []*adcp.Command{
+			adcp.Command_builder{Name: "review", From: adcp.CommandFrom_builder{
+				Github: adcp.GitReference_builder{Path: "https://github.com/org/repo/blob/main/review.md"}.Build(),
+			}.Build()}.Build(),
+		},
+		Allow: []*adcp.OperationPermission{adcptest.Bash("git push:*"), adcptest.Read("src/**")},
+		Deny:  []*adcp.OperationPermission{adcptest.Bash("git push:*"), adcptest.Read(".env")},
+	})
+	recipe := adcptest.Recipe(ide,
+		adcptest.GithubContext("pinned.md", "https://github.com/org/repo/blob/"+sha+"/README.md"),
+		adcptest.GithubContext("main.md", "https://github.com/org/repo/blob/main/README.md"),
+		adcptest.TextContext("local.md", "text"),
+	)
+
+	var got []string
+	for _, w := range recipes.Validate(recipe) {
+		got = append(got, w.Code+" "+w.Path+" "+w.Message)
+	}
+	assert.Equal(t, []string{
+		core.WarningConflictingPermission + "  Permission Bash(git push:*) is both allowed and denied; the deny rule wins",
+		core.WarningUnpinnedSource + " main.md Context main.md is fetched from https://github.com/org/repo/blob/main/README.md, which is not pinned to a commit",
+		core.WarningUnpinnedSource + "  Command review is fetched from https://github.com/org/repo/blob/main/review.md, which is not pinned to a commit",
+	}, got)
+}
+
+func TestRecipe_Materialize_OversizedEntry(t *testing.T) {
+	report := core.NewReport()
+	recipe := adcptest.Recipe(nil,
+		adcptest.TextContext("small.md", "ok"),
+		adcptest.TextContext("large.md", strings.Repeat("x", 11)),
+	)
+
+	_, err := recipes.New(&adcptest.FakeIDE{}, recipes.WithReport(report), recipes.WithMaxEntrySize(10)).Materialize(context.Background(), recipe)
+	require.NoError(t, err)
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, core.WarningOversizedEntry, report.Data().Warnings[0].Code)
+	assert.Equal(t, "large.md", report.Data().Warnings[0].Path)
+}
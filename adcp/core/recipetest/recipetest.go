@@ -0,0 +1,250 @@
+// Package recipetest lets recipe authors unit test their recipes in CI: a recipe is materialized
+// into memory and the result is checked with assertions on files, JSON values and permissions.
+//
+//	func TestRecipe(t *testing.T) {
+//		res := recipetest.Materialize(t, claude.NewIDEProvider(), recipe)
+//		res.FileExists(".mcp.json")
+//		res.JSONPathEquals(".mcp.json", "mcpServers.devplan.command", "devplan")
+//		res.PermissionAllowed("Bash(make test)")
+//	}
+//
+// Failed assertions are reported with t.Errorf and return false, so tests keep going and report
+// every problem at once.
+package recipetest
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/adcptest"
+	"github.com/devplaninc/adcp-core/adcp/core/recipes"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// SettingsPaths lists the IDE settings files searched by PermissionAllowed and PermissionDenied.
+var SettingsPaths = []string{".claude/settings.local.json", ".cursor/cli.json"}
+
+// Option configures Materialize.
+type Option func(c *config)
+
+type config struct {
+	files      map[string]string
+	recipeOpts []recipes.Option
+}
+
+// WithFiles pre-populates the in-memory repository, e.g. with settings the recipe is merged into.
+func WithFiles(files map[string]string) Option {
+	return func(c *config) {
+		c.files = files
+	}
+}
+
+// WithRecipeOptions passes options to the underlying recipes.Recipe, e.g. recipes.WithHTTPClient
+// with an adcptest.HTTPStub to serve GitHub sources offline.
+func WithRecipeOptions(opts ...recipes.Option) Option {
+	return func(c *config) {
+		c.recipeOpts = append(c.recipeOpts, opts...)
+	}
+}
+
+// Result is a recipe materialized into memory.
+type Result struct {
+	t      testing.TB
+	target *adcptest.MemoryTarget
+	report core.ReportData
+}
+
+// Materialize materializes recipe with ide into an in-memory repository and returns the result for
+// assertions. It fails the test immediately if materialization fails.
+func Materialize(t testing.TB, ide recipes.IDEProvider, recipe *adcp.Recipe, opts ...Option) *Result {
+	t.Helper()
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	target := adcptest.NewMemoryTarget(c.files)
+	report := core.NewReport()
+	recipeOpts := append([]recipes.Option{recipes.WithFS(target.FS()), recipes.WithReport(report)}, c.recipeOpts...)
+	ctx := context.Background()
+	res, err := recipes.New(ide, recipeOpts...).Materialize(ctx, recipe)
+	if err != nil {
+		t.Fatalf("failed to materialize recipe: %v", err)
+	}
+	if err := target.Persist(ctx, res); err != nil {
+		t.Fatalf("failed to persist materialized recipe: %v", err)
+	}
+	return &Result{t: t, target: target, report: report.Data()}
+}
+
+// Files returns all files of the repository after materialization, keyed by path.
+func (r *Result) Files() map[string]string {
+	return r.target.Files()
+}
+
+// File returns the content of the file at path, or "" if it does not exist.
+func (r *Result) File(path string) string {
+	content, _ := r.target.File(path)
+	return content
+}
+
+// Warnings returns the warnings reported during materialization.
+func (r *Result) Warnings() []core.Warning {
+	return r.report.Warnings
+}
+
+// FileExists asserts that a file exists at path.
+func (r *Result) FileExists(path string) bool {
+	r.t.Helper()
+	if _, ok := r.target.File(path); !ok {
+		r.t.Errorf("expected file %s to exist; files: %v", path, r.paths())
+		return false
+	}
+	return true
+}
+
+// NoFile asserts that no file exists at path.
+func (r *Result) NoFile(path string) bool {
+	r.t.Helper()
+	if _, ok := r.target.File(path); ok {
+		r.t.Errorf("expected file %s not to exist", path)
+		return false
+	}
+	return true
+}
+
+// FileContains asserts that the file at path contains substr.
+func (r *Result) FileContains(path, substr string) bool {
+	r.t.Helper()
+	content, ok := r.target.File(path)
+	if !ok {
+		r.t.Errorf("expected file %s to exist; files: %v", path, r.paths())
+		return false
+	}
+	if !strings.Contains(content, substr) {
+		r.t.Errorf("expected file %s to contain %q, got:\n%s", path, substr, content)
+		return false
+	}
+	return true
+}
+
+// JSONPathEquals asserts that the value at jsonPath in the JSON file at path equals want once both
+// are converted to JSON. jsonPath is a dot-separated list of object keys and array indices, e.g.
+// "mcpServers.devplan.args.0"; an empty jsonPath refers to the whole document.
+func (r *Result) JSONPathEquals(path, jsonPath string, want any) bool {
+	r.t.Helper()
+	got, ok := r.lookup(path, jsonPath)
+	if !ok {
+		return false
+	}
+	raw, err := json.Marshal(want)
+	if err != nil {
+		r.t.Errorf("cannot convert expected value %v to JSON: %v", want, err)
+		return false
+	}
+	var normalized any
+	_ = json.Unmarshal(raw, &normalized)
+	if !reflect.DeepEqual(got, normalized) {
+		gotRaw, _ := json.Marshal(got)
+		r.t.Errorf("%s: expected %s to be %s, got %s", path, jsonPath, raw, gotRaw)
+		return false
+	}
+	return true
+}
+
+// PermissionAllowed asserts that rule, as written by the IDE (e.g. "Bash(make test)"), is in the
+// allow list of one of the SettingsPaths.
+func (r *Result) PermissionAllowed(rule string) bool {
+	r.t.Helper()
+	return r.permission("allow", rule)
+}
+
+// PermissionDenied asserts that rule is in the deny list of one of the SettingsPaths.
+func (r *Result) PermissionDenied(rule string) bool {
+	r.t.Helper()
+	return r.permission("deny", rule)
+}
+
+// NoWarnings asserts that materialization reported no warnings.
+func (r *Result) NoWarnings() bool {
+	r.t.Helper()
+	if len(r.report.Warnings) > 0 {
+		r.t.Errorf("expected no warnings, got %v", r.report.Warnings)
+		return false
+	}
+	return true
+}
+
+func (r *Result) permission(list, rule string) bool {
+	r.t.Helper()
+	var found []string
+	for _, p := range SettingsPaths {
+		content, ok := r.target.File(p)
+		if !ok {
+			continue
+		}
+		var settings struct {
+			Permissions map[string]json.RawMessage `json:"permissions"`
+		}
+		var rules []string
+		if json.Unmarshal([]byte(content), &settings) != nil || json.Unmarshal(settings.Permissions[list], &rules) != nil {
+			continue
+		}
+		if slices.Contains(rules, rule) {
+			return true
+		}
+		found = append(found, rules...)
+	}
+	r.t.Errorf("expected %s to be in the %s list, got %v", rule, list, found)
+	return false
+}
+
+func (r *Result) lookup(path, jsonPath string) (any, bool) {
+	r.t.Helper()
+	content, ok := r.target.File(path)
+	if !ok {
+		r.t.Errorf("expected file %s to exist; files: %v", path, r.paths())
+		return nil, false
+	}
+	var v any
+	if err := json.Unmarshal([]byte(content), &v); err != nil {
+		r.t.Errorf("%s is not valid JSON: %v", path, err)
+		return nil, false
+	}
+	if jsonPath == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(jsonPath, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v, ok = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			ok = err == nil && i >= 0 && i < len(node)
+			if ok {
+				v = node[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			r.t.Errorf("%s: %s not found (at %q)", path, jsonPath, key)
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func (r *Result) paths() []string {
+	var paths []string
+	for p := range r.target.Files() {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
@@ -0,0 +1,67 @@
+package recipetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core/plugins/claude"
+	"github.com/devplaninc/adcp-core/adcp/core/recipebuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder captures failed assertions instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func materialize(t *testing.T) *Result {
+	recipe := recipebuilder.New().
+		WithContextFile("@rules/style.md", recipebuilder.Text("Use gofmt.\n")).
+		WithCommand("review", recipebuilder.Text("Review the diff.\n")).
+		WithMCPStdio("devplan", "devplan mcp").
+		WithAllow(recipebuilder.Bash("make test")).
+		WithDeny(recipebuilder.Read(".env")).
+		MustBuild()
+	return Materialize(t, claude.NewIDEProvider(), recipe, WithFiles(map[string]string{
+		".mcp.json": `{"mcpServers": {"existing": {"type": "http", "url": "https://example.com"}}}`,
+	}))
+}
+
+func TestResult_Assertions(t *testing.T) {
+	res := materialize(t)
+	res.FileExists(".claude/rules/style.md")
+	res.FileContains(".claude/commands/review.md", "Review the diff.")
+	res.NoFile("CLAUDE.md")
+	res.JSONPathEquals(".mcp.json", "mcpServers.devplan.args", []string{"mcp"})
+	res.JSONPathEquals(".mcp.json", "mcpServers.existing.url", "https://example.com")
+	res.JSONPathEquals(".claude/settings.local.json", "permissions.allow.0", "Bash(make test)")
+	res.PermissionAllowed("Bash(make test)")
+	res.PermissionAllowed("mcp__devplan")
+	res.PermissionDenied("Read(.env)")
+	res.NoWarnings()
+	assert.Equal(t, "Use gofmt.\n", res.File(".claude/rules/style.md"))
+}
+
+func TestResult_FailedAssertions(t *testing.T) {
+	res := materialize(t)
+	rec := &recorder{TB: t}
+	res.t = rec
+
+	assert.False(t, res.FileExists("missing.md"))
+	assert.False(t, res.NoFile(".mcp.json"))
+	assert.False(t, res.FileContains(".claude/rules/style.md", "tabs"))
+	assert.False(t, res.JSONPathEquals(".mcp.json", "mcpServers.devplan.command", "other"))
+	assert.False(t, res.JSONPathEquals(".mcp.json", "mcpServers.devplan.args.5", "x"))
+	assert.False(t, res.JSONPathEquals(".claude/rules/style.md", "", "x"))
+	assert.False(t, res.PermissionAllowed("Bash(rm -rf /)"))
+	assert.False(t, res.PermissionDenied("Bash(make test)"))
+	assert.Len(t, rec.errors, 8)
+	assert.Contains(t, rec.errors[3], `expected mcpServers.devplan.command to be "other", got "devplan"`)
+}
@@ -0,0 +1,20 @@
+package core
+
+import "regexp"
+
+var secretPatterns = []*regexp.Regexp{
+	// key=value and key: value pairs with secret-looking keys.
+	regexp.MustCompile(`(?i)((?:api[_-]?key|token|secret|password|passwd|authorization)["']?\s*[:=]\s*["']?)(?:bearer\s+)?[^\s"',]+`),
+	// Bearer tokens.
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`),
+	// GitHub and similar prefixed tokens.
+	regexp.MustCompile(`()\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|sk-[A-Za-z0-9_-]{20,})`),
+}
+
+// Redact replaces values that look like credentials in s with "<redacted>".
+func Redact(s string) string {
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, "${1}<redacted>")
+	}
+	return s
+}
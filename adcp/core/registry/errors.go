@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrInvalidRef is returned when a registry reference cannot be parsed.
+	ErrInvalidRef = errors.New("invalid registry reference")
+	// ErrNotFound matches errors returned when the registry has no such recipe or version.
+	ErrNotFound = errors.New("recipe not found in registry")
+	// ErrUnauthorized matches errors returned when the registry rejects the credentials.
+	ErrUnauthorized = errors.New("registry request unauthorized")
+)
+
+// ResponseError describes an unsuccessful registry response. It matches ErrNotFound for 404 and
+// ErrUnauthorized for 401 and 403 responses.
+type ResponseError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("registry request %s returned status %d", e.URL, e.StatusCode)
+}
+
+func (e *ResponseError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+// Package registry is a client for recipe registries, which serve versioned executable recipes by
+// name over HTTP.
+//
+// A registry exposes:
+//
+//	GET {base}/recipes                                  {"recipes": [RecipeInfo...]}
+//	GET {base}/recipes/{name}                           RecipeInfo
+//	GET {base}/recipes/{name}/versions/{version}        adcp.ExecutableRecipe as JSON
+//
+// Recipe names may contain slashes, e.g. "org/backend-recipe".
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Scheme prefixes references to registry recipes, e.g. "registry://org/backend-recipe@1.4.0".
+const Scheme = "registry://"
+
+// MaxResponseSize bounds the size of a single registry response in bytes.
+const MaxResponseSize = 32 << 20
+
+// Ref references a recipe in a registry. An empty Version means the latest one.
+type Ref struct {
+	Name    string
+	Version string
+}
+
+// IsRef reports whether s is a registry reference.
+func IsRef(s string) bool {
+	return strings.HasPrefix(s, Scheme)
+}
+
+// ParseRef parses a reference of the form "registry://name[@version]".
+func ParseRef(s string) (Ref, error) {
+	rest, ok := strings.CutPrefix(s, Scheme)
+	if !ok {
+		return Ref{}, fmt.Errorf("%w: %q does not start with %s", ErrInvalidRef, s, Scheme)
+	}
+	name, version, hasVersion := strings.Cut(rest, "@")
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return Ref{}, fmt.Errorf("%w: %q has no recipe name", ErrInvalidRef, s)
+	}
+	if hasVersion && version == "" {
+		return Ref{}, fmt.Errorf("%w: %q has an empty version", ErrInvalidRef, s)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return Ref{}, fmt.Errorf("%w: %q has an invalid recipe name", ErrInvalidRef, s)
+		}
+	}
+	return Ref{Name: name, Version: version}, nil
+}
+
+func (r Ref) String() string {
+	if r.Version == "" {
+		return Scheme + r.Name
+	}
+	return Scheme + r.Name + "@" + r.Version
+}
+
+// RecipeInfo describes a recipe published in a registry.
+type RecipeInfo struct {
+	Name     string   `json:"name"`
+	Versions []string `json:"versions,omitempty"`
+	// Latest is the version the registry considers the latest. When empty, the highest of
+	// Versions is used.
+	Latest string `json:"latest,omitempty"`
+}
+
+// LatestVersion returns Latest, or the highest of Versions if it is not set.
+func (i *RecipeInfo) LatestVersion() string {
+	if i.Latest != "" {
+		return i.Latest
+	}
+	latest := ""
+	for _, v := range i.Versions {
+		if latest == "" || compareVersions(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// Client talks to a recipe registry.
+type Client struct {
+	// BaseURL is the registry endpoint, e.g. "https://registry.example.com/v1".
+	BaseURL string
+	// Token, when set, is sent as a bearer token with every request.
+	Token string
+	// HTTPClient is used for requests. Defaults to the client carried by the context
+	// (see core.WithHTTPClient), or core.DefaultHTTPClient().
+	HTTPClient *http.Client
+}
+
+// Option configures a Client.
+type Option func(c *Client)
+
+// NewClient returns a client for the registry at baseURL.
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithToken authenticates requests with a bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.Token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for registry requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = client
+	}
+}
+
+// List returns all recipes published in the registry.
+func (c *Client) List(ctx context.Context) ([]RecipeInfo, error) {
+	var resp struct {
+		Recipes []RecipeInfo `json:"recipes"`
+	}
+	if err := c.getJSON(ctx, "/recipes", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Recipes, nil
+}
+
+// Info returns the description of the recipe called name.
+func (c *Client) Info(ctx context.Context, name string) (*RecipeInfo, error) {
+	info := &RecipeInfo{}
+	if err := c.getJSON(ctx, "/recipes/"+escapeName(name), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Latest returns the latest version of the recipe called name.
+func (c *Client) Latest(ctx context.Context, name string) (string, error) {
+	info, err := c.Info(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	version := info.LatestVersion()
+	if version == "" {
+		return "", fmt.Errorf("%w: %s has no versions", ErrNotFound, name)
+	}
+	return version, nil
+}
+
+// Fetch returns the given version of the recipe called name.
+func (c *Client) Fetch(ctx context.Context, name, version string) (*adcp.ExecutableRecipe, error) {
+	data, err := c.get(ctx, "/recipes/"+escapeName(name)+"/versions/"+url.PathEscape(version))
+	if err != nil {
+		return nil, err
+	}
+	recipe := &adcp.ExecutableRecipe{}
+	// Registries may serve newer schema versions; fields this client does not know are ignored.
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, recipe); err != nil {
+		return nil, fmt.Errorf("failed to decode recipe %s@%s: %w", name, version, err)
+	}
+	return recipe, nil
+}
+
+// Resolve fetches the recipe referenced by ref, e.g. "registry://org/backend-recipe@1.4.0",
+// resolving the latest version when ref has none. It returns the resolved reference as well.
+func (c *Client) Resolve(ctx context.Context, ref string) (*adcp.ExecutableRecipe, Ref, error) {
+	r, err := ParseRef(ref)
+	if err != nil {
+		return nil, Ref{}, err
+	}
+	if r.Version == "" {
+		if r.Version, err = c.Latest(ctx, r.Name); err != nil {
+			return nil, Ref{}, err
+		}
+	}
+	recipe, err := c.Fetch(ctx, r.Name, r.Version)
+	if err != nil {
+		return nil, Ref{}, err
+	}
+	return recipe, r, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	data, err := c.get(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode registry response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	u := c.BaseURL + path
+	log := core.Logger(ctx).With("op", "registry.Client", "url", u)
+	start := time.Now()
+	data, err := c.do(ctx, u)
+	metrics := core.MetricsFrom(ctx)
+	tags := map[string]string{"source": "registry", "status": core.StatusTag(err)}
+	metrics.IncCounter(core.MetricFetches, tags)
+	metrics.ObserveDuration(core.MetricFetchDuration, time.Since(start), tags)
+	if err != nil {
+		log.Debug("Registry request failed", "error", err)
+		return nil, err
+	}
+	log.Debug("Registry request succeeded", "bytes", len(data))
+	return data, nil
+}
+
+func (c *Client) do(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = core.HTTPClient(ctx)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ResponseError{URL: u, StatusCode: resp.StatusCode}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry response: %w", err)
+	}
+	if len(data) > MaxResponseSize {
+		return nil, fmt.Errorf("registry response %s exceeds %d bytes", u, MaxResponseSize)
+	}
+	return data, nil
+}
+
+// escapeName escapes each segment of a recipe name, keeping the slashes between them.
+func escapeName(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// compareVersions compares dotted versions such as "1.4.0" or "v2.0", numerically where both parts
+// are numbers and lexically otherwise.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if nx != ny {
+				return nx - ny
+			}
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
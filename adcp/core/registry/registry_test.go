@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
+	"github.com/devplaninc/adcp-core/adcp/core/adcptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Ref
+		wantErr bool
+	}{
+		{in: "registry://org/backend-recipe@1.4.0", want: Ref{Name: "org/backend-recipe", Version: "1.4.0"}},
+		{in: "registry://backend", want: Ref{Name: "backend"}},
+		{in: "registry:///org/backend/", want: Ref{Name: "org/backend"}},
+		{in: "https://example.com/recipe", wantErr: true},
+		{in: "registry://", wantErr: true},
+		{in: "registry://org/backend@", wantErr: true},
+		{in: "registry://org/../backend", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRef(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRef)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, "registry://org/backend@1.0", Ref{Name: "org/backend", Version: "1.0"}.String())
+}
+
+func TestRecipeInfo_LatestVersion(t *testing.T) {
+	assert.Equal(t, "1.10.0", (&RecipeInfo{Versions: []string{"1.2.0", "1.10.0", "1.9.3"}}).LatestVersion())
+	assert.Equal(t, "1.2.0", (&RecipeInfo{Versions: []string{"1.10.0"}, Latest: "1.2.0"}).LatestVersion())
+	assert.Empty(t, (&RecipeInfo{}).LatestVersion())
+}
+
+const recipeJSON = `{
+  "entryPoint": {"ideType": "claude"},
+  "recipe": {"context": {"entries": [{"path": "a.md", "from": {"text": "hello"}}]}},
+  "addedInLaterSchema": true
+}`
+
+func newRegistry(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /recipes", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"recipes": [{"name": "org/backend", "versions": ["1.0.0", "1.4.0"]}]}`))
+	})
+	mux.HandleFunc("GET /recipes/org/backend", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "org/backend", "versions": ["1.0.0", "1.4.0"]}`))
+	})
+	mux.HandleFunc("GET /recipes/org/backend/versions/1.4.0", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(recipeJSON))
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	srv := newRegistry(t, "secret")
+	metrics := &adcptest.Metrics{}
+	ctx := core.WithMetrics(context.Background(), metrics)
+	c := NewClient(srv.URL+"/", WithToken("secret"), WithHTTPClient(srv.Client()))
+
+	list, err := c.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RecipeInfo{{Name: "org/backend", Versions: []string{"1.0.0", "1.4.0"}}}, list)
+
+	latest, err := c.Latest(ctx, "org/backend")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", latest)
+
+	recipe, ref, err := c.Resolve(ctx, "registry://org/backend")
+	require.NoError(t, err)
+	assert.Equal(t, Ref{Name: "org/backend", Version: "1.4.0"}, ref)
+	assert.Equal(t, "claude", recipe.GetEntryPoint().GetIdeType())
+	assert.Equal(t, "a.md", recipe.GetRecipe().GetContext().GetEntries()[0].GetPath())
+	assert.Equal(t, 4, metrics.Counter(core.MetricFetches))
+}
+
+func TestClient_Errors(t *testing.T) {
+	srv := newRegistry(t, "secret")
+	ctx := context.Background()
+
+	_, err := NewClient(srv.URL, WithHTTPClient(srv.Client())).List(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	c := NewClient(srv.URL, WithToken("secret"), WithHTTPClient(srv.Client()))
+	_, err = c.Fetch(ctx, "org/backend", "9.9.9")
+	assert.ErrorIs(t, err, ErrNotFound)
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusNotFound, respErr.StatusCode)
+
+	_, _, err = c.Resolve(ctx, "org/backend")
+	assert.ErrorIs(t, err, ErrInvalidRef)
+}
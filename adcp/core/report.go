@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Report summarizes a materialization for tooling. It is safe for concurrent use.
+type Report struct {
+	mu            sync.Mutex
+	compatibility Compatibility
+	files         []string
+	warnings      []Warning
+	secrets       []string
+}
+
+// ReportData is a snapshot of a Report.
+type ReportData struct {
+	Compatibility Compatibility `json:"compatibility"`
+	// Files lists the paths of the materialized files in result order.
+	Files    []string  `json:"files"`
+	Warnings []Warning `json:"warnings"`
+	// Secrets lists the names of the secrets resolved during materialization, for auditing.
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// NewReport returns an empty report stamped with the current compatibility information.
+func NewReport() *Report {
+	return &Report{compatibility: CurrentCompatibility()}
+}
+
+// AddFiles records materialized file paths. Adding to a nil Report is a no-op.
+func (r *Report) AddFiles(paths ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files = append(r.files, paths...)
+}
+
+// AddWarning records a warning. Adding to a nil Report is a no-op; see Warn.
+func (r *Report) AddWarning(w Warning) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, w)
+}
+
+// AddSecrets records the names of resolved secrets, once each. Adding to a nil Report is a no-op;
+// see LookupSecret.
+func (r *Report) AddSecrets(names ...string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		if !slices.Contains(r.secrets, name) {
+			r.secrets = append(r.secrets, name)
+		}
+	}
+}
+
+// Data returns a snapshot of the report.
+func (r *Report) Data() ReportData {
+	if r == nil {
+		return ReportData{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReportData{
+		Compatibility: r.compatibility,
+		Files:         append([]string{}, r.files...),
+		Warnings:      append([]Warning{}, r.warnings...),
+		Secrets:       slices.Sorted(slices.Values(r.secrets)),
+	}
+}
+
+type reportKey struct{}
+
+// WithReport returns a copy of ctx carrying report, which components fill during materialization.
+func WithReport(ctx context.Context, report *Report) context.Context {
+	return context.WithValue(ctx, reportKey{}, report)
+}
+
+// ReportFrom returns the Report carried by ctx, or nil if there is none. All Report methods
+// accept a nil receiver.
+func ReportFrom(ctx context.Context) *Report {
+	report, _ := ctx.Value(reportKey{}).(*Report)
+	return report
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// SARIFVersion is the version of the SARIF format written by WriteSARIF.
+const SARIFVersion = "2.1.0"
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// WriteJSON writes the report as indented JSON followed by a newline.
+func (d ReportData) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// WriteSARIF writes the warnings of the report as a SARIF log, which CI systems such as GitHub code
+// scanning turn into pull request annotations. Every warning code becomes a rule and every warning a
+// result located at its path, relative to the repository root.
+func (d ReportData) WriteSARIF(w io.Writer) error {
+	var codes []string
+	results := make([]sarifResult, 0, len(d.Warnings))
+	for _, warning := range d.Warnings {
+		if !slices.Contains(codes, warning.Code) {
+			codes = append(codes, warning.Code)
+		}
+		result := sarifResult{RuleID: warning.Code, Level: "warning", Message: sarifText{Text: warning.Message}}
+		if warning.Path != "" {
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: warning.Path},
+			}}}
+		}
+		results = append(results, result)
+	}
+	slices.Sort(codes)
+	rules := make([]sarifRule, 0, len(codes))
+	for _, code := range codes {
+		rule := sarifRule{ID: code}
+		if desc := WarningDescription(code); desc != "" {
+			rule.ShortDescription = &sarifText{Text: desc}
+		}
+		rules = append(rules, rule)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: SARIFVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "adcp", Version: d.Compatibility.Version, Rules: rules}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("failed to encode sarif report: %w", err)
+	}
+	return nil
+}
+
+// The sarif types cover the subset of SARIF 2.1.0 needed to report warnings.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string     `json:"id"`
+	ShortDescription *sarifText `json:"shortDescription,omitempty"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
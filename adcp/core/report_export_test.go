@@ -0,0 +1,59 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReportData() ReportData {
+	return ReportData{
+		Compatibility: Compatibility{Version: "1.2.3", MinSchemaVersion: 1, MaxSchemaVersion: 1},
+		Files:         []string{".claude/settings.local.json"},
+		Warnings: []Warning{
+			{Code: WarningSuspiciousPermission, Path: ".claude/settings.local.json", Message: "Bash(git <push>) never matches"},
+			{Code: WarningUnsupportedFeature, Message: "permissions are not supported"},
+			{Code: WarningSuspiciousPermission, Path: ".claude/settings.local.json", Message: "Read() is empty"},
+		},
+	}
+}
+
+func TestReportData_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReportData().WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"message": "Bash(git <push>) never matches"`)
+	assert.Contains(t, buf.String(), `"files": [`)
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("}\n")))
+
+	buf.Reset()
+	require.NoError(t, ReportData{}.WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"warnings": null`)
+}
+
+func TestReportData_WriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReportData().WriteSARIF(&buf))
+	assert.JSONEq(t, `{
+	  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+	  "version": "2.1.0",
+	  "runs": [{
+	    "tool": {"driver": {"name": "adcp", "version": "1.2.3", "rules": [
+	      {"id": "suspicious-permission", "shortDescription": {"text": "A permission pattern will most likely never match."}},
+	      {"id": "unsupported-feature", "shortDescription": {"text": "The target IDE cannot represent part of the recipe."}}
+	    ]}},
+	    "results": [
+	      {"ruleId": "suspicious-permission", "level": "warning", "message": {"text": "Bash(git <push>) never matches"},
+	       "locations": [{"physicalLocation": {"artifactLocation": {"uri": ".claude/settings.local.json"}}}]},
+	      {"ruleId": "unsupported-feature", "level": "warning", "message": {"text": "permissions are not supported"}},
+	      {"ruleId": "suspicious-permission", "level": "warning", "message": {"text": "Read() is empty"},
+	       "locations": [{"physicalLocation": {"artifactLocation": {"uri": ".claude/settings.local.json"}}}]}
+	    ]
+	  }]
+	}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, ReportData{}.WriteSARIF(&buf))
+	assert.Contains(t, buf.String(), `"results": []`)
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// GithubTokenSecret is the secret used to authenticate GitHub fetches, if a SecretsProvider provides it.
+const GithubTokenSecret = "GITHUB_TOKEN"
+
+// ErrSecretNotFound matches errors returned when a SecretsProvider does not know a secret.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretsProvider resolves secrets by name. It is the one place credentials enter materialization:
+// GitHub authentication (see GithubTokenSecret), MCP server env and headers and the environment of
+// prefetch commands all resolve their secrets through the provider carried by the context (see
+// WithSecrets). Implementations return an error matching ErrSecretNotFound for unknown secrets.
+type SecretsProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// EnvSecrets reads secrets from environment variables named Prefix followed by the secret name.
+type EnvSecrets struct {
+	Prefix string
+}
+
+func (e EnvSecrets) Secret(_ context.Context, name string) (string, error) {
+	if v, ok := os.LookupEnv(e.Prefix + name); ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+// FileSecrets reads each secret from the file of the same name in Dir, e.g. a mounted Kubernetes
+// secret. A single trailing newline is removed.
+type FileSecrets struct {
+	Dir string
+}
+
+func (f FileSecrets) Secret(_ context.Context, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == ".." {
+		return "", fmt.Errorf("%w: invalid secret name %q", ErrSecretNotFound, name)
+	}
+	data, err := os.ReadFile(filepath.Join(f.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// ExecSecrets runs Command with the secret name appended as the last argument and uses its
+// standard output, without the trailing newline, as the secret, e.g. Command: []string{"op", "read"}.
+// Empty output means the secret does not exist.
+type ExecSecrets struct {
+	Command []string
+}
+
+func (e ExecSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if len(e.Command) == 0 {
+		return "", fmt.Errorf("secrets command cannot be empty")
+	}
+	args := append(append([]string(nil), e.Command[1:]...), name)
+	out, err := exec.CommandContext(ctx, e.Command[0], args...).Output()
+	if err != nil {
+		// The output may contain the secret, so only the error is reported.
+		return "", fmt.Errorf("failed to run secrets command for %s: %w", name, err)
+	}
+	secret := strings.TrimRight(string(out), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return secret, nil
+}
+
+// VaultSecrets reads secrets from a HashiCorp Vault KV version 2 secrets engine. A secret name has
+// the form "path#key", e.g. "ci/github#token"; without "#key" the key "value" is read.
+type VaultSecrets struct {
+	// Addr is the address of the Vault server, e.g. "https://vault.example.com".
+	Addr  string
+	Token string
+	// Mount is the mount path of the secrets engine. Defaults to "secret".
+	Mount string
+	// HTTPClient is used for requests. Defaults to HTTPClient(ctx).
+	HTTPClient *http.Client
+}
+
+func (v VaultSecrets) Secret(ctx context.Context, name string) (string, error) {
+	p, key, ok := strings.Cut(name, "#")
+	if !ok {
+		key = "value"
+	}
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(v.Addr, "/"), mount, strings.Trim(p, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	client := v.HTTPClient
+	if client == nil {
+		client = HTTPClient(ctx)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach vault: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, p)
+	}
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response for %s: %w", p, err)
+	}
+	value, ok := body.Data.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// ChainSecrets asks each provider in turn and returns the first secret found.
+type ChainSecrets []SecretsProvider
+
+func (c ChainSecrets) Secret(ctx context.Context, name string) (string, error) {
+	for _, p := range c {
+		secret, err := p.Secret(ctx, name)
+		if err == nil {
+			return secret, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+type secretsKey struct{}
+
+// WithSecrets returns a copy of ctx carrying provider, which resolves every secret needed during
+// materialization.
+func WithSecrets(ctx context.Context, provider SecretsProvider) context.Context {
+	return context.WithValue(ctx, secretsKey{}, provider)
+}
+
+// SecretsFrom returns the SecretsProvider carried by ctx, or nil if there is none.
+func SecretsFrom(ctx context.Context) SecretsProvider {
+	provider, _ := ctx.Value(secretsKey{}).(SecretsProvider)
+	return provider
+}
+
+// LookupSecret resolves the secret name with the provider carried by ctx. Every successful lookup
+// is logged and recorded in the Report carried by ctx, by name only, so that credential use can be
+// audited. Without a provider, all secrets are reported as not found.
+func LookupSecret(ctx context.Context, name string) (string, error) {
+	provider := SecretsFrom(ctx)
+	if provider == nil {
+		return "", fmt.Errorf("%w: %s (no secrets provider configured)", ErrSecretNotFound, name)
+	}
+	secret, err := provider.Secret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	Logger(ctx).Debug("Resolved secret", "name", name, "provider", fmt.Sprintf("%T", provider))
+	ReportFrom(ctx).AddSecrets(name)
+	return secret, nil
+}
+
+// secretRef matches secret references in templates, e.g. "${secret:GITHUB_TOKEN}".
+var secretRef = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// ExpandSecrets replaces references of the form "${secret:NAME}" in s with the secrets resolved by
+// LookupSecret. Text without references is returned unchanged.
+func ExpandSecrets(ctx context.Context, s string) (string, error) {
+	var firstErr error
+	out := secretRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if firstErr != nil {
+			return ref
+		}
+		secret, err := LookupSecret(ctx, secretRef.FindStringSubmatch(ref)[1])
+		if err != nil {
+			firstErr = err
+			return ref
+		}
+		return secret
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
+
+// ExpandSecretsMap returns a copy of m with secret references in its values expanded (see ExpandSecrets).
+func ExpandSecretsMap(ctx context.Context, m map[string]string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		expanded, err := ExpandSecrets(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		out[k] = expanded
+	}
+	return out, nil
+}
+
+type commandEnvKey struct{}
+
+// WithCommandEnv returns a copy of ctx adding env to the environment of commands run with it.
+// Values may reference secrets (see ExpandSecrets), which are resolved when a command runs.
+func WithCommandEnv(ctx context.Context, env map[string]string) context.Context {
+	return context.WithValue(ctx, commandEnvKey{}, env)
+}
+
+// CommandEnv returns the variables added by WithCommandEnv as sorted "KEY=value" pairs, with
+// secret references expanded.
+func CommandEnv(ctx context.Context) ([]string, error) {
+	env, _ := ctx.Value(commandEnvKey{}).(map[string]string)
+	if len(env) == 0 {
+		return nil, nil
+	}
+	expanded, err := ExpandSecretsMap(ctx, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand command env: %w", err)
+	}
+	pairs := make([]string, 0, len(expanded))
+	for k, v := range expanded {
+		pairs = append(pairs, k+"="+v)
+	}
+	slices.Sort(pairs)
+	return pairs, nil
+}
+
+type commandDirKey struct{}
+
+// WithCommandDir returns a copy of ctx running commands in dir instead of the working directory,
+// e.g. the root of the repository being materialized.
+func WithCommandDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, commandDirKey{}, dir)
+}
+
+// CommandDir returns the directory set by WithCommandDir, or "" for the working directory.
+func CommandDir(ctx context.Context) string {
+	dir, _ := ctx.Value(commandDirKey{}).(string)
+	return dir
+}
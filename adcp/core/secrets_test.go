@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsProviders(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("ADCP_TEST_TOKEN", "from-env")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TOKEN"), []byte("from-file\n"), 0o600))
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.URL.Path != "/v1/kv/data/ci/github" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"data": {"token": "from-vault", "value": "default"}}}`))
+	}))
+	defer vault.Close()
+
+	tests := []struct {
+		name     string
+		provider SecretsProvider
+		secret   string
+		want     string
+		wantErr  error
+	}{
+		{name: "env", provider: EnvSecrets{Prefix: "ADCP_TEST_"}, secret: "TOKEN", want: "from-env"},
+		{name: "env missing", provider: EnvSecrets{Prefix: "ADCP_TEST_"}, secret: "MISSING", wantErr: ErrSecretNotFound},
+		{name: "file", provider: FileSecrets{Dir: dir}, secret: "TOKEN", want: "from-file"},
+		{name: "file missing", provider: FileSecrets{Dir: dir}, secret: "MISSING", wantErr: ErrSecretNotFound},
+		{name: "file outside dir", provider: FileSecrets{Dir: dir}, secret: "../TOKEN", wantErr: ErrSecretNotFound},
+		{name: "exec", provider: ExecSecrets{Command: []string{"echo", "value-of"}}, secret: "TOKEN", want: "value-of TOKEN"},
+		{name: "exec empty", provider: ExecSecrets{Command: []string{"true"}}, secret: "TOKEN", wantErr: ErrSecretNotFound},
+		{name: "vault key", provider: VaultSecrets{Addr: vault.URL, Token: "root", Mount: "kv"}, secret: "ci/github#token", want: "from-vault"},
+		{name: "vault default key", provider: VaultSecrets{Addr: vault.URL, Token: "root", Mount: "kv"}, secret: "ci/github", want: "default"},
+		{name: "vault missing", provider: VaultSecrets{Addr: vault.URL, Token: "root", Mount: "kv"}, secret: "ci/other", wantErr: ErrSecretNotFound},
+		{
+			name:     "chain",
+			provider: ChainSecrets{EnvSecrets{Prefix: "ADCP_TEST_"}, FileSecrets{Dir: dir}},
+			secret:   "TOKEN",
+			want:     "from-env",
+		},
+		{name: "chain missing", provider: ChainSecrets{EnvSecrets{}, FileSecrets{Dir: dir}}, secret: "NOPE", wantErr: ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Secret(ctx, tt.secret)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type failingSecrets struct{}
+
+func (failingSecrets) Secret(context.Context, string) (string, error) {
+	return "", errors.New("backend down")
+}
+
+func TestChainSecrets_StopsOnFailure(t *testing.T) {
+	t.Setenv("ADCP_TEST_TOKEN", "from-env")
+	_, err := ChainSecrets{failingSecrets{}, EnvSecrets{Prefix: "ADCP_TEST_"}}.Secret(context.Background(), "TOKEN")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestExpandSecrets(t *testing.T) {
+	t.Setenv("ADCP_TEST_TOKEN", "s3cret")
+	report := NewReport()
+	ctx := WithReport(WithSecrets(context.Background(), EnvSecrets{Prefix: "ADCP_TEST_"}), report)
+
+	got, err := ExpandSecrets(ctx, "Bearer ${secret:TOKEN}, again ${secret:TOKEN}")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer s3cret, again s3cret", got)
+	assert.Equal(t, []string{"TOKEN"}, report.Data().Secrets)
+
+	got, err = ExpandSecrets(context.Background(), "no references")
+	require.NoError(t, err)
+	assert.Equal(t, "no references", got)
+
+	_, err = ExpandSecrets(context.Background(), "${secret:TOKEN}")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	m, err := ExpandSecretsMap(ctx, map[string]string{"A": "${secret:TOKEN}", "B": "plain"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "s3cret", "B": "plain"}, m)
+}
+
+func TestCommandEnv(t *testing.T) {
+	t.Setenv("ADCP_TEST_TOKEN", "s3cret")
+	ctx := WithSecrets(context.Background(), EnvSecrets{Prefix: "ADCP_TEST_"})
+
+	env, err := CommandEnv(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, env)
+
+	env, err = CommandEnv(WithCommandEnv(ctx, map[string]string{"TOKEN": "${secret:TOKEN}", "MODE": "ci"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MODE=ci", "TOKEN=s3cret"}, env)
+
+	_, err = CommandEnv(WithCommandEnv(context.Background(), map[string]string{"TOKEN": "${secret:TOKEN}"}))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
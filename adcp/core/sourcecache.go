@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// SourceCache holds the content of sources fetched during a run, so that materializing the same
+// recipe several times in one process, e.g. once per IDE, runs each command and fetches each file
+// only once and every run sees the same content. Unlike State, it is never persisted and caches
+// every source regardless of its inputs; content streamed to blobs is not cached. It is safe for
+// concurrent use; a nil SourceCache caches nothing.
+type SourceCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+// NewSourceCache returns an empty cache.
+func NewSourceCache() *SourceCache {
+	return &SourceCache{entries: map[string]string{}}
+}
+
+// Lookup returns the content cached for key.
+func (c *SourceCache) Lookup(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content, ok := c.entries[key]
+	return content, ok
+}
+
+// Store caches content for key.
+func (c *SourceCache) Store(key, content string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = content
+}
+
+type sourceCacheKey struct{}
+
+// WithSourceCache returns a copy of ctx whose commands and fetches are served from cache.
+func WithSourceCache(ctx context.Context, cache *SourceCache) context.Context {
+	return context.WithValue(ctx, sourceCacheKey{}, cache)
+}
+
+// SourceCacheFrom returns the SourceCache carried by ctx, or nil if there is none.
+func SourceCacheFrom(ctx context.Context) *SourceCache {
+	c, _ := ctx.Value(sourceCacheKey{}).(*SourceCache)
+	return c
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// DefaultStatePath is the conventional location of the incremental materialization state.
+const DefaultStatePath = ".adcp/state.json"
+
+// stateIgnore is written as .gitignore into state directories created by Save.
+const stateIgnore = "# Created by adcp: the incremental state is a local cache.\n*\n"
+
+// stateVersion is bumped whenever the state file format changes; older files are discarded.
+const stateVersion = 1
+
+// State caches the content of sources whose inputs are known not to have changed between runs,
+// such as GitHub files pinned to a commit SHA or commands with declared inputs (see
+// WithCommandInputs), so re-runs skip fetching and executing them. It is safe for concurrent use;
+// a nil State caches nothing.
+type State struct {
+	mu      sync.Mutex
+	entries map[string]StateEntry
+	used    map[string]bool
+}
+
+// StateEntry is the cached content of one source.
+type StateEntry struct {
+	// Inputs is a hash of everything besides the key the content depends on; a mismatch invalidates the entry.
+	Inputs  string `json:"inputs,omitempty"`
+	Content string `json:"content"`
+}
+
+type stateFile struct {
+	Version int                   `json:"version"`
+	Entries map[string]StateEntry `json:"entries"`
+}
+
+// NewState returns an empty state.
+func NewState() *State {
+	return &State{entries: map[string]StateEntry{}, used: map[string]bool{}}
+}
+
+// LoadState reads the state at path (see ReadFile), resolved against CommandDir(ctx) unless ctx
+// carries a filesystem (see ResolvePath). A missing file or a file written by another format
+// version yields an empty state, as does a file that cannot be parsed, with a WarningInvalidState.
+func LoadState(ctx context.Context, path string) (*State, error) {
+	s := NewState()
+	if fsys, ok := ctx.Value(fsKey{}).(fs.FS); !ok || fsys == nil {
+		path = ResolvePath(ctx, path)
+	}
+	data, err := ReadFile(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read state %s: %w", path, err)
+	}
+	var f stateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		Warn(ctx, Warning{
+			Code:    WarningInvalidState,
+			Path:    path,
+			Message: fmt.Sprintf("Failed to parse state, starting from an empty one: %v", err),
+		})
+		return s, nil
+	}
+	if f.Version == stateVersion && f.Entries != nil {
+		s.entries = f.Entries
+	}
+	return s, nil
+}
+
+// Lookup returns the cached content for key if it was recorded with the same inputs hash.
+func (s *State) Lookup(key, inputs string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || e.Inputs != inputs {
+		return "", false
+	}
+	s.used[key] = true
+	return e.Content, true
+}
+
+// Store records content for key and inputs hash.
+func (s *State) Store(key, inputs, content string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = StateEntry{Inputs: inputs, Content: content}
+	s.used[key] = true
+}
+
+// Save writes the entries looked up or stored since the state was loaded to path, so sources a
+// recipe no longer uses are dropped. Relative paths are resolved against CommandDir(ctx) (see
+// ResolvePath). Nothing is written in dry-run mode.
+//
+// The state holds the content of the cached sources, which may be private, so it is readable by
+// the owner only and replaced atomically. If its directory, e.g. .adcp, does not exist, it is
+// created with a .gitignore keeping it out of version control.
+func (s *State) Save(ctx context.Context, path string) error {
+	if s == nil || IsDryRun(ctx) {
+		return nil
+	}
+	path = ResolvePath(ctx, path)
+	s.mu.Lock()
+	f := stateFile{Version: stateVersion, Entries: map[string]StateEntry{}}
+	for key := range s.used {
+		f.Entries[key] = s.entries[key]
+	}
+	s.mu.Unlock()
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directories for %s: %w", path, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(stateIgnore), 0o644); err != nil {
+			return fmt.Errorf("failed to write .gitignore for %s: %w", path, err)
+		}
+	}
+	if err := replaceFile(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	return nil
+}
+
+// replaceFile writes data to a temporary file readable by the owner only and renames it to path,
+// so readers never see a partially written file.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type stateKey struct{}
+
+// WithState returns a copy of ctx carrying state, enabling incremental materialization.
+func WithState(ctx context.Context, state *State) context.Context {
+	return context.WithValue(ctx, stateKey{}, state)
+}
+
+// StateFrom returns the State carried by ctx, or nil if there is none.
+func StateFrom(ctx context.Context) *State {
+	s, _ := ctx.Value(stateKey{}).(*State)
+	return s
+}
+
+type commandInputsKey struct{}
+
+// WithCommandInputs returns a copy of ctx declaring the files each command (keyed by its command
+// line) depends on. Only commands with declared inputs are cached in the State; they are executed
+// again whenever the content of one of their inputs changes.
+func WithCommandInputs(ctx context.Context, inputs map[string][]string) context.Context {
+	return context.WithValue(ctx, commandInputsKey{}, inputs)
+}
+
+// CommandInputsHash returns a hash of the command line and the content of its declared inputs,
+// and false if cmd has no declared inputs.
+func CommandInputsHash(ctx context.Context, cmd string) (string, bool) {
+	inputs, _ := ctx.Value(commandInputsKey{}).(map[string][]string)
+	paths, ok := inputs[cmd]
+	if !ok {
+		return "", false
+	}
+	paths = slices.Sorted(slices.Values(paths))
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%q\n", cmd)
+	for _, p := range paths {
+		data, err := ReadFile(ctx, p)
+		if err != nil {
+			_, _ = fmt.Fprintf(h, "%q missing\n", p)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		_, _ = fmt.Fprintf(h, "%q %x\n", p, sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_SaveAndLoad(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), DefaultStatePath)
+
+	s, err := LoadState(ctx, path)
+	require.NoError(t, err)
+	s.Store("a", "h1", "content a")
+	s.Store("b", "", "content b")
+	require.NoError(t, s.Save(ctx, path))
+
+	loaded, err := LoadState(ctx, path)
+	require.NoError(t, err)
+	content, ok := loaded.Lookup("a", "h1")
+	assert.True(t, ok)
+	assert.Equal(t, "content a", content)
+	_, ok = loaded.Lookup("a", "h2")
+	assert.False(t, ok, "changed inputs must invalidate the entry")
+
+	// Only entries used since loading are kept.
+	require.NoError(t, loaded.Save(ctx, path))
+	loaded, err = LoadState(ctx, path)
+	require.NoError(t, err)
+	_, ok = loaded.Lookup("b", "")
+	assert.False(t, ok)
+}
+
+func TestState_Save_PrivateAndIgnored(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	path := filepath.Join(root, DefaultStatePath)
+
+	s := NewState()
+	s.Store("a", "", "private content")
+	require.NoError(t, s.Save(ctx, path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	ignore, err := os.ReadFile(filepath.Join(root, ".adcp", ".gitignore"))
+	require.NoError(t, err)
+	assert.Contains(t, string(ignore), "*\n")
+
+	// An existing state is replaced without leaving temporary files behind.
+	require.NoError(t, os.Chmod(path, 0o644))
+	require.NoError(t, s.Save(ctx, path))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	files, err := os.ReadDir(filepath.Join(root, ".adcp"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Existing directories are left as they are.
+	require.NoError(t, s.Save(ctx, filepath.Join(root, "state.json")))
+	assert.NoFileExists(t, filepath.Join(root, ".gitignore"))
+}
+
+func TestState_CommandDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	root := t.TempDir()
+	ctx := WithCommandDir(context.Background(), root)
+
+	s := NewState()
+	s.Store("a", "", "content a")
+	require.NoError(t, s.Save(ctx, DefaultStatePath))
+	assert.FileExists(t, filepath.Join(root, DefaultStatePath))
+	assert.NoFileExists(t, DefaultStatePath, "relative paths resolve against the command dir, not the working directory")
+
+	for _, ctx := range []context.Context{ctx, WithFS(ctx, os.DirFS(root))} {
+		loaded, err := LoadState(ctx, DefaultStatePath)
+		require.NoError(t, err)
+		_, ok := loaded.Lookup("a", "")
+		assert.True(t, ok)
+	}
+}
+
+func TestLoadState_Invalid(t *testing.T) {
+	report := NewReport()
+	ctx := WithFS(WithReport(context.Background(), report), fstest.MapFS{
+		"bad.json": {Data: []byte("{")},
+		"old.json": {Data: []byte(`{"version": 0, "entries": {"a": {"content": "x"}}}`)},
+	})
+	s, err := LoadState(ctx, "bad.json")
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	s.Store("a", "", "x")
+	require.Len(t, report.Data().Warnings, 1)
+	assert.Equal(t, WarningInvalidState, report.Data().Warnings[0].Code)
+	assert.Equal(t, "bad.json", report.Data().Warnings[0].Path)
+
+	s, err = LoadState(ctx, "old.json")
+	require.NoError(t, err)
+	_, ok := s.Lookup("a", "")
+	assert.False(t, ok)
+}
+
+func TestState_DryRunAndNil(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := NewState()
+	s.Store("a", "", "x")
+	require.NoError(t, s.Save(WithDryRun(context.Background(), &Plan{}), path))
+	assert.NoFileExists(t, path)
+
+	var nilState *State
+	nilState.Store("a", "", "x")
+	_, ok := nilState.Lookup("a", "")
+	assert.False(t, ok)
+	assert.NoError(t, nilState.Save(context.Background(), path))
+}
+
+func TestCommandInputsHash(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	require.NoError(t, os.WriteFile(input, []byte("v1"), 0o644))
+	ctx := WithCommandInputs(context.Background(), map[string][]string{"gen": {input}})
+
+	_, ok := CommandInputsHash(ctx, "other")
+	assert.False(t, ok)
+
+	h1, ok := CommandInputsHash(ctx, "gen")
+	require.True(t, ok)
+	again, _ := CommandInputsHash(ctx, "gen")
+	assert.Equal(t, h1, again)
+
+	require.NoError(t, os.WriteFile(input, []byte("v2"), 0o644))
+	h2, _ := CommandInputsHash(ctx, "gen")
+	assert.NotEqual(t, h1, h2)
+}
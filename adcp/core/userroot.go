@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+)
+
+// HomePrefix starts the paths of user-level files, e.g. "~/.codex/config.toml" for configuration an
+// IDE only reads from the home directory (see SetUserFile).
+const HomePrefix = "~/"
+
+// HomeRelative reports whether path starts with HomePrefix and returns it relative to the home
+// directory.
+func HomeRelative(path string) (string, bool) {
+	return strings.CutPrefix(strings.TrimSpace(path), HomePrefix)
+}
+
+type userRootKey struct{}
+
+// WithUserRoot returns a copy of ctx in which IDE providers produce user-level files, and
+// PersistMaterializedResult writes them under root, normally "~", instead of the project root.
+// Without it, providers skip configuration their IDE only reads from the home directory and warn
+// about it (see WarningSkippedUserFile).
+func WithUserRoot(ctx context.Context, root string) context.Context {
+	return context.WithValue(ctx, userRootKey{}, root)
+}
+
+// UserRoot returns the root set by WithUserRoot, or "" if user-level files are not enabled.
+func UserRoot(ctx context.Context) string {
+	root, _ := ctx.Value(userRootKey{}).(string)
+	return root
+}
+
+// ReadUserFile reads the user-level file at path, which starts with HomePrefix, under the root set
+// by WithUserRoot.
+func ReadUserFile(ctx context.Context, path string) ([]byte, error) {
+	rel, ok := HomeRelative(path)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a user-level path", path)
+	}
+	root, err := resolveUserRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if root == "" {
+		return nil, fmt.Errorf("user-level files are not enabled: %s", path)
+	}
+	full := filepath.Join(root, filepath.FromSlash(rel))
+	if !isPathWithinRoot(root, full) {
+		return nil, fmt.Errorf("%w: %s", ErrPathEscapesRoot, path)
+	}
+	return os.ReadFile(full)
+}
+
+// userFiles holds the files marked by SetUserFile.
+var userFiles fileMarks
+
+// SetUserFile marks file, whose path starts with HomePrefix, as user-level configuration produced
+// by an IDE provider, e.g. Codex's ~/.codex/config.toml. PersistMaterializedResult writes it under
+// the root set by WithUserRoot. Like for SetExecutable, the mark belongs to this message, so files
+// from recipes or plugins cannot reach the home directory.
+func SetUserFile(file *adcp.FullFileContent) {
+	userFiles.set(file)
+}
+
+// IsUserFile reports whether file was marked by SetUserFile.
+func IsUserFile(file *adcp.FullFileContent) bool {
+	return userFiles.has(file)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSourceFetch matches errors returned when content cannot be fetched from a remote source.
+	ErrSourceFetch = errors.New("source fetch failed")
+	// ErrCommandFailed matches errors returned when a shell command exits unsuccessfully.
+	ErrCommandFailed = errors.New("command failed")
+)
+
+// FetchError describes a failed fetch from URL. It matches ErrSourceFetch and unwraps to the cause.
+type FetchError struct {
+	URL string
+	// StatusCode is the HTTP status of the response, or 0 if no response was received.
+	StatusCode int
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() []error {
+	return []error{ErrSourceFetch, e.Err}
+}
+
+// CommandError describes a failed command execution. It matches ErrCommandFailed and unwraps to
+// the underlying error, e.g. an *exec.ExitError.
+type CommandError struct {
+	Cmd    string
+	Output string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command execution failed: %v (output: %s)", e.Err, e.Output)
+}
+
+func (e *CommandError) Unwrap() []error {
+	return []error{ErrCommandFailed, e.Err}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package utils
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on platforms without process groups; only the shell itself is
+// killed on cancellation.
+func configureProcessGroup(*exec.Cmd) {}
@@ -2,18 +2,48 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 )
 
+// rawURLCacheSize bounds the number of remembered ConvertToRawURL results.
+const rawURLCacheSize = 1024
+
+// rawURLCache remembers ConvertToRawURL results; recipes commonly reference the same files many times.
+var rawURLCache = newLRU[rawURLKey, string](rawURLCacheSize)
+
+type rawURLKey struct {
+	path    string
+	version string
+	ref     string
+}
+
 // ConvertToRawURL converts a github.com URL to raw.githubusercontent.com format.
 // It handles various GitHub URL formats including /blob/ and /tree/ patterns.
 // If a version is provided, it will be used; otherwise defaults to "main" branch.
 func ConvertToRawURL(githubPath string, version *adcp.GitVersion) (string, error) {
+	key := rawURLKey{path: githubPath, version: version.WhichType().String(), ref: version.GetTag() + version.GetCommit()}
+	if url, ok := rawURLCache.get(key); ok {
+		return url, nil
+	}
+	url, err := convertToRawURL(githubPath, version)
+	if err != nil {
+		return "", err
+	}
+	rawURLCache.add(key, url)
+	return url, nil
+}
+
+func convertToRawURL(githubPath string, version *adcp.GitVersion) (string, error) {
 	// If it's already a raw.githubusercontent.com URL or doesn't contain github.com, return as-is
 	if strings.Contains(githubPath, "raw.githubusercontent.com") || !strings.Contains(githubPath, "github.com") {
 		return githubPath, nil
@@ -68,7 +98,8 @@ func ConvertToRawURL(githubPath string, version *adcp.GitVersion) (string, error
 }
 
 // FetchGithub fetches the content of a GitHub file reference using a raw content URL.
-// If the provided ref.Path is not a github.com URL, it is used as-is.
+// If the provided ref.Path is not a github.com URL, it is used as-is. In streaming mode (see
+// core.WithStreaming), large content is returned as a blob reference instead.
 func FetchGithub(ctx context.Context, ref *adcp.GitReference) (string, error) {
 	if ref == nil {
 		return "", fmt.Errorf("github reference cannot be nil")
@@ -84,25 +115,99 @@ func FetchGithub(ctx context.Context, ref *adcp.GitReference) (string, error) {
 		return "", err
 	}
 
+	log := core.Logger(ctx).With("op", "FetchGithub", "url", url)
+	cache := core.SourceCacheFrom(ctx)
+	if content, ok := cache.Lookup(githubStateKey(url)); ok {
+		log.Debug("Already fetched in this run, using its content", "bytes", len(content))
+		core.MetricsFrom(ctx).IncCounter(core.MetricCacheHits, map[string]string{"source": "run"})
+		return content, nil
+	}
+	// Content of a commit is immutable, so it can be reused from a previous run.
+	state := core.StateFrom(ctx)
+	pinned := pinnedCommitURL.MatchString(url)
+	if pinned {
+		if content, ok := state.Lookup(githubStateKey(url), ""); ok {
+			log.Debug("Pinned to a commit, using cached content", "bytes", len(content))
+			core.MetricsFrom(ctx).IncCounter(core.MetricCacheHits, map[string]string{"source": "state"})
+			return content, nil
+		}
+	}
+	limiter := core.LimiterFrom(ctx)
+	if err := limiter.Acquire(ctx); err != nil {
+		return "", &FetchError{URL: url, Err: err}
+	}
+	defer limiter.Release()
+	log.Debug("Fetching from github")
+	start := time.Now()
+	spool := core.NewSpool(ctx)
+	err = fetchGithub(ctx, log, url, spool)
+	metrics := core.MetricsFrom(ctx)
+	tags := map[string]string{"source": "github", "status": core.StatusTag(err)}
+	metrics.IncCounter(core.MetricFetches, tags)
+	metrics.ObserveDuration(core.MetricFetchDuration, time.Since(start), tags)
+	if err != nil {
+		return "", err
+	}
+	content, err := spool.Content()
+	if err != nil {
+		return "", &FetchError{URL: url, Err: err}
+	}
+
+	log.Debug("Fetched from github", "bytes", spool.Len(), "spilled", spool.Spilled())
+	core.ObserverFrom(ctx).BytesFetched(url, int(spool.Len()))
+	if !spool.Spilled() {
+		cache.Store(githubStateKey(url), content)
+		if pinned {
+			state.Store(githubStateKey(url), "", content)
+		}
+	}
+	return content, nil
+}
+
+// IsPinnedToCommit reports whether ref refers to the content of a fixed commit, either through its
+// version or a full commit SHA in its path.
+func IsPinnedToCommit(ref *adcp.GitReference) bool {
+	url, err := ConvertToRawURL(ref.GetPath(), ref.GetVersion())
+	return err == nil && pinnedCommitURL.MatchString(url)
+}
+
+// pinnedCommitURL matches raw GitHub URLs whose ref is a full commit SHA.
+var pinnedCommitURL = regexp.MustCompile(`^https://raw\.githubusercontent\.com/[^/]+/[^/]+/[0-9a-f]{40}/`)
+
+// githubStateKey is the core.State key of the content at url.
+func githubStateKey(url string) string {
+	return "github:" + url
+}
+
+// fetchGithub copies the content at url to w.
+func fetchGithub(ctx context.Context, log *slog.Logger, url string, w io.Writer) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if core.SecretsFrom(ctx) != nil {
+		token, err := core.LookupSecret(ctx, core.GithubTokenSecret)
+		switch {
+		case err == nil:
+			req.Header.Set("Authorization", "Bearer "+token)
+		case !errors.Is(err, core.ErrSecretNotFound):
+			return &FetchError{URL: url, Err: fmt.Errorf("failed to resolve github token: %w", err)}
+		}
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := core.HTTPClient(ctx).Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch from github: %w", err)
+		return &FetchError{URL: url, Err: fmt.Errorf("failed to fetch from github: %w", err)}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("github fetch returned status %d", resp.StatusCode)
+		log.Debug("Github fetch failed", "status", resp.StatusCode)
+		return &FetchError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("github fetch returned status %d", resp.StatusCode)}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return &FetchError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to read response body: %w", err)}
 	}
-
-	return string(body), nil
+	return nil
 }
@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/devplaninc/adcp-core/adcp/core"
 	"github.com/devplaninc/adcp/clients/go/adcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -68,3 +72,35 @@ func TestConvertToRawURL_TreeFormat(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "https://raw.githubusercontent.com/owner/repo/v1.0.0/docs/guide.md", result)
 }
+
+func TestFetchGithub_StatusError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := FetchGithub(context.Background(), adcp.GitReference_builder{Path: srv.URL + "/missing.md"}.Build())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrSourceFetch)
+	var fetchErr *FetchError
+	require.ErrorAs(t, err, &fetchErr)
+	assert.Equal(t, http.StatusNotFound, fetchErr.StatusCode)
+	assert.Equal(t, "github fetch returned status 404", err.Error())
+}
+
+func TestFetchGithub_TokenFromSecrets(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	ref := adcp.GitReference_builder{Path: srv.URL + "/file.md"}.Build()
+
+	_, err := FetchGithub(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Empty(t, auth)
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	_, err = FetchGithub(core.WithSecrets(context.Background(), core.EnvSecrets{Prefix: "TEST_"}), ref)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer ghp_test", auth)
+}
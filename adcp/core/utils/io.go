@@ -3,20 +3,113 @@ package utils
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/devplaninc/adcp-core/adcp/core"
 )
 
+// commandWaitDelay bounds how long ExecuteCommand waits for output pipes to close after the
+// command was killed, e.g. because a background child process keeps them open.
+const commandWaitDelay = 2 * time.Second
+
 // ExecuteCommand runs the provided shell command and returns its combined stdout/stderr output as string.
+// When ctx is cancelled, the whole process group of the command is killed. In streaming mode (see
+// core.WithStreaming), large output is returned as a blob reference instead.
 func ExecuteCommand(ctx context.Context, cmd string) (string, error) {
 	if cmd == "" {
 		return "", fmt.Errorf("command cannot be empty")
 	}
+	if err := ctx.Err(); err != nil {
+		return "", &CommandError{Cmd: cmd, Err: err}
+	}
+	log := core.Logger(ctx).With("op", "ExecuteCommand", "cmd", cmd)
+	env, err := core.CommandEnv(ctx)
+	if err != nil {
+		return "", &CommandError{Cmd: cmd, Err: err}
+	}
+	// The same command line may behave differently in another directory or environment.
+	runKey := strings.Join(append([]string{commandStateKey(cmd), core.CommandDir(ctx)}, env...), "\x00")
+	cache := core.SourceCacheFrom(ctx)
+	if output, ok := cache.Lookup(runKey); ok {
+		log.Debug("Already executed in this run, using its output", "bytes", len(output))
+		core.MetricsFrom(ctx).IncCounter(core.MetricCacheHits, map[string]string{"source": "run"})
+		return output, nil
+	}
+	state := core.StateFrom(ctx)
+	var inputs string
+	cacheable := false
+	if state != nil {
+		inputs, cacheable = core.CommandInputsHash(ctx, cmd)
+	}
+	if cacheable {
+		if output, ok := state.Lookup(commandStateKey(cmd), inputs); ok {
+			log.Debug("Inputs unchanged, using cached command output", "bytes", len(output))
+			core.MetricsFrom(ctx).IncCounter(core.MetricCacheHits, map[string]string{"source": "state"})
+			return output, nil
+		}
+	}
+
+	limiter := core.LimiterFrom(ctx)
+	if err := limiter.Acquire(ctx); err != nil {
+		return "", &CommandError{Cmd: cmd, Err: err}
+	}
+	defer limiter.Release()
 
+	log.Debug("Executing command")
+	start := time.Now()
 	command := exec.CommandContext(ctx, "sh", "-c", cmd)
-	output, err := command.CombinedOutput()
+	command.Dir = core.CommandDir(ctx)
+	if len(env) > 0 {
+		command.Env = append(os.Environ(), env...)
+	}
+	command.WaitDelay = commandWaitDelay
+	configureProcessGroup(command)
+	// Large output is streamed to a blob file if the caller allows it (see core.WithStreaming).
+	spool := core.NewSpool(ctx)
+	command.Stdout = spool
+	command.Stderr = spool
+	err = command.Run()
+	recordCommandMetrics(ctx, time.Since(start), err)
+	output, spoolErr := spool.Content()
+	if err == nil {
+		err = spoolErr
+	}
 	if err != nil {
-		return "", fmt.Errorf("command execution failed: %w (output: %s)", err, string(output))
+		log.Debug("Command failed", "error", err, "duration", time.Since(start))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		if spool.Spilled() {
+			output = fmt.Sprintf("<%d bytes of output>", spool.Len())
+		}
+		return "", &CommandError{Cmd: cmd, Output: output, Err: err}
+	}
+	log.Debug("Command finished", "bytes", spool.Len(), "duration", time.Since(start), "spilled", spool.Spilled())
+	core.ObserverFrom(ctx).BytesFetched(cmd, int(spool.Len()))
+	if !spool.Spilled() {
+		cache.Store(runKey, output)
+		if cacheable {
+			state.Store(commandStateKey(cmd), inputs, output)
+		}
 	}
 
-	return string(output), nil
+	return output, nil
+}
+
+// commandStateKey is the core.State key of the output of cmd.
+func commandStateKey(cmd string) string {
+	return "cmd:" + cmd
+}
+
+func recordCommandMetrics(ctx context.Context, d time.Duration, err error) {
+	metrics := core.MetricsFrom(ctx)
+	tags := map[string]string{"status": core.StatusTag(err)}
+	metrics.IncCounter(core.MetricCommands, tags)
+	metrics.ObserveDuration(core.MetricCommandDuration, d, tags)
+	if err != nil {
+		metrics.IncCounter(core.MetricCommandFailures, nil)
+	}
 }
@@ -5,7 +5,9 @@ package utils
 
 import (
 	"context"
+	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,4 +23,20 @@ func TestExecuteCommand_Integration_Error(t *testing.T) {
 	_, err := ExecuteCommand(context.Background(), "exit 1")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "command execution failed")
+	assert.ErrorIs(t, err, ErrCommandFailed)
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+}
+
+func TestExecuteCommand_Integration_CancelKillsChildren(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// The background sleep keeps the output pipe open after the shell is killed.
+	_, err := ExecuteCommand(ctx, "sleep 30 & sleep 30")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, ErrCommandFailed)
+	assert.Less(t, time.Since(start), 5*time.Second)
 }
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/devplaninc/adcp/clients/go/adcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU[string, int](2)
+	c.add("a", 1)
+	c.add("b", 2)
+	_, _ = c.get("a")
+	c.add("c", 3)
+
+	_, ok := c.get("b")
+	assert.False(t, ok)
+	v, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, ok = c.get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+}
+
+func TestConvertToRawURL_CachedPerVersion(t *testing.T) {
+	url := "https://github.com/myorg/cached/file.md"
+	for _, tc := range []struct {
+		version *adcp.GitVersion
+		want    string
+	}{
+		{nil, "https://raw.githubusercontent.com/myorg/cached/main/file.md"},
+		{adcp.GitVersion_builder{Tag: strPtr("v1")}.Build(), "https://raw.githubusercontent.com/myorg/cached/v1/file.md"},
+		{adcp.GitVersion_builder{Commit: strPtr("v1")}.Build(), "https://raw.githubusercontent.com/myorg/cached/v1/file.md"},
+		{adcp.GitVersion_builder{Commit: strPtr("abc")}.Build(), "https://raw.githubusercontent.com/myorg/cached/abc/file.md"},
+		{nil, "https://raw.githubusercontent.com/myorg/cached/main/file.md"},
+	} {
+		got, err := ConvertToRawURL(url, tc.version)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func BenchmarkConvertToRawURL(b *testing.B) {
+	version := adcp.GitVersion_builder{Tag: strPtr("v1.2.3")}.Build()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ConvertToRawURL("https://github.com/myorg/repo/blob/main/docs/guide.md", version); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+const (
+	modulePath      = "github.com/devplaninc/adcp-core"
+	protoModulePath = "github.com/devplaninc/adcp/clients/go"
+)
+
+// Recipe schema versions this library can materialize. The schema version is bumped whenever the
+// recipe proto gains fields that older clients would silently ignore.
+const (
+	MinRecipeSchemaVersion = 1
+	MaxRecipeSchemaVersion = 1
+)
+
+// version can be set at build time with
+// -ldflags "-X github.com/devplaninc/adcp-core/adcp/core.version=v1.2.3".
+var version string
+
+var buildInfo = sync.OnceValues(func() (string, string) {
+	coreVersion, protoVersion := "", ""
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return coreVersion, protoVersion
+	}
+	if info.Main.Path == modulePath {
+		coreVersion = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		switch dep.Path {
+		case modulePath:
+			coreVersion = dep.Version
+		case protoModulePath:
+			protoVersion = dep.Version
+		}
+	}
+	return coreVersion, protoVersion
+})
+
+// Version returns the version of this library: the build-time version if set, otherwise the
+// module version recorded in the binary, or "devel".
+func Version() string {
+	if version != "" {
+		return version
+	}
+	if v, _ := buildInfo(); v != "" && v != "(devel)" {
+		return v
+	}
+	return "devel"
+}
+
+// Compatibility describes which recipes this library understands.
+type Compatibility struct {
+	Version string `json:"version"`
+	// ProtoVersion is the version of the recipe proto module, or "" if unknown.
+	ProtoVersion     string `json:"protoVersion,omitempty"`
+	MinSchemaVersion int    `json:"minSchemaVersion"`
+	MaxSchemaVersion int    `json:"maxSchemaVersion"`
+}
+
+// CurrentCompatibility returns the compatibility information of this build.
+func CurrentCompatibility() Compatibility {
+	_, protoVersion := buildInfo()
+	return Compatibility{
+		Version:          Version(),
+		ProtoVersion:     protoVersion,
+		MinSchemaVersion: MinRecipeSchemaVersion,
+		MaxSchemaVersion: MaxRecipeSchemaVersion,
+	}
+}
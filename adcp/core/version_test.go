@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	assert.NotEmpty(t, Version())
+
+	compat := CurrentCompatibility()
+	assert.Equal(t, Version(), compat.Version)
+	assert.LessOrEqual(t, compat.MinSchemaVersion, compat.MaxSchemaVersion)
+}
+
+func TestReport(t *testing.T) {
+	assert.Nil(t, ReportFrom(context.Background()))
+	ReportFrom(context.Background()).AddFiles("ignored")
+
+	report := NewReport()
+	ctx := WithReport(context.Background(), report)
+	ReportFrom(ctx).AddFiles("a.md", "b.md")
+
+	data := report.Data()
+	assert.Equal(t, []string{"a.md", "b.md"}, data.Files)
+	assert.Equal(t, CurrentCompatibility(), data.Compatibility)
+}
@@ -0,0 +1,86 @@
+package core
+
+import "context"
+
+// Warning codes identify non-fatal situations reported during materialization.
+const (
+	// WarningInvalidExistingFile: an existing file could not be parsed and is replaced instead of merged.
+	WarningInvalidExistingFile = "invalid-existing-file"
+	// WarningIgnoredMCPServer: an MCP server without a transport type is left out.
+	WarningIgnoredMCPServer = "ignored-mcp-server"
+	// WarningDroppedPermission: a permission of an unknown or unsupported kind is left out.
+	WarningDroppedPermission = "dropped-permission"
+	// WarningSuspiciousPermission: a permission pattern will most likely never match.
+	WarningSuspiciousPermission = "suspicious-permission"
+	// WarningUnsupportedFeature: the target IDE cannot represent part of the recipe.
+	WarningUnsupportedFeature = "unsupported-feature"
+	// WarningDebugDumpFailed: the generation context debug dump could not be written.
+	WarningDebugDumpFailed = "debug-dump-failed"
+	// WarningInvalidState: the incremental state could not be read; all sources are fetched again.
+	WarningInvalidState = "invalid-state"
+	// WarningStateNotSaved: the incremental state could not be written; the next run fetches everything again.
+	WarningStateNotSaved = "state-not-saved"
+	// WarningReplacedGitHook: a git hook not installed by adcp is replaced.
+	WarningReplacedGitHook = "replaced-git-hook"
+	// WarningSkippedImport: existing IDE configuration is not, or only partly, imported into a recipe.
+	WarningSkippedImport = "skipped-import"
+	// WarningNonPortablePath: a file is written to a path that cannot be created on every OS, e.g. "aux.md" on Windows.
+	WarningNonPortablePath = "non-portable-path"
+	// WarningConflictingPermission: a permission is both allowed and denied; the deny rule wins.
+	WarningConflictingPermission = "conflicting-permission"
+	// WarningUnpinnedSource: a GitHub source is not pinned to a commit, so its content may change between runs.
+	WarningUnpinnedSource = "unpinned-source"
+	// WarningOversizedEntry: a materialized file is larger than agents can usefully read (see recipes.Recipe.MaxEntrySize).
+	WarningOversizedEntry = "oversized-entry"
+	// WarningSkippedUserFile: configuration the IDE only reads from the home directory is not written, as user-level files are not enabled (see WithUserRoot).
+	WarningSkippedUserFile = "skipped-user-file"
+)
+
+// warningDescriptions describes each warning code in one sentence, e.g. for SARIF rules.
+var warningDescriptions = map[string]string{
+	WarningInvalidExistingFile:   "An existing file could not be parsed and is replaced instead of merged.",
+	WarningIgnoredMCPServer:      "An MCP server without a transport type is left out.",
+	WarningDroppedPermission:     "A permission of an unknown or unsupported kind is left out.",
+	WarningSuspiciousPermission:  "A permission pattern will most likely never match.",
+	WarningUnsupportedFeature:    "The target IDE cannot represent part of the recipe.",
+	WarningDebugDumpFailed:       "The generation context debug dump could not be written.",
+	WarningInvalidState:          "The incremental state could not be read; all sources are fetched again.",
+	WarningStateNotSaved:         "The incremental state could not be written; the next run fetches everything again.",
+	WarningReplacedGitHook:       "A git hook not installed by adcp is replaced.",
+	WarningSkippedImport:         "Existing IDE configuration is not, or only partly, imported into a recipe.",
+	WarningNonPortablePath:       "A file is written to a path that cannot be created on every OS.",
+	WarningConflictingPermission: "A permission is both allowed and denied; the deny rule wins.",
+	WarningUnpinnedSource:        "A GitHub source is not pinned to a commit, so its content may change between runs.",
+	WarningOversizedEntry:        "A materialized file is larger than agents can usefully read.",
+	WarningSkippedUserFile:       "Configuration the IDE only reads from the home directory is not written, as user-level files are not enabled.",
+}
+
+// WarningDescription returns a one-sentence description of the warning code, or "" if it is unknown.
+func WarningDescription(code string) string {
+	return warningDescriptions[code]
+}
+
+// Warning describes a non-fatal problem: materialization succeeded, but the result may not be what
+// the user expects.
+type Warning struct {
+	Code string `json:"code"`
+	// Path is the affected file, if any.
+	Path string `json:"path,omitempty"`
+	// Feature is the recipe feature that cannot be represented, for WarningUnsupportedFeature,
+	// e.g. "commands" or "mcp".
+	Feature string `json:"feature,omitempty"`
+	Message string `json:"message"`
+}
+
+// Warn logs w and records it in the Report carried by ctx, if any.
+func Warn(ctx context.Context, w Warning) {
+	args := []any{"code", w.Code}
+	if w.Path != "" {
+		args = append(args, "path", w.Path)
+	}
+	if w.Feature != "" {
+		args = append(args, "feature", w.Feature)
+	}
+	Logger(ctx).Warn(w.Message, args...)
+	ReportFrom(ctx).AddWarning(w)
+}